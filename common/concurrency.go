@@ -0,0 +1,77 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrVersionConflict is returned when a record was modified since the client read it
+	ErrVersionConflict = errors.New("record was modified by someone else")
+	// ErrPreconditionRequired is returned when an update carries neither If-Match nor version
+	ErrPreconditionRequired = errors.New("If-Match header or version field is required")
+	// ErrInvalidPrecondition is returned when If-Match cannot be parsed
+	ErrInvalidPrecondition = errors.New("invalid If-Match header")
+)
+
+// ETag formats a record version as an entity tag
+func ETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// Precondition is the version a client expects to be modifying
+type Precondition struct {
+	Version    int
+	Any        bool // If-Match: * matches any current version
+	FromHeader bool
+}
+
+// ParsePrecondition reads the expected version from the If-Match header,
+// falling back to the version field of the request body
+func ParsePrecondition(c *gin.Context, bodyVersion *int) (*Precondition, error) {
+	if header := strings.TrimSpace(c.GetHeader("If-Match")); header != "" {
+		if header == "*" {
+			return &Precondition{Any: true, FromHeader: true}, nil
+		}
+		// Only the first tag matters since we issue a single ETag per record
+		tag := strings.TrimSpace(strings.Split(header, ",")[0])
+		tag = strings.Trim(strings.TrimPrefix(tag, "W/"), `"`)
+		version, err := strconv.Atoi(tag)
+		if err != nil {
+			return nil, ErrInvalidPrecondition
+		}
+		return &Precondition{Version: version, FromHeader: true}, nil
+	}
+
+	if bodyVersion != nil {
+		return &Precondition{Version: *bodyVersion}, nil
+	}
+
+	return nil, ErrPreconditionRequired
+}
+
+// Matches checks if the precondition holds for the current version
+func (p *Precondition) Matches(version int) bool {
+	return p.Any || p.Version == version
+}
+
+// ConflictStatus is 412 when the client used If-Match and 409 when it sent a version field
+func (p *Precondition) ConflictStatus() int {
+	if p.FromHeader {
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusConflict
+}
+
+// PreconditionErrorStatus maps errors from ParsePrecondition to HTTP statuses
+func PreconditionErrorStatus(err error) int {
+	if errors.Is(err, ErrPreconditionRequired) {
+		return http.StatusPreconditionRequired
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParsePrecondition(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifMatch     string
+		bodyVersion *int
+		want        *Precondition
+		err         error
+		status      int // conflict status of the precondition, or error status
+	}{
+		{"strong tag", `"3"`, nil, &Precondition{Version: 3, FromHeader: true}, nil, http.StatusPreconditionFailed},
+		{"weak tag", `W/"3"`, nil, &Precondition{Version: 3, FromHeader: true}, nil, http.StatusPreconditionFailed},
+		{"unquoted", ` 3 `, nil, &Precondition{Version: 3, FromHeader: true}, nil, http.StatusPreconditionFailed},
+		{"first of a list", `"4", "5"`, nil, &Precondition{Version: 4, FromHeader: true}, nil, http.StatusPreconditionFailed},
+		{"any", `*`, nil, &Precondition{Any: true, FromHeader: true}, nil, http.StatusPreconditionFailed},
+		{"header wins over body", `"3"`, intPtr(7), &Precondition{Version: 3, FromHeader: true}, nil, http.StatusPreconditionFailed},
+		{"body version", "", intPtr(7), &Precondition{Version: 7}, nil, http.StatusConflict},
+		{"body version zero", "", intPtr(0), &Precondition{Version: 0}, nil, http.StatusConflict},
+		{"not a number", `"abc"`, intPtr(7), nil, ErrInvalidPrecondition, http.StatusBadRequest},
+		{"missing", "", nil, nil, ErrPreconditionRequired, http.StatusPreconditionRequired},
+		{"blank header", "   ", nil, nil, ErrPreconditionRequired, http.StatusPreconditionRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			got, err := ParsePrecondition(c, tt.bodyVersion)
+			if err != tt.err {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				if status := PreconditionErrorStatus(err); status != tt.status {
+					t.Fatalf("got status %d, want %d", status, tt.status)
+				}
+				return
+			}
+			if *got != *tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			if status := got.ConflictStatus(); status != tt.status {
+				t.Fatalf("got conflict status %d, want %d", status, tt.status)
+			}
+		})
+	}
+}
+
+func TestPreconditionMatches(t *testing.T) {
+	tests := []struct {
+		name         string
+		precondition Precondition
+		version      int
+		want         bool
+	}{
+		{"same version", Precondition{Version: 3}, 3, true},
+		{"older version", Precondition{Version: 2}, 3, false},
+		{"newer version", Precondition{Version: 4}, 3, false},
+		{"any", Precondition{Any: true}, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.precondition.Matches(tt.version); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestETag(t *testing.T) {
+	if got := ETag(12); got != `"12"` {
+		t.Fatalf(`got %s, want "12"`, got)
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,51 @@
+package common
+
+import (
+	"errors"
+	"time"
+)
+
+// DateLayout is the date-only format used by query parameters
+const DateLayout = "2006-01-02"
+
+// ParseTimeParam parses a query value as RFC3339 timestamp or YYYY-MM-DD date (UTC midnight).
+// The second result reports whether the value was a date without time.
+func ParseTimeParam(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(DateLayout, value); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, errors.New("invalid date, expected YYYY-MM-DD or RFC3339")
+}
+
+// ParseDateRange parses optional from/to query values into a half-open range [from, to).
+// A date-only "to" is inclusive, so it is moved to the start of the next day.
+func ParseDateRange(fromStr, toStr string) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+
+	if fromStr != "" {
+		t, _, err := ParseTimeParam(fromStr)
+		if err != nil {
+			return nil, nil, errors.New("invalid from: expected YYYY-MM-DD or RFC3339")
+		}
+		from = &t
+	}
+
+	if toStr != "" {
+		t, dateOnly, err := ParseTimeParam(toStr)
+		if err != nil {
+			return nil, nil, errors.New("invalid to: expected YYYY-MM-DD or RFC3339")
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = &t
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, errors.New("from must be before to")
+	}
+	return from, to, nil
+}
@@ -0,0 +1,117 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrUnsupportedPatchType is returned when the body is not a JSON merge patch
+	ErrUnsupportedPatchType = errors.New("Content-Type must be application/merge-patch+json or application/json")
+	// ErrInvalidPatch is returned when the body is not a JSON object
+	ErrInvalidPatch = errors.New("merge patch must be a JSON object")
+)
+
+// MergePatch is an RFC 7396 JSON merge patch document.
+// A member set to null clears the field, an absent member leaves it unchanged.
+type MergePatch map[string]json.RawMessage
+
+// ParseMergePatch reads the request body as a JSON merge patch
+func ParseMergePatch(c *gin.Context) (MergePatch, error) {
+	if contentType := c.GetHeader("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != "application/merge-patch+json" && mediaType != "application/json") {
+			return nil, ErrUnsupportedPatchType
+		}
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		return nil, err
+	}
+
+	var patch MergePatch
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		return nil, ErrInvalidPatch
+	}
+	return patch, nil
+}
+
+// Has checks if the patch mentions the field, including explicit nulls
+func (p MergePatch) Has(field string) bool {
+	_, ok := p[field]
+	return ok
+}
+
+// IsNull checks if the patch explicitly sets the field to null
+func (p MergePatch) IsNull(field string) bool {
+	raw, ok := p[field]
+	return ok && bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// Decode unmarshals the patch into a request struct. Null members leave
+// the destination field at its zero value.
+func (p MergePatch) Decode(dest interface{}) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, dest)
+}
+
+// Version returns the "version" member, used as precondition when If-Match is not sent
+func (p MergePatch) Version() (*int, error) {
+	if !p.Has("version") || p.IsNull("version") {
+		return nil, nil
+	}
+	var version int
+	if err := json.Unmarshal(p["version"], &version); err != nil {
+		return nil, errors.New("version must be an integer")
+	}
+	return &version, nil
+}
+
+// CheckFields rejects members that are not in the allowed list
+func (p MergePatch) CheckFields(allowed ...string) error {
+	known := make(map[string]bool, len(allowed))
+	for _, field := range allowed {
+		known[field] = true
+	}
+
+	var unknown []string
+	for field := range p {
+		if !known[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown field(s): %v", unknown)
+	}
+	return nil
+}
+
+// CheckNotNull rejects explicit nulls for fields that cannot be cleared
+func (p MergePatch) CheckNotNull(fields ...string) error {
+	for _, field := range fields {
+		if p.IsNull(field) {
+			return fmt.Errorf("%s cannot be cleared", field)
+		}
+	}
+	return nil
+}
+
+// PatchErrorStatus maps errors from ParseMergePatch to HTTP statuses
+func PatchErrorStatus(err error) int {
+	if errors.Is(err, ErrUnsupportedPatchType) {
+		return http.StatusUnsupportedMediaType
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,167 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// patchContext creates a request context with the body and Content-Type
+func patchContext(body, contentType string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(body))
+	if contentType != "" {
+		c.Request.Header.Set("Content-Type", contentType)
+	}
+	return c
+}
+
+func TestParseMergePatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		err         error
+		status      int
+		fields      int
+	}{
+		{"merge patch", `{"title": "Bón phân", "note": null}`, "application/merge-patch+json", nil, 0, 2},
+		{"json with charset", `{"title": "Bón phân"}`, "application/json; charset=utf-8", nil, 0, 1},
+		{"no content type", `{}`, "", nil, 0, 0},
+		{"json patch", `[{"op": "remove", "path": "/note"}]`, "application/json-patch+json", ErrUnsupportedPatchType, http.StatusUnsupportedMediaType, 0},
+		{"form", `title=x`, "application/x-www-form-urlencoded", ErrUnsupportedPatchType, http.StatusUnsupportedMediaType, 0},
+		{"array", `[]`, "application/merge-patch+json", ErrInvalidPatch, http.StatusBadRequest, 0},
+		{"null", `null`, "application/merge-patch+json", ErrInvalidPatch, http.StatusBadRequest, 0},
+		{"string", `"title"`, "application/merge-patch+json", ErrInvalidPatch, http.StatusBadRequest, 0},
+		{"malformed", `{"title":`, "application/merge-patch+json", ErrInvalidPatch, http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := ParseMergePatch(patchContext(tt.body, tt.contentType))
+			if err != tt.err {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				if status := PatchErrorStatus(err); status != tt.status {
+					t.Fatalf("got status %d, want %d", status, tt.status)
+				}
+				return
+			}
+			if len(patch) != tt.fields {
+				t.Fatalf("got %d fields, want %d", len(patch), tt.fields)
+			}
+		})
+	}
+}
+
+func TestMergePatchMembers(t *testing.T) {
+	patch, err := ParseMergePatch(patchContext(`{"title": "Tưới nước", "note": null, "money": 0, "tags": ["a"]}`, "application/merge-patch+json"))
+	if err != nil {
+		t.Fatalf("ParseMergePatch: %v", err)
+	}
+
+	tests := []struct {
+		field string
+		has   bool
+		null  bool
+	}{
+		{"title", true, false},
+		{"note", true, true},
+		{"money", true, false},
+		{"tags", true, false},
+		{"unit", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if patch.Has(tt.field) != tt.has || patch.IsNull(tt.field) != tt.null {
+				t.Fatalf("got has %v null %v, want has %v null %v", patch.Has(tt.field), patch.IsNull(tt.field), tt.has, tt.null)
+			}
+		})
+	}
+}
+
+func TestMergePatchDecode(t *testing.T) {
+	type request struct {
+		Title *string  `json:"title"`
+		Note  *string  `json:"note"`
+		Money *float64 `json:"money"`
+		Unit  *string  `json:"unit"`
+	}
+	note := "cũ"
+	unit := "kg"
+	// Fields missing from the patch keep their value, nulls clear them
+	dest := request{Note: &note, Unit: &unit}
+
+	patch := MergePatch{"title": []byte(`"mới"`), "note": []byte(`null`), "money": []byte(`12.5`)}
+	if err := patch.Decode(&dest); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if dest.Title == nil || *dest.Title != "mới" {
+		t.Fatalf("got title %v, want mới", dest.Title)
+	}
+	if dest.Note != nil {
+		t.Fatalf("got note %q, want it cleared", *dest.Note)
+	}
+	if dest.Money == nil || *dest.Money != 12.5 {
+		t.Fatalf("got money %v, want 12.5", dest.Money)
+	}
+	if dest.Unit == nil || *dest.Unit != "kg" {
+		t.Fatalf("got unit %v, want it unchanged", dest.Unit)
+	}
+}
+
+func TestMergePatchVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   MergePatch
+		want    *int
+		wantErr bool
+	}{
+		{"absent", MergePatch{}, nil, false},
+		{"null", MergePatch{"version": []byte(`null`)}, nil, false},
+		{"integer", MergePatch{"version": []byte(`3`)}, intPtr(3), false},
+		{"string", MergePatch{"version": []byte(`"3"`)}, nil, true},
+		{"fraction", MergePatch{"version": []byte(`3.5`)}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.patch.Version()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePatchChecks(t *testing.T) {
+	patch := MergePatch{"title": []byte(`null`), "zeta": []byte(`1`), "alpha": []byte(`2`), "note": []byte(`"x"`)}
+
+	tests := []struct {
+		name  string
+		check func() error
+		want  string
+	}{
+		{"all fields allowed", func() error { return patch.CheckFields("title", "zeta", "alpha", "note") }, ""},
+		{"unknown fields sorted", func() error { return patch.CheckFields("title", "note") }, "unknown field(s): [alpha zeta]"},
+		{"nullable", func() error { return patch.CheckNotNull("note", "unit") }, ""},
+		{"not nullable", func() error { return patch.CheckNotNull("note", "title") }, "title cannot be cleared"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.check()
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
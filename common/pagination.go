@@ -0,0 +1,219 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor is returned when a cursor cannot be decoded or belongs to another sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the decoded form of an opaque pagination cursor.
+// It holds the sort key and id of the row a page starts after (or before, for prev cursors).
+type Cursor struct {
+	Sort  string      `json:"s"`
+	Kind  string      `json:"k"` // time, number, string or null
+	Value interface{} `json:"v,omitempty"`
+	ID    string      `json:"id"`
+	Prev  bool        `json:"p,omitempty"`
+}
+
+// CursorPage holds the cursors of a keyset paginated response, nil when there is no such page
+type CursorPage struct {
+	NextCursor *string `json:"next_cursor"`
+	PrevCursor *string `json:"prev_cursor"`
+	Limit      int     `json:"limit"`
+}
+
+// Keyset orders a list by one column, ties are broken by the id.
+// Null values sort last in both directions.
+type Keyset struct {
+	Column string
+	Desc   bool
+}
+
+// sortKey identifies the order a cursor was created for
+func (k Keyset) sortKey() string {
+	if k.Desc {
+		return k.Column + ":desc"
+	}
+	return k.Column + ":asc"
+}
+
+// ParseCursor decodes a cursor created for the keyset, an empty string is the first page
+func (k Keyset) ParseCursor(encoded string) (*Cursor, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" || cursor.Sort != k.sortKey() {
+		return nil, ErrInvalidCursor
+	}
+
+	switch cursor.Kind {
+	case "time":
+		s, ok := cursor.Value.(string)
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		cursor.Value = t
+	case "number":
+		if _, ok := cursor.Value.(float64); !ok {
+			return nil, ErrInvalidCursor
+		}
+	case "string":
+		if _, ok := cursor.Value.(string); !ok {
+			return nil, ErrInvalidCursor
+		}
+	case "null":
+		cursor.Value = nil
+	default:
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// encodeCursor creates the cursor of a row, value is the row's sort key
+func (k Keyset) encodeCursor(value interface{}, id string, prev bool) string {
+	cursor := Cursor{Sort: k.sortKey(), ID: id, Prev: prev}
+	switch v := value.(type) {
+	case nil:
+		cursor.Kind = "null"
+	case *time.Time:
+		return k.encodeCursor(derefTime(v), id, prev)
+	case *float64:
+		return k.encodeCursor(derefFloat(v), id, prev)
+	case *string:
+		return k.encodeCursor(derefString(v), id, prev)
+	case time.Time:
+		cursor.Kind, cursor.Value = "time", v.Format(time.RFC3339Nano)
+	case float64:
+		cursor.Kind, cursor.Value = "number", v
+	case int:
+		cursor.Kind, cursor.Value = "number", float64(v)
+	default:
+		cursor.Kind, cursor.Value = "string", v
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Apply orders the query by the keyset, starts it at the cursor and fetches one row
+// more than limit so Page can tell whether another page follows.
+// Prev cursors read the list backwards, Page puts the rows back in order.
+func (k Keyset) Apply(query *gorm.DB, cursor *Cursor, limit int) *gorm.DB {
+	backward := cursor != nil && cursor.Prev
+	// Rows after the cursor are smaller for descending order, read backwards it flips
+	less := k.Desc != backward
+
+	if cursor != nil {
+		sql, values := k.condition(cursor, less)
+		query = query.Where(sql, values...)
+	}
+
+	direction, nulls := "ASC", "NULLS LAST"
+	if less {
+		direction = "DESC"
+	}
+	if backward {
+		nulls = "NULLS FIRST"
+	}
+	return query.Order(k.Column + " " + direction + " " + nulls).Order("id " + direction).Limit(limit + 1)
+}
+
+// condition selects the rows that come after the cursor in the read direction
+func (k Keyset) condition(cursor *Cursor, less bool) (string, []interface{}) {
+	op := ">"
+	if less {
+		op = "<"
+	}
+	// Nulls are last, so reading forwards they follow any value
+	nullsAhead := !cursor.Prev
+
+	if cursor.Value == nil {
+		if nullsAhead {
+			return "(" + k.Column + " IS NULL AND id " + op + " ?)", []interface{}{cursor.ID}
+		}
+		return "(" + k.Column + " IS NOT NULL OR (" + k.Column + " IS NULL AND id " + op + " ?))", []interface{}{cursor.ID}
+	}
+	sql := k.Column + " " + op + " ? OR (" + k.Column + " = ? AND id " + op + " ?)"
+	if nullsAhead {
+		sql += " OR " + k.Column + " IS NULL"
+	}
+	return "(" + sql + ")", []interface{}{cursor.Value, cursor.Value, cursor.ID}
+}
+
+// Page trims the extra row fetched by Apply, restores the order of backward reads
+// and creates the next and prev cursors. key returns the sort value and id of a row.
+func Page[T any](rows []T, cursor *Cursor, limit int, keyset Keyset, key func(row *T) (interface{}, string)) ([]T, CursorPage) {
+	page := CursorPage{Limit: limit}
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+
+	hasNext, hasPrev := more, cursor != nil
+	if cursor != nil && cursor.Prev {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+		hasNext, hasPrev = true, more
+	}
+
+	if len(rows) == 0 {
+		return rows, page
+	}
+	if hasNext {
+		value, id := key(&rows[len(rows)-1])
+		next := keyset.encodeCursor(value, id, false)
+		page.NextCursor = &next
+	}
+	if hasPrev {
+		value, id := key(&rows[0])
+		prev := keyset.encodeCursor(value, id, true)
+		page.PrevCursor = &prev
+	}
+	return rows, page
+}
+
+// derefTime, derefFloat and derefString turn a nil pointer into an untyped nil
+func derefTime(v *time.Time) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func derefFloat(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func derefString(v *string) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// CursorRequested checks if the client asked for keyset pagination with the cursor
+// query parameter, an empty cursor requests the first page. Without it lists use page/limit.
+func CursorRequested(c *gin.Context) bool {
+	_, ok := c.GetQuery("cursor")
+	return ok
+}
@@ -0,0 +1,196 @@
+package common
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKeysetCursorRoundTrip(t *testing.T) {
+	keyset := Keyset{Column: "created_at", Desc: true}
+	at := time.Date(2025, 3, 5, 7, 30, 0, 123456789, time.UTC)
+	money := 1500.5
+	name := "Lúa"
+
+	tests := []struct {
+		name  string
+		value interface{}
+		prev  bool
+		kind  string
+		want  interface{}
+	}{
+		{"time", at, false, "time", at},
+		{"time pointer", &at, false, "time", at},
+		{"nil time pointer", (*time.Time)(nil), false, "null", nil},
+		{"number", 42.25, false, "number", 42.25},
+		{"int", 7, false, "number", 7.0},
+		{"number pointer", &money, true, "number", money},
+		{"nil number pointer", (*float64)(nil), false, "null", nil},
+		{"string", "abc", false, "string", "abc"},
+		{"string pointer", &name, true, "string", name},
+		{"nil string pointer", (*string)(nil), true, "null", nil},
+		{"nil", nil, false, "null", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := keyset.encodeCursor(tt.value, "row-1", tt.prev)
+			cursor, err := keyset.ParseCursor(encoded)
+			if err != nil {
+				t.Fatalf("ParseCursor: %v", err)
+			}
+			if cursor.Kind != tt.kind || cursor.ID != "row-1" || cursor.Prev != tt.prev {
+				t.Fatalf("got kind %q id %q prev %v", cursor.Kind, cursor.ID, cursor.Prev)
+			}
+			if want, ok := tt.want.(time.Time); ok {
+				got, ok := cursor.Value.(time.Time)
+				if !ok || !got.Equal(want) {
+					t.Fatalf("got value %v, want %v", cursor.Value, want)
+				}
+				return
+			}
+			if !reflect.DeepEqual(cursor.Value, tt.want) {
+				t.Fatalf("got value %#v, want %#v", cursor.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeysetParseCursorInvalid(t *testing.T) {
+	keyset := Keyset{Column: "created_at"}
+	raw := func(json string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(json))
+	}
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"not base64", "***"},
+		{"not json", raw("cursor")},
+		{"other column", Keyset{Column: "name"}.encodeCursor("a", "row-1", false)},
+		{"other direction", Keyset{Column: "created_at", Desc: true}.encodeCursor(time.Now(), "row-1", false)},
+		{"missing id", raw(`{"s":"created_at:asc","k":"null"}`)},
+		{"unknown kind", raw(`{"s":"created_at:asc","k":"bool","v":true,"id":"row-1"}`)},
+		{"bad time", raw(`{"s":"created_at:asc","k":"time","v":"yesterday","id":"row-1"}`)},
+		{"time not a string", raw(`{"s":"created_at:asc","k":"time","v":5,"id":"row-1"}`)},
+		{"number not a number", raw(`{"s":"created_at:asc","k":"number","v":"5","id":"row-1"}`)},
+		{"string not a string", raw(`{"s":"created_at:asc","k":"string","v":5,"id":"row-1"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := keyset.ParseCursor(tt.encoded); err != ErrInvalidCursor {
+				t.Fatalf("got %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+
+	cursor, err := keyset.ParseCursor("")
+	if cursor != nil || err != nil {
+		t.Fatalf("empty cursor: got %v, %v, want the first page", cursor, err)
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	keyset := Keyset{Column: "money"}
+
+	tests := []struct {
+		name   string
+		cursor Cursor
+		less   bool
+		sql    string
+		values []interface{}
+	}{
+		{
+			"forward after a value",
+			Cursor{Value: 5.0, ID: "b"}, false,
+			"(money > ? OR (money = ? AND id > ?) OR money IS NULL)", []interface{}{5.0, 5.0, "b"},
+		},
+		{
+			"backward before a value",
+			Cursor{Value: 5.0, ID: "b", Prev: true}, true,
+			"(money < ? OR (money = ? AND id < ?))", []interface{}{5.0, 5.0, "b"},
+		},
+		{
+			"forward after a null",
+			Cursor{ID: "b"}, false,
+			"(money IS NULL AND id > ?)", []interface{}{"b"},
+		},
+		{
+			"backward before a null",
+			Cursor{ID: "b", Prev: true}, true,
+			"(money IS NOT NULL OR (money IS NULL AND id < ?))", []interface{}{"b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, values := keyset.condition(&tt.cursor, tt.less)
+			if sql != tt.sql {
+				t.Fatalf("got %q, want %q", sql, tt.sql)
+			}
+			if !reflect.DeepEqual(values, tt.values) {
+				t.Fatalf("got values %v, want %v", values, tt.values)
+			}
+		})
+	}
+}
+
+func TestPage(t *testing.T) {
+	keyset := Keyset{Column: "name"}
+	key := func(row *string) (interface{}, string) {
+		return *row, *row
+	}
+	forward := &Cursor{Sort: keyset.sortKey(), Kind: "string", Value: "a", ID: "a"}
+	backward := &Cursor{Sort: keyset.sortKey(), Kind: "string", Value: "z", ID: "z", Prev: true}
+
+	tests := []struct {
+		name     string
+		rows     []string
+		cursor   *Cursor
+		want     []string
+		nextFrom string // row the next cursor starts after, empty for none
+		prevFrom string // row the prev cursor starts before, empty for none
+	}{
+		{"first page with more", []string{"b", "c", "d"}, nil, []string{"b", "c"}, "c", ""},
+		{"first and last page", []string{"b", "c"}, nil, []string{"b", "c"}, "", ""},
+		{"middle page", []string{"b", "c", "d"}, forward, []string{"b", "c"}, "c", "b"},
+		{"last page", []string{"b"}, forward, []string{"b"}, "", "b"},
+		{"backward with more", []string{"y", "x", "w"}, backward, []string{"x", "y"}, "y", "x"},
+		{"backward to the first page", []string{"y", "x"}, backward, []string{"x", "y"}, "y", ""},
+		{"empty", nil, forward, nil, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, page := Page(append([]string(nil), tt.rows...), tt.cursor, 2, keyset, key)
+			if len(rows) != len(tt.want) || (len(rows) > 0 && !reflect.DeepEqual(rows, tt.want)) {
+				t.Fatalf("got rows %v, want %v", rows, tt.want)
+			}
+			if page.Limit != 2 {
+				t.Fatalf("got limit %d, want 2", page.Limit)
+			}
+			checkPageCursor(t, "next", keyset, page.NextCursor, tt.nextFrom, false)
+			checkPageCursor(t, "prev", keyset, page.PrevCursor, tt.prevFrom, true)
+		})
+	}
+}
+
+// checkPageCursor checks a cursor of a page starts at the row, or is nil for an empty row
+func checkPageCursor(t *testing.T, name string, keyset Keyset, encoded *string, row string, prev bool) {
+	t.Helper()
+	if row == "" {
+		if encoded != nil {
+			t.Fatalf("got a %s cursor, want none", name)
+		}
+		return
+	}
+	if encoded == nil {
+		t.Fatalf("got no %s cursor, want one from %q", name, row)
+	}
+	cursor, err := keyset.ParseCursor(*encoded)
+	if err != nil {
+		t.Fatalf("%s cursor: %v", name, err)
+	}
+	if cursor.ID != row || cursor.Value != row || cursor.Prev != prev {
+		t.Fatalf("got %s cursor %+v, want row %q", name, cursor, row)
+	}
+}
@@ -0,0 +1,108 @@
+package common
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// OrganizationHeader picks the organization a request works in, without it the request
+// works on the user's own data
+const OrganizationHeader = "X-Organization-ID"
+
+// Roles of a member in an organization, from most to least privileged
+const (
+	OrgRoleOwner   = "owner"
+	OrgRoleManager = "manager" // Manages plots, inventory and members below manager
+	OrgRoleWorker  = "worker"  // Records activities
+	OrgRoleViewer  = "viewer"  // Read only
+)
+
+// orgRoleRanks orders the organization roles
+var orgRoleRanks = map[string]int{
+	OrgRoleViewer:  1,
+	OrgRoleWorker:  2,
+	OrgRoleManager: 3,
+	OrgRoleOwner:   4,
+}
+
+// IsOrgRole checks if role is an organization role
+func IsOrgRole(role string) bool {
+	_, ok := orgRoleRanks[role]
+	return ok
+}
+
+// OrgRoleAtLeast checks if role is as privileged as min
+func OrgRoleAtLeast(role, min string) bool {
+	return orgRoleRanks[role] >= orgRoleRanks[min]
+}
+
+// Tenant is the data space of a request: the current user's own data,
+// or the organization picked with the X-Organization-ID header
+type Tenant struct {
+	UserID         string
+	OrganizationID string // Empty for the user's own data
+	Role           string // Role of the user in the organization
+}
+
+// CurrentTenant returns the tenant set by the authentication and organization middlewares
+func CurrentTenant(c *gin.Context) Tenant {
+	return Tenant{
+		UserID:         c.GetString("user_id"),
+		OrganizationID: c.GetString("organization_id"),
+		Role:           c.GetString("organization_role"),
+	}
+}
+
+// InOrganization checks if the request works in an organization
+func (t Tenant) InOrganization() bool {
+	return t.OrganizationID != ""
+}
+
+// Allows checks if the user may act with the given organization role,
+// users always have every right on their own data
+func (t Tenant) Allows(role string) bool {
+	return !t.InOrganization() || OrgRoleAtLeast(t.Role, role)
+}
+
+// OrganizationRef returns the organization to stamp on new records, nil for the user's own data
+func (t Tenant) OrganizationRef() *string {
+	if !t.InOrganization() {
+		return nil
+	}
+	organizationID := t.OrganizationID
+	return &organizationID
+}
+
+// Scope restricts query to the records of the tenant. The table must have
+// user_id and organization_id columns, records of an organization keep the user who created them.
+func (t Tenant) Scope(query *gorm.DB) *gorm.DB {
+	if t.InOrganization() {
+		return query.Where("organization_id = ?", t.OrganizationID)
+	}
+	return query.Where("user_id = ? AND organization_id IS NULL", t.UserID)
+}
+
+// Owns checks if a record with the given creator and organization belongs to the tenant
+func (t Tenant) Owns(userID string, organizationID *string) bool {
+	if t.InOrganization() {
+		return organizationID != nil && *organizationID == t.OrganizationID
+	}
+	return organizationID == nil && userID == t.UserID
+}
+
+// Sees checks if a shared record of the given organization, nil for none, is visible in the tenant.
+// Records of an organization are only listed and served inside it.
+func (t Tenant) Sees(organizationID *string) bool {
+	if organizationID == nil {
+		return !t.InOrganization()
+	}
+	return *organizationID == t.OrganizationID
+}
+
+// ScopeShared restricts query to the shared records visible in the tenant, see Sees
+func (t Tenant) ScopeShared(query *gorm.DB) *gorm.DB {
+	if t.InOrganization() {
+		return query.Where("organization_id = ?", t.OrganizationID)
+	}
+	return query.Where("organization_id IS NULL")
+}
@@ -0,0 +1,52 @@
+package common
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	// Embed the timezone database so zones resolve on hosts without tzdata
+	_ "time/tzdata"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrInvalidTimezone is returned for names that are not IANA timezones
+var ErrInvalidTimezone = errors.New("invalid timezone, expected an IANA name such as Asia/Ho_Chi_Minh")
+
+// LoadTimezone loads an IANA timezone, an empty name is UTC
+func LoadTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC, nil
+	}
+	// Local depends on the server, clients must name their zone
+	if name == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
+// RequestTimezone returns the timezone days and months are computed in: the tz query
+// parameter, else the current user's profile timezone, else UTC
+func RequestTimezone(c *gin.Context) (*time.Location, error) {
+	if tz := c.Query("tz"); tz != "" {
+		return LoadTimezone(tz)
+	}
+	if tz := c.GetString("user_timezone"); tz != "" {
+		if loc, err := LoadTimezone(tz); err == nil {
+			return loc, nil
+		}
+	}
+	return time.UTC, nil
+}
+
+// StartOfDay returns midnight of the day t is on in loc
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
@@ -1,6 +1,8 @@
 package common
 
 import (
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -62,3 +64,12 @@ func ValidateJWT(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
+
+
+// GetEnvInt reads an integer environment variable, falling back to def when unset or invalid
+func GetEnvInt(key string, def int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return def
+}
@@ -3,11 +3,23 @@ package main
 import (
 	"log"
 	"os"
+	"time"
 
 	"plantheon-backend/common"
 	"plantheon-backend/models/activities"
+	"plantheon-backend/models/contacts"
 	"plantheon-backend/models/diseases"
+	"plantheon-backend/models/harvests"
+	"plantheon-backend/models/inventory"
+	"plantheon-backend/models/media"
+	"plantheon-backend/models/organizations"
+	"plantheon-backend/models/plots"
+	"plantheon-backend/models/seasons"
+	"plantheon-backend/models/sync"
+	"plantheon-backend/models/templates"
+	"plantheon-backend/models/units"
 	"plantheon-backend/models/users"
+	"plantheon-backend/models/workers"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
@@ -22,10 +34,33 @@ func main() {
 	db := common.Init()
 
 	// Auto migrate database tables
-	err := db.AutoMigrate(&users.User{}, &diseases.Disease{}, &activities.Activity{})
+	err := db.AutoMigrate(&users.User{}, &users.Role{}, &users.RoleChange{}, &diseases.Disease{}, &activities.Activity{}, &media.Media{}, &media.MediaReference{}, &plots.Plot{}, &plots.Diagnosis{}, &seasons.Season{}, &harvests.Harvest{}, &inventory.Item{}, &inventory.Movement{}, &units.CustomUnit{}, &templates.Template{}, &templates.TemplateItem{}, &workers.Worker{}, &workers.WorkSession{}, &workers.Payment{}, &activities.ActivityAssignee{}, &activities.ChecklistItem{}, &activities.Attachment{}, &contacts.Contact{}, &organizations.Organization{}, &organizations.Membership{}, &organizations.Invitation{})
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
+	if err := users.SeedRoles(); err != nil {
+		log.Fatal("Failed to create built-in roles:", err)
+	}
+
+	// go run main.go create-admin -email ... creates or promotes an admin and exits
+	if len(os.Args) > 1 && os.Args[1] == "create-admin" {
+		if err := users.RunCreateAdminCommand(os.Args[2:]); err != nil {
+			log.Fatal("Failed to create admin: ", err)
+		}
+		return
+	}
+
+	// Initialize media storage (local filesystem or S3-compatible)
+	media.InitStorage()
+	// Remove uploads that were never attached to a disease or profile
+	media.StartOrphanCleanup(time.Hour, 24*time.Hour)
+
+	// Permanently delete diseases and activities kept in trash longer than TRASH_RETENTION_DAYS
+	trashRetention := time.Duration(common.GetEnvInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour
+	diseases.StartTrashPurge(time.Hour, trashRetention)
+	activities.StartTrashPurge(time.Hour, trashRetention)
+	// Sync tokens older than the retention may miss purged deletes, those clients resync from scratch
+	sync.SetTombstoneRetention(trashRetention)
 
 	// Set up Gin router
 	router := gin.Default()
@@ -33,8 +68,9 @@ func main() {
 	// CORS middleware
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, If-Match, "+common.OrganizationHeader)
+		c.Header("Access-Control-Expose-Headers", "ETag")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -60,6 +96,8 @@ func main() {
 		{
 			auth.POST("/register", users.Register)
 			auth.POST("/login", users.Login)
+			// First admin with the one-time ADMIN_SETUP_TOKEN, disabled once an admin exists
+			auth.POST("/setup-admin", users.SetupAdminHandler)
 		}
 
 		// User routes (protected)
@@ -68,17 +106,36 @@ func main() {
 		{
 			userRoutes.GET("/profile", users.GetProfile)
 			userRoutes.PUT("/profile", users.UpdateProfile)
+			userRoutes.PATCH("/profile", users.PatchProfile)
+			userRoutes.POST("/profile/avatar", users.UploadAvatarHandler)
+		}
+
+		// Media routes
+		mediaRoutes := api.Group("/media")
+		{
+			// Public routes (uploaded images are served to anyone)
+			mediaRoutes.GET("/:id", media.GetMediaHandler)
+			mediaRoutes.GET("/:id/content", media.GetMediaContentHandler)
+			mediaRoutes.POST("", users.AuthMiddleware(), media.UploadMediaHandler)
+		}
+
+		// Unit registry routes, a token adds the user's custom packaging units
+		unitRoutes := api.Group("/units")
+		unitRoutes.Use(users.OptionalAuthMiddleware())
+		{
+			unitRoutes.GET("", units.GetUnitsHandler)
+			unitRoutes.GET("/convert", units.ConvertHandler)
+			unitRoutes.POST("", users.AuthMiddleware(), units.CreateCustomUnitHandler)
+			unitRoutes.DELETE("/:id", users.AuthMiddleware(), units.DeleteCustomUnitHandler)
 		}
 
 		// Admin-only user management routes
 		adminUserRoutes := api.Group("/admin/users")
-		adminUserRoutes.Use(users.RequireAdmin())
+		adminUserRoutes.Use(users.AuthMiddleware(), users.RequirePermission(users.PermUserManage))
 		{
-			// Add admin-only user management endpoints here
-			// adminUserRoutes.GET("", users.GetAllUsers)
-			// adminUserRoutes.GET("/:id", users.GetUserByIDHandler)  
-			// adminUserRoutes.PUT("/:id/role", users.UpdateUserRole)
-			// adminUserRoutes.DELETE("/:id", users.DeleteUserHandler)
+			adminUserRoutes.GET("", users.GetAllUsers)
+			adminUserRoutes.PUT("/:id/role", users.UpdateUserRoleHandler)
+			adminUserRoutes.GET("/:id/role-changes", users.GetUserRoleChangesHandler)
 		}
 
 		// Disease routes
@@ -88,40 +145,219 @@ func main() {
 			diseaseRoutes.GET("", diseases.GetDiseases)
 			diseaseRoutes.GET("/all", diseases.GetAllDiseasesHandler)
 			diseaseRoutes.GET("/count", diseases.GetDiseasesCountHandler)
+			diseaseRoutes.GET("/plants", diseases.GetPlantsHandler)
 			// diseaseRoutes.GET("/:id", diseases.GetDisease)
 			diseaseRoutes.GET("/:ClassName", diseases.GetDiseaseByClassNameHandler)
 		}
 
-		// Admin-only disease routes (require admin role)
+		// Disease catalog management routes (require the disease permissions)
 		adminDiseaseRoutes := api.Group("/diseases")
-		adminDiseaseRoutes.Use(users.RequireAdmin())
+		adminDiseaseRoutes.Use(users.AuthMiddleware())
+		{
+			canWrite := users.RequirePermission(users.PermDiseaseWrite)
+			canPublish := users.RequirePermission(users.PermDiseasePublish)
+			adminDiseaseRoutes.POST("", canWrite, diseases.CreateDiseaseHandler)
+			adminDiseaseRoutes.POST("/import-excel", canPublish, diseases.ImportDiseasesFromExcelHandler)
+			adminDiseaseRoutes.PUT("/:id", canWrite, diseases.UpdateDiseaseHandler)
+			adminDiseaseRoutes.PATCH("/:id", canWrite, diseases.PatchDiseaseHandler)
+			adminDiseaseRoutes.POST("/:id/images", canWrite, diseases.UploadDiseaseImageHandler)
+			adminDiseaseRoutes.GET("/trash", canPublish, diseases.GetTrashedDiseasesHandler)
+			adminDiseaseRoutes.POST("/:id/restore", canPublish, diseases.RestoreDiseaseHandler)
+			adminDiseaseRoutes.DELETE("/:ClassName", canPublish, diseases.DeleteDiseaseHandler)
+		}
+
+		// Role management routes (require the role:manage permission)
+		adminRoleRoutes := api.Group("/admin")
+		adminRoleRoutes.Use(users.AuthMiddleware(), users.RequirePermission(users.PermRoleManage))
 		{
-			adminDiseaseRoutes.POST("", diseases.CreateDiseaseHandler)
-			adminDiseaseRoutes.POST("/import-excel", diseases.ImportDiseasesFromExcelHandler)
-			adminDiseaseRoutes.PUT("/:id", diseases.UpdateDiseaseHandler)
-			adminDiseaseRoutes.DELETE("/:ClassName", diseases.DeleteDiseaseHandler)
+			adminRoleRoutes.GET("/permissions", users.GetPermissionsHandler)
+			adminRoleRoutes.GET("/roles", users.GetRolesHandler)
+			adminRoleRoutes.POST("/roles", users.CreateRoleHandler)
+			adminRoleRoutes.GET("/roles/:name", users.GetRoleHandler)
+			adminRoleRoutes.PUT("/roles/:name", users.UpdateRoleHandler)
+			adminRoleRoutes.DELETE("/roles/:name", users.DeleteRoleHandler)
 		}
 
 		// Activity routes
 		activityRoutes := api.Group("/activities")
+		// Token is optional, it links created activities to their owner and plots.
+		// The X-Organization-ID header works on the activities of an organization,
+		// users with activity:read:any read those of any organization.
+		activityRoutes.Use(users.OptionalAuthMiddleware(), organizations.TenantMiddleware(users.PermActivityReadAny))
 		{
-			// Public routes (anyone can view activities)
+			// Lists, changes and trash cover the caller's own activities, or those of the organization
 			activityRoutes.GET("", activities.GetActivities)
 			activityRoutes.GET("/all", activities.GetAllActivitiesHandler)
 			activityRoutes.GET("/count", activities.GetActivitiesCountHandler)
+			activityRoutes.GET("/export", activities.ExportActivitiesHandler)
+			activityRoutes.GET("/types", activities.GetActivityTypesHandler)
 			activityRoutes.GET("/get-activites-by-month", activities.GetActivitiesCalendarByMonthHandler)
 			activityRoutes.GET("/by-day", activities.GetActivitiesByDayHandler)
+			activityRoutes.GET("/calendar/week", activities.GetActivitiesCalendarByWeekHandler)
+			activityRoutes.GET("/calendar/agenda", activities.GetActivitiesAgendaHandler)
+			activityRoutes.GET("/free-busy", activities.GetFreeBusyHandler)
+			activityRoutes.GET("/trash", activities.GetTrashedActivitiesHandler)
+			activityRoutes.GET("/my-tasks/today", activities.GetMyTasksTodayHandler)
 			activityRoutes.GET("/:id", activities.GetActivity)
 			activityRoutes.POST("", activities.CreateActivityHandler)
+			activityRoutes.POST("/bulk/create", activities.BulkCreateActivitiesHandler)
+			activityRoutes.POST("/bulk/update", activities.BulkUpdateActivitiesHandler)
+			activityRoutes.POST("/bulk/delete", activities.BulkDeleteActivitiesHandler)
 			activityRoutes.PUT("/:id", activities.UpdateActivityHandler)
+			activityRoutes.PATCH("/:id", activities.PatchActivityHandler)
 			activityRoutes.DELETE("/:id", activities.DeleteActivityHandler)
+			activityRoutes.POST("/:id/restore", activities.RestoreActivityHandler)
+			activityRoutes.POST("/:id/duplicate", activities.DuplicateActivityHandler)
+			// Task tracking, for the owner and the assignees of an activity
+			activityRoutes.GET("/:id/task", activities.GetActivityTaskHandler)
+			activityRoutes.POST("/:id/status", activities.UpdateActivityStatusHandler)
+			activityRoutes.PUT("/:id/assignees", activities.SetActivityAssigneesHandler)
+			activityRoutes.POST("/:id/checklist", activities.CreateChecklistItemHandler)
+			activityRoutes.PATCH("/:id/checklist/:item_id", activities.UpdateChecklistItemHandler)
+			activityRoutes.DELETE("/:id/checklist/:item_id", activities.DeleteChecklistItemHandler)
+			activityRoutes.GET("/:id/attachments", activities.GetActivityAttachmentsHandler)
+			activityRoutes.POST("/:id/attachments", activities.UploadActivityAttachmentHandler)
+			activityRoutes.GET("/:id/attachments/:attachment_id/content", activities.GetActivityAttachmentContentHandler)
+			activityRoutes.DELETE("/:id/attachments/:attachment_id", activities.DeleteActivityAttachmentHandler)
+		}
+
+		// Plot routes (protected, each user manages their own plots, managers those of an organization)
+		plotRoutes := api.Group("/plots")
+		plotRoutes.Use(users.AuthMiddleware(), organizations.TenantMiddleware(""))
+		{
+			plotRoutes.GET("", plots.GetPlotsHandler)
+			plotRoutes.POST("", organizations.RequireOrgRole(common.OrgRoleManager), plots.CreatePlotHandler)
+			plotRoutes.GET("/:id", plots.GetPlotHandler)
+			plotRoutes.PUT("/:id", organizations.RequireOrgRole(common.OrgRoleManager), plots.UpdatePlotHandler)
+			plotRoutes.DELETE("/:id", organizations.RequireOrgRole(common.OrgRoleManager), plots.DeletePlotHandler)
+			plotRoutes.GET("/:id/timeline", plots.GetPlotTimelineHandler)
+			plotRoutes.GET("/:id/costs", plots.GetPlotCostsHandler)
+			plotRoutes.GET("/:id/diagnoses", plots.GetPlotDiagnosesHandler)
+			plotRoutes.POST("/:id/diagnoses", plots.CreateDiagnosisHandler)
+			plotRoutes.DELETE("/:id/diagnoses/:diagnosisId", plots.DeleteDiagnosisHandler)
+		}
+
+		// Crop season routes (protected, a season belongs to one of the user's plots)
+		seasonRoutes := api.Group("/seasons")
+		seasonRoutes.Use(users.AuthMiddleware())
+		{
+			seasonRoutes.GET("", seasons.GetSeasonsHandler)
+			seasonRoutes.POST("", seasons.CreateSeasonHandler)
+			seasonRoutes.GET("/:id", seasons.GetSeasonHandler)
+			seasonRoutes.PUT("/:id", seasons.UpdateSeasonHandler)
+			seasonRoutes.DELETE("/:id", seasons.DeleteSeasonHandler)
+			seasonRoutes.GET("/:id/activities", seasons.GetSeasonActivitiesHandler)
+			seasonRoutes.GET("/:id/dashboard", seasons.GetSeasonDashboardHandler)
+		}
+
+		// Harvest routes (protected, a harvest belongs to one of the user's plots)
+		harvestRoutes := api.Group("/harvests")
+		harvestRoutes.Use(users.AuthMiddleware())
+		{
+			harvestRoutes.GET("", harvests.GetHarvestsHandler)
+			harvestRoutes.POST("", harvests.CreateHarvestHandler)
+			harvestRoutes.GET("/:id", harvests.GetHarvestHandler)
+			harvestRoutes.PUT("/:id", harvests.UpdateHarvestHandler)
+			harvestRoutes.DELETE("/:id", harvests.DeleteHarvestHandler)
+		}
+
+		// Profitability analytics (protected)
+		analyticsRoutes := api.Group("/analytics")
+		analyticsRoutes.Use(users.AuthMiddleware())
+		{
+			analyticsRoutes.GET("/seasons", harvests.GetSeasonProfitsHandler)
+			analyticsRoutes.GET("/plots", harvests.GetPlotProfitsHandler)
+		}
+
+		// Inventory routes (protected, each user manages their own stock, managers that of an organization
+		// and its workers record movements)
+		inventoryRoutes := api.Group("/inventory")
+		inventoryRoutes.Use(users.AuthMiddleware(), organizations.TenantMiddleware(""))
+		{
+			inventoryRoutes.GET("/items", inventory.GetItemsHandler)
+			inventoryRoutes.POST("/items", organizations.RequireOrgRole(common.OrgRoleManager), inventory.CreateItemHandler)
+			inventoryRoutes.GET("/items/:id", inventory.GetItemHandler)
+			inventoryRoutes.PUT("/items/:id", organizations.RequireOrgRole(common.OrgRoleManager), inventory.UpdateItemHandler)
+			inventoryRoutes.DELETE("/items/:id", organizations.RequireOrgRole(common.OrgRoleManager), inventory.DeleteItemHandler)
+			inventoryRoutes.GET("/items/:id/movements", inventory.GetMovementsHandler)
+			inventoryRoutes.POST("/items/:id/movements", inventory.CreateMovementHandler)
+			inventoryRoutes.GET("/alerts", inventory.GetLowStockAlertsHandler)
+			inventoryRoutes.GET("/valuation", inventory.GetValuationHandler)
+		}
+
+		// Activity template routes (protected, each user keeps their own routines,
+		// they can be applied in an organization with the X-Organization-ID header)
+		templateRoutes := api.Group("/templates")
+		templateRoutes.Use(users.AuthMiddleware(), organizations.TenantMiddleware(""))
+		{
+			templateRoutes.GET("", templates.GetTemplatesHandler)
+			templateRoutes.POST("", templates.CreateTemplateHandler)
+			templateRoutes.POST("/from-activities", templates.CreateTemplateFromActivitiesHandler)
+			templateRoutes.GET("/:id", templates.GetTemplateHandler)
+			templateRoutes.DELETE("/:id", templates.DeleteTemplateHandler)
+			templateRoutes.POST("/:id/apply", templates.ApplyTemplateHandler)
 		}
 
-		// Admin-only activity routes (require admin role)
-		adminActivityRoutes := api.Group("/activities")
-		adminActivityRoutes.Use(users.RequireAdmin())
+		// Farm worker routes (protected, each user manages the workers of their farm)
+		workerRoutes := api.Group("/workers")
+		workerRoutes.Use(users.AuthMiddleware())
 		{
-			
+			workerRoutes.GET("", workers.GetWorkersHandler)
+			workerRoutes.POST("", workers.CreateWorkerHandler)
+			workerRoutes.GET("/payroll", workers.GetPayrollHandler)
+			workerRoutes.GET("/:id", workers.GetWorkerHandler)
+			workerRoutes.PUT("/:id", workers.UpdateWorkerHandler)
+			workerRoutes.DELETE("/:id", workers.DeleteWorkerHandler)
+			workerRoutes.GET("/:id/sessions", workers.GetWorkSessionsHandler)
+			workerRoutes.POST("/:id/sessions", workers.CreateWorkSessionHandler)
+			workerRoutes.PUT("/:id/sessions/:session_id", workers.UpdateWorkSessionHandler)
+			workerRoutes.DELETE("/:id/sessions/:session_id", workers.DeleteWorkSessionHandler)
+			workerRoutes.GET("/:id/payments", workers.GetPaymentsHandler)
+			workerRoutes.POST("/:id/payments", workers.CreatePaymentHandler)
+			workerRoutes.DELETE("/:id/payments/:payment_id", workers.DeletePaymentHandler)
+			workerRoutes.GET("/:id/payslip", workers.GetPayslipHandler)
+		}
+
+		// Contact routes (protected, each user keeps their own buyers, suppliers, workers and advisors)
+		contactRoutes := api.Group("/contacts")
+		contactRoutes.Use(users.AuthMiddleware())
+		{
+			contactRoutes.GET("", contacts.GetContactsHandler)
+			contactRoutes.POST("", contacts.CreateContactHandler)
+			contactRoutes.GET("/autocomplete", contacts.AutocompleteContactsHandler)
+			contactRoutes.GET("/:id", contacts.GetContactHandler)
+			contactRoutes.PUT("/:id", contacts.UpdateContactHandler)
+			contactRoutes.DELETE("/:id", contacts.DeleteContactHandler)
+			contactRoutes.POST("/:id/link-activities", contacts.LinkContactActivitiesHandler)
+			contactRoutes.GET("/:id/statement", contacts.GetContactStatementHandler)
+		}
+
+		// Organization routes (protected, farms shared by their members)
+		organizationRoutes := api.Group("/organizations")
+		organizationRoutes.Use(users.AuthMiddleware())
+		{
+			organizationRoutes.GET("", organizations.GetOrganizationsHandler)
+			organizationRoutes.POST("", organizations.CreateOrganizationHandler)
+			organizationRoutes.GET("/invitations", organizations.GetMyInvitationsHandler)
+			organizationRoutes.POST("/invitations/:invitation_id/accept", organizations.AcceptInvitationHandler)
+			organizationRoutes.POST("/invitations/:invitation_id/decline", organizations.DeclineInvitationHandler)
+			organizationRoutes.GET("/:id", organizations.GetOrganizationHandler)
+			organizationRoutes.PUT("/:id", organizations.UpdateOrganizationHandler)
+			organizationRoutes.DELETE("/:id", organizations.DeleteOrganizationHandler)
+			organizationRoutes.GET("/:id/members", organizations.GetMembersHandler)
+			organizationRoutes.PUT("/:id/members/:user_id", organizations.UpdateMemberHandler)
+			organizationRoutes.DELETE("/:id/members/:user_id", organizations.RemoveMemberHandler)
+			organizationRoutes.GET("/:id/invitations", organizations.GetInvitationsHandler)
+			organizationRoutes.POST("/:id/invitations", organizations.CreateInvitationHandler)
+			organizationRoutes.DELETE("/:id/invitations/:invitation_id", organizations.RevokeInvitationHandler)
+		}
+
+		// Offline sync routes (protected, activities and plots of the user or of the X-Organization-ID organization plus the disease catalog)
+		syncRoutes := api.Group("/sync")
+		syncRoutes.Use(users.AuthMiddleware(), organizations.TenantMiddleware(""))
+		{
+			syncRoutes.GET("", sync.PullHandler)
+			syncRoutes.POST("", sync.PushHandler)
 		}
 	}
 
@@ -137,31 +373,136 @@ func main() {
 	log.Printf("Auth routes:")
 	log.Printf("  POST /api/auth/register - Đăng ký tài khoản")
 	log.Printf("  POST /api/auth/login - Đăng nhập")
+	log.Printf("  POST /api/auth/setup-admin - Tạo admin đầu tiên bằng ADMIN_SETUP_TOKEN")
 	log.Printf("User routes (cần token):")
 	log.Printf("  GET  /api/users/profile - Xem profile")
 	log.Printf("  PUT  /api/users/profile - Cập nhật profile")
+	log.Printf("  PATCH /api/users/profile - Cập nhật một phần profile (merge patch)")
+	log.Printf("  POST /api/users/profile/avatar - Upload ảnh đại diện")
+	log.Printf("Admin user routes (cần quyền user:manage):")
+	log.Printf("  GET  /api/admin/users - Xem danh sách người dùng (page/limit hoặc cursor)")
+	log.Printf("  PUT  /api/admin/users/:id/role - Đổi vai trò người dùng (ghi lịch sử)")
+	log.Printf("  GET  /api/admin/users/:id/role-changes - Xem lịch sử đổi vai trò")
+	log.Printf("Admin role routes (cần quyền role:manage):")
+	log.Printf("  GET  /api/admin/permissions - Xem danh sách quyền")
+	log.Printf("  GET|POST /api/admin/roles - Xem, tạo vai trò (tập quyền)")
+	log.Printf("  GET|PUT|DELETE /api/admin/roles/:name - Xem, sửa quyền, xóa vai trò")
+	log.Printf("Media routes:")
+	log.Printf("  POST /api/media - Upload ảnh (cần token)")
+	log.Printf("  GET  /api/media/:id - Xem thông tin ảnh")
+	log.Printf("  GET  /api/media/:id/content?size=small|medium|large - Tải ảnh hoặc thumbnail")
+	log.Printf("Unit routes:")
+	log.Printf("  GET  /api/units?dimension=mass - Xem danh sách đơn vị (kèm đơn vị tự tạo nếu có token)")
+	log.Printf("  GET  /api/units/convert?value=&from=&to= - Quy đổi đơn vị")
+	log.Printf("  POST /api/units - Tạo đơn vị đóng gói (cần token)")
+	log.Printf("  DELETE /api/units/:id - Xóa đơn vị đóng gói (cần token)")
 	log.Printf("Disease routes (public):")
 	log.Printf("  GET  /api/diseases - Xem danh sách bệnh (có pagination, search, filter)")
 	log.Printf("  GET  /api/diseases/all - Xem tất cả bệnh (không pagination)")
 	log.Printf("  GET  /api/diseases/count - Xem số lượng bệnh")
+	log.Printf("  GET  /api/diseases/plants - Xem danh sách cây trồng")
 	log.Printf("  GET  /api/diseases/:id - Xem chi tiết bệnh")
 	log.Printf("  GET  /api/diseases/class/:className - Xem bệnh theo class name")
-	log.Printf("Disease routes (cần admin role):")
+	log.Printf("Disease routes (cần quyền disease:write):")
 	log.Printf("  POST /api/diseases - Tạo bệnh mới")
-	log.Printf("  POST /api/diseases/import-excel - Import nhiều bệnh từ Excel")
 	log.Printf("  PUT  /api/diseases/:id - Cập nhật bệnh")
-	log.Printf("  DELETE /api/diseases/:ClassName - Xóa bệnh")
+	log.Printf("  PATCH /api/diseases/:id - Cập nhật một phần bệnh (merge patch)")
+	log.Printf("  POST /api/diseases/:id/images - Upload ảnh bệnh")
+	log.Printf("Disease routes (cần quyền disease:publish):")
+	log.Printf("  POST /api/diseases/import-excel - Import nhiều bệnh từ Excel")
+	log.Printf("  DELETE /api/diseases/:ClassName - Chuyển bệnh vào thùng rác")
+	log.Printf("  GET  /api/diseases/trash - Xem thùng rác")
+	log.Printf("  POST /api/diseases/:id/restore - Khôi phục bệnh")
 	log.Printf("Activity routes (public):")
-	log.Printf("  GET  /api/activities - Xem danh sách hoạt động (có pagination, search, filter)")
+	log.Printf("  GET  /api/activities - Xem danh sách hoạt động (có pagination, search, filter, sort)")
 	log.Printf("  GET  /api/activities/all - Xem tất cả hoạt động (không pagination)")
 	log.Printf("  GET  /api/activities/count - Xem số lượng hoạt động")
+	log.Printf("  GET  /api/activities/export - Xuất hoạt động ra CSV/XLSX (cùng bộ lọc)")
+	log.Printf("  GET  /api/activities/types - Xem các loại hoạt động và trường của từng loại")
+	log.Printf("  GET  /api/activities/get-activites-by-month?year=&month=&tz= - Lịch theo tháng (tiêu đề)")
+	log.Printf("  GET  /api/activities/by-day?date=&tz= - Hoạt động trong ngày")
+	log.Printf("  GET  /api/activities/calendar/week?date=&tz= - Lịch theo tuần (chi tiết, gồm lặp lại)")
+	log.Printf("  GET  /api/activities/calendar/agenda?from=&to=&tz= - Lịch theo khoảng ngày (chi tiết, gồm lặp lại)")
+	log.Printf("  GET  /api/activities/free-busy?from=&to=&plot_id=&person= - Khoảng thời gian bận/rảnh")
 	log.Printf("  GET  /api/activities/:id - Xem chi tiết hoạt động")
 	log.Printf("Activity routes (cần admin role):")
-	log.Printf("  POST /api/activities - Tạo hoạt động mới")
-	log.Printf("  PUT  /api/activities/:id - Cập nhật hoạt động")
-	log.Printf("  DELETE /api/activities/:id - Xóa hoạt động")
-	log.Printf("Admin routes (cần admin role):")
-	log.Printf("  /api/admin/users/* - Quản lý người dùng (commented out)")
+	log.Printf("  POST /api/activities - Tạo hoạt động mới (?strict=true: từ chối nếu trùng lịch)")
+	log.Printf("  PUT  /api/activities/:id - Cập nhật hoạt động (?strict=true: từ chối nếu trùng lịch)")
+	log.Printf("  PATCH /api/activities/:id - Cập nhật một phần hoạt động (merge patch)")
+	log.Printf("  POST /api/activities/bulk/create|update|delete - Tạo, sửa, xóa hàng loạt (?dry_run=true: xem trước)")
+	log.Printf("  DELETE /api/activities/:id - Chuyển hoạt động vào thùng rác")
+	log.Printf("  GET  /api/activities/trash - Xem thùng rác")
+	log.Printf("  POST /api/activities/:id/restore - Khôi phục hoạt động")
+	log.Printf("  POST /api/activities/:id/duplicate - Nhân bản hoạt động (có thể dời ngày, đổi ruộng/mùa vụ)")
+	log.Printf("Task routes (cần token, chủ hoạt động hoặc người được giao):")
+	log.Printf("  GET  /api/activities/my-tasks/today?date=&tz= - Việc được giao trong ngày và việc quá hạn")
+	log.Printf("  GET  /api/activities/:id/task - Hoạt động kèm người được giao và checklist")
+	log.Printf("  POST /api/activities/:id/status - Đổi trạng thái (planned, in_progress, done, skipped)")
+	log.Printf("  PUT  /api/activities/:id/assignees - Giao việc cho người dùng hoặc nhân công")
+	log.Printf("  POST /api/activities/:id/checklist - Thêm mục checklist")
+	log.Printf("  PATCH|DELETE /api/activities/:id/checklist/:item_id - Đánh dấu, sửa, xóa mục checklist")
+	log.Printf("  GET|POST /api/activities/:id/attachments - Xem, đính kèm ảnh/PDF (hóa đơn, ảnh ruộng)")
+	log.Printf("  GET /api/activities/:id/attachments/:attachment_id/content - Tải tệp đính kèm (chủ hoặc người được giao)")
+	log.Printf("  DELETE /api/activities/:id/attachments/:attachment_id - Xóa tệp đính kèm")
+	log.Printf("Plot routes (cần token):")
+	log.Printf("  GET  /api/plots - Xem danh sách ruộng/vườn")
+	log.Printf("  POST /api/plots - Tạo ruộng/vườn mới")
+	log.Printf("  GET|PUT|DELETE /api/plots/:id - Xem, sửa, xóa ruộng/vườn")
+	log.Printf("  GET  /api/plots/:id/timeline - Dòng thời gian hoạt động và chẩn đoán")
+	log.Printf("  GET  /api/plots/:id/costs - Tổng chi phí theo loại hoạt động")
+	log.Printf("  GET|POST /api/plots/:id/diagnoses - Chẩn đoán bệnh trên ruộng")
+	log.Printf("Season routes (cần token):")
+	log.Printf("  GET  /api/seasons?plot_id=&status= - Xem danh sách mùa vụ")
+	log.Printf("  POST /api/seasons - Tạo mùa vụ mới")
+	log.Printf("  GET|PUT|DELETE /api/seasons/:id - Xem, sửa, xóa mùa vụ")
+	log.Printf("  GET  /api/seasons/:id/activities - Hoạt động của mùa vụ")
+	log.Printf("  GET  /api/seasons/:id/dashboard - Tổng hợp chi phí, công lao động, vật tư, bệnh và năng suất")
+	log.Printf("Harvest routes (cần token):")
+	log.Printf("  GET  /api/harvests?plot_id=&season_id=&from=&to= - Xem danh sách thu hoạch")
+	log.Printf("  POST /api/harvests - Ghi nhận thu hoạch")
+	log.Printf("  GET|PUT|DELETE /api/harvests/:id - Xem, sửa, xóa thu hoạch")
+	log.Printf("Analytics routes (cần token):")
+	log.Printf("  GET  /api/analytics/seasons - So sánh năng suất, doanh thu, chi phí, lợi nhuận theo mùa vụ")
+	log.Printf("  GET  /api/analytics/plots?from=&to= - So sánh năng suất, doanh thu, chi phí, lợi nhuận theo ruộng")
+	log.Printf("Inventory routes (cần token):")
+	log.Printf("  GET|POST /api/inventory/items - Xem danh sách, thêm vật tư")
+	log.Printf("  GET|PUT|DELETE /api/inventory/items/:id - Xem, sửa, xóa vật tư")
+	log.Printf("  GET|POST /api/inventory/items/:id/movements - Xem, ghi nhận nhập/xuất/điều chỉnh kho")
+	log.Printf("  GET  /api/inventory/alerts - Vật tư sắp hết")
+	log.Printf("  GET  /api/inventory/valuation - Giá trị tồn kho")
+	log.Printf("Template routes (cần token):")
+	log.Printf("  GET|POST /api/templates - Xem danh sách, tạo mẫu hoạt động")
+	log.Printf("  POST /api/templates/from-activities - Lưu các hoạt động thành mẫu (theo khoảng cách ngày)")
+	log.Printf("  GET|DELETE /api/templates/:id - Xem, xóa mẫu")
+	log.Printf("  POST /api/templates/:id/apply - Tạo hoạt động từ mẫu theo ngày bắt đầu, ruộng/mùa vụ")
+	log.Printf("Worker routes (cần token):")
+	log.Printf("  GET|POST /api/workers - Xem danh sách, thêm nhân công")
+	log.Printf("  GET|PUT|DELETE /api/workers/:id - Xem, sửa, xóa nhân công")
+	log.Printf("  GET /api/workers/payroll?from=&to= - Bảng lương của kỳ trả công")
+	log.Printf("  GET|POST /api/workers/:id/sessions - Xem, chấm công theo giờ hoặc theo sản phẩm")
+	log.Printf("  PUT|DELETE /api/workers/:id/sessions/:session_id - Sửa, xóa buổi công chưa trả")
+	log.Printf("  GET|POST /api/workers/:id/payments - Xem, trả công (ghi chi phí nhân công)")
+	log.Printf("  DELETE /api/workers/:id/payments/:payment_id - Hủy lần trả công")
+	log.Printf("  GET /api/workers/:id/payslip?from=&to= - Xuất phiếu lương XLSX")
+	log.Printf("Contact routes (cần token):")
+	log.Printf("  GET|POST /api/contacts?search=&role= - Xem danh sách, thêm liên hệ (người mua, nhà cung cấp, nhân công, cố vấn)")
+	log.Printf("  GET  /api/contacts/autocomplete?q=&role= - Gợi ý liên hệ và tên người chưa liên kết")
+	log.Printf("  GET|PUT|DELETE /api/contacts/:id - Xem, sửa, xóa liên hệ")
+	log.Printf("  POST /api/contacts/:id/link-activities - Liên kết hoạt động ghi tên người này với liên hệ")
+	log.Printf("  GET  /api/contacts/:id/statement?from=&to= - Sao kê đã trả, đã nhận trong kỳ")
+	log.Printf("Organization routes (cần token):")
+	log.Printf("  GET|POST /api/organizations - Xem trang trại của tôi, tạo trang trại")
+	log.Printf("  GET|PUT|DELETE /api/organizations/:id - Xem, đổi tên, xóa trang trại (chủ trang trại)")
+	log.Printf("  GET  /api/organizations/:id/members - Xem thành viên")
+	log.Printf("  PUT|DELETE /api/organizations/:id/members/:user_id - Đổi vai trò, xóa thành viên hoặc rời trang trại")
+	log.Printf("  GET|POST /api/organizations/:id/invitations - Xem, mời thành viên qua email hoặc username")
+	log.Printf("  DELETE /api/organizations/:id/invitations/:invitation_id - Thu hồi lời mời")
+	log.Printf("  GET  /api/organizations/invitations - Lời mời gửi cho tôi")
+	log.Printf("  POST /api/organizations/invitations/:invitation_id/accept|decline - Chấp nhận, từ chối lời mời")
+	log.Printf("  Header X-Organization-ID - Làm việc trên hoạt động, ruộng, kho của trang trại")
+	log.Printf("Sync routes (cần token):")
+	log.Printf("  GET  /api/sync?token= - Lấy thay đổi từ lần đồng bộ trước (hoạt động, ruộng, danh mục bệnh)")
+	log.Printf("  POST /api/sync - Gửi thay đổi offline, báo cáo xung đột từng bản ghi")
 
 	if err := router.Run(":" + port); err != nil {
 		log.Fatal("Failed to start server:", err)
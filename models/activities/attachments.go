@@ -0,0 +1,274 @@
+package activities
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/media"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MaxAttachments limits the number of files attached to one activity
+const MaxAttachments = 20
+
+// GetActivityAttachmentsHandler lists the attachments of an activity
+func GetActivityAttachmentsHandler(c *gin.Context) {
+	activity, ok := loadAttachmentActivity(c)
+	if !ok {
+		return
+	}
+
+	attachments, err := GetAttachments(activity.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get attachments",
+		})
+		return
+	}
+
+	mediaIDs := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		mediaIDs = append(mediaIDs, attachment.MediaID)
+	}
+	files, err := media.GetMediaByIDs(mediaIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get attachments",
+		})
+		return
+	}
+
+	response := make([]AttachmentResponse, 0, len(attachments))
+	for i := range attachments {
+		response = append(response, attachments[i].ToAttachmentResponse(c, files[attachments[i].MediaID]))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// UploadActivityAttachmentHandler attaches an image or PDF uploaded through the multipart "file" field,
+// with an optional "caption" form field
+func UploadActivityAttachmentHandler(c *gin.Context) {
+	activity, ok := loadAttachmentActivity(c)
+	if !ok {
+		return
+	}
+
+	count, err := CountAttachments(activity.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get attachments",
+		})
+		return
+	}
+	if count >= MaxAttachments {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Activity has too many attachments",
+		})
+		return
+	}
+
+	data, err := media.ReadUpload(c, "file")
+	if err != nil {
+		media.RespondUploadError(c, err)
+		return
+	}
+	caption := strings.TrimSpace(c.PostForm("caption"))
+	if len(caption) > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "caption must be less than 500 characters",
+		})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	m, err := media.StoreFile(c.Request.Context(), data, userID)
+	if err != nil {
+		media.RespondUploadError(c, err)
+		return
+	}
+
+	attached, err := IsAttached(activity.ID, m.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get attachments",
+		})
+		return
+	}
+	if attached {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "File is already attached to this activity",
+		})
+		return
+	}
+
+	attachment := &Attachment{
+		ActivityID: activity.ID,
+		MediaID:    m.ID,
+		FileName:   attachmentFileName(c),
+		UploadedBy: &userID,
+	}
+	if caption != "" {
+		attachment.Caption = &caption
+	}
+	if err := CreateAttachmentRecord(attachment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create attachment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Attachment uploaded successfully",
+		"data":    attachment.ToAttachmentResponse(c, m),
+	})
+}
+
+// GetActivityAttachmentContentHandler streams an attached file or a thumbnail (?size=small|medium|large)
+// to the users allowed to see the activity
+func GetActivityAttachmentContentHandler(c *gin.Context) {
+	activity, ok := loadAttachmentActivity(c)
+	if !ok {
+		return
+	}
+	attachment, ok := loadAttachment(c, activity)
+	if !ok {
+		return
+	}
+
+	m, err := media.GetMediaByID(attachment.MediaID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "File not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get attachment",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName}))
+	media.ServeContent(c, m, false)
+}
+
+// DeleteActivityAttachmentHandler deletes an attachment, allowed to the activity owner, the uploader
+// and the managers of the organization of the activity
+func DeleteActivityAttachmentHandler(c *gin.Context) {
+	activity, ok := loadAttachmentActivity(c)
+	if !ok {
+		return
+	}
+	attachment, ok := loadAttachment(c, activity)
+	if !ok {
+		return
+	}
+
+	userID := c.GetString("user_id")
+	owner := activity.UserID == nil || *activity.UserID == userID ||
+		activity.OrganizationID != nil && common.CurrentTenant(c).Allows(common.OrgRoleManager)
+	uploader := attachment.UploadedBy != nil && *attachment.UploadedBy == userID
+	if !owner && !uploader {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only the owner or the uploader can delete this attachment",
+		})
+		return
+	}
+
+	if err := DeleteAttachment(attachment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete attachment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attachment deleted successfully",
+	})
+}
+
+// attachmentFileName keeps the base name of the uploaded file for downloads
+func attachmentFileName(c *gin.Context) string {
+	name := "attachment"
+	if file, err := c.FormFile("file"); err == nil {
+		if base := filepath.Base(strings.ReplaceAll(file.Filename, "\\", "/")); base != "." && base != "/" {
+			name = base
+		}
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
+
+// loadAttachmentActivity loads the activity from the :id parameter if the current user may see its attachments:
+// the owner, an assignee, any member for activities of an organization, or anyone signed in for unowned activities.
+// It writes the error response and returns false otherwise.
+func loadAttachmentActivity(c *gin.Context) (*Activity, bool) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Authentication is required to access attachments",
+		})
+		return nil, false
+	}
+
+	activity, err := GetTenantActivityByID(c.Param("id"), common.CurrentTenant(c))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Activity not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activity",
+		})
+		return nil, false
+	}
+	if activity.UserID == nil || *activity.UserID == userID || activity.OrganizationID != nil {
+		return activity, true
+	}
+
+	assigned, err := IsAssignee(activity.ID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check assignees",
+		})
+		return nil, false
+	}
+	if !assigned {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only the owner or an assignee can access the attachments of this activity",
+		})
+		return nil, false
+	}
+	return activity, true
+}
+
+// loadAttachment loads the attachment from the :attachment_id parameter,
+// writing the error response and returning false if the activity has no such attachment
+func loadAttachment(c *gin.Context, activity *Activity) (*Attachment, bool) {
+	attachment, err := GetAttachment(activity.ID, c.Param("attachment_id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Attachment not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get attachment",
+		})
+		return nil, false
+	}
+	return attachment, true
+}
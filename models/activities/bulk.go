@@ -0,0 +1,516 @@
+package activities
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/units"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxBulkItems is the largest number of activities a bulk operation changes
+const MaxBulkItems = 500
+
+// Statuses of the items of a bulk operation
+const (
+	BulkCreated  = "created"
+	BulkUpdated  = "updated"
+	BulkDeleted  = "deleted"
+	BulkInvalid  = "invalid"
+	BulkNotFound = "not_found"
+	BulkConflict = "conflict"
+)
+
+// bulkTarget is an activity selected by a bulk update or delete, nil when the ID was not found
+type bulkTarget struct {
+	index    int
+	id       string
+	activity *Activity
+}
+
+// Apply moves the times of the activity in loc so days keep their wall-clock time.
+// end_repeat_day is a date and only moves by days.
+func (s *BulkShift) Apply(activity *Activity, loc *time.Location) {
+	offset := time.Duration(s.Hours)*time.Hour + time.Duration(s.Minutes)*time.Minute
+	shift := func(t *time.Time) *time.Time {
+		if t == nil {
+			return nil
+		}
+		moved := t.In(loc).AddDate(0, 0, s.Days).Add(offset)
+		return &moved
+	}
+	activity.TimeStart = shift(activity.TimeStart)
+	activity.TimeEnd = shift(activity.TimeEnd)
+	if activity.EndRepeatDay != nil {
+		endRepeat := activity.EndRepeatDay.AddDate(0, 0, s.Days)
+		activity.EndRepeatDay = &endRepeat
+	}
+}
+
+// BulkCreateActivitiesHandler creates several activities in one transaction.
+// Nothing is saved if one of them is invalid, ?dry_run=true only previews the results.
+// POST /api/v1/activities/bulk/create
+func BulkCreateActivitiesHandler(c *gin.Context) {
+	var req BulkCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+	if err := ValidateBulkCreateRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	RespondBulkCreate(c, req.Activities)
+}
+
+// RespondBulkCreate checks and creates the activities in one transaction like the bulk create
+// endpoint, honouring ?dry_run and ?strict, and writes the response
+func RespondBulkCreate(c *gin.Context, requests []CreateActivityRequest) {
+	loc, err := common.RequestTimezone(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tenant := common.CurrentTenant(c)
+	response := newBulkResponse(c)
+	items := make([]*Activity, len(requests))
+	for i := range requests {
+		result := BulkItemResult{Index: i, Status: BulkCreated}
+		activity, invalid, err := prepareBulkCreate(&requests[i], tenant)
+		if err == nil && invalid == nil {
+			invalid, err = checkBulkConflicts(c, activity, &result, loc, nil, items[:i])
+		}
+		if err != nil {
+			log.Printf("Failed to check bulk activity %d: %v", i, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check activities",
+			})
+			return
+		}
+		if invalid != nil {
+			response.add(failedItem(result, invalid))
+			continue
+		}
+		items[i] = activity
+		response.add(result)
+	}
+
+	finishBulk(c, response, items, http.StatusCreated, func(created []*Activity) error {
+		if err := BulkCreateActivities(created); err != nil {
+			return err
+		}
+		for _, activity := range created {
+			RecordInventoryConsumption(activity)
+		}
+		return nil
+	})
+}
+
+// BulkUpdateActivitiesHandler applies a merge patch and/or a time shift to the activities listed
+// by ID or matching a filter in one transaction. Nothing is saved if one of them fails,
+// ?dry_run=true only previews the results.
+// POST /api/v1/activities/bulk/update
+func BulkUpdateActivitiesHandler(c *gin.Context) {
+	var req BulkUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+	if err := ValidateBulkUpdateRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	loc, err := common.RequestTimezone(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	targets, ok := selectBulkTargets(c, req.IDs, req.Filter)
+	if !ok {
+		return
+	}
+
+	// The selected activities move together, so each item is checked against the new times
+	// of the earlier items instead of their stored rows
+	selected := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if target.activity != nil {
+			selected = append(selected, target.activity.ID)
+		}
+	}
+
+	userID := c.GetString("user_id")
+	response := newBulkResponse(c)
+	items := make([]*Activity, len(targets))
+	for i, target := range targets {
+		result := BulkItemResult{Index: target.index, ID: target.id, Status: BulkUpdated}
+		if target.activity == nil {
+			result.Status = BulkNotFound
+			result.Error = "Activity not found"
+			response.add(result)
+			continue
+		}
+		activity := target.activity
+		invalid, err := prepareBulkUpdate(activity, &req, userID, loc)
+		if err == nil && invalid == nil && (scheduleChanged(req.Patch.Has) || req.Shift != nil) {
+			invalid, err = checkBulkConflicts(c, activity, &result, loc, selected, items[:i])
+		}
+		if err != nil {
+			log.Printf("Failed to check bulk update of activity %s: %v", target.id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check activities",
+			})
+			return
+		}
+		if invalid != nil {
+			response.add(failedItem(result, invalid))
+			continue
+		}
+		items[i] = activity
+		response.add(result)
+	}
+
+	finishBulk(c, response, items, http.StatusOK, func(updated []*Activity) error {
+		failed, err := BulkUpdateActivities(updated)
+		if err == common.ErrVersionConflict {
+			// Someone else saved the activity after it was read
+			response.Results[failed].Status = BulkConflict
+			response.Results[failed].Error = "Activity was modified by someone else"
+			response.Succeeded--
+			response.Failed++
+		}
+		return err
+	})
+}
+
+// BulkDeleteActivitiesHandler moves the activities listed by ID or matching a filter to trash
+// in one transaction. Nothing is deleted if an ID is not found, ?dry_run=true only previews the results.
+// POST /api/v1/activities/bulk/delete
+func BulkDeleteActivitiesHandler(c *gin.Context) {
+	var req BulkDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+	if err := ValidateBulkDeleteRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	targets, ok := selectBulkTargets(c, req.IDs, req.Filter)
+	if !ok {
+		return
+	}
+
+	ids := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if target.activity != nil {
+			ids = append(ids, target.activity.ID)
+		}
+	}
+	paid, err := GetPaymentActivityIDs(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check worker payments",
+		})
+		return
+	}
+
+	response := newBulkResponse(c)
+	items := make([]*Activity, len(targets))
+	for i, target := range targets {
+		result := BulkItemResult{Index: target.index, ID: target.id, Status: BulkDeleted}
+		if target.activity == nil {
+			result.Status = BulkNotFound
+			result.Error = "Activity not found"
+			response.add(result)
+			continue
+		}
+		if paid[target.activity.ID] {
+			result.Status = BulkConflict
+			result.Error = ErrPaymentActivity.Error()
+			response.add(result)
+			continue
+		}
+		items[i] = target.activity
+		response.add(result)
+	}
+
+	finishBulk(c, response, items, http.StatusOK, func(deleted []*Activity) error {
+		ids := make([]string, 0, len(deleted))
+		for _, activity := range deleted {
+			ids = append(ids, activity.ID)
+		}
+		_, err := BulkDeleteActivities(ids)
+		return err
+	})
+}
+
+// prepareBulkCreate validates one activity of a bulk create like CreateActivityHandler.
+// invalid is the reason the item is rejected, err a database error.
+func prepareBulkCreate(req *CreateActivityRequest, tenant common.Tenant) (activity *Activity, invalid error, err error) {
+	if invalid := ValidateCreateActivityRequest(req); invalid != nil {
+		return nil, invalid, nil
+	}
+	if invalid, err := bulkUnitError(units.NormalizeUnit(tenant.UserID, req.Unit, "")); invalid != nil || err != nil {
+		return nil, invalid, err
+	}
+
+	activity = req.ToActivity()
+	if tenant.UserID != "" {
+		activity.UserID = &tenant.UserID
+	}
+	activity.OrganizationID = tenant.OrganizationRef()
+	if invalid, err := bulkLinkError(ResolveActivityLinks(activity, tenant.UserID)); invalid != nil || err != nil {
+		return nil, invalid, err
+	}
+	return activity, nil, nil
+}
+
+// prepareBulkUpdate applies the patch and shift of a bulk update to one activity and
+// validates it like PatchActivityHandler
+func prepareBulkUpdate(activity *Activity, req *BulkUpdateRequest, userID string, loc *time.Location) (invalid error, err error) {
+	if len(req.Patch) > 0 {
+		if invalid := ApplyActivityPatch(activity, req.Patch); invalid != nil {
+			return invalid, nil
+		}
+	}
+	if req.Shift != nil {
+		req.Shift.Apply(activity, loc)
+	}
+
+	if LinksChanged(req.Patch.Has) {
+		if invalid, err := bulkLinkError(ResolveActivityLinks(activity, userID)); invalid != nil || err != nil {
+			return invalid, err
+		}
+	}
+	if req.Patch.Has("unit") {
+		if invalid, err := bulkUnitError(units.NormalizeUnit(userID, activity.Unit, "")); invalid != nil || err != nil {
+			return invalid, err
+		}
+	}
+	return nil, nil
+}
+
+// checkBulkConflicts adds the schedule conflicts of an item, with stored activities other than the
+// selected ones and with the earlier items of the batch, to its warnings. In strict mode they reject the item.
+func checkBulkConflicts(c *gin.Context, activity *Activity, result *BulkItemResult, loc *time.Location, selected []string, batch []*Activity) (invalid error, err error) {
+	conflicts, err := FindScheduleConflictsExcept(activity, selected, loc)
+	if err != nil {
+		return nil, err
+	}
+	if batchConflicts := FindBatchConflicts(activity, batch, loc); len(batchConflicts) > 0 {
+		conflicts = append(conflicts, batchConflicts...)
+		sortConflicts(conflicts)
+	}
+	if len(conflicts) == 0 {
+		return nil, nil
+	}
+	result.Warnings = conflicts
+	if c.Query("strict") == "true" {
+		result.Status = BulkConflict
+		return errors.New("Activity overlaps other activities"), nil
+	}
+	return nil, nil
+}
+
+// bulkLinkError splits the errors of ResolveActivityLinks into item and database errors
+func bulkLinkError(err error) (invalid error, dbErr error) {
+	switch err {
+	case nil:
+		return nil, nil
+	case ErrLinkRequiresAuth, ErrSeasonNotFound, ErrSeasonOnOtherPlot, ErrPlotNotFound, ErrContactNotFound:
+		return err, nil
+	}
+	return nil, err
+}
+
+// bulkUnitError splits the errors of units.NormalizeUnit into item and database errors
+func bulkUnitError(err error) (invalid error, dbErr error) {
+	if err == nil {
+		return nil, nil
+	}
+	if errors.Is(err, units.ErrUnknownUnit) || errors.Is(err, units.ErrIncompatibleUnits) {
+		return err, nil
+	}
+	return nil, err
+}
+
+// selectBulkTargets loads the activities listed by ID, in request order, or matching the filter.
+// The error response is written and false returned when the selection is invalid.
+func selectBulkTargets(c *gin.Context, ids []string, filterValues map[string]string) ([]bulkTarget, bool) {
+	if len(ids) > 0 {
+		found, err := GetActivitiesByIDs(ids, common.CurrentTenant(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to get activities",
+			})
+			return nil, false
+		}
+		byID := make(map[string]*Activity, len(found))
+		for i := range found {
+			byID[found[i].ID] = &found[i]
+		}
+		targets := make([]bulkTarget, len(ids))
+		for i, id := range ids {
+			targets[i] = bulkTarget{index: i, id: id, activity: byID[id]}
+		}
+		return targets, true
+	}
+
+	values := url.Values{}
+	for name, value := range filterValues {
+		values.Set(name, value)
+	}
+	filter, err := ParseActivityFilterValues(values)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+	filter.Tenant = common.CurrentTenant(c)
+	count, err := CountActivities(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activities",
+		})
+		return nil, false
+	}
+	if count > MaxBulkItems {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("filter matches %d activities, at most %d can be changed at once", count, MaxBulkItems),
+		})
+		return nil, false
+	}
+	found, err := FindAllActivities(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activities",
+		})
+		return nil, false
+	}
+	targets := make([]bulkTarget, len(found))
+	for i := range found {
+		targets[i] = bulkTarget{index: i, id: found[i].ID, activity: &found[i]}
+	}
+	return targets, true
+}
+
+// newBulkResponse starts the response of a bulk operation, ?dry_run=true previews it
+func newBulkResponse(c *gin.Context) *BulkResponse {
+	return &BulkResponse{DryRun: c.Query("dry_run") == "true", Results: []BulkItemResult{}}
+}
+
+// add appends an item result and counts it
+func (r *BulkResponse) add(result BulkItemResult) {
+	r.Results = append(r.Results, result)
+	switch result.Status {
+	case BulkCreated, BulkUpdated, BulkDeleted:
+		r.Succeeded++
+	default:
+		r.Failed++
+	}
+}
+
+// failedItem rejects an item, keeping a conflict status set by checkBulkConflicts
+func failedItem(result BulkItemResult, err error) BulkItemResult {
+	if result.Status != BulkConflict {
+		result.Status = BulkInvalid
+	}
+	result.Error = err.Error()
+	return result
+}
+
+// finishBulk writes the response once every item was checked. Nothing is saved on dry runs
+// or when an item failed (400, or 409 when only schedule conflicts failed), otherwise save
+// runs with the activities and the saved items are returned with status.
+// items holds the activity of each result, nil for failed ones.
+func finishBulk(c *gin.Context, response *BulkResponse, items []*Activity, status int, save func([]*Activity) error) {
+	fillBulkData(response, items)
+	if response.DryRun {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Dry run, nothing was saved",
+			"data":    response,
+		})
+		return
+	}
+	if response.Failed > 0 {
+		c.JSON(bulkFailureStatus(response), gin.H{
+			"error": fmt.Sprintf("%d of %d activities failed, nothing was saved", response.Failed, len(response.Results)),
+			"data":  response,
+		})
+		return
+	}
+
+	// No item failed, so every result has its activity
+	if len(items) > 0 {
+		if err := save(items); err != nil {
+			if err == common.ErrVersionConflict {
+				c.JSON(http.StatusConflict, gin.H{
+					"error": "An activity was modified by someone else, nothing was saved",
+					"data":  response,
+				})
+				return
+			}
+			log.Printf("Failed to save bulk activities: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to save activities",
+			})
+			return
+		}
+	}
+
+	response.Saved = true
+	fillBulkData(response, items)
+	c.JSON(status, gin.H{
+		"message": fmt.Sprintf("%d activities saved", response.Succeeded),
+		"data":    response,
+	})
+}
+
+// fillBulkData sets the activity of each successful result
+func fillBulkData(response *BulkResponse, items []*Activity) {
+	for i, activity := range items {
+		if activity == nil {
+			continue
+		}
+		data := activity.ToActivityResponse()
+		response.Results[i].ID = data.ID
+		response.Results[i].Data = &data
+	}
+}
+
+// bulkFailureStatus is 409 when every failed item is a schedule conflict, 400 otherwise
+func bulkFailureStatus(response *BulkResponse) int {
+	for _, result := range response.Results {
+		if result.Status == BulkInvalid || result.Status == BulkNotFound {
+			return http.StatusBadRequest
+		}
+	}
+	return http.StatusConflict
+}
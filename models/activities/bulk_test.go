@@ -0,0 +1,101 @@
+package activities
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"plantheon-backend/common/dbtest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestBulkUpdateActivitiesHandlerScheduleConflicts(t *testing.T) {
+	const mon, wed = "0b6c1f1e-3c1a-4f7e-8d55-6f0e2a9b1c01", "0b6c1f1e-3c1a-4f7e-8d55-6f0e2a9b1c02"
+	// Monday and Wednesday mornings of the owner
+	selected := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "user_id", "title", "type", "time_start", "time_end", "version"}).
+			AddRow(mon, "owner", "Tưới nước", "irrigation", at(t, "2025-03-03 08:00", vietnam), at(t, "2025-03-03 10:00", vietnam), 1).
+			AddRow(wed, "owner", "Tưới gốc", "irrigation", at(t, "2025-03-05 08:00", vietnam), at(t, "2025-03-05 10:00", vietnam), 1)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		// batch index each item overlaps, -1 for none
+		overlaps []int
+	}{
+		{
+			name:     "shifting the week keeps the batch apart",
+			body:     `{"ids": ["` + mon + `", "` + wed + `"], "shift": {"days": 2}}`,
+			status:   http.StatusOK,
+			overlaps: []int{-1, -1},
+		},
+		{
+			name:     "moving onto the same time",
+			body:     `{"ids": ["` + mon + `", "` + wed + `"], "patch": {"time_start": "2025-03-07T08:00:00+07:00", "time_end": "2025-03-07T10:00:00+07:00"}}`,
+			status:   http.StatusConflict,
+			overlaps: []int{-1, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := dbtest.Mock(t)
+			mock.ExpectQuery(`SELECT \* FROM "activities" WHERE id IN \(\$1,\$2\)`).
+				WithArgs(mon, wed, "owner").
+				WillReturnRows(selected())
+			for _, id := range []string{mon, wed} {
+				mock.ExpectQuery(`SELECT \* FROM "activity_assignees" WHERE activity_id IN \(\$1\)`).
+					WithArgs(id).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				// The stored rows of the selected activities are not candidates, they move too
+				mock.ExpectQuery(`SELECT \* FROM "activities" WHERE user_id = \$1 AND .* AND id NOT IN \(\$6,\$7\)`).
+					WithArgs("owner", "owner", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), mon, wed).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			}
+			if tt.status == http.StatusOK {
+				mock.ExpectBegin()
+				for _, id := range []string{mon, wed} {
+					mock.ExpectQuery(`SELECT .* FROM "activities" WHERE \(id = \$1 AND version = \$2\)`).
+						WithArgs(id, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "type"}).AddRow(id, "irrigation"))
+					mock.ExpectExec(`UPDATE "activities" SET .* WHERE version = \$\d+ AND .*"id" = \$\d+`).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectCommit()
+			}
+
+			w := dbtest.Serve(http.MethodPost, "/activities/bulk/update", "/activities/bulk/update?strict=true&tz=Asia/Ho_Chi_Minh",
+				tt.body, nil, "owner", BulkUpdateActivitiesHandler)
+			if w.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+
+			var response struct {
+				Data BulkResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Data.Saved != (tt.status == http.StatusOK) {
+				t.Fatalf("got saved %v", response.Data.Saved)
+			}
+			for i, result := range response.Data.Results {
+				if tt.overlaps[i] < 0 {
+					if len(result.Warnings) > 0 || result.Status != BulkUpdated {
+						t.Fatalf("item %d: got %s with conflicts %+v, want none", i, result.Status, result.Warnings)
+					}
+					continue
+				}
+				if result.Status != BulkConflict || len(result.Warnings) != 1 {
+					t.Fatalf("item %d: got %s with conflicts %+v, want one", i, result.Status, result.Warnings)
+				}
+				conflict := result.Warnings[0]
+				if conflict.BatchIndex == nil || *conflict.BatchIndex != tt.overlaps[i] || conflict.ActivityID != "" {
+					t.Fatalf("item %d: got conflict %+v, want batch item %d", i, conflict, tt.overlaps[i])
+				}
+			}
+		})
+	}
+}
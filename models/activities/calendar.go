@@ -0,0 +1,202 @@
+package activities
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"plantheon-backend/common"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAgendaDays bounds the range of an agenda query
+const maxAgendaDays = 92
+
+// CalendarEntry is an activity on one calendar day with the times of its occurrence.
+// A multi-day occurrence has an entry on each day it spans.
+type CalendarEntry struct {
+	ActivityResponse
+	OccurrenceStart time.Time  `json:"occurrence_start"`
+	OccurrenceEnd   *time.Time `json:"occurrence_end"`
+	AllDay          bool       `json:"all_day"`
+	Recurring       bool       `json:"recurring"`
+	SpanDay         int        `json:"span_day"`  // day of the occurrence this entry is on, from 1
+	SpanDays        int        `json:"span_days"` // number of days the occurrence spans
+}
+
+// CalendarDayEntries lists the entries of one day, all-day ones first
+type CalendarDayEntries struct {
+	Date       string          `json:"date"` // YYYY-MM-DD
+	Activities []CalendarEntry `json:"activities"`
+}
+
+// BuildCalendarDays places the occurrences of the activities in [from, to) on each day
+// of the range, days are taken in loc and from must be a midnight there
+func BuildCalendarDays(activities []Activity, from, to time.Time, loc *time.Location) []CalendarDayEntries {
+	var days []CalendarDayEntries
+	index := make(map[string]int)
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format(common.DateLayout)
+		index[key] = len(days)
+		days = append(days, CalendarDayEntries{Date: key, Activities: []CalendarEntry{}})
+	}
+
+	for i := range activities {
+		activity := &activities[i]
+		response := activity.ToActivityResponse()
+		for _, occurrence := range activity.Occurrences(from, to, loc) {
+			first, last := occurrenceDays(occurrence, loc)
+			spanDays := daysBetween(first, last) + 1
+			for day, n := first, 1; !day.After(last); day, n = day.AddDate(0, 0, 1), n+1 {
+				position, ok := index[day.Format(common.DateLayout)]
+				if !ok {
+					continue
+				}
+				days[position].Activities = append(days[position].Activities, CalendarEntry{
+					ActivityResponse: response,
+					OccurrenceStart:  occurrence.Start,
+					OccurrenceEnd:    occurrence.End,
+					AllDay:           activity.Day != nil && *activity.Day,
+					Recurring:        occurrence.Recurring,
+					SpanDay:          n,
+					SpanDays:         spanDays,
+				})
+			}
+		}
+	}
+
+	for i := range days {
+		entries := days[i].Activities
+		sort.SliceStable(entries, func(a, b int) bool {
+			if entries[a].AllDay != entries[b].AllDay {
+				return entries[a].AllDay
+			}
+			if !entries[a].OccurrenceStart.Equal(entries[b].OccurrenceStart) {
+				return entries[a].OccurrenceStart.Before(entries[b].OccurrenceStart)
+			}
+			return entries[a].Title < entries[b].Title
+		})
+	}
+	return days
+}
+
+// occurrenceDays returns the first and last day an occurrence is on in loc.
+// An occurrence ending exactly at midnight does not reach into that day.
+func occurrenceDays(occurrence Occurrence, loc *time.Location) (time.Time, time.Time) {
+	first := common.StartOfDay(occurrence.Start, loc)
+	last := first
+	if occurrence.End != nil && occurrence.End.After(occurrence.Start) {
+		last = common.StartOfDay(occurrence.End.Add(-time.Nanosecond), loc)
+	}
+	return first, last
+}
+
+// daysBetween counts calendar days from one midnight to another, days may be
+// 23 or 25 hours long around daylight saving changes
+func daysBetween(first, last time.Time) int {
+	a := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// GetActivitiesCalendarByWeekHandler returns the 7 days of the week (Monday first) containing date,
+// with every activity occurrence including spanned days and repetitions.
+// Days are taken in the tz parameter, the user's timezone or UTC.
+// GET /api/v1/activities/calendar/week?date=YYYY-MM-DD&tz=Asia/Ho_Chi_Minh
+func GetActivitiesCalendarByWeekHandler(c *gin.Context) {
+	loc, err := common.RequestTimezone(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	date := time.Now().In(loc)
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := time.ParseInLocation(common.DateLayout, dateStr, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format, expected YYYY-MM-DD"})
+			return
+		}
+		date = parsed
+	}
+
+	// Go counts weekdays from Sunday, the week starts on Monday
+	offset := (int(date.Weekday()) + 6) % 7
+	from := common.StartOfDay(date, loc).AddDate(0, 0, -offset)
+	to := from.AddDate(0, 0, 7)
+
+	acts, err := GetActivitiesInRange(from, to, common.CurrentTenant(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activities"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"from":     from.Format(common.DateLayout),
+			"to":       to.AddDate(0, 0, -1).Format(common.DateLayout),
+			"timezone": loc.String(),
+			"days":     BuildCalendarDays(acts, from, to, loc),
+		},
+	})
+}
+
+// GetActivitiesAgendaHandler returns the days between from and to (inclusive) that have
+// activity occurrences, including spanned days and repetitions.
+// Days are taken in the tz parameter, the user's timezone or UTC.
+// GET /api/v1/activities/calendar/agenda?from=YYYY-MM-DD&to=YYYY-MM-DD&tz=Asia/Ho_Chi_Minh
+func GetActivitiesAgendaHandler(c *gin.Context) {
+	fromStr, toStr := c.Query("from"), c.Query("to")
+	if fromStr == "" || toStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to are required (YYYY-MM-DD)"})
+		return
+	}
+	loc, err := common.RequestTimezone(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, err := time.ParseInLocation(common.DateLayout, fromStr, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from, expected YYYY-MM-DD"})
+		return
+	}
+	last, err := time.ParseInLocation(common.DateLayout, toStr, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to, expected YYYY-MM-DD"})
+		return
+	}
+	to := last.AddDate(0, 0, 1)
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+	if daysBetween(from, to) > maxAgendaDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "agenda range must be at most 92 days"})
+		return
+	}
+
+	acts, err := GetActivitiesInRange(from, to, common.CurrentTenant(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activities"})
+		return
+	}
+
+	days := []CalendarDayEntries{}
+	for _, day := range BuildCalendarDays(acts, from, to, loc) {
+		if len(day.Activities) > 0 {
+			days = append(days, day)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"from":     fromStr,
+			"to":       toStr,
+			"timezone": loc.String(),
+			"days":     days,
+			"count":    len(days),
+		},
+	})
+}
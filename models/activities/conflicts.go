@@ -0,0 +1,474 @@
+package activities
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"plantheon-backend/common"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reasons two activities are scheduled against each other
+const (
+	ConflictSameOwner  = "same_owner"
+	ConflictSamePlot   = "same_plot"
+	ConflictSamePerson = "same_person"
+	// The same user or worker is assigned to both activities
+	ConflictSameAssignee = "same_assignee"
+)
+
+// conflictHorizon bounds how far ahead the repetitions of an activity are checked for overlaps
+const conflictHorizon = 366 * 24 * time.Hour
+
+// scheduleFields are the fields whose change may create an overlap
+var scheduleFields = []string{
+	"time_start", "time_end", "day", "plot_id", "season_id",
+	"target_person", "target_contact_id", "is_repeat", "repeat", "end_repeat_day",
+}
+
+// ScheduleConflict is another activity overlapping the one being saved.
+// Start and End are the first overlapping occurrence of the other activity.
+// Conflicts with another item of the same bulk create or update have BatchIndex instead of ActivityID.
+type ScheduleConflict struct {
+	ActivityID  string    `json:"activity_id"`
+	BatchIndex  *int      `json:"batch_index,omitempty"`
+	Title       string    `json:"title"`
+	Type        string    `json:"type"`
+	Reasons     []string  `json:"reasons"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Recurring   bool      `json:"recurring"`
+	Occurrences int       `json:"occurrences"` // Number of overlapping occurrences
+}
+
+// BusyPeriod is a time range taken by one or more activities
+type BusyPeriod struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	ActivityIDs []string  `json:"activity_ids"`
+}
+
+// FreePeriod is a time range without activities
+type FreePeriod struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// ScheduleMatch selects activities by owner, plot, assigned person (target_person) and assignees
+// among the activities of the tenant, see scopeOwned
+type ScheduleMatch struct {
+	UserID    *string
+	PlotID    *string
+	Person    *string
+	Assignees []ActivityAssignee
+	Tenant    common.Tenant
+}
+
+// IsTimed checks if the activity blocks time: it has a start and a later end and is not all-day
+func (a *Activity) IsTimed() bool {
+	return a.TimeStart != nil && a.TimeEnd != nil && a.TimeEnd.After(*a.TimeStart) &&
+		(a.Day == nil || !*a.Day)
+}
+
+// scheduleChanged checks if a request or patch setting these fields may move the activity onto another
+func scheduleChanged(has func(field string) bool) bool {
+	for _, field := range scheduleFields {
+		if has(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindScheduleConflicts returns the timed activities of the same owner, plot, assigned person or
+// assignee overlapping the activity, repetitions included up to a year ahead. Repetitions are expanded in loc.
+func FindScheduleConflicts(activity *Activity, loc *time.Location) ([]ScheduleConflict, error) {
+	return FindScheduleConflictsExcept(activity, nil, loc)
+}
+
+// FindScheduleConflictsExcept is FindScheduleConflicts ignoring the stored activities with these IDs,
+// like the items of a bulk update that move in the same transaction
+func FindScheduleConflictsExcept(activity *Activity, excludeIDs []string, loc *time.Location) ([]ScheduleConflict, error) {
+	if !activity.IsTimed() {
+		return nil, nil
+	}
+	var assignees []ActivityAssignee
+	if activity.ID != "" {
+		stored, err := GetActivityAssignees([]string{activity.ID})
+		if err != nil {
+			return nil, err
+		}
+		assignees = stored[activity.ID]
+	}
+	return findConflicts(activity, assignees, excludeIDs, loc)
+}
+
+// FindAssigneeConflicts is FindScheduleConflicts with the given assignees instead of the stored ones
+func FindAssigneeConflicts(activity *Activity, assignees []ActivityAssignee, loc *time.Location) ([]ScheduleConflict, error) {
+	return findConflicts(activity, assignees, nil, loc)
+}
+
+// findConflicts finds the stored activities overlapping the activity with the assignees,
+// except the activity itself and the excluded ones
+func findConflicts(activity *Activity, assignees []ActivityAssignee, excludeIDs []string, loc *time.Location) ([]ScheduleConflict, error) {
+	if !activity.IsTimed() {
+		return nil, nil
+	}
+	match := ScheduleMatch{
+		UserID:    activity.UserID,
+		PlotID:    activity.PlotID,
+		Person:    normalizePerson(activity.TargetPerson),
+		Assignees: assignees,
+		Tenant:    activity.tenant(),
+	}
+	if match.UserID == nil && match.PlotID == nil && match.Person == nil && len(match.Assignees) == 0 {
+		return nil, nil
+	}
+
+	from, windowEnd, own := conflictWindow(activity, loc)
+	if len(own) == 0 {
+		return nil, nil
+	}
+
+	if activity.ID != "" && !containsString(excludeIDs, activity.ID) {
+		excludeIDs = append([]string{activity.ID}, excludeIDs...)
+	}
+	candidates, err := GetScheduleCandidates(match, from, windowEnd, excludeIDs)
+	if err != nil {
+		return nil, err
+	}
+	candidateIDs := make([]string, len(candidates))
+	for i := range candidates {
+		candidateIDs[i] = candidates[i].ID
+	}
+	candidateAssignees, err := GetActivityAssignees(candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []ScheduleConflict{}
+	for i := range candidates {
+		other := &candidates[i]
+		reasons := conflictReasons(activity, other, assignees, candidateAssignees[other.ID])
+		if conflict := overlapConflict(own, other, reasons, from, windowEnd, loc); conflict != nil {
+			conflict.ActivityID = other.ID
+			conflicts = append(conflicts, *conflict)
+		}
+	}
+	sortConflicts(conflicts)
+	return conflicts, nil
+}
+
+// FindBatchConflicts returns the earlier items of a batch, not saved yet, overlapping the activity
+// for the same reasons as FindScheduleConflicts. Nil items were rejected and are skipped.
+func FindBatchConflicts(activity *Activity, batch []*Activity, loc *time.Location) []ScheduleConflict {
+	if !activity.IsTimed() {
+		return nil
+	}
+	from, windowEnd, own := conflictWindow(activity, loc)
+	if len(own) == 0 {
+		return nil
+	}
+
+	var conflicts []ScheduleConflict
+	for i, other := range batch {
+		if other == nil || !other.IsTimed() || !sameTenant(activity, other) {
+			continue
+		}
+		reasons := conflictReasons(activity, other, nil, nil)
+		if conflict := overlapConflict(own, other, reasons, from, windowEnd, loc); conflict != nil {
+			index := i
+			conflict.BatchIndex = &index
+			conflicts = append(conflicts, *conflict)
+		}
+	}
+	sortConflicts(conflicts)
+	return conflicts
+}
+
+// conflictWindow returns the range the activity is checked in and its occurrences in it
+func conflictWindow(activity *Activity, loc *time.Location) (time.Time, time.Time, []Occurrence) {
+	from := *activity.TimeStart
+	to := *activity.TimeEnd
+	if activity.Frequency() != "" {
+		to = from.Add(conflictHorizon)
+	}
+	own := activity.Occurrences(from, to, loc)
+	if len(own) == 0 {
+		return from, to, nil
+	}
+	return from, *own[len(own)-1].End, own
+}
+
+// overlapConflict describes the first occurrence of other overlapping the sorted occurrences,
+// nil when they do not overlap or share nothing
+func overlapConflict(own []Occurrence, other *Activity, reasons []string, from, to time.Time, loc *time.Location) *ScheduleConflict {
+	if len(reasons) == 0 {
+		return nil
+	}
+	var conflict *ScheduleConflict
+	for _, occurrence := range other.Occurrences(from, to, loc) {
+		if !overlapsAny(own, occurrence) {
+			continue
+		}
+		if conflict == nil {
+			conflict = &ScheduleConflict{
+				Title:     other.Title,
+				Type:      other.Type,
+				Reasons:   reasons,
+				Start:     occurrence.Start,
+				End:       *occurrence.End,
+				Recurring: occurrence.Recurring,
+			}
+		}
+		conflict.Occurrences++
+	}
+	return conflict
+}
+
+// sortConflicts orders the conflicts by their first overlap
+func sortConflicts(conflicts []ScheduleConflict) {
+	sort.SliceStable(conflicts, func(a, b int) bool {
+		return conflicts[a].Start.Before(conflicts[b].Start)
+	})
+}
+
+// sameTenant checks if the two activities live in the same data space
+func sameTenant(a, b *Activity) bool {
+	if a.OrganizationID != nil || b.OrganizationID != nil {
+		return a.OrganizationID != nil && b.OrganizationID != nil && *a.OrganizationID == *b.OrganizationID
+	}
+	return true
+}
+
+// conflictReasons lists what the two activities share, given their assignees
+func conflictReasons(a, b *Activity, aAssignees, bAssignees []ActivityAssignee) []string {
+	var reasons []string
+	if a.UserID != nil && b.UserID != nil && *a.UserID == *b.UserID {
+		reasons = append(reasons, ConflictSameOwner)
+	}
+	if a.PlotID != nil && b.PlotID != nil && *a.PlotID == *b.PlotID {
+		reasons = append(reasons, ConflictSamePlot)
+	}
+	if person := normalizePerson(a.TargetPerson); person != nil {
+		if other := normalizePerson(b.TargetPerson); other != nil && *person == *other {
+			reasons = append(reasons, ConflictSamePerson)
+		}
+	}
+	if sharesAssignee(aAssignees, bAssignees) {
+		reasons = append(reasons, ConflictSameAssignee)
+	}
+	return reasons
+}
+
+// sharesAssignee checks if a user or a worker is in both lists
+func sharesAssignee(a, b []ActivityAssignee) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if (x.UserID != nil && y.UserID != nil && *x.UserID == *y.UserID) ||
+				(x.WorkerID != nil && y.WorkerID != nil && *x.WorkerID == *y.WorkerID) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// overlapsAny checks if the occurrence overlaps one of the sorted occurrences,
+// which all have the same length so their ends are sorted too
+func overlapsAny(occurrences []Occurrence, occurrence Occurrence) bool {
+	i := sort.Search(len(occurrences), func(i int) bool {
+		return occurrences[i].End.After(occurrence.Start)
+	})
+	return i < len(occurrences) && occurrences[i].Start.Before(*occurrence.End)
+}
+
+// normalizePerson compares people by name ignoring case and surrounding spaces
+func normalizePerson(person *string) *string {
+	if person == nil {
+		return nil
+	}
+	name := strings.ToLower(strings.TrimSpace(*person))
+	if name == "" {
+		return nil
+	}
+	return &name
+}
+
+// BuildFreeBusy merges the occurrences of the activities in [from, to) into busy periods,
+// clipped to the range, and returns the gaps between them as free periods
+func BuildFreeBusy(activities []Activity, from, to time.Time, loc *time.Location) ([]BusyPeriod, []FreePeriod) {
+	var occurrences []Occurrence
+	for i := range activities {
+		if activities[i].IsTimed() {
+			occurrences = append(occurrences, activities[i].Occurrences(from, to, loc)...)
+		}
+	}
+	sort.SliceStable(occurrences, func(a, b int) bool {
+		return occurrences[a].Start.Before(occurrences[b].Start)
+	})
+
+	busy := []BusyPeriod{}
+	for _, occurrence := range occurrences {
+		start, end := occurrence.Start, *occurrence.End
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !end.After(start) {
+			continue
+		}
+		if n := len(busy); n > 0 && !start.After(busy[n-1].End) {
+			last := &busy[n-1]
+			if end.After(last.End) {
+				last.End = end
+			}
+			if !containsString(last.ActivityIDs, occurrence.Activity.ID) {
+				last.ActivityIDs = append(last.ActivityIDs, occurrence.Activity.ID)
+			}
+			continue
+		}
+		busy = append(busy, BusyPeriod{Start: start, End: end, ActivityIDs: []string{occurrence.Activity.ID}})
+	}
+
+	free := []FreePeriod{}
+	cursor := from
+	for _, period := range busy {
+		if period.Start.After(cursor) {
+			free = append(free, FreePeriod{Start: cursor, End: period.Start})
+		}
+		cursor = period.End
+	}
+	if to.After(cursor) {
+		free = append(free, FreePeriod{Start: cursor, End: to})
+	}
+	return busy, free
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// checkScheduleConflicts finds the activities overlapping the one being saved. With ?strict=true
+// an overlap rejects the request with 409, otherwise the conflicts are returned as warnings.
+// The error response is written and false returned when the request must stop.
+func checkScheduleConflicts(c *gin.Context, activity *Activity) ([]ScheduleConflict, bool) {
+	return respondScheduleConflicts(c, func(loc *time.Location) ([]ScheduleConflict, error) {
+		return FindScheduleConflicts(activity, loc)
+	})
+}
+
+// checkAssigneeConflicts is checkScheduleConflicts for the assignees about to be set on the activity
+func checkAssigneeConflicts(c *gin.Context, activity *Activity, assignees []ActivityAssignee) ([]ScheduleConflict, bool) {
+	return respondScheduleConflicts(c, func(loc *time.Location) ([]ScheduleConflict, error) {
+		return FindAssigneeConflicts(activity, assignees, loc)
+	})
+}
+
+// respondScheduleConflicts runs find in the request timezone, see checkScheduleConflicts
+func respondScheduleConflicts(c *gin.Context, find func(loc *time.Location) ([]ScheduleConflict, error)) ([]ScheduleConflict, bool) {
+	loc, err := common.RequestTimezone(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+	conflicts, err := find(loc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check schedule conflicts",
+		})
+		return nil, false
+	}
+	if len(conflicts) > 0 && c.Query("strict") == "true" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "Activity overlaps other activities",
+			"conflicts": conflicts,
+		})
+		return nil, false
+	}
+	return conflicts, true
+}
+
+// GetFreeBusyHandler returns the busy and free periods between from and to (inclusive days)
+// of the current user, narrowed to a plot or an assigned person
+// GET /api/v1/activities/free-busy?from=YYYY-MM-DD&to=YYYY-MM-DD&plot_id=&person=&tz=
+func GetFreeBusyHandler(c *gin.Context) {
+	loc, err := common.RequestTimezone(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, to, err := parseFreeBusyRange(c.Query("from"), c.Query("to"), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	match := ScheduleMatch{Tenant: common.CurrentTenant(c)}
+	if userID := c.GetString("user_id"); userID != "" {
+		match.UserID = &userID
+	}
+	if plotID := c.Query("plot_id"); plotID != "" {
+		match.PlotID = &plotID
+	}
+	if person := c.Query("person"); person != "" {
+		match.Person = normalizePerson(&person)
+	}
+	if match.UserID == nil && match.PlotID == nil && match.Person == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "plot_id or person is required without a token"})
+		return
+	}
+
+	acts, err := GetBusyActivities(match, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activities"})
+		return
+	}
+	busy, free := BuildFreeBusy(acts, from, to, loc)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"from":     from,
+			"to":       to,
+			"timezone": loc.String(),
+			"busy":     busy,
+			"free":     free,
+		},
+	})
+}
+
+// parseFreeBusyRange parses an inclusive range of days in loc, at most maxAgendaDays long
+func parseFreeBusyRange(fromStr, toStr string, loc *time.Location) (time.Time, time.Time, error) {
+	if fromStr == "" || toStr == "" {
+		return time.Time{}, time.Time{}, errors.New("from and to are required (YYYY-MM-DD)")
+	}
+	from, err := time.ParseInLocation(common.DateLayout, fromStr, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid from, expected YYYY-MM-DD")
+	}
+	last, err := time.ParseInLocation(common.DateLayout, toStr, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid to, expected YYYY-MM-DD")
+	}
+	to := last.AddDate(0, 0, 1)
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	if daysBetween(from, to) > maxAgendaDays {
+		return time.Time{}, time.Time{}, errors.New("free-busy range must be at most 92 days")
+	}
+	return from, to, nil
+}
@@ -0,0 +1,363 @@
+package activities
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/common/dbtest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// timedActivity creates an activity of the owner from start to end, "2006-01-02 15:04" in vietnam
+func timedActivity(t *testing.T, id, start, end string) Activity {
+	t.Helper()
+	timeStart := at(t, start, vietnam)
+	timeEnd := at(t, end, vietnam)
+	return Activity{ID: id, Title: id, UserID: strPtr("owner"), TimeStart: &timeStart, TimeEnd: &timeEnd}
+}
+
+// period formats a range of the 5th of March 2025 as "15:04-15:04", its end is "24:00"
+func period(start, end time.Time) string {
+	clock := func(t time.Time) string {
+		if t.In(vietnam).Format(common.DateLayout) == "2025-03-06" {
+			return "24:00"
+		}
+		return t.In(vietnam).Format("15:04")
+	}
+	return clock(start) + "-" + clock(end)
+}
+
+func TestBuildFreeBusy(t *testing.T) {
+	allDay := timedActivity(t, "all-day", "2025-03-05 00:00", "2025-03-06 00:00")
+	allDay.Day = boolPtr(true)
+	daily := timedActivity(t, "daily", "2025-03-01 12:00", "2025-03-01 13:00")
+	daily.Repeat = strPtr("daily")
+
+	tests := []struct {
+		name       string
+		activities []Activity
+		busy       []string
+		ids        [][]string
+		free       []string
+	}{
+		{
+			name: "empty day",
+			free: []string{"00:00-24:00"},
+		},
+		{
+			name:       "separate",
+			activities: []Activity{timedActivity(t, "a", "2025-03-05 08:00", "2025-03-05 09:00"), timedActivity(t, "b", "2025-03-05 10:00", "2025-03-05 11:00")},
+			busy:       []string{"08:00-09:00", "10:00-11:00"},
+			ids:        [][]string{{"a"}, {"b"}},
+			free:       []string{"00:00-08:00", "09:00-10:00", "11:00-24:00"},
+		},
+		{
+			name:       "overlapping merge",
+			activities: []Activity{timedActivity(t, "a", "2025-03-05 08:00", "2025-03-05 10:00"), timedActivity(t, "b", "2025-03-05 09:00", "2025-03-05 11:00")},
+			busy:       []string{"08:00-11:00"},
+			ids:        [][]string{{"a", "b"}},
+			free:       []string{"00:00-08:00", "11:00-24:00"},
+		},
+		{
+			name:       "touching merge",
+			activities: []Activity{timedActivity(t, "b", "2025-03-05 09:00", "2025-03-05 10:00"), timedActivity(t, "a", "2025-03-05 08:00", "2025-03-05 09:00")},
+			busy:       []string{"08:00-10:00"},
+			ids:        [][]string{{"a", "b"}},
+			free:       []string{"00:00-08:00", "10:00-24:00"},
+		},
+		{
+			name:       "contained",
+			activities: []Activity{timedActivity(t, "a", "2025-03-05 08:00", "2025-03-05 12:00"), timedActivity(t, "b", "2025-03-05 09:00", "2025-03-05 10:00")},
+			busy:       []string{"08:00-12:00"},
+			ids:        [][]string{{"a", "b"}},
+			free:       []string{"00:00-08:00", "12:00-24:00"},
+		},
+		{
+			name:       "clipped to the range",
+			activities: []Activity{timedActivity(t, "night", "2025-03-04 22:00", "2025-03-05 06:00"), timedActivity(t, "late", "2025-03-05 23:00", "2025-03-06 02:00")},
+			busy:       []string{"00:00-06:00", "23:00-24:00"},
+			ids:        [][]string{{"night"}, {"late"}},
+			free:       []string{"06:00-23:00"},
+		},
+		{
+			name:       "outside and untimed skipped",
+			activities: []Activity{timedActivity(t, "before", "2025-03-04 08:00", "2025-03-04 09:00"), allDay, {ID: "no-time"}},
+			free:       []string{"00:00-24:00"},
+		},
+		{
+			name:       "recurring",
+			activities: []Activity{daily, timedActivity(t, "lunch", "2025-03-05 12:30", "2025-03-05 14:00")},
+			busy:       []string{"12:00-14:00"},
+			ids:        [][]string{{"daily", "lunch"}},
+			free:       []string{"00:00-12:00", "14:00-24:00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			busy, free := BuildFreeBusy(tt.activities, at(t, "2025-03-05 00:00", vietnam), at(t, "2025-03-06 00:00", vietnam), vietnam)
+
+			gotBusy, gotIDs := []string{}, [][]string{}
+			for _, b := range busy {
+				gotBusy = append(gotBusy, period(b.Start, b.End))
+				gotIDs = append(gotIDs, b.ActivityIDs)
+			}
+			if tt.busy == nil {
+				tt.busy, tt.ids = []string{}, [][]string{}
+			}
+			if !reflect.DeepEqual(gotBusy, tt.busy) || !reflect.DeepEqual(gotIDs, tt.ids) {
+				t.Fatalf("got busy %v %v, want %v %v", gotBusy, gotIDs, tt.busy, tt.ids)
+			}
+
+			gotFree := []string{}
+			for _, f := range free {
+				gotFree = append(gotFree, period(f.Start, f.End))
+			}
+			if !reflect.DeepEqual(gotFree, tt.free) {
+				t.Fatalf("got free %v, want %v", gotFree, tt.free)
+			}
+		})
+	}
+}
+
+func TestConflictReasons(t *testing.T) {
+	assignee := func(userID, workerID string) ActivityAssignee {
+		a := ActivityAssignee{}
+		if userID != "" {
+			a.UserID = strPtr(userID)
+		}
+		if workerID != "" {
+			a.WorkerID = strPtr(workerID)
+		}
+		return a
+	}
+
+	tests := []struct {
+		name       string
+		a, b       Activity
+		aAssignees []ActivityAssignee
+		bAssignees []ActivityAssignee
+		want       []string
+	}{
+		{"nothing shared", Activity{UserID: strPtr("u1")}, Activity{UserID: strPtr("u2")}, nil, nil, nil},
+		{"same owner", Activity{UserID: strPtr("u1")}, Activity{UserID: strPtr("u1")}, nil, nil, []string{ConflictSameOwner}},
+		{"same plot", Activity{PlotID: strPtr("p1")}, Activity{PlotID: strPtr("p1")}, nil, nil, []string{ConflictSamePlot}},
+		{"other plot", Activity{PlotID: strPtr("p1")}, Activity{PlotID: strPtr("p2")}, nil, nil, nil},
+		{"same person ignoring case", Activity{TargetPerson: strPtr(" Anh Ba ")}, Activity{TargetPerson: strPtr("anh ba")}, nil, nil, []string{ConflictSamePerson}},
+		{"blank person", Activity{TargetPerson: strPtr(" ")}, Activity{TargetPerson: strPtr("")}, nil, nil, nil},
+		{
+			"same assigned user", Activity{}, Activity{},
+			[]ActivityAssignee{assignee("u3", "")}, []ActivityAssignee{assignee("", "w1"), assignee("u3", "")},
+			[]string{ConflictSameAssignee},
+		},
+		{
+			"same assigned worker", Activity{}, Activity{},
+			[]ActivityAssignee{assignee("", "w1")}, []ActivityAssignee{assignee("", "w1")},
+			[]string{ConflictSameAssignee},
+		},
+		{
+			"user and worker do not match", Activity{}, Activity{},
+			[]ActivityAssignee{assignee("x", "")}, []ActivityAssignee{assignee("", "x")},
+			nil,
+		},
+		{
+			"everything", Activity{UserID: strPtr("u1"), PlotID: strPtr("p1"), TargetPerson: strPtr("Ba")},
+			Activity{UserID: strPtr("u1"), PlotID: strPtr("p1"), TargetPerson: strPtr("ba")},
+			[]ActivityAssignee{assignee("", "w1")}, []ActivityAssignee{assignee("", "w1")},
+			[]string{ConflictSameOwner, ConflictSamePlot, ConflictSamePerson, ConflictSameAssignee},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := conflictReasons(&tt.a, &tt.b, tt.aAssignees, tt.bAssignees)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindBatchConflicts(t *testing.T) {
+	weekly := timedActivity(t, "weekly", "2025-02-26 08:30", "2025-02-26 09:30")
+	weekly.Repeat = strPtr("weekly")
+	otherOrg := timedActivity(t, "other-org", "2025-03-05 08:00", "2025-03-05 09:00")
+	otherOrg.OrganizationID = strPtr("org-1")
+	otherOwner := timedActivity(t, "other-owner", "2025-03-05 08:00", "2025-03-05 09:00")
+	otherOwner.UserID = strPtr("someone")
+
+	tests := []struct {
+		name    string
+		batch   []Activity
+		indexes []int
+		starts  []string
+	}{
+		{"overlapping", []Activity{timedActivity(t, "a", "2025-03-05 08:30", "2025-03-05 10:00")}, []int{0}, []string{"08:30"}},
+		{"touching", []Activity{timedActivity(t, "a", "2025-03-05 09:00", "2025-03-05 10:00")}, nil, nil},
+		{"other organization", []Activity{otherOrg}, nil, nil},
+		{"nothing shared", []Activity{otherOwner}, nil, nil},
+		{"recurring", []Activity{weekly}, []int{0}, []string{"08:30"}},
+		{
+			"sorted by start",
+			[]Activity{timedActivity(t, "late", "2025-03-05 08:45", "2025-03-05 10:00"), {}, timedActivity(t, "early", "2025-03-05 07:00", "2025-03-05 08:15")},
+			[]int{2, 0}, []string{"07:00", "08:45"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			activity := timedActivity(t, "new", "2025-03-05 08:00", "2025-03-05 09:00")
+			batch := make([]*Activity, len(tt.batch))
+			for i := range tt.batch {
+				if tt.batch[i].ID != "" {
+					batch[i] = &tt.batch[i]
+				}
+			}
+
+			conflicts := FindBatchConflicts(&activity, batch, vietnam)
+			if len(conflicts) != len(tt.indexes) {
+				t.Fatalf("got %d conflicts %+v, want %d", len(conflicts), conflicts, len(tt.indexes))
+			}
+			for i, conflict := range conflicts {
+				if conflict.BatchIndex == nil || *conflict.BatchIndex != tt.indexes[i] || conflict.ActivityID != "" {
+					t.Fatalf("conflict %d: got batch index %v activity %q, want %d", i, conflict.BatchIndex, conflict.ActivityID, tt.indexes[i])
+				}
+				if start := conflict.Start.In(vietnam).Format("15:04"); start != tt.starts[i] {
+					t.Fatalf("conflict %d: got start %s, want %s", i, start, tt.starts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOverlapsAny(t *testing.T) {
+	occurrence := func(start, end string) Occurrence {
+		s := at(t, "2025-03-05 "+start, vietnam)
+		e := at(t, "2025-03-05 "+end, vietnam)
+		return Occurrence{Start: s, End: &e}
+	}
+	own := []Occurrence{occurrence("08:00", "09:00"), occurrence("12:00", "13:00")}
+
+	tests := []struct {
+		name       string
+		start, end string
+		want       bool
+	}{
+		{"before", "06:00", "07:00", false},
+		{"ends at the start", "07:00", "08:00", false},
+		{"overlaps the start", "07:30", "08:30", true},
+		{"inside", "12:15", "12:45", true},
+		{"covers", "07:00", "14:00", true},
+		{"between", "09:00", "12:00", false},
+		{"after", "13:00", "14:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overlapsAny(own, occurrence(tt.start, tt.end)); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFreeBusyRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		days    int
+		wantErr bool
+	}{
+		{"one day", "2025-03-05", "2025-03-05", 1, false},
+		{"a month", "2025-03-01", "2025-03-31", 31, false},
+		{"longest", "2025-01-01", "2025-04-02", maxAgendaDays, false},
+		{"too long", "2025-01-01", "2025-04-03", 0, true},
+		{"reversed", "2025-03-05", "2025-03-04", 0, true},
+		{"missing", "", "2025-03-04", 0, true},
+		{"bad date", "2025-03-05", "05/03/2025", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := parseFreeBusyRange(tt.from, tt.to, vietnam)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && daysBetween(from, to) != tt.days {
+				t.Fatalf("got %d days, want %d", daysBetween(from, to), tt.days)
+			}
+		})
+	}
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func TestCreateActivityHandlerScheduleConflicts(t *testing.T) {
+	body := `{"title": "Phun thuốc", "type": "labor", "target_person": "Anh Ba", "time_start": "2025-03-05T08:00:00+07:00", "time_end": "2025-03-05T10:00:00+07:00"}`
+	stored := func(start, end string) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "user_id", "title", "type", "time_start", "time_end"}).
+			AddRow("stored", "owner", "Bón phân", "labor", at(t, start, vietnam), at(t, end, vietnam))
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		rows     *sqlmock.Rows
+		status   int
+		warnings int
+	}{
+		{"overlap warns", "", stored("2025-03-05 09:00", "2025-03-05 11:00"), http.StatusCreated, 1},
+		{"strict overlap is rejected", "?strict=true", stored("2025-03-05 09:00", "2025-03-05 11:00"), http.StatusConflict, 0},
+		{"strict touching is created", "?strict=true", stored("2025-03-05 10:00", "2025-03-05 11:00"), http.StatusCreated, 0},
+		{"strict without candidates is created", "?strict=true", sqlmock.NewRows([]string{"id"}), http.StatusCreated, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := dbtest.Mock(t)
+			mock.ExpectQuery(`SELECT \* FROM "activities" WHERE \(user_id = \$1 OR LOWER\(TRIM\(target_person\)\) = \$2\)`).
+				WillReturnRows(tt.rows)
+			if tt.name != "strict without candidates is created" {
+				mock.ExpectQuery(`SELECT \* FROM "activity_assignees" WHERE activity_id IN \(\$1\)`).
+					WithArgs("stored").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			}
+			if tt.status == http.StatusCreated {
+				mock.ExpectQuery(`INSERT INTO "activities"`).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+			}
+
+			w := dbtest.Serve(http.MethodPost, "/activities", "/activities"+tt.query, body, nil, "owner", CreateActivityHandler)
+			if w.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+
+			var response struct {
+				Conflicts []ScheduleConflict `json:"conflicts"`
+				Warnings  []ScheduleConflict `json:"warnings"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			reported := response.Warnings
+			if tt.status == http.StatusConflict {
+				reported = response.Conflicts
+			}
+			want := tt.warnings
+			if tt.status == http.StatusConflict {
+				want = 1
+			}
+			if len(reported) != want {
+				t.Fatalf("got %d conflicts %+v, want %d", len(reported), reported, want)
+			}
+			if want > 0 {
+				conflict := reported[0]
+				if conflict.ActivityID != "stored" || !reflect.DeepEqual(conflict.Reasons, []string{ConflictSameOwner}) ||
+					!conflict.Start.Equal(at(t, "2025-03-05 09:00", vietnam)) {
+					t.Fatalf("got conflict %+v, want the stored activity from 09:00 for the same owner", conflict)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package activities
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// exportHeader are the columns of an activities export
+var exportHeader = []string{
+	"id", "type", "title", "time_start", "time_end", "money", "object", "amount", "unit",
+	"plot_id", "season_id", "target_person", "source_person", "repeat", "alert_time", "note",
+	"status", "completed_at", "created_at",
+}
+
+// ExportActivitiesHandler exports the activities matching the list filters as CSV or XLSX
+// GET /api/v1/activities/export?format=csv|xlsx
+func ExportActivitiesHandler(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "format must be csv or xlsx",
+		})
+		return
+	}
+
+	filter, err := ParseActivityFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	activities, err := FindAllActivities(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activities",
+		})
+		return
+	}
+
+	rows := [][]string{exportHeader}
+	for i := range activities {
+		rows = append(rows, exportRow(&activities[i]))
+	}
+
+	filename := fmt.Sprintf("activities-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		writer := csv.NewWriter(c.Writer)
+		if err := writer.WriteAll(rows); err != nil {
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	file, err := WriteExcelRows(rows)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to build export",
+		})
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	if err := file.Write(c.Writer); err != nil {
+		c.Status(http.StatusInternalServerError)
+	}
+}
+
+// WriteExcelRows writes rows to the first sheet of a new workbook
+func WriteExcelRows(rows [][]string) (*excelize.File, error) {
+	file := excelize.NewFile()
+	sheet := file.GetSheetName(0)
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		values := make([]interface{}, len(row))
+		for j, value := range row {
+			values[j] = value
+		}
+		if err := file.SetSheetRow(sheet, cell, &values); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+// exportRow formats an activity in the order of exportHeader
+func exportRow(a *Activity) []string {
+	str := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+	timestamp := func(value *time.Time) string {
+		if value == nil {
+			return ""
+		}
+		return value.Format(time.RFC3339)
+	}
+
+	money := ""
+	if a.Money != nil {
+		money = strconv.FormatFloat(*a.Money, 'f', 2, 64)
+	}
+	amount := ""
+	if a.Amount != nil {
+		amount = strconv.Itoa(*a.Amount)
+	}
+
+	return []string{
+		a.ID, a.Type, a.Title, timestamp(a.TimeStart), timestamp(a.TimeEnd), money, str(a.Object), amount, str(a.Unit),
+		str(a.PlotID), str(a.SeasonID), str(a.TargetPerson), str(a.SourcePerson), str(a.Repeat), str(a.AlertTime),
+		str(a.Note), a.Status, timestamp(a.CompletedAt), a.CreatedAt.Format(time.RFC3339),
+	}
+}
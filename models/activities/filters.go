@@ -0,0 +1,262 @@
+package activities
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"plantheon-backend/common"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SortColumns are the indexed columns activities can be sorted by
+var SortColumns = []string{"created_at", "updated_at", "time_start", "money", "type", "title"}
+
+// ActivityFilter holds the list filters shared by the list, all, count and export endpoints.
+// Empty fields do not filter.
+type ActivityFilter struct {
+	Types     []string
+	Search    string
+	From      *time.Time // time_start >= From
+	To        *time.Time // time_start < To
+	MinMoney  *float64
+	MaxMoney  *float64
+	PlotID    string
+	SeasonID  string
+	Person    string // matched against target_person and source_person
+	ContactID string // matched against target_contact_id and source_contact_id
+	HasAlert  *bool
+	Repeat    string // "true"/"false" for any/no repeat, otherwise the exact repeat value
+	Status    string // a stored status or "overdue"
+	SortBy    string
+	Desc      bool
+	// Tenant is taken from the request, not from the parameters, see scopeOwned
+	Tenant common.Tenant
+}
+
+// ParseActivityFilter reads the filter from query parameters:
+// type (comma separated), search, from, to, min_money, max_money, plot_id, season_id,
+// person, contact_id, has_alert, repeat, status, sort and order
+func ParseActivityFilter(c *gin.Context) (*ActivityFilter, error) {
+	filter, err := ParseActivityFilterValues(c.Request.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+	filter.Tenant = common.CurrentTenant(c)
+	return filter, nil
+}
+
+// ParseActivityFilterValues reads the filter from the same parameters given as values
+func ParseActivityFilterValues(values url.Values) (*ActivityFilter, error) {
+	filter := &ActivityFilter{
+		Search: strings.TrimSpace(values.Get("search")),
+		Person: strings.TrimSpace(values.Get("person")),
+		Repeat: strings.TrimSpace(values.Get("repeat")),
+		SortBy: "created_at",
+		Desc:   true,
+	}
+	if sortBy, ok := values["sort"]; ok && len(sortBy) > 0 {
+		filter.SortBy = sortBy[0]
+	}
+
+	for _, t := range strings.Split(values.Get("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			filter.Types = append(filter.Types, t)
+		}
+	}
+
+	from, to, err := common.ParseDateRange(values.Get("from"), values.Get("to"))
+	if err != nil {
+		return nil, err
+	}
+	filter.From, filter.To = from, to
+
+	if filter.MinMoney, err = parseMoneyParam("min_money", values.Get("min_money")); err != nil {
+		return nil, err
+	}
+	if filter.MaxMoney, err = parseMoneyParam("max_money", values.Get("max_money")); err != nil {
+		return nil, err
+	}
+	if filter.MinMoney != nil && filter.MaxMoney != nil && *filter.MinMoney > *filter.MaxMoney {
+		return nil, errors.New("min_money must not be greater than max_money")
+	}
+
+	if plotID := values.Get("plot_id"); plotID != "" {
+		if err := validateReferenceID("plot_id", &plotID); err != nil {
+			return nil, err
+		}
+		filter.PlotID = plotID
+	}
+	if seasonID := values.Get("season_id"); seasonID != "" {
+		if err := validateReferenceID("season_id", &seasonID); err != nil {
+			return nil, err
+		}
+		filter.SeasonID = seasonID
+	}
+
+	if contactID := values.Get("contact_id"); contactID != "" {
+		if err := validateReferenceID("contact_id", &contactID); err != nil {
+			return nil, err
+		}
+		filter.ContactID = contactID
+	}
+
+	if hasAlert := values.Get("has_alert"); hasAlert != "" {
+		value, err := strconv.ParseBool(hasAlert)
+		if err != nil {
+			return nil, errors.New("has_alert must be true or false")
+		}
+		filter.HasAlert = &value
+	}
+
+	if status := strings.ToLower(strings.TrimSpace(values.Get("status"))); status != "" {
+		if status != "overdue" && !isStatus(status) {
+			return nil, errors.New("status must be one of " + strings.Join(ValidStatuses, ", ") + ", overdue")
+		}
+		filter.Status = status
+	}
+
+	if !isSortColumn(filter.SortBy) {
+		return nil, errors.New("sort must be one of " + strings.Join(SortColumns, ", "))
+	}
+	order := "desc"
+	if o, ok := values["order"]; ok && len(o) > 0 {
+		order = o[0]
+	}
+	switch strings.ToLower(order) {
+	case "asc":
+		filter.Desc = false
+	case "desc":
+		filter.Desc = true
+	default:
+		return nil, errors.New("order must be asc or desc")
+	}
+
+	return filter, nil
+}
+
+// parseMoneyParam parses an optional non-negative money bound
+func parseMoneyParam(name, value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	money, err := strconv.ParseFloat(value, 64)
+	if err != nil || money < 0 {
+		return nil, errors.New(name + " must be a non-negative number")
+	}
+	return &money, nil
+}
+
+// isSortColumn checks the column is one activities can be sorted by
+func isSortColumn(column string) bool {
+	for _, allowed := range SortColumns {
+		if column == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Where adds the filter conditions to a query
+func (f *ActivityFilter) Where(query *gorm.DB) *gorm.DB {
+	query = scopeOwned(query, f.Tenant)
+	if len(f.Types) == 1 {
+		query = query.Where("type = ?", f.Types[0])
+	} else if len(f.Types) > 1 {
+		query = query.Where("type IN ?", f.Types)
+	}
+	if f.Search != "" {
+		searchQuery := "%" + f.Search + "%"
+		query = query.Where("title ILIKE ? OR description ILIKE ? OR description2 ILIKE ? OR description3 ILIKE ?",
+			searchQuery, searchQuery, searchQuery, searchQuery)
+	}
+	if f.From != nil {
+		query = query.Where("time_start >= ?", *f.From)
+	}
+	if f.To != nil {
+		query = query.Where("time_start < ?", *f.To)
+	}
+	if f.MinMoney != nil {
+		query = query.Where("money >= ?", *f.MinMoney)
+	}
+	if f.MaxMoney != nil {
+		query = query.Where("money <= ?", *f.MaxMoney)
+	}
+	if f.PlotID != "" {
+		query = query.Where("plot_id = ?", f.PlotID)
+	}
+	if f.SeasonID != "" {
+		query = query.Where("season_id = ?", f.SeasonID)
+	}
+	if f.Person != "" {
+		personQuery := "%" + f.Person + "%"
+		query = query.Where("target_person ILIKE ? OR source_person ILIKE ?", personQuery, personQuery)
+	}
+	if f.ContactID != "" {
+		query = query.Where("target_contact_id = ? OR source_contact_id = ?", f.ContactID, f.ContactID)
+	}
+	if f.HasAlert != nil {
+		if *f.HasAlert {
+			query = query.Where("alert_time IS NOT NULL AND alert_time <> ''")
+		} else {
+			query = query.Where("alert_time IS NULL OR alert_time = ''")
+		}
+	}
+	switch strings.ToLower(f.Repeat) {
+	case "":
+	case "true":
+		query = query.Where("repeat IS NOT NULL AND repeat <> ''")
+	case "false":
+		query = query.Where("repeat IS NULL OR repeat = ''")
+	default:
+		query = query.Where("repeat = ?", f.Repeat)
+	}
+	switch f.Status {
+	case "":
+	case "overdue":
+		query = overdue(query, time.Now())
+	default:
+		query = query.Where("status = ?", f.Status)
+	}
+	return query
+}
+
+// Keyset is the filter's sort order for cursor pagination
+func (f *ActivityFilter) Keyset() common.Keyset {
+	sortBy := f.SortBy
+	if !isSortColumn(sortBy) {
+		sortBy = "created_at"
+	}
+	return common.Keyset{Column: sortBy, Desc: f.Desc}
+}
+
+// Order sorts a query by the filter's column, the id keeps the order stable between pages
+func (f *ActivityFilter) Order(query *gorm.DB) *gorm.DB {
+	keyset := f.Keyset()
+	direction := "ASC"
+	if keyset.Desc {
+		direction = "DESC"
+	}
+	return query.Order(keyset.Column + " " + direction + " NULLS LAST").Order("id " + direction)
+}
+
+// sortValue returns the value an activity is sorted by, used to build cursors
+func (f *ActivityFilter) sortValue(a *Activity) (interface{}, string) {
+	switch f.Keyset().Column {
+	case "updated_at":
+		return a.UpdatedAt, a.ID
+	case "time_start":
+		return a.TimeStart, a.ID
+	case "money":
+		return a.Money, a.ID
+	case "type":
+		return a.Type, a.ID
+	case "title":
+		return a.Title, a.ID
+	}
+	return a.CreatedAt, a.ID
+}
@@ -3,21 +3,87 @@ package activities
 import (
 	"time"
 
+	"plantheon-backend/common"
+	"plantheon-backend/models/inventory"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// Activity types, their fields are declared in ActivityTypes
+const (
+	TypeExpense     = "expense"
+	TypeIncome      = "income" // Money is received instead of spent
+	TypeLabor       = "labor"  // Money paid for hired work
+	TypeSpraying    = "spraying"
+	TypeFertilizing = "fertilizing"
+	TypeSowing      = "sowing"
+	TypeHarvest     = "harvest"
+	TypeReminder    = "reminder"
+	TypeOther       = "other"
+)
+
+// Task statuses. Overdue is not stored, it is computed for open activities past their end.
+const (
+	StatusPlanned    = "planned"
+	StatusInProgress = "in_progress"
+	StatusDone       = "done"
+	StatusSkipped    = "skipped"
+)
+
+// ConsumesInventory checks if the activity takes material out of stock
+func (a *Activity) ConsumesInventory() bool {
+	schema, ok := LookupType(a.Type)
+	return ok && schema.ConsumesInventory &&
+		a.UserID != nil && a.Object != nil && a.Amount != nil && *a.Amount > 0
+}
+
+// inventoryConsumption describes the material the activity takes out of its owner's stock,
+// at its start or else its creation, nil when it consumes none
+func (a *Activity) inventoryConsumption() *inventory.ActivityConsumption {
+	if !a.ConsumesInventory() {
+		return nil
+	}
+	consumption := &inventory.ActivityConsumption{
+		Tenant:     a.TenantFor(*a.UserID),
+		Object:     *a.Object,
+		Amount:     float64(*a.Amount),
+		OccurredAt: a.CreatedAt,
+	}
+	if a.TimeStart != nil {
+		consumption.OccurredAt = *a.TimeStart
+	}
+	if a.Unit != nil {
+		consumption.Unit = *a.Unit
+	}
+	return consumption
+}
+
+// consumptionChanged checks if changing the activity from stored changes what it takes out of stock
+func consumptionChanged(stored, activity *Activity) bool {
+	before, after := stored.inventoryConsumption(), activity.inventoryConsumption()
+	if before == nil || after == nil {
+		return before != after
+	}
+	return before.Tenant != after.Tenant || before.Object != after.Object || before.Amount != after.Amount ||
+		before.Unit != after.Unit || !before.OccurredAt.Equal(after.OccurredAt)
+}
+
 type Activity struct {
 	ID              string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID          *string   `json:"user_id" gorm:"type:uuid;index"`
+	OrganizationID  *string   `json:"organization_id" gorm:"type:uuid;index"` // Organization sharing the activity
+	PlotID          *string   `json:"plot_id" gorm:"type:uuid;index"`
+	SeasonID        *string   `json:"season_id" gorm:"type:uuid;index"`
 	Description     *string   `json:"description" gorm:"type:text"`
 	Description2    *string   `json:"description2" gorm:"type:text"`
 	Description3    *string   `json:"description3" gorm:"type:text"`
-	TimeStart       *time.Time `json:"time_start" gorm:"type:timestamp"`
+	TimeStart       *time.Time `json:"time_start" gorm:"type:timestamp;index"`
     TimeEnd         *time.Time `json:"time_end" gorm:"type:timestamp"`
     Day             *bool      `json:"day" gorm:"type:boolean"`
-	Money           *float64  `json:"money" gorm:"type:decimal(15,2)"`
-    Type            string    `json:"type" gorm:"type:varchar(255);not null"`
-	Title           string    `json:"title" gorm:"not null;type:varchar(255)"`
+	Money           *float64  `json:"money" gorm:"type:decimal(15,2);index"`
+    Type            string    `json:"type" gorm:"type:varchar(255);not null;index"`
+	Title           string    `json:"title" gorm:"not null;type:varchar(255);index"`
 	IsRepeat        *string   `json:"is_repeat" gorm:"type:varchar(50)"`
     Repeat          *string   `json:"repeat" gorm:"type:varchar(50)"`
 	EndRepeatDay    *time.Time `json:"end_repeat_day" gorm:"type:timestamp"`
@@ -28,17 +94,133 @@ type Activity struct {
 	Purpose         *string   `json:"purpose" gorm:"type:text"`
 	TargetPerson    *string   `json:"target_person" gorm:"type:varchar(255)"`
 	SourcePerson    *string   `json:"source_person" gorm:"type:varchar(255)"`
+	TargetContactID *string   `json:"target_contact_id" gorm:"type:uuid;index"` // Contact of target_person
+	SourceContactID *string   `json:"source_contact_id" gorm:"type:uuid;index"` // Contact of source_person
 	AttachedLink    *string   `json:"attached_link" gorm:"type:text"`
 	Note            *string   `json:"note" gorm:"type:text"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	Status          string    `json:"status" gorm:"type:varchar(20);not null;default:planned;index"`
+	CompletedAt     *time.Time `json:"completed_at" gorm:"type:timestamp"`
+	CompletedBy     *string   `json:"completed_by" gorm:"type:uuid"`
+	CompletionNote  *string   `json:"completion_note" gorm:"type:text"`
+	Version         int       `json:"version" gorm:"not null;default:1"`
+	CreatedAt       time.Time `json:"created_at" gorm:"index"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"index"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// TenantFor returns the data space of the activity for the user: its organization, or the user's own data
+func (a *Activity) TenantFor(userID string) common.Tenant {
+	tenant := common.Tenant{UserID: userID}
+	if a.OrganizationID != nil {
+		tenant.OrganizationID = *a.OrganizationID
+	}
+	return tenant
+}
+
+// tenant returns the data space the activity lives in, the one of its owner
+func (a *Activity) tenant() common.Tenant {
+	userID := ""
+	if a.UserID != nil {
+		userID = *a.UserID
+	}
+	return a.TenantFor(userID)
+}
+
+// Duplicate returns a new activity with the same content, to be saved under a new ID.
+// The copy keeps the person names but not their contacts, which may belong to someone else.
+func (a *Activity) Duplicate() *Activity {
+	duplicate := *a
+	duplicate.ID = ""
+	duplicate.Version = 0
+	duplicate.CreatedAt = time.Time{}
+	duplicate.UpdatedAt = time.Time{}
+	duplicate.DeletedAt = gorm.DeletedAt{}
+	duplicate.Status = StatusPlanned
+	duplicate.CompletedAt = nil
+	duplicate.CompletedBy = nil
+	duplicate.CompletionNote = nil
+	duplicate.TargetContactID = nil
+	duplicate.SourceContactID = nil
+	return &duplicate
 }
 
 // BeforeCreate will set a UUID rather than numeric ID.
 func (a *Activity) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	if a.Status == "" {
+		a.Status = StatusPlanned
+	}
+	return nil
+}
+
+// ActivityAssignee assigns an activity to a user or to a worker of the owner's farm,
+// exactly one of UserID and WorkerID is set
+type ActivityAssignee struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ActivityID string    `json:"activity_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_assignee_user;uniqueIndex:idx_assignee_worker"`
+	UserID     *string   `json:"user_id" gorm:"type:uuid;index;uniqueIndex:idx_assignee_user"`
+	WorkerID   *string   `json:"worker_id" gorm:"type:uuid;index;uniqueIndex:idx_assignee_worker"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ChecklistItem is a sub-item of an activity to tick off while doing it
+type ChecklistItem struct {
+	ID         string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ActivityID string     `json:"activity_id" gorm:"type:uuid;not null;index"`
+	Position   int        `json:"position" gorm:"not null;default:0"`
+	Title      string     `json:"title" gorm:"type:varchar(255);not null"`
+	Done       bool       `json:"done" gorm:"not null;default:false"`
+	DoneAt     *time.Time `json:"done_at" gorm:"type:timestamp"`
+	DoneBy     *string    `json:"done_by" gorm:"type:uuid"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName keeps checklist items next to the activities table
+func (ChecklistItem) TableName() string {
+	return "activity_checklist_items"
+}
+
+// Attachment is an uploaded file of an activity, such as a receipt photo or an invoice PDF.
+// The file itself is a media record referenced by the activity, it is private to the activity.
+type Attachment struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ActivityID string    `json:"activity_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_attachment_media"`
+	MediaID    string    `json:"media_id" gorm:"type:uuid;not null;uniqueIndex:idx_attachment_media"`
+	FileName   string    `json:"file_name" gorm:"type:varchar(255);not null"`
+	Caption    *string   `json:"caption" gorm:"type:varchar(500)"`
+	UploadedBy *string   `json:"uploaded_by" gorm:"type:uuid"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName keeps attachments next to the activities table
+func (Attachment) TableName() string {
+	return "activity_attachments"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (a *ActivityAssignee) BeforeCreate(tx *gorm.DB) error {
 	if a.ID == "" {
 		a.ID = uuid.New().String()
 	}
 	return nil
 }
 
+// BeforeCreate will set a UUID rather than numeric ID.
+func (i *ChecklistItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
+
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (a *Attachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package activities
+
+import (
+	"strings"
+	"time"
+)
+
+// Recurrence frequencies understood in the repeat field
+const (
+	RepeatDaily   = "daily"
+	RepeatWeekly  = "weekly"
+	RepeatMonthly = "monthly"
+	RepeatYearly  = "yearly"
+)
+
+// maxOccurrences bounds the expansion of one recurring activity in a calendar range
+const maxOccurrences = 1000
+
+// repeatAliases maps the values the app sends to a frequency
+var repeatAliases = map[string]string{
+	"daily": RepeatDaily, "day": RepeatDaily, "hằng ngày": RepeatDaily, "hàng ngày": RepeatDaily,
+	"weekly": RepeatWeekly, "week": RepeatWeekly, "hằng tuần": RepeatWeekly, "hàng tuần": RepeatWeekly,
+	"monthly": RepeatMonthly, "month": RepeatMonthly, "hằng tháng": RepeatMonthly, "hàng tháng": RepeatMonthly,
+	"yearly": RepeatYearly, "year": RepeatYearly, "hằng năm": RepeatYearly, "hàng năm": RepeatYearly,
+}
+
+// Occurrence is one occurrence of an activity, the activity itself when it does not repeat
+type Occurrence struct {
+	Activity  *Activity
+	Start     time.Time
+	End       *time.Time
+	Recurring bool
+}
+
+// Frequency returns how often the activity repeats, empty when it does not
+// or the repeat value is not a known frequency
+func (a *Activity) Frequency() string {
+	if a.Repeat == nil {
+		return ""
+	}
+	if a.IsRepeat != nil {
+		switch strings.ToLower(strings.TrimSpace(*a.IsRepeat)) {
+		case "false", "no", "0", "none":
+			return ""
+		}
+	}
+	return repeatAliases[strings.ToLower(strings.TrimSpace(*a.Repeat))]
+}
+
+// Occurrences expands the activity into the occurrences overlapping [from, to).
+// Repetitions keep the wall-clock time in loc across daylight saving changes.
+// Activities without time_start have no place on the calendar.
+func (a *Activity) Occurrences(from, to time.Time, loc *time.Location) []Occurrence {
+	if a.TimeStart == nil {
+		return nil
+	}
+	timeStart := a.TimeStart.In(loc)
+
+	var duration time.Duration
+	if a.TimeEnd != nil && a.TimeEnd.After(*a.TimeStart) {
+		duration = a.TimeEnd.Sub(*a.TimeStart)
+	}
+	occurrence := func(start time.Time, recurring bool) Occurrence {
+		o := Occurrence{Activity: a, Start: start, Recurring: recurring}
+		if a.TimeEnd != nil {
+			end := start.Add(duration)
+			o.End = &end
+		}
+		return o
+	}
+	overlaps := func(start time.Time) bool {
+		return start.Before(to) && !start.Add(duration).Before(from)
+	}
+
+	frequency := a.Frequency()
+	if frequency == "" {
+		if overlaps(timeStart) {
+			return []Occurrence{occurrence(timeStart, false)}
+		}
+		return nil
+	}
+
+	// The last occurrence may start on end_repeat_day, a date picked by the user
+	until := to
+	if a.EndRepeatDay != nil {
+		endRepeat := time.Date(a.EndRepeatDay.Year(), a.EndRepeatDay.Month(), a.EndRepeatDay.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+		if endRepeat.Before(until) {
+			until = endRepeat
+		}
+	}
+
+	// Skip the occurrences that ended before the range for fixed-length periods,
+	// one period early since daylight saving makes some days shorter
+	first := 0
+	if period := fixedPeriod(frequency); period > 0 {
+		if gap := from.Sub(timeStart.Add(duration)); gap > period {
+			first = int(gap/period) - 1
+		}
+	}
+
+	var occurrences []Occurrence
+	for n := first; len(occurrences) < maxOccurrences; n++ {
+		start, ok := nthOccurrence(timeStart, frequency, n)
+		if !start.Before(until) {
+			break
+		}
+		if ok && overlaps(start) {
+			occurrences = append(occurrences, occurrence(start, true))
+		}
+	}
+	return occurrences
+}
+
+// fixedPeriod returns the length of daily and weekly periods, zero for calendar periods
+func fixedPeriod(frequency string) time.Duration {
+	switch frequency {
+	case RepeatDaily:
+		return 24 * time.Hour
+	case RepeatWeekly:
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+// nthOccurrence returns the start of the nth repetition. Months or years without
+// the start's day (e.g. the 31st) have no occurrence and return false.
+func nthOccurrence(start time.Time, frequency string, n int) (time.Time, bool) {
+	var next time.Time
+	switch frequency {
+	case RepeatDaily:
+		return start.AddDate(0, 0, n), true
+	case RepeatWeekly:
+		return start.AddDate(0, 0, 7*n), true
+	case RepeatMonthly:
+		next = start.AddDate(0, n, 0)
+	default:
+		next = start.AddDate(n, 0, 0)
+	}
+	return next, next.Day() == start.Day()
+}
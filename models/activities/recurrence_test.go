@@ -0,0 +1,230 @@
+package activities
+
+import (
+	"testing"
+	"time"
+)
+
+// vietnam is the fixed +07:00 zone of the farms
+var vietnam = time.FixedZone("ICT", 7*60*60)
+
+// at parses a wall-clock time in loc, "2006-01-02 15:04"
+func at(t *testing.T, value string, loc *time.Location) time.Time {
+	t.Helper()
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func strPtr(v string) *string {
+	return &v
+}
+
+func TestActivityFrequency(t *testing.T) {
+	tests := []struct {
+		name     string
+		repeat   *string
+		isRepeat *string
+		want     string
+	}{
+		{"no repeat", nil, nil, ""},
+		{"english", strPtr("weekly"), nil, RepeatWeekly},
+		{"short", strPtr("month"), nil, RepeatMonthly},
+		{"vietnamese", strPtr(" Hằng Ngày "), strPtr("true"), RepeatDaily},
+		{"vietnamese variant", strPtr("hàng năm"), nil, RepeatYearly},
+		{"unknown", strPtr("fortnightly"), nil, ""},
+		{"turned off", strPtr("daily"), strPtr("false"), ""},
+		{"turned off with none", strPtr("daily"), strPtr("None"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			activity := &Activity{Repeat: tt.repeat, IsRepeat: tt.isRepeat}
+			if got := activity.Frequency(); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActivityOccurrences(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     string
+		end       string // empty for no time_end
+		repeat    string
+		endRepeat string // empty for no end_repeat_day
+		from      string
+		to        string
+		want      []string
+	}{
+		{
+			name: "single inside", start: "2025-03-05 08:00", end: "2025-03-05 10:00",
+			from: "2025-03-01 00:00", to: "2025-04-01 00:00",
+			want: []string{"2025-03-05 08:00"},
+		},
+		{
+			name: "single before", start: "2025-02-05 08:00",
+			from: "2025-03-01 00:00", to: "2025-04-01 00:00",
+		},
+		{
+			name: "single spanning into the range", start: "2025-02-28 20:00", end: "2025-03-01 06:00",
+			from: "2025-03-01 00:00", to: "2025-04-01 00:00",
+			want: []string{"2025-02-28 20:00"},
+		},
+		{
+			name: "daily", start: "2025-03-01 06:00", repeat: "daily",
+			from: "2025-03-03 00:00", to: "2025-03-06 00:00",
+			want: []string{"2025-03-03 06:00", "2025-03-04 06:00", "2025-03-05 06:00"},
+		},
+		{
+			name: "daily started long ago", start: "2020-01-01 06:00", repeat: "daily",
+			from: "2025-03-03 00:00", to: "2025-03-05 00:00",
+			want: []string{"2025-03-03 06:00", "2025-03-04 06:00"},
+		},
+		{
+			name: "daily overnight overlaps the range start", start: "2025-03-01 22:00", end: "2025-03-02 02:00", repeat: "daily",
+			from: "2025-03-05 00:00", to: "2025-03-06 00:00",
+			want: []string{"2025-03-04 22:00", "2025-03-05 22:00"},
+		},
+		{
+			name: "daily until end_repeat_day", start: "2025-03-01 06:00", repeat: "daily", endRepeat: "2025-03-03 00:00",
+			from: "2025-03-01 00:00", to: "2025-04-01 00:00",
+			want: []string{"2025-03-01 06:00", "2025-03-02 06:00", "2025-03-03 06:00"},
+		},
+		{
+			name: "weekly keeps the weekday", start: "2025-01-06 07:30", repeat: "weekly",
+			from: "2025-03-01 00:00", to: "2025-04-01 00:00",
+			want: []string{"2025-03-03 07:30", "2025-03-10 07:30", "2025-03-17 07:30", "2025-03-24 07:30", "2025-03-31 07:30"},
+		},
+		{
+			name: "monthly on the 31st skips short months", start: "2025-01-31 09:00", repeat: "monthly",
+			from: "2025-01-01 00:00", to: "2025-08-01 00:00",
+			want: []string{"2025-01-31 09:00", "2025-03-31 09:00", "2025-05-31 09:00", "2025-07-31 09:00"},
+		},
+		{
+			name: "monthly before the start", start: "2025-03-15 09:00", repeat: "monthly",
+			from: "2025-01-01 00:00", to: "2025-05-01 00:00",
+			want: []string{"2025-03-15 09:00", "2025-04-15 09:00"},
+		},
+		{
+			name: "yearly on leap day", start: "2024-02-29 05:00", repeat: "yearly",
+			from: "2024-01-01 00:00", to: "2030-01-01 00:00",
+			want: []string{"2024-02-29 05:00", "2028-02-29 05:00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := at(t, tt.start, vietnam)
+			activity := &Activity{TimeStart: &start}
+			if tt.end != "" {
+				end := at(t, tt.end, vietnam)
+				activity.TimeEnd = &end
+			}
+			if tt.repeat != "" {
+				activity.Repeat = strPtr(tt.repeat)
+			}
+			if tt.endRepeat != "" {
+				endRepeat := at(t, tt.endRepeat, vietnam)
+				activity.EndRepeatDay = &endRepeat
+			}
+
+			occurrences := activity.Occurrences(at(t, tt.from, vietnam), at(t, tt.to, vietnam), vietnam)
+			if len(occurrences) != len(tt.want) {
+				t.Fatalf("got %d occurrences %v, want %v", len(occurrences), occurrences, tt.want)
+			}
+			for i, occurrence := range occurrences {
+				if got := occurrence.Start.Format("2006-01-02 15:04"); got != tt.want[i] {
+					t.Fatalf("occurrence %d: got %s, want %s", i, got, tt.want[i])
+				}
+				if occurrence.Recurring != (tt.repeat != "") {
+					t.Fatalf("occurrence %d: got recurring %v", i, occurrence.Recurring)
+				}
+				if (occurrence.End != nil) != (tt.end != "") {
+					t.Fatalf("occurrence %d: got end %v", i, occurrence.End)
+				}
+				if occurrence.End != nil && occurrence.End.Sub(occurrence.Start) != activity.TimeEnd.Sub(start) {
+					t.Fatalf("occurrence %d: got duration %v", i, occurrence.End.Sub(occurrence.Start))
+				}
+			}
+		})
+	}
+}
+
+func TestActivityOccurrencesWithoutStart(t *testing.T) {
+	activity := &Activity{Repeat: strPtr("daily")}
+	if occurrences := activity.Occurrences(time.Now(), time.Now().AddDate(0, 1, 0), vietnam); occurrences != nil {
+		t.Fatalf("got %v, want none", occurrences)
+	}
+}
+
+func TestActivityOccurrencesCap(t *testing.T) {
+	tests := []struct {
+		repeat string
+		from   string
+		to     string
+	}{
+		{"daily", "2000-01-01 00:00", "2010-01-01 00:00"},
+		{"monthly", "2000-01-01 00:00", "2200-01-01 00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.repeat, func(t *testing.T) {
+			start := at(t, "2000-01-01 06:00", vietnam)
+			activity := &Activity{TimeStart: &start, Repeat: strPtr(tt.repeat)}
+			occurrences := activity.Occurrences(at(t, tt.from, vietnam), at(t, tt.to, vietnam), vietnam)
+			if len(occurrences) != maxOccurrences {
+				t.Fatalf("got %d occurrences, want %d", len(occurrences), maxOccurrences)
+			}
+		})
+	}
+}
+
+func TestActivityOccurrencesKeepWallClock(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database is not available")
+	}
+	// Summer time starts on 2025-03-30
+	start := at(t, "2025-03-28 08:00", loc)
+	activity := &Activity{TimeStart: &start, Repeat: strPtr("daily")}
+	occurrences := activity.Occurrences(at(t, "2025-03-28 00:00", loc), at(t, "2025-04-01 00:00", loc), loc)
+	if len(occurrences) != 4 {
+		t.Fatalf("got %d occurrences, want 4", len(occurrences))
+	}
+	for _, occurrence := range occurrences {
+		if occurrence.Start.Hour() != 8 || occurrence.Start.Minute() != 0 {
+			t.Fatalf("got %s, want 08:00 every day", occurrence.Start)
+		}
+	}
+}
+
+func TestNthOccurrence(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     string
+		frequency string
+		n         int
+		want      string
+		ok        bool
+	}{
+		{"daily", "2025-03-01 06:00", RepeatDaily, 40, "2025-04-10 06:00", true},
+		{"weekly", "2025-03-03 06:00", RepeatWeekly, 4, "2025-03-31 06:00", true},
+		{"monthly", "2025-01-15 06:00", RepeatMonthly, 13, "2026-02-15 06:00", true},
+		{"monthly missing day", "2025-01-31 06:00", RepeatMonthly, 1, "2025-03-03 06:00", false},
+		{"monthly 30th in february", "2025-01-30 06:00", RepeatMonthly, 1, "2025-03-02 06:00", false},
+		{"yearly", "2025-06-01 06:00", RepeatYearly, 3, "2028-06-01 06:00", true},
+		{"yearly leap day", "2024-02-29 06:00", RepeatYearly, 1, "2025-03-01 06:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := nthOccurrence(at(t, tt.start, vietnam), tt.frequency, tt.n)
+			if got.Format("2006-01-02 15:04") != tt.want || ok != tt.ok {
+				t.Fatalf("got %s %v, want %s %v", got.Format("2006-01-02 15:04"), ok, tt.want, tt.ok)
+			}
+			if tt.ok && got.Weekday() != at(t, tt.start, vietnam).Weekday() && tt.frequency == RepeatWeekly {
+				t.Fatalf("got %s, want the weekday of the start", got.Weekday())
+			}
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package activities
 
 import (
+	"log"
 	"net/http"
 	"strconv"
 	"time"
 
+	"plantheon-backend/common"
+	"plantheon-backend/models/inventory"
+	"plantheon-backend/models/units"
+
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -26,30 +31,23 @@ func CreateActivityHandler(c *gin.Context) {
 		})
 		return
 	}
+	if !units.CheckUnit(c, req.Unit, "") {
+		return
+	}
 
 	// Create activity
-    activity := &Activity{
-		Description:     req.Description,
-		Description2:    req.Description2,
-		Description3:    req.Description3,
-		TimeStart:       req.TimeStart,
-		TimeEnd:         req.TimeEnd,
-		Day:             req.Day,
-		Money:           req.Money,
-        Type:            req.Type,
-		Title:           req.Title,
-		IsRepeat:        req.IsRepeat,
-		Repeat:          req.Repeat,
-		EndRepeatDay:    req.EndRepeatDay,
-		AlertTime:       req.AlertTime,
-		Object:          req.Object,
-		Amount:          req.Amount,
-		Unit:            req.Unit,
-		Purpose:         req.Purpose,
-		TargetPerson:    req.TargetPerson,
-		SourcePerson:    req.SourcePerson,
-		AttachedLink:    req.AttachedLink,
-		Note:            req.Note,
+	activity := req.ToActivity()
+	if userID := c.GetString("user_id"); userID != "" {
+		activity.UserID = &userID
+	}
+	activity.OrganizationID = common.CurrentTenant(c).OrganizationRef()
+
+	if !checkActivityLinks(c, activity) {
+		return
+	}
+	conflicts, ok := checkScheduleConflicts(c, activity)
+	if !ok {
+		return
 	}
 
 	if err := CreateActivityRecord(activity); err != nil {
@@ -59,10 +57,17 @@ func CreateActivityHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	response := gin.H{
 		"message": "Activity created successfully",
 		"data":    activity.ToActivityResponse(),
-	})
+	}
+	if consumption := RecordInventoryConsumption(activity); consumption != nil {
+		response["inventory"] = consumption
+	}
+	if len(conflicts) > 0 {
+		response["warnings"] = conflicts
+	}
+	c.JSON(http.StatusCreated, response)
 }
 
 // GetActivity handles getting activity by ID
@@ -75,7 +80,7 @@ func GetActivity(c *gin.Context) {
 		return
 	}
 
-	activity, err := GetActivityByID(id)
+	activity, err := GetTenantActivityByID(id, common.CurrentTenant(c))
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -89,6 +94,7 @@ func GetActivity(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", common.ETag(activity.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"data": activity.ToActivityResponse(),
 	})
@@ -99,8 +105,6 @@ func GetActivities(c *gin.Context) {
 	// Parse query parameters
 	pageStr := c.DefaultQuery("page", "1")
 	limitStr := c.DefaultQuery("limit", "10")
-	activityType := c.Query("type")
-	search := c.Query("search")
 
 	page, err := strconv.Atoi(pageStr)
 	if err != nil {
@@ -112,22 +116,41 @@ func GetActivities(c *gin.Context) {
 		limit = 10
 	}
 
+	filter, err := ParseActivityFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
 	// Validate pagination
 	page, limit, _ = ValidatePaginationParams(page, limit)
-	offset := (page - 1) * limit
-
-	var activities []Activity
-	var total int64
 
-	// Handle different query types
-	if search != "" {
-		activities, total, err = SearchActivities(search, offset, limit)
-	} else if activityType != "" {
-		activities, total, err = GetActivitiesByType(activityType, offset, limit)
-	} else {
-		activities, total, err = GetAllActivities(offset, limit)
+	// Keyset pagination when a cursor is sent, page/limit otherwise
+	if common.CursorRequested(c) {
+		cursor, err := filter.Keyset().ParseCursor(c.Query("cursor"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		activities, cursorPage, err := FindActivitiesPage(filter, cursor, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to get activities",
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": ToActivitiesCursorResponse(activities, cursorPage),
+		})
+		return
 	}
+	offset := (page - 1) * limit
 
+	activities, total, err := FindActivities(filter, offset, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to get activities",
@@ -144,31 +167,15 @@ func GetActivities(c *gin.Context) {
 // GetAllActivitiesHandler handles getting all activities without pagination
 func GetAllActivitiesHandler(c *gin.Context) {
 	// Parse query parameters for filtering
-	activityType := c.Query("type")
-	search := c.Query("search")
-
-	var activities []Activity
-	var total int64
-	var err error
-
-	// Handle different query types and get count
-	if search != "" {
-		activities, err = SearchAllActivities(search)
-		if err == nil {
-			total = int64(len(activities))
-		}
-	} else if activityType != "" {
-		activities, err = GetAllActivitiesByTypeWithoutPagination(activityType)
-		if err == nil {
-			total = int64(len(activities))
-		}
-	} else {
-		activities, err = GetAllActivitiesWithoutPagination()
-		if err == nil {
-			total = int64(len(activities))
-		}
+	filter, err := ParseActivityFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
 	}
 
+	activities, err := FindAllActivities(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to get activities",
@@ -185,7 +192,7 @@ func GetAllActivitiesHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"data": gin.H{
 			"activities": response,
-			"total":      total,
+			"total":      int64(len(activities)),
 			"count":      len(response),
 		},
 	})
@@ -194,21 +201,15 @@ func GetAllActivitiesHandler(c *gin.Context) {
 // GetActivitiesCountHandler handles getting activities count only
 func GetActivitiesCountHandler(c *gin.Context) {
 	// Parse query parameters for filtering
-	activityType := c.Query("type")
-	search := c.Query("search")
-
-	var count int64
-	var err error
-
-	// Handle different query types
-	if search != "" {
-		count, err = SearchActivitiesCount(search)
-	} else if activityType != "" {
-		count, err = GetActivitiesCountByType(activityType)
-	} else {
-		count, err = GetActivitiesCount()
+	filter, err := ParseActivityFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
 	}
 
+	count, err := CountActivities(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to get activities count",
@@ -223,21 +224,27 @@ func GetActivitiesCountHandler(c *gin.Context) {
 	})
 }
 
-// GetActivitiesByDayHandler returns all activities of a specific day (UTC) with full info
-// GET /api/v1/activities/by-day?date=YYYY-MM-DD
+// GetActivitiesByDayHandler returns all activities of a specific day with full info.
+// The day is taken in the tz parameter, the user's timezone or UTC.
+// GET /api/v1/activities/by-day?date=YYYY-MM-DD&tz=Asia/Ho_Chi_Minh
 func GetActivitiesByDayHandler(c *gin.Context) {
     dateStr := c.Query("date")
     if dateStr == "" {
         c.JSON(http.StatusBadRequest, gin.H{"error": "date is required (YYYY-MM-DD)"})
         return
     }
-    day, err := time.Parse("2006-01-02", dateStr)
+    loc, err := common.RequestTimezone(c)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    day, err := time.ParseInLocation("2006-01-02", dateStr, loc)
     if err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format, expected YYYY-MM-DD"})
         return
     }
 
-    activities, err := GetActivitiesByDay(day)
+    activities, err := GetActivitiesByDay(day, loc, common.CurrentTenant(c))
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activities"})
         return
@@ -251,6 +258,7 @@ func GetActivitiesByDayHandler(c *gin.Context) {
     c.JSON(http.StatusOK, gin.H{
         "data": gin.H{
             "date":       dateStr,
+            "timezone":   loc.String(),
             "activities": response,
             "count":      len(response),
         },
@@ -259,7 +267,8 @@ func GetActivitiesByDayHandler(c *gin.Context) {
 
 // GetActivitiesCalendarByMonthHandler returns an array sized by days in month
 // Each element contains list of activities having time_start or day matching that date
-// Query: GET /api/v1/activities/calendar?year=2025&month=9
+// in the tz parameter, the user's timezone or UTC
+// Query: GET /api/v1/activities/calendar?year=2025&month=9&tz=Asia/Ho_Chi_Minh
 func GetActivitiesCalendarByMonthHandler(c *gin.Context) {
     yearStr := c.Query("year")
     monthStr := c.Query("month")
@@ -281,9 +290,14 @@ func GetActivitiesCalendarByMonthHandler(c *gin.Context) {
         c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month"})
         return
     }
+    loc, err := common.RequestTimezone(c)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
 
     // Get all activities in month
-    acts, err := GetActivitiesByMonthYear(year, month)
+    acts, err := GetActivitiesByMonthYear(year, month, loc, common.CurrentTenant(c))
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activities"})
         return
@@ -291,19 +305,19 @@ func GetActivitiesCalendarByMonthHandler(c *gin.Context) {
 
     // Prepare map date -> items
     // Determine number of days in month
-    firstDay := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+    firstDay := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)
     nextMonth := firstDay.AddDate(0, 1, 0)
     daysInMonth := int(nextMonth.Add(-time.Nanosecond).Day())
 
     dayMap := make(map[string][]ActivityCalendarItem)
     for d := 1; d <= daysInMonth; d++ {
-        key := time.Date(year, time.Month(month), d, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+        key := time.Date(year, time.Month(month), d, 0, 0, 0, 0, loc).Format("2006-01-02")
         dayMap[key] = []ActivityCalendarItem{}
     }
 
     for _, a := range acts {
         if a.TimeStart != nil {
-            key := time.Date(a.TimeStart.UTC().Year(), a.TimeStart.UTC().Month(), a.TimeStart.UTC().Day(), 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+            key := a.TimeStart.In(loc).Format("2006-01-02")
             if _, ok := dayMap[key]; ok {
                 dayMap[key] = append(dayMap[key], ActivityCalendarItem{Title: a.Title})
             }
@@ -313,7 +327,7 @@ func GetActivitiesCalendarByMonthHandler(c *gin.Context) {
     // Build ordered array by days
     var days []ActivityCalendarDay
     for d := 1; d <= daysInMonth; d++ {
-        key := time.Date(year, time.Month(month), d, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+        key := time.Date(year, time.Month(month), d, 0, 0, 0, 0, loc).Format("2006-01-02")
         days = append(days, ActivityCalendarDay{
             Date:       key,
             Activities: dayMap[key],
@@ -322,10 +336,11 @@ func GetActivitiesCalendarByMonthHandler(c *gin.Context) {
 
     c.JSON(http.StatusOK, gin.H{
         "data": gin.H{
-            "year":  year,
-            "month": month,
-            "days":  days,
-            "count": len(days),
+            "year":     year,
+            "month":    month,
+            "timezone": loc.String(),
+            "days":     days,
+            "count":    len(days),
         },
     })
 }
@@ -341,7 +356,7 @@ func UpdateActivityHandler(c *gin.Context) {
 	}
 
 	// Get existing activity
-	activity, err := GetActivityByID(id)
+	activity, err := GetOwnedActivityByID(id, common.CurrentTenant(c))
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -365,14 +380,36 @@ func UpdateActivityHandler(c *gin.Context) {
 	}
 
 	// Validate request
-	if err := ValidateUpdateActivityRequest(&req); err != nil {
+	if err := ValidateUpdateActivityRequest(&req, activity); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
+	if !units.CheckUnit(c, req.Unit, "") {
+		return
+	}
+
+	// Reject the update if the client edited an outdated version
+	precondition, err := common.ParsePrecondition(c, req.Version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(activity.Version) {
+		respondActivityConflict(c, precondition, activity)
+		return
+	}
 
 	// Update activity fields if provided
+	if req.PlotID != nil {
+		activity.PlotID = req.PlotID
+	}
+	if req.SeasonID != nil {
+		activity.SeasonID = req.SeasonID
+	}
 	if req.Description != nil {
 		activity.Description = req.Description
 	}
@@ -430,6 +467,12 @@ func UpdateActivityHandler(c *gin.Context) {
 	if req.SourcePerson != nil {
 		activity.SourcePerson = req.SourcePerson
 	}
+	if req.TargetContactID != nil {
+		activity.TargetContactID = req.TargetContactID
+	}
+	if req.SourceContactID != nil {
+		activity.SourceContactID = req.SourceContactID
+	}
 	if req.AttachedLink != nil {
 		activity.AttachedLink = req.AttachedLink
 	}
@@ -437,17 +480,187 @@ func UpdateActivityHandler(c *gin.Context) {
 		activity.Note = req.Note
 	}
 
+	if fields := setFields(&req); LinksChanged(func(field string) bool { return fields[field] }) && !checkActivityLinks(c, activity) {
+		return
+	}
+	var conflicts []ScheduleConflict
+	if fields := setFields(&req); scheduleChanged(func(field string) bool { return fields[field] }) {
+		var ok bool
+		if conflicts, ok = checkScheduleConflicts(c, activity); !ok {
+			return
+		}
+	}
+
 	// Save updated activity
 	if err := UpdateActivity(activity); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetActivityByID(id); err == nil {
+				respondActivityConflict(c, precondition, current)
+				return
+			}
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to update activity",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	c.Header("ETag", common.ETag(activity.Version))
+	response := gin.H{
+		"message": "Activity updated successfully",
+		"data":    activity.ToActivityResponse(),
+	}
+	if len(conflicts) > 0 {
+		response["warnings"] = conflicts
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// PatchActivityHandler handles partial activity update with JSON merge patch (RFC 7396)
+func PatchActivityHandler(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Activity ID is required",
+		})
+		return
+	}
+
+	activity, err := GetOwnedActivityByID(id, common.CurrentTenant(c))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Activity not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activity",
+		})
+		return
+	}
+
+	patch, err := common.ParseMergePatch(c)
+	if err != nil {
+		c.JSON(common.PatchErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Reject the patch if the client edited an outdated version
+	version, err := patch.Version()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	precondition, err := common.ParsePrecondition(c, version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(activity.Version) {
+		respondActivityConflict(c, precondition, activity)
+		return
+	}
+
+	if err := ApplyActivityPatch(activity, patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if LinksChanged(patch.Has) && !checkActivityLinks(c, activity) {
+		return
+	}
+	if patch.Has("unit") && !units.CheckUnit(c, activity.Unit, "") {
+		return
+	}
+	var conflicts []ScheduleConflict
+	if scheduleChanged(patch.Has) {
+		var ok bool
+		if conflicts, ok = checkScheduleConflicts(c, activity); !ok {
+			return
+		}
+	}
+
+	if err := UpdateActivity(activity); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetActivityByID(id); err == nil {
+				respondActivityConflict(c, precondition, current)
+				return
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update activity",
+		})
+		return
+	}
+
+	c.Header("ETag", common.ETag(activity.Version))
+	response := gin.H{
 		"message": "Activity updated successfully",
 		"data":    activity.ToActivityResponse(),
+	}
+	if len(conflicts) > 0 {
+		response["warnings"] = conflicts
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RecordInventoryConsumption takes the material used by the activity out of the owner's stock.
+// Failures are logged rather than failing the request since the activity is already saved.
+func RecordInventoryConsumption(activity *Activity) *inventory.ConsumptionResult {
+	used := activity.inventoryConsumption()
+	if used == nil {
+		return nil
+	}
+
+	consumption, err := inventory.RecordActivityConsumption(activity.ID, used)
+	if err != nil {
+		log.Printf("Failed to record inventory consumption of activity %s: %v", activity.ID, err)
+		return nil
+	}
+	return consumption
+}
+
+// checkActivityLinks verifies the linked plot, season and contacts belong to the current user,
+// writing the error response and returning false otherwise.
+func checkActivityLinks(c *gin.Context, activity *Activity) bool {
+	err := ResolveActivityLinks(activity, c.GetString("user_id"))
+	if err == nil {
+		return true
+	}
+
+	switch err {
+	case ErrLinkRequiresAuth:
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+	case ErrSeasonNotFound, ErrSeasonOnOtherPlot, ErrPlotNotFound, ErrContactNotFound:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check plot, season and contacts",
+		})
+	}
+	return false
+}
+
+// respondActivityConflict returns the current representation so the client can merge and retry
+func respondActivityConflict(c *gin.Context, precondition *common.Precondition, current *Activity) {
+	c.Header("ETag", common.ETag(current.Version))
+	c.JSON(precondition.ConflictStatus(), gin.H{
+		"error": "Activity was modified by someone else",
+		"data":  current.ToActivityResponse(),
 	})
 }
 
@@ -462,7 +675,7 @@ func DeleteActivityHandler(c *gin.Context) {
 	}
 
 	// Check if activity exists
-	_, err := GetActivityByID(id)
+	_, err := GetOwnedActivityByID(id, common.CurrentTenant(c))
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -478,6 +691,12 @@ func DeleteActivityHandler(c *gin.Context) {
 
 	// Delete activity
 	if err := DeleteActivity(id); err != nil {
+		if err == ErrPaymentActivity {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to delete activity",
 		})
@@ -485,6 +704,205 @@ func DeleteActivityHandler(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Activity deleted successfully",
+		"message": "Activity moved to trash",
+	})
+}
+
+// GetTrashedActivitiesHandler handles listing soft-deleted activities with pagination
+func GetTrashedActivitiesHandler(c *gin.Context) {
+	if c.GetString("user_id") == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Authentication is required to manage the trash",
+		})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		limit = 10
+	}
+
+	// Validate pagination
+	page, limit, _ = ValidatePaginationParams(page, limit)
+	offset := (page - 1) * limit
+
+	activities, total, err := GetTrashedActivities(common.CurrentTenant(c), offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get trashed activities",
+		})
+		return
+	}
+
+	response := ToActivitiesListResponse(activities, total, page, limit)
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// RestoreActivityHandler handles moving an activity out of trash
+func RestoreActivityHandler(c *gin.Context) {
+	if c.GetString("user_id") == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Authentication is required to manage the trash",
+		})
+		return
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Activity ID is required",
+		})
+		return
+	}
+
+	if _, err := GetTrashedActivityByID(id, common.CurrentTenant(c)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Activity not found in trash",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activity",
+		})
+		return
+	}
+
+	if err := RestoreActivity(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to restore activity",
+		})
+		return
+	}
+
+	activity, err := GetTenantActivityByID(id, common.CurrentTenant(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activity",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Activity restored successfully",
+		"data":    activity.ToActivityResponse(),
+	})
+}
+
+// DuplicateActivityHandler copies an activity, optionally to a new start time, plot or season.
+// The copy belongs to the current user and gets schedule conflict warnings like a new activity.
+// POST /api/v1/activities/:id/duplicate
+func DuplicateActivityHandler(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Activity ID is required",
+		})
+		return
+	}
+
+	// The body is optional, without it the copy is made as is
+	var req DuplicateActivityRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+	}
+	if err := ValidateDuplicateActivityRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	original, err := GetTenantActivityByID(id, common.CurrentTenant(c))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Activity not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activity",
+		})
+		return
+	}
+
+	activity := original.Duplicate()
+	activity.UserID = nil
+	if userID := c.GetString("user_id"); userID != "" {
+		activity.UserID = &userID
+	}
+	if req.TimeStart != nil {
+		if activity.TimeStart != nil {
+			offset := req.TimeStart.Sub(*activity.TimeStart)
+			if activity.TimeEnd != nil {
+				timeEnd := activity.TimeEnd.Add(offset)
+				activity.TimeEnd = &timeEnd
+			}
+			if activity.EndRepeatDay != nil {
+				endRepeat := activity.EndRepeatDay.AddDate(0, 0, daysBetween(*activity.TimeStart, *req.TimeStart))
+				activity.EndRepeatDay = &endRepeat
+			}
+		}
+		activity.TimeStart = req.TimeStart
+	}
+	if req.PlotID != nil {
+		activity.PlotID = req.PlotID
+		// The season of the original may be on another plot
+		activity.SeasonID = nil
+	}
+	if req.SeasonID != nil {
+		activity.SeasonID = req.SeasonID
+	}
+
+	if !checkActivityLinks(c, activity) {
+		return
+	}
+	conflicts, ok := checkScheduleConflicts(c, activity)
+	if !ok {
+		return
+	}
+
+	if err := CreateActivityRecord(activity); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to duplicate activity",
+		})
+		return
+	}
+
+	response := gin.H{
+		"message": "Activity duplicated successfully",
+		"data":    activity.ToActivityResponse(),
+	}
+	if consumption := RecordInventoryConsumption(activity); consumption != nil {
+		response["inventory"] = consumption
+	}
+	if len(conflicts) > 0 {
+		response["warnings"] = conflicts
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetActivityTypesHandler lists the activity types with the fields each one uses,
+// so the app can render its forms from the registry
+func GetActivityTypesHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"types":         ActivityTypes,
+			"common_fields": CommonFields,
+			"count":         len(ActivityTypes),
+		},
 	})
 }
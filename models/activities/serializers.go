@@ -1,12 +1,22 @@
 package activities
 
 import (
+	"fmt"
 	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/media"
+
+	"github.com/gin-gonic/gin"
 )
 
 // ActivityResponse represents activity response
 type ActivityResponse struct {
 	ID              string     `json:"id"`
+	UserID          *string    `json:"user_id"`
+	OrganizationID  *string    `json:"organization_id"`
+	PlotID          *string    `json:"plot_id"`
+	SeasonID        *string    `json:"season_id"`
 	Description     *string    `json:"description"`
 	Description2    *string    `json:"description2"`
 	Description3    *string    `json:"description3"`
@@ -26,10 +36,19 @@ type ActivityResponse struct {
 	Purpose         *string    `json:"purpose"`
 	TargetPerson    *string    `json:"target_person"`
 	SourcePerson    *string    `json:"source_person"`
+	TargetContactID *string    `json:"target_contact_id"`
+	SourceContactID *string    `json:"source_contact_id"`
 	AttachedLink    *string    `json:"attached_link"`
 	Note            *string    `json:"note"`
+	Status          string     `json:"status"`
+	Overdue         bool       `json:"overdue"` // Open and past its end, see Activity.IsOverdue
+	CompletedAt     *time.Time `json:"completed_at"`
+	CompletedBy     *string    `json:"completed_by"`
+	CompletionNote  *string    `json:"completion_note"`
+	Version         int        `json:"version"`
 	CreatedAt       time.Time  `json:"created_at"`
 	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
 }
 
 // Minimal activity item for calendar list (only title)
@@ -55,6 +74,8 @@ type ActivityCalendarDay struct {
 
 // CreateActivityRequest represents activity creation request
 type CreateActivityRequest struct {
+	PlotID          *string    `json:"plot_id"`
+	SeasonID        *string    `json:"season_id"`
 	Description     *string    `json:"description"`
 	Description2    *string    `json:"description2"`
 	Description3    *string    `json:"description3"`
@@ -74,12 +95,16 @@ type CreateActivityRequest struct {
 	Purpose         *string    `json:"purpose"`
 	TargetPerson    *string    `json:"target_person"`
 	SourcePerson    *string    `json:"source_person"`
+	TargetContactID *string    `json:"target_contact_id"`
+	SourceContactID *string    `json:"source_contact_id"`
 	AttachedLink    *string    `json:"attached_link"`
 	Note            *string    `json:"note"`
 }
 
 // UpdateActivityRequest represents activity update request
 type UpdateActivityRequest struct {
+	PlotID          *string    `json:"plot_id"`
+	SeasonID        *string    `json:"season_id"`
 	Description     *string    `json:"description"`
 	Description2    *string    `json:"description2"`
 	Description3    *string    `json:"description3"`
@@ -99,8 +124,34 @@ type UpdateActivityRequest struct {
 	Purpose         *string    `json:"purpose"`
 	TargetPerson    *string    `json:"target_person"`
 	SourcePerson    *string    `json:"source_person"`
+	TargetContactID *string    `json:"target_contact_id"`
+	SourceContactID *string    `json:"source_contact_id"`
 	AttachedLink    *string    `json:"attached_link"`
 	Note            *string    `json:"note"`
+	Version         *int       `json:"version"` // Expected version when If-Match is not sent
+}
+
+// MoneyByType represents money totals of activities grouped by type
+type MoneyByType struct {
+	Type  string  `json:"type"`
+	Total float64 `json:"total"`
+	Count int64   `json:"count"`
+}
+
+// MoneyTotals represents expense and income totals of the activities of one season or plot
+type MoneyTotals struct {
+	Key    string  `json:"key"` // Season or plot ID
+	Cost   float64 `json:"cost"`
+	Income float64 `json:"income"`
+}
+
+// InputUsage represents the quantity of a material (fertilizer, seed, pesticide...) used by activities
+type InputUsage struct {
+	Object string  `json:"object"`
+	Unit   *string `json:"unit"`
+	Amount float64 `json:"amount"`
+	Money  float64 `json:"money"`
+	Count  int64   `json:"count"`
 }
 
 // ActivitiesListResponse represents paginated activities list response
@@ -112,10 +163,144 @@ type ActivitiesListResponse struct {
 	TotalPages int                `json:"total_pages"`
 }
 
+// ActivitiesCursorResponse represents a keyset paginated activities list response
+type ActivitiesCursorResponse struct {
+	Activities []ActivityResponse `json:"activities"`
+	common.CursorPage
+}
+
+// DuplicateActivityRequest optionally moves the copy of an activity. A new time_start
+// shifts time_end and end_repeat_day by the same amount.
+type DuplicateActivityRequest struct {
+	TimeStart *time.Time `json:"time_start"`
+	PlotID    *string    `json:"plot_id"`
+	SeasonID  *string    `json:"season_id"`
+}
+
+// BulkCreateRequest creates several activities in one transaction
+type BulkCreateRequest struct {
+	Activities []CreateActivityRequest `json:"activities"`
+}
+
+// BulkShift moves the times of activities, keeping their length
+type BulkShift struct {
+	Days    int `json:"days"`
+	Hours   int `json:"hours"`
+	Minutes int `json:"minutes"`
+}
+
+// BulkUpdateRequest applies the same merge patch and/or shift to the activities listed in IDs
+// or, without IDs, to those matching Filter (the list query parameters, e.g. {"type": "spraying"})
+type BulkUpdateRequest struct {
+	IDs    []string          `json:"ids"`
+	Filter map[string]string `json:"filter"`
+	Patch  common.MergePatch `json:"patch"`
+	Shift  *BulkShift        `json:"shift"`
+}
+
+// BulkDeleteRequest moves the activities listed in IDs or matching Filter to trash
+type BulkDeleteRequest struct {
+	IDs    []string          `json:"ids"`
+	Filter map[string]string `json:"filter"`
+}
+
+// BulkItemResult reports one item of a bulk operation. Index is the position of the
+// item in the request, or in the filter matches.
+type BulkItemResult struct {
+	Index    int                `json:"index"`
+	ID       string             `json:"id,omitempty"`
+	Status   string             `json:"status"`
+	Error    string             `json:"error,omitempty"`
+	Data     *ActivityResponse  `json:"data,omitempty"`
+	Warnings []ScheduleConflict `json:"warnings,omitempty"`
+}
+
+// BulkResponse lists the result of each item. Saved is false on dry runs
+// and when an item failed, then nothing was changed.
+type BulkResponse struct {
+	DryRun    bool             `json:"dry_run"`
+	Saved     bool             `json:"saved"`
+	Results   []BulkItemResult `json:"results"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+}
+
+// UpdateStatusRequest moves an activity to another status, with a note when it is done or skipped
+type UpdateStatusRequest struct {
+	Status  string  `json:"status" binding:"required"`
+	Note    *string `json:"note"`
+	Version *int    `json:"version"` // Expected version when If-Match is not sent
+}
+
+// AssigneeRequest names a user or a worker of the owner's farm
+type AssigneeRequest struct {
+	UserID   *string `json:"user_id"`
+	WorkerID *string `json:"worker_id"`
+}
+
+// SetAssigneesRequest replaces the assignees of an activity, an empty list unassigns everyone
+type SetAssigneesRequest struct {
+	Assignees []AssigneeRequest `json:"assignees"`
+}
+
+// AssigneeResponse represents an assignee with the name of the user or worker
+type AssigneeResponse struct {
+	ID       string  `json:"id"`
+	UserID   *string `json:"user_id"`
+	WorkerID *string `json:"worker_id"`
+	Name     string  `json:"name"`
+}
+
+// CreateChecklistItemRequest adds a sub-item at the end of the checklist
+type CreateChecklistItemRequest struct {
+	Title string `json:"title" binding:"required"`
+}
+
+// UpdateChecklistItemRequest renames, ticks or moves a checklist item
+type UpdateChecklistItemRequest struct {
+	Title    *string `json:"title"`
+	Done     *bool   `json:"done"`
+	Position *int    `json:"position"`
+}
+
+// ChecklistItemResponse represents checklist item response
+type ChecklistItemResponse struct {
+	ID       string     `json:"id"`
+	Position int        `json:"position"`
+	Title    string     `json:"title"`
+	Done     bool       `json:"done"`
+	DoneAt   *time.Time `json:"done_at"`
+	DoneBy   *string    `json:"done_by"`
+}
+
+// TaskResponse represents an activity with its assignees and checklist
+type TaskResponse struct {
+	ActivityResponse
+	Assignees     []AssigneeResponse      `json:"assignees"`
+	Checklist     []ChecklistItemResponse `json:"checklist"`
+	ChecklistDone int                     `json:"checklist_done"`
+}
+
+// MyTasksResponse represents the tasks of a worker for a day
+type MyTasksResponse struct {
+	Date    string         `json:"date"` // YYYY-MM-DD
+	Today   []TaskResponse `json:"today"`
+	Overdue []TaskResponse `json:"overdue"` // Open tasks of earlier days
+}
+
 // ToActivityResponse converts Activity to ActivityResponse
 func (a *Activity) ToActivityResponse() ActivityResponse {
+	var deletedAt *time.Time
+	if a.DeletedAt.Valid {
+		deletedAt = &a.DeletedAt.Time
+	}
+
 	return ActivityResponse{
 		ID:              a.ID,
+		UserID:          a.UserID,
+		OrganizationID:  a.OrganizationID,
+		PlotID:          a.PlotID,
+		SeasonID:        a.SeasonID,
 		Description:     a.Description,
 		Description2:    a.Description2,
 		Description3:    a.Description3,
@@ -135,10 +320,50 @@ func (a *Activity) ToActivityResponse() ActivityResponse {
 		Purpose:         a.Purpose,
 		TargetPerson:    a.TargetPerson,
 		SourcePerson:    a.SourcePerson,
+		TargetContactID: a.TargetContactID,
+		SourceContactID: a.SourceContactID,
 		AttachedLink:    a.AttachedLink,
 		Note:            a.Note,
+		Status:          a.Status,
+		Overdue:         a.IsOverdue(time.Now()),
+		CompletedAt:     a.CompletedAt,
+		CompletedBy:     a.CompletedBy,
+		CompletionNote:  a.CompletionNote,
+		Version:         a.Version,
 		CreatedAt:       a.CreatedAt,
 		UpdatedAt:       a.UpdatedAt,
+		DeletedAt:       deletedAt,
+	}
+}
+
+// ToActivity builds a new activity from the request, the caller sets the owner
+func (req *CreateActivityRequest) ToActivity() *Activity {
+	return &Activity{
+		PlotID:          req.PlotID,
+		SeasonID:        req.SeasonID,
+		Description:     req.Description,
+		Description2:    req.Description2,
+		Description3:    req.Description3,
+		TimeStart:       req.TimeStart,
+		TimeEnd:         req.TimeEnd,
+		Day:             req.Day,
+		Money:           req.Money,
+		Type:            req.Type,
+		Title:           req.Title,
+		IsRepeat:        req.IsRepeat,
+		Repeat:          req.Repeat,
+		EndRepeatDay:    req.EndRepeatDay,
+		AlertTime:       req.AlertTime,
+		Object:          req.Object,
+		Amount:          req.Amount,
+		Unit:            req.Unit,
+		Purpose:         req.Purpose,
+		TargetPerson:    req.TargetPerson,
+		SourcePerson:    req.SourcePerson,
+		TargetContactID: req.TargetContactID,
+		SourceContactID: req.SourceContactID,
+		AttachedLink:    req.AttachedLink,
+		Note:            req.Note,
 	}
 }
 
@@ -162,3 +387,85 @@ func ToActivitiesListResponse(activities []Activity, total int64, page, limit in
 		TotalPages: totalPages,
 	}
 }
+
+// ToActivitiesCursorResponse converts a keyset page of activities to response
+func ToActivitiesCursorResponse(activities []Activity, page common.CursorPage) ActivitiesCursorResponse {
+	response := make([]ActivityResponse, 0, len(activities))
+	for _, activity := range activities {
+		response = append(response, activity.ToActivityResponse())
+	}
+	return ActivitiesCursorResponse{Activities: response, CursorPage: page}
+}
+
+// ToChecklistItemResponse converts ChecklistItem to ChecklistItemResponse
+func (i *ChecklistItem) ToChecklistItemResponse() ChecklistItemResponse {
+	return ChecklistItemResponse{
+		ID:       i.ID,
+		Position: i.Position,
+		Title:    i.Title,
+		Done:     i.Done,
+		DoneAt:   i.DoneAt,
+		DoneBy:   i.DoneBy,
+	}
+}
+
+// ToTaskResponse converts an activity with its assignees and checklist to TaskResponse
+func (a *Activity) ToTaskResponse(assignees []AssigneeResponse, checklist []ChecklistItem) TaskResponse {
+	response := TaskResponse{
+		ActivityResponse: a.ToActivityResponse(),
+		Assignees:        assignees,
+		Checklist:        make([]ChecklistItemResponse, 0, len(checklist)),
+	}
+	if response.Assignees == nil {
+		response.Assignees = []AssigneeResponse{}
+	}
+	for i := range checklist {
+		response.Checklist = append(response.Checklist, checklist[i].ToChecklistItemResponse())
+		if checklist[i].Done {
+			response.ChecklistDone++
+		}
+	}
+	return response
+}
+
+// AttachmentResponse represents an activity attachment with the URLs serving its file
+type AttachmentResponse struct {
+	ID          string            `json:"id"`
+	ActivityID  string            `json:"activity_id"`
+	FileName    string            `json:"file_name"`
+	Caption     *string           `json:"caption"`
+	URL         string            `json:"url"`
+	Thumbnails  map[string]string `json:"thumbnails"`
+	ContentType string            `json:"content_type"`
+	Size        int64             `json:"size"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	UploadedBy  *string           `json:"uploaded_by"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// ToAttachmentResponse converts Attachment and its media to AttachmentResponse.
+// The URLs point to the activity's attachment routes, which check access, not to the public media routes.
+func (a *Attachment) ToAttachmentResponse(c *gin.Context, m *media.Media) AttachmentResponse {
+	url := fmt.Sprintf("%s/api/v1/activities/%s/attachments/%s/content", media.BaseURL(c), a.ActivityID, a.ID)
+	response := AttachmentResponse{
+		ID:         a.ID,
+		ActivityID: a.ActivityID,
+		FileName:   a.FileName,
+		Caption:    a.Caption,
+		URL:        url,
+		Thumbnails: map[string]string{},
+		UploadedBy: a.UploadedBy,
+		CreatedAt:  a.CreatedAt,
+	}
+	if m != nil {
+		for _, size := range m.Thumbnails {
+			response.Thumbnails[size] = url + "?size=" + size
+		}
+		response.ContentType = m.ContentType
+		response.Size = m.Size
+		response.Width = m.Width
+		response.Height = m.Height
+	}
+	return response
+}
@@ -1,12 +1,47 @@
 package activities
 
 import (
+	"errors"
+	"log"
 	"plantheon-backend/common"
+	"plantheon-backend/models/inventory"
+	"plantheon-backend/models/media"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// Errors returned by ResolveActivityLinks
+var (
+	ErrLinkRequiresAuth  = errors.New("Authentication is required to link an activity to a plot, season or contact")
+	ErrSeasonNotFound    = errors.New("Season not found")
+	ErrSeasonOnOtherPlot = errors.New("Season belongs to another plot")
+	ErrPlotNotFound      = errors.New("Plot not found")
+	ErrContactNotFound   = errors.New("Contact not found")
+)
+
+// ErrPaymentActivity is returned when deleting the ledger activity of a worker payment,
+// the payment has to be cancelled instead so its work sessions become unpaid again
+var ErrPaymentActivity = errors.New("Activity records a worker payment, cancel the payment instead")
+
+// paymentActivities selects the ledger activities of the worker payments that are not cancelled
+const paymentActivities = "SELECT activity_id FROM worker_payments WHERE deleted_at IS NULL"
+
+// linkFields are the fields referencing records owned by the user
+var linkFields = []string{"plot_id", "season_id", "target_contact_id", "source_contact_id"}
+
+// LinksChanged checks if a request or patch setting these fields links the activity
+// to other records, which ResolveActivityLinks must then verify
+func LinksChanged(has func(field string) bool) bool {
+	for _, field := range linkFields {
+		if has(field) {
+			return true
+		}
+	}
+	return false
+}
+
 // ActivityService handles all database operations for activities
 type ActivityService struct {
 	db *gorm.DB
@@ -33,150 +68,927 @@ func GetActivityByID(id string) (*Activity, error) {
 	return &activity, err
 }
 
-// GetAllActivities gets all activities with pagination
-func GetAllActivities(offset, limit int) ([]Activity, int64, error) {
+// FindActivities gets activities matching the filter with pagination
+func FindActivities(filter *ActivityFilter, offset, limit int) ([]Activity, int64, error) {
 	service := NewActivityService()
 	var activities []Activity
 	var total int64
 	
+	query := filter.Where(service.db.Model(&Activity{}))
+	
 	// Count total records
-	if err := service.db.Model(&Activity{}).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 	
 	// Get paginated results
-	err := service.db.Offset(offset).Limit(limit).Order("created_at DESC").Find(&activities).Error
+	err := filter.Order(query).Offset(offset).Limit(limit).Find(&activities).Error
 	return activities, total, err
 }
 
-// GetActivitiesByType gets activities by type with pagination
-func GetActivitiesByType(activityType string, offset, limit int) ([]Activity, int64, error) {
+// FindActivitiesPage gets a page of activities matching the filter after the cursor,
+// the cursor is nil for the first page
+func FindActivitiesPage(filter *ActivityFilter, cursor *common.Cursor, limit int) ([]Activity, common.CursorPage, error) {
 	service := NewActivityService()
 	var activities []Activity
-	var total int64
-	
-	query := service.db.Where("type = ?", activityType)
-	
-	// Count total records
-	if err := query.Model(&Activity{}).Count(&total).Error; err != nil {
-		return nil, 0, err
+	keyset := filter.Keyset()
+	if err := keyset.Apply(filter.Where(service.db), cursor, limit).Find(&activities).Error; err != nil {
+		return nil, common.CursorPage{}, err
 	}
-	
-	// Get paginated results
-	err := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&activities).Error
-	return activities, total, err
+	activities, page := common.Page(activities, cursor, limit, keyset, filter.sortValue)
+	return activities, page, nil
+}
+
+// FindAllActivities gets all activities matching the filter without pagination
+func FindAllActivities(filter *ActivityFilter) ([]Activity, error) {
+	service := NewActivityService()
+	var activities []Activity
+	err := filter.Order(filter.Where(service.db)).Find(&activities).Error
+	return activities, err
+}
+
+// CountActivities gets count of activities matching the filter
+func CountActivities(filter *ActivityFilter) (int64, error) {
+	service := NewActivityService()
+	var count int64
+	err := filter.Where(service.db.Model(&Activity{})).Count(&count).Error
+	return count, err
+}
+
+// UpdateActivity updates activity information
+// The update only applies if the stored version still equals activity.Version,
+// otherwise common.ErrVersionConflict is returned. On success the version is incremented.
+func UpdateActivity(activity *Activity) error {
+	service := NewActivityService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		return updateActivity(tx, activity)
+	})
+}
+
+// updateActivity saves the activity with the version check of UpdateActivity in the transaction tx,
+// moving its stock movement when what it consumes changed
+func updateActivity(tx *gorm.DB, activity *Activity) error {
+	expected := activity.Version
+	var stored Activity
+	err := tx.Select("id", "type", "user_id", "organization_id", "object", "amount", "unit", "time_start", "created_at").
+		Where("id = ? AND version = ?", activity.ID, expected).First(&stored).Error
+	if err == gorm.ErrRecordNotFound {
+		return common.ErrVersionConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	activity.Version = expected + 1
+	result := tx.Model(activity).
+		Where("version = ?", expected).
+		Select("*").Omit("id", "created_at").
+		Updates(activity)
+	err = result.Error
+	if err == nil && result.RowsAffected == 0 {
+		err = common.ErrVersionConflict
+	}
+	if err == nil && consumptionChanged(&stored, activity) {
+		err = syncInventoryConsumption(tx, activity)
+	}
+	if err != nil {
+		activity.Version = expected
+	}
+	return err
+}
+
+// syncInventoryConsumption updates the stock movement of the activity in the transaction tx
+// changing it, see inventory.SyncActivityConsumption
+func syncInventoryConsumption(tx *gorm.DB, activity *Activity) error {
+	return inventory.SyncActivityConsumption(tx, activity.ID, activity.inventoryConsumption())
+}
+
+// GetTenantActivityByID finds an activity to read by ID: activities outside organizations can be
+// read by anyone knowing the ID, those of another organization are not found. Changes use GetOwnedActivityByID.
+func GetTenantActivityByID(id string, tenant common.Tenant) (*Activity, error) {
+	service := NewActivityService()
+	var activity Activity
+	err := tenant.ScopeShared(service.db.Where("id = ?", id)).First(&activity).Error
+	return &activity, err
+}
+
+// GetOwnedActivityByID finds an activity the tenant may change, see scopeOwned
+func GetOwnedActivityByID(id string, tenant common.Tenant) (*Activity, error) {
+	service := NewActivityService()
+	var activity Activity
+	err := scopeOwned(service.db.Where("id = ?", id), tenant).First(&activity).Error
+	return &activity, err
+}
+
+// GetActivitiesByIDs finds the activities with the given IDs the tenant may change, missing ones are left out
+func GetActivitiesByIDs(ids []string, tenant common.Tenant) ([]Activity, error) {
+	service := NewActivityService()
+	var activities []Activity
+	err := scopeOwned(service.db.Where("id IN ?", ids), tenant).Find(&activities).Error
+	return activities, err
+}
+
+// scopeOwned restricts query to the activities of the tenant: those of its organization, or outside
+// organizations the user's own activities and those created without a token, which stay open to everyone
+func scopeOwned(query *gorm.DB, tenant common.Tenant) *gorm.DB {
+	if tenant.InOrganization() {
+		return tenant.Scope(query)
+	}
+	if tenant.UserID == "" {
+		return query.Where("user_id IS NULL AND organization_id IS NULL")
+	}
+	return query.Where("(user_id = ? OR user_id IS NULL) AND organization_id IS NULL", tenant.UserID)
+}
+
+// BulkCreateActivities creates all the activities or none of them
+func BulkCreateActivities(activities []*Activity) error {
+	service := NewActivityService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		for _, activity := range activities {
+			if err := tx.Create(activity).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// BulkUpdateActivities saves all the activities or none of them. Each update checks the
+// version like UpdateActivity, the index of the activity modified meanwhile is returned
+// with common.ErrVersionConflict. After an error the in-memory versions are not reliable.
+func BulkUpdateActivities(activities []*Activity) (int, error) {
+	service := NewActivityService()
+	failed := -1
+	err := service.db.Transaction(func(tx *gorm.DB) error {
+		for i, activity := range activities {
+			if err := updateActivity(tx, activity); err != nil {
+				failed = i
+				return err
+			}
+		}
+		return nil
+	})
+	return failed, err
+}
+
+// BulkDeleteActivities moves all the activities to trash or none of them, giving their material back to stock.
+// The ledger activities of worker payments are kept, see GetPaymentActivityIDs.
+func BulkDeleteActivities(ids []string) (int64, error) {
+	service := NewActivityService()
+	var deleted int64
+	err := service.db.Transaction(func(tx *gorm.DB) error {
+		var deletable []string
+		if err := tx.Model(&Activity{}).Where("id IN ? AND id NOT IN ("+paymentActivities+")", ids).
+			Pluck("id", &deletable).Error; err != nil {
+			return err
+		}
+		if len(deletable) == 0 {
+			return nil
+		}
+		result := tx.Where("id IN ?", deletable).Delete(&Activity{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return inventory.RemoveActivityConsumption(tx, deletable)
+	})
+	return deleted, err
+}
+
+// DeleteActivity moves activity to trash by ID, giving its material back to stock.
+// It returns ErrPaymentActivity for the ledger activity of a worker payment.
+func DeleteActivity(id string) error {
+	service := NewActivityService()
+	var deleted int64
+	err := service.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND id NOT IN ("+paymentActivities+")", id).Delete(&Activity{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return inventory.RemoveActivityConsumption(tx, []string{id})
+	})
+	if err != nil || deleted > 0 {
+		return err
+	}
+	paid, err := GetPaymentActivityIDs([]string{id})
+	if err != nil {
+		return err
+	}
+	if paid[id] {
+		return ErrPaymentActivity
+	}
+	return nil
+}
+
+// GetPaymentActivityIDs returns which of the activities record a worker payment. They are
+// only removed by cancelling the payment, so its sessions never point to a deleted ledger entry.
+func GetPaymentActivityIDs(ids []string) (map[string]bool, error) {
+	service := NewActivityService()
+	var paid []string
+	err := service.db.Raw(paymentActivities+" AND activity_id IN ?", ids).Scan(&paid).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool, len(paid))
+	for _, id := range paid {
+		result[id] = true
+	}
+	return result, nil
 }
 
-// SearchActivities searches activities by title or description
-func SearchActivities(keyword string, offset, limit int) ([]Activity, int64, error) {
+// GetTrashedActivities gets soft-deleted activities of the tenant with pagination, most recently deleted first
+func GetTrashedActivities(tenant common.Tenant, offset, limit int) ([]Activity, int64, error) {
 	service := NewActivityService()
 	var activities []Activity
 	var total int64
-	
-	searchQuery := "%" + keyword + "%"
-	query := service.db.Where("title ILIKE ? OR description ILIKE ? OR description2 ILIKE ? OR description3 ILIKE ?", 
-		searchQuery, searchQuery, searchQuery, searchQuery)
-	
+
+	query := scopeOwned(service.db.Unscoped().Where("deleted_at IS NOT NULL"), tenant)
+
 	// Count total records
 	if err := query.Model(&Activity{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
-	
+
 	// Get paginated results
-	err := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&activities).Error
+	err := query.Offset(offset).Limit(limit).Order("deleted_at DESC").Find(&activities).Error
 	return activities, total, err
 }
 
-// GetAllActivitiesWithoutPagination gets all activities without pagination
-func GetAllActivitiesWithoutPagination() ([]Activity, error) {
+// GetTrashedActivityByID finds a soft-deleted activity of the tenant by ID
+func GetTrashedActivityByID(id string, tenant common.Tenant) (*Activity, error) {
+	service := NewActivityService()
+	var activity Activity
+	err := scopeOwned(service.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id), tenant).First(&activity).Error
+	return &activity, err
+}
+
+// RestoreActivity moves a soft-deleted activity out of trash, taking its material out of stock again
+func RestoreActivity(id string) error {
+	service := NewActivityService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&Activity{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Update("deleted_at", nil)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		var activity Activity
+		if err := tx.Where("id = ?", id).First(&activity).Error; err != nil {
+			return err
+		}
+		return syncInventoryConsumption(tx, &activity)
+	})
+}
+
+// PurgeTrashedActivities permanently deletes activities trashed before the given time
+// with their assignees, checklists, attachments and stock movements, cleaning up the attached files.
+// Ledger activities of worker payments are kept until the payment is cancelled.
+func PurgeTrashedActivities(before time.Time) (int64, error) {
+	service := NewActivityService()
+	var ids []string
+	if err := service.db.Unscoped().Model(&Activity{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("id NOT IN (" + paymentActivities + ")").
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var purged int64
+	err := service.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("activity_id IN ?", ids).Delete(&ActivityAssignee{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("activity_id IN ?", ids).Delete(&ChecklistItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("activity_id IN ?", ids).Delete(&Attachment{}).Error; err != nil {
+			return err
+		}
+		if err := inventory.RemoveActivityConsumption(tx, ids); err != nil {
+			return err
+		}
+		// Work sessions are kept for the payroll, only their link is dropped
+		if err := tx.Exec("UPDATE work_sessions SET activity_id = NULL WHERE activity_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id IN ? AND deleted_at IS NOT NULL", ids).Delete(&Activity{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if err := media.ReleaseOwner(media.OwnerActivity, id); err != nil {
+			log.Printf("Failed to clean up attachments of activity %s: %v", id, err)
+		}
+	}
+	return purged, nil
+}
+
+// StartTrashPurge periodically purges activities that stayed in trash longer than retention
+func StartTrashPurge(interval, retention time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if n, err := PurgeTrashedActivities(time.Now().Add(-retention)); err != nil {
+				log.Println("Activity trash purge failed:", err)
+			} else if n > 0 {
+				log.Printf("Activity trash purge removed %d activities", n)
+			}
+		}
+	}()
+}
+
+// GetActivitiesByMonthYear returns activities of the tenant whose time_start or day fall within the given month/year in loc
+func GetActivitiesByMonthYear(year int, month int, loc *time.Location, tenant common.Tenant) ([]Activity, error) {
+	service := NewActivityService()
+    startOfMonth := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)
+    startOfNextMonth := startOfMonth.AddDate(0, 1, 0)
+
+    var activities []Activity
+    // Half-open interval [startOfMonth, startOfNextMonth)
+    err := scopeOwned(service.db, tenant).Where(
+        "time_start IS NOT NULL AND time_start >= ? AND time_start < ?",
+        startOfMonth.UTC(), startOfNextMonth.UTC(),
+    ).Order("created_at DESC").Find(&activities).Error
+    return activities, err
+}
+
+// GetActivitiesByDay returns activities of the tenant that match the specific day in loc by either time_start's date or day field
+func GetActivitiesByDay(day time.Time, loc *time.Location, tenant common.Tenant) ([]Activity, error) {
+	service := NewActivityService()
+    // Normalize to date (midnight in loc)
+    start := common.StartOfDay(day, loc)
+    next := start.AddDate(0, 0, 1)
+
+    var activities []Activity
+    err := scopeOwned(service.db, tenant).Where(
+        "time_start IS NOT NULL AND time_start >= ? AND time_start < ?",
+        start.UTC(), next.UTC(),
+    ).Order("created_at DESC").Find(&activities).Error
+    return activities, err
+}
+
+// GetActivitiesInRange returns activities of the tenant that may occur in [from, to): those overlapping
+// the range and repeating ones started before it that have not stopped repeating
+func GetActivitiesInRange(from, to time.Time, tenant common.Tenant) ([]Activity, error) {
+	service := NewActivityService()
+	var activities []Activity
+	err := occursIn(scopeOwned(service.db, tenant), from, to).Order("time_start ASC").Find(&activities).Error
+	return activities, err
+}
+
+// occursIn limits the query to activities that may occur in [from, to)
+func occursIn(query *gorm.DB, from, to time.Time) *gorm.DB {
+	return query.Where("time_start IS NOT NULL AND time_start < ?", to.UTC()).
+		Where("COALESCE(time_end, time_start) >= ? OR (repeat IS NOT NULL AND repeat <> '' AND (end_repeat_day IS NULL OR end_repeat_day >= ?))",
+			from.UTC(), from.UTC().AddDate(0, 0, -1))
+}
+
+// timed limits the query to activities blocking time, see Activity.IsTimed
+func timed(query *gorm.DB) *gorm.DB {
+	return query.Where("time_end IS NOT NULL AND time_end > time_start AND (day IS NULL OR day = false)")
+}
+
+// GetScheduleCandidates returns the timed activities of the tenant of match sharing the owner,
+// the plot, the assigned person or an assignee that may occur in [from, to), except the excluded ones:
+// the activity being checked and the other activities saved along with it
+func GetScheduleCandidates(match ScheduleMatch, from, to time.Time, excludeIDs []string) ([]Activity, error) {
+	service := NewActivityService()
+	shared := service.db
+	if match.UserID != nil {
+		shared = shared.Or("user_id = ?", *match.UserID)
+	}
+	if match.PlotID != nil {
+		shared = shared.Or("plot_id = ?", *match.PlotID)
+	}
+	if match.Person != nil {
+		shared = shared.Or("LOWER(TRIM(target_person)) = ?", *match.Person)
+	}
+	var userIDs, workerIDs []string
+	for _, assignee := range match.Assignees {
+		if assignee.UserID != nil {
+			userIDs = append(userIDs, *assignee.UserID)
+		} else if assignee.WorkerID != nil {
+			workerIDs = append(workerIDs, *assignee.WorkerID)
+		}
+	}
+	if len(userIDs) > 0 {
+		shared = shared.Or("id IN (SELECT activity_id FROM activity_assignees WHERE user_id IN ?)", userIDs)
+	}
+	if len(workerIDs) > 0 {
+		shared = shared.Or("id IN (SELECT activity_id FROM activity_assignees WHERE worker_id IN ?)", workerIDs)
+	}
+
+	var activities []Activity
+	query := timed(occursIn(scopeOwned(service.db.Where(shared), match.Tenant), from, to))
+	if len(excludeIDs) > 0 {
+		query = query.Where("id NOT IN ?", excludeIDs)
+	}
+	err := query.Order("time_start ASC").Find(&activities).Error
+	return activities, err
+}
+
+// GetBusyActivities returns the timed activities of the tenant of match, matching every
+// set field of match, that may occur in [from, to)
+func GetBusyActivities(match ScheduleMatch, from, to time.Time) ([]Activity, error) {
 	service := NewActivityService()
+	query := timed(occursIn(scopeOwned(service.db, match.Tenant), from, to))
+	if match.UserID != nil {
+		query = query.Where("user_id = ?", *match.UserID)
+	}
+	if match.PlotID != nil {
+		query = query.Where("plot_id = ?", *match.PlotID)
+	}
+	if match.Person != nil {
+		query = query.Where("LOWER(TRIM(target_person)) = ?", *match.Person)
+	}
+
 	var activities []Activity
-	err := service.db.Order("created_at DESC").Find(&activities).Error
+	err := query.Order("time_start ASC").Find(&activities).Error
 	return activities, err
 }
 
-// GetAllActivitiesByTypeWithoutPagination gets all activities by type without pagination
-func GetAllActivitiesByTypeWithoutPagination(activityType string) ([]Activity, error) {
+// PlotBelongsToTenant checks that the plot exists, is not deleted and belongs to the user's own data
+// or to the organization of the tenant
+func PlotBelongsToTenant(plotID string, tenant common.Tenant) (bool, error) {
+	service := NewActivityService()
+	var count int64
+	err := tenant.Scope(service.db.Table("plots")).
+		Where("id = ? AND deleted_at IS NULL", plotID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetUserSeasonPlotID returns the plot of a season owned by the user,
+// or gorm.ErrRecordNotFound when the season does not exist or belongs to someone else
+func GetUserSeasonPlotID(seasonID, userID string) (string, error) {
+	service := NewActivityService()
+	var plotIDs []string
+	err := service.db.Table("seasons").
+		Where("id = ? AND user_id = ? AND deleted_at IS NULL", seasonID, userID).
+		Pluck("plot_id", &plotIDs).Error
+	if err != nil {
+		return "", err
+	}
+	if len(plotIDs) == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+	return plotIDs[0], nil
+}
+
+// ResolveActivityLinks verifies the linked season belongs to the user, the plot to the user or to the
+// organization of the activity and the contacts to the owner of the activity or to a member of its organization.
+// An activity linked to a season is placed on the season's plot, an empty person
+// field takes the name of its contact.
+func ResolveActivityLinks(activity *Activity, userID string) error {
+	if activity.PlotID == nil && activity.SeasonID == nil &&
+		activity.TargetContactID == nil && activity.SourceContactID == nil {
+		return nil
+	}
+	if userID == "" {
+		return ErrLinkRequiresAuth
+	}
+
+	contactTenant := activity.TenantFor(userID)
+	if activity.UserID != nil {
+		contactTenant.UserID = *activity.UserID
+	}
+	if err := resolveContact(activity.TargetContactID, &activity.TargetPerson, contactTenant); err != nil {
+		return err
+	}
+	if err := resolveContact(activity.SourceContactID, &activity.SourcePerson, contactTenant); err != nil {
+		return err
+	}
+	if activity.PlotID == nil && activity.SeasonID == nil {
+		return nil
+	}
+
+	if activity.SeasonID != nil {
+		seasonPlotID, err := GetUserSeasonPlotID(*activity.SeasonID, userID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrSeasonNotFound
+			}
+			return err
+		}
+		if activity.PlotID != nil && *activity.PlotID != seasonPlotID {
+			return ErrSeasonOnOtherPlot
+		}
+		activity.PlotID = &seasonPlotID
+	}
+
+	ok, err := PlotBelongsToTenant(*activity.PlotID, activity.TenantFor(userID))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPlotNotFound
+	}
+	return nil
+}
+
+// resolveContact checks the contact belongs to the tenant and names the person after it when empty
+func resolveContact(contactID *string, person **string, tenant common.Tenant) error {
+	if contactID == nil {
+		return nil
+	}
+	name, err := GetTenantContactName(*contactID, tenant)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrContactNotFound
+		}
+		return err
+	}
+	if *person == nil || strings.TrimSpace(**person) == "" {
+		*person = &name
+	}
+	return nil
+}
+
+// GetTenantContactName returns the name of a contact owned by the user of the tenant,
+// or by a member of its organization. Contacts are kept by users, not by organizations.
+func GetTenantContactName(contactID string, tenant common.Tenant) (string, error) {
+	service := NewActivityService()
+	query := service.db.Table("contacts").Where("id = ? AND deleted_at IS NULL", contactID)
+	if tenant.InOrganization() {
+		query = query.Where("user_id IN (SELECT user_id FROM organization_members WHERE organization_id = ?)", tenant.OrganizationID)
+	} else {
+		query = query.Where("user_id = ?", tenant.UserID)
+	}
+	var names []string
+	err := query.Limit(1).Pluck("name", &names).Error
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+	return names[0], nil
+}
+
+// GetActivitiesByPlot returns activities done on a plot, optionally limited to [from, to)
+// by time_start (or creation time for activities without a start time)
+func GetActivitiesByPlot(plotID string, from, to *time.Time) ([]Activity, error) {
+	service := NewActivityService()
+	var activities []Activity
+	query := service.db.Where("plot_id = ?", plotID)
+	if from != nil {
+		query = query.Where("COALESCE(time_start, created_at) >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("COALESCE(time_start, created_at) < ?", *to)
+	}
+	err := query.Order("COALESCE(time_start, created_at) DESC").Find(&activities).Error
+	return activities, err
+}
+
+// GetMoneyByTypeForPlot sums activity money on a plot by activity type, optionally limited to [from, to)
+func GetMoneyByTypeForPlot(plotID string, from, to *time.Time) ([]MoneyByType, error) {
+	service := NewActivityService()
+	var totals []MoneyByType
+	query := service.db.Model(&Activity{}).
+		Select("type, COALESCE(SUM(money), 0) AS total, COUNT(*) AS count").
+		Where("plot_id = ?", plotID)
+	if from != nil {
+		query = query.Where("COALESCE(time_start, created_at) >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("COALESCE(time_start, created_at) < ?", *to)
+	}
+	err := query.Group("type").Order("type").Scan(&totals).Error
+	return totals, err
+}
+
+// GetActivitiesBySeason returns activities of a crop season, newest first
+func GetActivitiesBySeason(seasonID string) ([]Activity, error) {
+	service := NewActivityService()
+	var activities []Activity
+	err := service.db.Where("season_id = ?", seasonID).
+		Order("COALESCE(time_start, created_at) DESC").Find(&activities).Error
+	return activities, err
+}
+
+// GetMoneyByTypeForSeason sums activity money of a crop season by activity type
+func GetMoneyByTypeForSeason(seasonID string) ([]MoneyByType, error) {
+	service := NewActivityService()
+	var totals []MoneyByType
+	err := service.db.Model(&Activity{}).
+		Select("type, COALESCE(SUM(money), 0) AS total, COUNT(*) AS count").
+		Where("season_id = ?", seasonID).
+		Group("type").Order("type").Scan(&totals).Error
+	return totals, err
+}
+
+// GetInputsUsedForSeason sums the materials (object, amount, unit) used by a crop season's activities
+func GetInputsUsedForSeason(seasonID string) ([]InputUsage, error) {
+	service := NewActivityService()
+	var inputs []InputUsage
+	err := service.db.Model(&Activity{}).
+		Select("object, unit, COALESCE(SUM(amount), 0) AS amount, COALESCE(SUM(money), 0) AS money, COUNT(*) AS count").
+		Where("season_id = ? AND object IS NOT NULL AND object <> ''", seasonID).
+		Where("type <> ?", TypeIncome).
+		Group("object, unit").Order("object").Scan(&inputs).Error
+	return inputs, err
+}
+
+// moneyTotalsSelect splits activity money into expenses and income
+const moneyTotalsSelect = "COALESCE(SUM(CASE WHEN type <> ? THEN money ELSE 0 END), 0) AS cost, " +
+	"COALESCE(SUM(CASE WHEN type = ? THEN money ELSE 0 END), 0) AS income"
+
+// GetMoneyTotalsBySeason sums expenses and income of the activities of each season
+func GetMoneyTotalsBySeason(seasonIDs []string) ([]MoneyTotals, error) {
+	service := NewActivityService()
+	var totals []MoneyTotals
+	if len(seasonIDs) == 0 {
+		return totals, nil
+	}
+	err := service.db.Model(&Activity{}).
+		Select("season_id AS key, "+moneyTotalsSelect, TypeIncome, TypeIncome).
+		Where("season_id IN ?", seasonIDs).
+		Group("season_id").Scan(&totals).Error
+	return totals, err
+}
+
+// GetMoneyTotalsByPlot sums expenses and income of the activities of each plot, optionally limited to [from, to)
+func GetMoneyTotalsByPlot(plotIDs []string, from, to *time.Time) ([]MoneyTotals, error) {
+	service := NewActivityService()
+	var totals []MoneyTotals
+	if len(plotIDs) == 0 {
+		return totals, nil
+	}
+	query := service.db.Model(&Activity{}).
+		Select("plot_id AS key, "+moneyTotalsSelect, TypeIncome, TypeIncome).
+		Where("plot_id IN ?", plotIDs)
+	if from != nil {
+		query = query.Where("COALESCE(time_start, created_at) >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("COALESCE(time_start, created_at) < ?", *to)
+	}
+	err := query.Group("plot_id").Scan(&totals).Error
+	return totals, err
+}
+
+// assignedTo limits the query to activities assigned to the user, directly or
+// through a worker linked to their account
+func assignedTo(query *gorm.DB, userID string) *gorm.DB {
+	return query.Where("id IN (SELECT activity_id FROM activity_assignees WHERE user_id = ? OR "+
+		"worker_id IN (SELECT id FROM workers WHERE linked_user_id = ? AND deleted_at IS NULL))", userID, userID)
+}
+
+// overdue limits the query to the activities reported overdue at now, see Activity.IsOverdue
+func overdue(query *gorm.DB, now time.Time) *gorm.DB {
+	return query.Where("status IN ? AND (repeat IS NULL OR repeat = '') AND "+
+		"COALESCE(time_end, CASE WHEN day THEN time_start + INTERVAL '1 day' ELSE time_start END) < ?",
+		[]string{StatusPlanned, StatusInProgress}, now)
+}
+
+// IsAssignee checks if the activity is assigned to the user, directly or through a worker
+func IsAssignee(activityID, userID string) (bool, error) {
+	service := NewActivityService()
+	var count int64
+	err := assignedTo(service.db.Model(&Activity{}), userID).Where("id = ?", activityID).Count(&count).Error
+	return count > 0, err
+}
+
+// GetAssignedActivities returns the activities assigned to the user that may occur in [from, to)
+func GetAssignedActivities(userID string, from, to time.Time) ([]Activity, error) {
 	service := NewActivityService()
 	var activities []Activity
-	err := service.db.Where("type = ?", activityType).Order("created_at DESC").Find(&activities).Error
+	err := occursIn(assignedTo(service.db, userID), from, to).Order("time_start ASC").Find(&activities).Error
 	return activities, err
 }
 
-// SearchAllActivities searches all activities by title or description without pagination
-func SearchAllActivities(keyword string) ([]Activity, error) {
+// GetOverdueAssignedActivities returns the activities assigned to the user that are overdue at now
+func GetOverdueAssignedActivities(userID string, now time.Time) ([]Activity, error) {
 	service := NewActivityService()
 	var activities []Activity
-	searchQuery := "%" + keyword + "%"
-	err := service.db.Where("title ILIKE ? OR description ILIKE ? OR description2 ILIKE ? OR description3 ILIKE ?", 
-		searchQuery, searchQuery, searchQuery, searchQuery).Order("created_at DESC").Find(&activities).Error
+	err := overdue(assignedTo(service.db, userID), now).Order("time_start ASC").Find(&activities).Error
 	return activities, err
 }
 
-// GetActivitiesCount gets total count of activities
-func GetActivitiesCount() (int64, error) {
+// CountUsers counts the existing users among the IDs
+func CountUsers(ids []string) (int64, error) {
 	service := NewActivityService()
 	var count int64
-	err := service.db.Model(&Activity{}).Count(&count).Error
+	err := service.db.Table("users").Where("id IN ?", ids).Count(&count).Error
 	return count, err
 }
 
-// GetActivitiesCountByType gets count of activities by type
-func GetActivitiesCountByType(activityType string) (int64, error) {
+// CountUserWorkers counts the workers of the user's farm among the IDs
+func CountUserWorkers(ids []string, userID string) (int64, error) {
 	service := NewActivityService()
 	var count int64
-	err := service.db.Model(&Activity{}).Where("type = ?", activityType).Count(&count).Error
+	err := service.db.Table("workers").
+		Where("id IN ? AND user_id = ? AND deleted_at IS NULL", ids, userID).
+		Count(&count).Error
 	return count, err
 }
 
-// SearchActivitiesCount gets count of activities matching search keyword
-func SearchActivitiesCount(keyword string) (int64, error) {
+// GetAssignees returns the assignees of the activities with the names of the users
+// and workers, keyed by activity ID
+func GetAssignees(activityIDs []string) (map[string][]AssigneeResponse, error) {
+	service := NewActivityService()
+	var rows []struct {
+		ActivityID string
+		AssigneeResponse
+	}
+	err := service.db.Table("activity_assignees AS a").
+		Select("a.activity_id, a.id, a.user_id, a.worker_id, "+
+			"COALESCE(NULLIF(u.full_name, ''), u.username, w.name, '') AS name").
+		Joins("LEFT JOIN users u ON u.id = a.user_id").
+		Joins("LEFT JOIN workers w ON w.id = a.worker_id").
+		Where("a.activity_id IN ?", activityIDs).
+		Order("a.created_at ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	assignees := make(map[string][]AssigneeResponse)
+	for _, row := range rows {
+		assignees[row.ActivityID] = append(assignees[row.ActivityID], row.AssigneeResponse)
+	}
+	return assignees, nil
+}
+
+// GetActivityAssignees returns the assignees of the activities keyed by activity ID
+func GetActivityAssignees(activityIDs []string) (map[string][]ActivityAssignee, error) {
+	assignees := make(map[string][]ActivityAssignee)
+	if len(activityIDs) == 0 {
+		return assignees, nil
+	}
+	service := NewActivityService()
+	var rows []ActivityAssignee
+	if err := service.db.Where("activity_id IN ?", activityIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		assignees[row.ActivityID] = append(assignees[row.ActivityID], row)
+	}
+	return assignees, nil
+}
+
+// SetAssignees replaces the assignees of an activity
+func SetAssignees(activityID string, assignees []ActivityAssignee) error {
+	service := NewActivityService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("activity_id = ?", activityID).Delete(&ActivityAssignee{}).Error; err != nil {
+			return err
+		}
+		if len(assignees) == 0 {
+			return nil
+		}
+		return tx.Create(&assignees).Error
+	})
+}
+
+// GetChecklists returns the checklist items of the activities in order, keyed by activity ID
+func GetChecklists(activityIDs []string) (map[string][]ChecklistItem, error) {
+	service := NewActivityService()
+	var items []ChecklistItem
+	err := service.db.Where("activity_id IN ?", activityIDs).
+		Order("position ASC, created_at ASC").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	checklists := make(map[string][]ChecklistItem)
+	for _, item := range items {
+		checklists[item.ActivityID] = append(checklists[item.ActivityID], item)
+	}
+	return checklists, nil
+}
+
+// GetChecklistItem finds a checklist item of the activity
+func GetChecklistItem(activityID, id string) (*ChecklistItem, error) {
+	service := NewActivityService()
+	var item ChecklistItem
+	err := service.db.Where("id = ? AND activity_id = ?", id, activityID).First(&item).Error
+	return &item, err
+}
+
+// CountChecklistItems counts the checklist items of an activity
+func CountChecklistItems(activityID string) (int64, error) {
 	service := NewActivityService()
 	var count int64
-	searchQuery := "%" + keyword + "%"
-	err := service.db.Model(&Activity{}).Where("title ILIKE ? OR description ILIKE ? OR description2 ILIKE ? OR description3 ILIKE ?", 
-		searchQuery, searchQuery, searchQuery, searchQuery).Count(&count).Error
+	err := service.db.Model(&ChecklistItem{}).Where("activity_id = ?", activityID).Count(&count).Error
 	return count, err
 }
 
-// UpdateActivity updates activity information
-func UpdateActivity(activity *Activity) error {
+// CreateChecklistItemRecord creates a new checklist item
+func CreateChecklistItemRecord(item *ChecklistItem) error {
 	service := NewActivityService()
-	return service.db.Save(activity).Error
+	return service.db.Create(item).Error
 }
 
-// DeleteActivity deletes activity by ID
-func DeleteActivity(id string) error {
+// UpdateChecklistItem saves a checklist item
+func UpdateChecklistItem(item *ChecklistItem) error {
 	service := NewActivityService()
-	return service.db.Where("id = ?", id).Delete(&Activity{}).Error
+	return service.db.Save(item).Error
 }
 
-// GetActivitiesByMonthYear returns activities whose time_start or day fall within the given month/year (UTC)
-func GetActivitiesByMonthYear(year int, month int) ([]Activity, error) {
+// DeleteChecklistItem deletes checklist item by ID
+func DeleteChecklistItem(id string) error {
 	service := NewActivityService()
-    startOfMonth := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
-    startOfNextMonth := startOfMonth.AddDate(0, 1, 0)
+	return service.db.Where("id = ?", id).Delete(&ChecklistItem{}).Error
+}
 
-    var activities []Activity
-    // Half-open interval [startOfMonth, startOfNextMonth)
-    err := service.db.Where(
-        "time_start IS NOT NULL AND time_start >= ? AND time_start < ?",
-        startOfMonth, startOfNextMonth,
-    ).Order("created_at DESC").Find(&activities).Error
-    return activities, err
+// MoveChecklistItem saves a checklist item at a new position, the other items
+// are renumbered to keep their order
+func MoveChecklistItem(item *ChecklistItem, position int) error {
+	service := NewActivityService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		var others []ChecklistItem
+		err := tx.Where("activity_id = ? AND id <> ?", item.ActivityID, item.ID).
+			Order("position ASC, created_at ASC").Find(&others).Error
+		if err != nil {
+			return err
+		}
+		if position > len(others) {
+			position = len(others)
+		}
+
+		item.Position = position
+		if err := tx.Save(item).Error; err != nil {
+			return err
+		}
+		for i := range others {
+			next := i
+			if i >= position {
+				next++
+			}
+			if others[i].Position == next {
+				continue
+			}
+			if err := tx.Model(&others[i]).Update("position", next).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-// GetActivitiesByDay returns activities that match the specific day (UTC) by either time_start's date or day field
-func GetActivitiesByDay(day time.Time) ([]Activity, error) {
+// GetAttachments lists the attachments of the activity, oldest first
+func GetAttachments(activityID string) ([]Attachment, error) {
 	service := NewActivityService()
-    // Normalize to date (midnight UTC)
-    start := time.Date(day.UTC().Year(), day.UTC().Month(), day.UTC().Day(), 0, 0, 0, 0, time.UTC)
-    next := start.AddDate(0, 0, 1)
+	var attachments []Attachment
+	err := service.db.Where("activity_id = ?", activityID).Order("created_at ASC").Find(&attachments).Error
+	return attachments, err
+}
 
-    var activities []Activity
-    err := service.db.Where(
-        "time_start IS NOT NULL AND time_start >= ? AND time_start < ?",
-        start, next,
-    ).Order("created_at DESC").Find(&activities).Error
-    return activities, err
-}
\ No newline at end of file
+// GetAttachment finds an attachment of the activity
+func GetAttachment(activityID, id string) (*Attachment, error) {
+	service := NewActivityService()
+	var attachment Attachment
+	err := service.db.Where("id = ? AND activity_id = ?", id, activityID).First(&attachment).Error
+	return &attachment, err
+}
+
+// CountAttachments counts the attachments of the activity
+func CountAttachments(activityID string) (int64, error) {
+	service := NewActivityService()
+	var count int64
+	err := service.db.Model(&Attachment{}).Where("activity_id = ?", activityID).Count(&count).Error
+	return count, err
+}
+
+// IsAttached checks if the file is already attached to the activity
+func IsAttached(activityID, mediaID string) (bool, error) {
+	service := NewActivityService()
+	var count int64
+	err := service.db.Model(&Attachment{}).Where("activity_id = ? AND media_id = ?", activityID, mediaID).Count(&count).Error
+	return count > 0, err
+}
+
+// CreateAttachmentRecord references the stored file from the activity and saves the attachment
+func CreateAttachmentRecord(attachment *Attachment) error {
+	if err := media.AddReference(media.OwnerActivity, attachment.ActivityID, attachment.MediaID); err != nil {
+		return err
+	}
+	service := NewActivityService()
+	if err := service.db.Create(attachment).Error; err != nil {
+		if releaseErr := media.ReleaseReference(media.OwnerActivity, attachment.ActivityID, attachment.MediaID); releaseErr != nil {
+			log.Printf("Failed to release media %s of activity %s: %v", attachment.MediaID, attachment.ActivityID, releaseErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteAttachment deletes the attachment and its file unless other owners still use it
+func DeleteAttachment(attachment *Attachment) error {
+	service := NewActivityService()
+	if err := service.db.Where("id = ?", attachment.ID).Delete(&Attachment{}).Error; err != nil {
+		return err
+	}
+	return media.ReleaseReference(media.OwnerActivity, attachment.ActivityID, attachment.MediaID)
+}
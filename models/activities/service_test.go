@@ -0,0 +1,138 @@
+package activities
+
+import (
+	"testing"
+
+	"plantheon-backend/common/dbtest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const (
+	consumingID = "fertilizing-1"
+	npkID       = "item-npk"
+	movementID  = "movement-1"
+)
+
+// expectNPK expects the owner's item named like the object to be looked up, found when it is Phân NPK
+func expectNPK(mock sqlmock.Sqlmock, object string) {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "name", "unit"})
+	if object == "Phân NPK" {
+		rows.AddRow(npkID, "owner", "Phân NPK", "kg")
+	}
+	mock.ExpectQuery(`SELECT \* FROM "inventory_items" WHERE LOWER\(name\) = LOWER\(\$1\)`).
+		WithArgs(object, "owner").
+		WillReturnRows(rows)
+}
+
+// expectMovementRemoved expects the stock movements of the activity to be deleted
+func expectMovementRemoved(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(`DELETE FROM "inventory_movements" WHERE activity_id IN \(\$1\)`).
+		WithArgs(consumingID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// fertilizing is the owner's activity using amount kg of the object
+func fertilizing(t *testing.T, object string, amount int, note string) *Activity {
+	start := at(t, "2025-03-05 07:00", vietnam)
+	return &Activity{
+		ID: consumingID, UserID: strPtr("owner"), Type: TypeFertilizing, Title: "Bón thúc",
+		Object: strPtr(object), Amount: &amount, Unit: strPtr("kg"), Note: strPtr(note), TimeStart: &start, Version: 2,
+	}
+}
+
+func TestUpdateActivityInventoryConsumption(t *testing.T) {
+	tests := []struct {
+		name   string
+		object string
+		amount int
+		note   string
+		expect func(mock sqlmock.Sqlmock)
+	}{
+		{
+			name: "other fields keep the movement", object: "Phân NPK", amount: 10, note: "Sau mưa",
+		},
+		{
+			name: "new amount moves the movement", object: "Phân NPK", amount: 25,
+			expect: func(mock sqlmock.Sqlmock) {
+				expectNPK(mock, "Phân NPK")
+				mock.ExpectQuery(`SELECT \* FROM "inventory_movements" WHERE activity_id = \$1`).
+					WithArgs(consumingID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "item_id", "quantity"}).AddRow(movementID, npkID, -10.0))
+				mock.ExpectExec(`UPDATE "inventory_movements" SET .* WHERE "id" = \$\d+`).
+					WithArgs(npkID, "owner", "consumption", -25.0, nil, nil, consumingID, sqlmock.AnyArg(), nil, sqlmock.AnyArg(), movementID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "object without an item gives the stock back", object: "Phân chuồng", amount: 10,
+			expect: func(mock sqlmock.Sqlmock) {
+				expectNPK(mock, "Phân chuồng")
+				expectMovementRemoved(mock)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := dbtest.Mock(t)
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT .* FROM "activities" WHERE \(id = \$1 AND version = \$2\)`).
+				WithArgs(consumingID, 2).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "type", "user_id", "object", "amount", "unit", "time_start"}).
+					AddRow(consumingID, TypeFertilizing, "owner", "Phân NPK", 10, "kg", at(t, "2025-03-05 07:00", vietnam)))
+			mock.ExpectExec(`UPDATE "activities" SET .* WHERE version = \$\d+ AND .*"id" = \$\d+`).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			if tt.expect != nil {
+				tt.expect(mock)
+			}
+			mock.ExpectCommit()
+
+			activity := fertilizing(t, tt.object, tt.amount, tt.note)
+			if err := UpdateActivity(activity); err != nil {
+				t.Fatal(err)
+			}
+			if activity.Version != 3 {
+				t.Fatalf("got version %d, want 3", activity.Version)
+			}
+		})
+	}
+}
+
+func TestDeleteActivityGivesStockBack(t *testing.T) {
+	mock := dbtest.Mock(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "activities" SET "deleted_at"=\$1 WHERE \(id = \$2 AND id NOT IN \(SELECT activity_id FROM worker_payments`).
+		WithArgs(sqlmock.AnyArg(), consumingID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectMovementRemoved(mock)
+	mock.ExpectCommit()
+
+	if err := DeleteActivity(consumingID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRestoreActivityTakesStockAgain(t *testing.T) {
+	mock := dbtest.Mock(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "activities" SET "deleted_at"=\$1,"updated_at"=\$2 WHERE id = \$3 AND deleted_at IS NOT NULL`).
+		WithArgs(nil, sqlmock.AnyArg(), consumingID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT \* FROM "activities" WHERE id = \$1`).
+		WithArgs(consumingID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "user_id", "object", "amount", "unit", "time_start"}).
+			AddRow(consumingID, TypeFertilizing, "owner", "Phân NPK", 1500, "g", at(t, "2025-03-05 07:00", vietnam)))
+	expectNPK(mock, "Phân NPK")
+	mock.ExpectQuery(`SELECT \* FROM "inventory_movements" WHERE activity_id = \$1`).
+		WithArgs(consumingID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	// 1500 g are taken out of the stock kept in kg
+	mock.ExpectQuery(`INSERT INTO "inventory_movements"`).
+		WithArgs(npkID, "owner", "consumption", -1.5, nil, nil, consumingID, sqlmock.AnyArg(), nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(movementID))
+	mock.ExpectCommit()
+
+	if err := RestoreActivity(consumingID); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,612 @@
+package activities
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"plantheon-backend/common"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MaxAssignees is the largest number of users and workers assigned to an activity
+const MaxAssignees = 20
+
+// MaxChecklistItems is the largest number of sub-items of an activity
+const MaxChecklistItems = 50
+
+// IsOpen checks if the activity is still to be done
+func (a *Activity) IsOpen() bool {
+	return a.Status == "" || a.Status == StatusPlanned || a.Status == StatusInProgress
+}
+
+// Deadline returns when the activity should be finished: its end, the end of the day
+// for all-day activities, otherwise its start. Activities without time_start have none.
+func (a *Activity) Deadline() *time.Time {
+	if a.TimeEnd != nil {
+		return a.TimeEnd
+	}
+	if a.TimeStart == nil {
+		return nil
+	}
+	deadline := *a.TimeStart
+	if a.Day != nil && *a.Day {
+		deadline = deadline.Add(24 * time.Hour)
+	}
+	return &deadline
+}
+
+// IsOverdue checks if the activity is still open after its deadline. Repeating activities
+// are never overdue, their next occurrence is always ahead.
+func (a *Activity) IsOverdue(now time.Time) bool {
+	if !a.IsOpen() || (a.Repeat != nil && *a.Repeat != "") {
+		return false
+	}
+	deadline := a.Deadline()
+	return deadline != nil && deadline.Before(now)
+}
+
+// GetActivityTaskHandler returns an activity with its assignees and checklist
+// GET /api/v1/activities/:id/task
+func GetActivityTaskHandler(c *gin.Context) {
+	activity, err := GetTenantActivityByID(c.Param("id"), common.CurrentTenant(c))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Activity not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activity",
+		})
+		return
+	}
+
+	tasks, err := toTaskResponses([]Activity{*activity})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get task",
+		})
+		return
+	}
+
+	c.Header("ETag", common.ETag(activity.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"data": tasks[0],
+	})
+}
+
+// UpdateActivityStatusHandler moves an activity to another status. Done and skipped record
+// when and by whom the activity was completed, reopening it clears the completion.
+// The owner and the assignees can change the status.
+// POST /api/v1/activities/:id/status
+func UpdateActivityStatusHandler(c *gin.Context) {
+	var req UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateUpdateStatusRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	activity, ok := loadTaskActivity(c, false)
+	if !ok {
+		return
+	}
+
+	// Reject the update if the client edited an outdated version
+	precondition, err := common.ParsePrecondition(c, req.Version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(activity.Version) {
+		respondActivityConflict(c, precondition, activity)
+		return
+	}
+
+	activity.Status = req.Status
+	if req.Status == StatusDone || req.Status == StatusSkipped {
+		now := time.Now()
+		userID := c.GetString("user_id")
+		activity.CompletedAt = &now
+		activity.CompletedBy = &userID
+		activity.CompletionNote = req.Note
+	} else {
+		activity.CompletedAt = nil
+		activity.CompletedBy = nil
+		activity.CompletionNote = nil
+	}
+
+	if err := UpdateActivity(activity); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetActivityByID(activity.ID); err == nil {
+				respondActivityConflict(c, precondition, current)
+				return
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update activity status",
+		})
+		return
+	}
+
+	c.Header("ETag", common.ETag(activity.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Activity status updated successfully",
+		"data":    activity.ToActivityResponse(),
+	})
+}
+
+// SetActivityAssigneesHandler replaces the users and workers assigned to an activity.
+// Workers must belong to the farm of the activity's owner. Assignees busy with another
+// activity at the same time are reported as warnings, or rejected with ?strict=true.
+// PUT /api/v1/activities/:id/assignees
+func SetActivityAssigneesHandler(c *gin.Context) {
+	var req SetAssigneesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateSetAssigneesRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	activity, ok := loadTaskActivity(c, true)
+	if !ok {
+		return
+	}
+
+	var userIDs, workerIDs []string
+	assignees := make([]ActivityAssignee, 0, len(req.Assignees))
+	for _, assignee := range req.Assignees {
+		if assignee.UserID != nil {
+			userIDs = append(userIDs, *assignee.UserID)
+		} else {
+			workerIDs = append(workerIDs, *assignee.WorkerID)
+		}
+		assignees = append(assignees, ActivityAssignee{
+			ActivityID: activity.ID,
+			UserID:     assignee.UserID,
+			WorkerID:   assignee.WorkerID,
+		})
+	}
+	if !checkAssignees(c, activity, userIDs, workerIDs) {
+		return
+	}
+	conflicts, ok := checkAssigneeConflicts(c, activity, assignees)
+	if !ok {
+		return
+	}
+
+	if err := SetAssignees(activity.ID, assignees); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to assign activity",
+		})
+		return
+	}
+
+	tasks, err := toTaskResponses([]Activity{*activity})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get task",
+		})
+		return
+	}
+
+	response := gin.H{
+		"message": "Activity assignees updated successfully",
+		"data":    tasks[0],
+	}
+	if len(conflicts) > 0 {
+		response["warnings"] = conflicts
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// checkAssignees verifies the users exist and the workers belong to the owner's farm,
+// writing the error response and returning false otherwise
+func checkAssignees(c *gin.Context, activity *Activity, userIDs, workerIDs []string) bool {
+	if len(userIDs) > 0 {
+		count, err := CountUsers(userIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check assignees",
+			})
+			return false
+		}
+		if count != int64(len(userIDs)) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "User not found",
+			})
+			return false
+		}
+	}
+
+	if len(workerIDs) > 0 {
+		if activity.UserID == nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Workers can only be assigned to activities with an owner",
+			})
+			return false
+		}
+		count, err := CountUserWorkers(workerIDs, *activity.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check assignees",
+			})
+			return false
+		}
+		if count != int64(len(workerIDs)) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Worker not found",
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// CreateChecklistItemHandler adds a sub-item at the end of an activity's checklist
+// POST /api/v1/activities/:id/checklist
+func CreateChecklistItemHandler(c *gin.Context) {
+	var req CreateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateChecklistItemRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	activity, ok := loadTaskActivity(c, true)
+	if !ok {
+		return
+	}
+
+	count, err := CountChecklistItems(activity.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get checklist",
+		})
+		return
+	}
+	if count >= MaxChecklistItems {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Checklist is full",
+		})
+		return
+	}
+
+	item := &ChecklistItem{
+		ActivityID: activity.ID,
+		Position:   int(count),
+		Title:      req.Title,
+	}
+	if err := CreateChecklistItemRecord(item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create checklist item",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Checklist item created successfully",
+		"data":    item.ToChecklistItemResponse(),
+	})
+}
+
+// UpdateChecklistItemHandler ticks, renames or moves a checklist item. Assignees can
+// tick items, renaming and moving them is left to the owner.
+// PATCH /api/v1/activities/:id/checklist/:item_id
+func UpdateChecklistItemHandler(c *gin.Context) {
+	var req UpdateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateUpdateChecklistItemRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	activity, ok := loadTaskActivity(c, req.Title != nil || req.Position != nil)
+	if !ok {
+		return
+	}
+	item, ok := loadChecklistItem(c, activity.ID)
+	if !ok {
+		return
+	}
+
+	if req.Title != nil {
+		item.Title = *req.Title
+	}
+	if req.Done != nil && *req.Done != item.Done {
+		item.Done = *req.Done
+		item.DoneAt = nil
+		item.DoneBy = nil
+		if item.Done {
+			now := time.Now()
+			userID := c.GetString("user_id")
+			item.DoneAt = &now
+			item.DoneBy = &userID
+		}
+	}
+
+	var err error
+	if req.Position != nil && *req.Position != item.Position {
+		err = MoveChecklistItem(item, *req.Position)
+	} else {
+		err = UpdateChecklistItem(item)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update checklist item",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Checklist item updated successfully",
+		"data":    item.ToChecklistItemResponse(),
+	})
+}
+
+// DeleteChecklistItemHandler removes a sub-item from an activity's checklist
+// DELETE /api/v1/activities/:id/checklist/:item_id
+func DeleteChecklistItemHandler(c *gin.Context) {
+	activity, ok := loadTaskActivity(c, true)
+	if !ok {
+		return
+	}
+	item, ok := loadChecklistItem(c, activity.ID)
+	if !ok {
+		return
+	}
+
+	if err := DeleteChecklistItem(item.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete checklist item",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Checklist item deleted successfully",
+	})
+}
+
+// GetMyTasksTodayHandler returns the activities assigned to the current user, directly or
+// through a worker linked to their account, occurring on a day (today by default),
+// followed by the open tasks overdue from earlier days.
+// The day is taken in the tz parameter, the user's timezone or UTC.
+// GET /api/v1/activities/my-tasks/today?date=YYYY-MM-DD&tz=Asia/Ho_Chi_Minh
+func GetMyTasksTodayHandler(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Authentication is required to list your tasks",
+		})
+		return
+	}
+	loc, err := common.RequestTimezone(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	date := now.In(loc)
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := time.ParseInLocation(common.DateLayout, dateStr, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			return
+		}
+		date = parsed
+	}
+	from := common.StartOfDay(date, loc)
+	to := from.AddDate(0, 0, 1)
+
+	assigned, err := GetAssignedActivities(userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get tasks",
+		})
+		return
+	}
+	overdueActivities, err := GetOverdueAssignedActivities(userID, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get tasks",
+		})
+		return
+	}
+
+	// Order the day's tasks by their occurrence on that day, repetitions included
+	type dayTask struct {
+		activity Activity
+		start    time.Time
+	}
+	var day []dayTask
+	onDay := make(map[string]bool)
+	for _, activity := range assigned {
+		if occurrences := activity.Occurrences(from, to, loc); len(occurrences) > 0 {
+			day = append(day, dayTask{activity: activity, start: occurrences[0].Start})
+			onDay[activity.ID] = true
+		}
+	}
+	sort.SliceStable(day, func(i, j int) bool {
+		return day[i].start.Before(day[j].start)
+	})
+
+	todayActivities := make([]Activity, 0, len(day))
+	for _, task := range day {
+		todayActivities = append(todayActivities, task.activity)
+	}
+	earlier := make([]Activity, 0, len(overdueActivities))
+	for _, activity := range overdueActivities {
+		if !onDay[activity.ID] {
+			earlier = append(earlier, activity)
+		}
+	}
+
+	today, err := toTaskResponses(todayActivities)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get tasks",
+		})
+		return
+	}
+	overdueTasks, err := toTaskResponses(earlier)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get tasks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": MyTasksResponse{
+			Date:    from.Format(common.DateLayout),
+			Today:   today,
+			Overdue: overdueTasks,
+		},
+	})
+}
+
+// toTaskResponses converts activities to TaskResponse with their assignees and checklists
+func toTaskResponses(activities []Activity) ([]TaskResponse, error) {
+	tasks := make([]TaskResponse, 0, len(activities))
+	if len(activities) == 0 {
+		return tasks, nil
+	}
+
+	ids := make([]string, 0, len(activities))
+	for i := range activities {
+		ids = append(ids, activities[i].ID)
+	}
+	assignees, err := GetAssignees(ids)
+	if err != nil {
+		return nil, err
+	}
+	checklists, err := GetChecklists(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range activities {
+		tasks = append(tasks, activities[i].ToTaskResponse(assignees[activities[i].ID], checklists[activities[i].ID]))
+	}
+	return tasks, nil
+}
+
+// loadTaskActivity loads the activity from the :id parameter for the current user. The owner
+// and the managers of its organization may manage it, activities without an owner are open
+// to everyone like their other endpoints.
+// When manage is false the assignees are allowed too. The error response is written and
+// false returned otherwise.
+func loadTaskActivity(c *gin.Context, manage bool) (*Activity, bool) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Authentication is required to manage tasks",
+		})
+		return nil, false
+	}
+
+	activity, err := GetTenantActivityByID(c.Param("id"), common.CurrentTenant(c))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Activity not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activity",
+		})
+		return nil, false
+	}
+	if activity.UserID == nil || *activity.UserID == userID {
+		return activity, true
+	}
+	if activity.OrganizationID != nil && common.CurrentTenant(c).Allows(common.OrgRoleManager) {
+		return activity, true
+	}
+
+	if !manage {
+		assigned, err := IsAssignee(activity.ID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check assignees",
+			})
+			return nil, false
+		}
+		if assigned {
+			return activity, true
+		}
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only the owner or an assignee can update this activity",
+		})
+		return nil, false
+	}
+	c.JSON(http.StatusForbidden, gin.H{
+		"error": "Only the owner can manage this activity",
+	})
+	return nil, false
+}
+
+// loadChecklistItem loads the checklist item from the :item_id parameter,
+// writing the error response and returning false otherwise
+func loadChecklistItem(c *gin.Context, activityID string) (*ChecklistItem, bool) {
+	item, err := GetChecklistItem(activityID, c.Param("item_id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Checklist item not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get checklist item",
+		})
+		return nil, false
+	}
+	return item, true
+}
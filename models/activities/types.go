@@ -0,0 +1,282 @@
+package activities
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Field kinds tell the app which input to render
+const (
+	KindText     = "text"
+	KindLongText = "long_text"
+	KindMoney    = "money"
+	KindInteger  = "integer"
+	KindUnit     = "unit"
+	KindDateTime = "datetime"
+	KindPerson   = "person"
+)
+
+// FieldRule declares how an activity type uses one of the activity columns
+type FieldRule struct {
+	Field    string `json:"field"` // JSON name of the column
+	Label    string `json:"label"` // What the column means for this type
+	Kind     string `json:"kind"`
+	Required bool   `json:"required"`
+	Positive bool   `json:"positive,omitempty"` // Numbers must be greater than zero
+}
+
+// TypeSchema declares the fields an activity type uses
+type TypeSchema struct {
+	Type              string      `json:"type"`
+	Label             string      `json:"label"`
+	Fields            []FieldRule `json:"fields"`
+	ConsumesInventory bool        `json:"consumes_inventory"` // Amount of Object is taken out of stock
+	Income            bool        `json:"income"`             // Money is received instead of spent
+}
+
+// CommonFields can be sent with every activity type
+var CommonFields = []string{
+	"plot_id", "season_id", "title", "type", "description", "time_start", "time_end", "day",
+	"is_repeat", "repeat", "end_repeat_day", "alert_time", "attached_link", "note", "version",
+}
+
+// ActivityTypes is the registry of activity types. The generic columns
+// (object, amount, purpose...) may only be sent for the types declaring them.
+var ActivityTypes = []TypeSchema{
+	{
+		Type:  TypeExpense,
+		Label: "Chi tiêu",
+		Fields: []FieldRule{
+			{Field: "money", Label: "Số tiền", Kind: KindMoney, Required: true, Positive: true},
+			{Field: "object", Label: "Khoản chi", Kind: KindText},
+			{Field: "amount", Label: "Số lượng", Kind: KindInteger},
+			{Field: "unit", Label: "Đơn vị", Kind: KindUnit},
+			{Field: "target_person", Label: "Người nhận", Kind: KindPerson},
+			{Field: "purpose", Label: "Mục đích", Kind: KindLongText},
+			{Field: "description2", Label: "Chi tiết", Kind: KindLongText},
+		},
+	},
+	{
+		Type:   TypeIncome,
+		Label:  "Thu nhập",
+		Income: true,
+		Fields: []FieldRule{
+			{Field: "money", Label: "Số tiền", Kind: KindMoney, Required: true, Positive: true},
+			{Field: "object", Label: "Sản phẩm bán", Kind: KindText},
+			{Field: "amount", Label: "Số lượng", Kind: KindInteger},
+			{Field: "unit", Label: "Đơn vị", Kind: KindUnit},
+			{Field: "source_person", Label: "Người trả", Kind: KindPerson},
+			{Field: "purpose", Label: "Nội dung", Kind: KindLongText},
+		},
+	},
+	{
+		Type:  TypeLabor,
+		Label: "Thuê nhân công",
+		Fields: []FieldRule{
+			{Field: "target_person", Label: "Người làm", Kind: KindPerson, Required: true},
+			{Field: "money", Label: "Tiền công", Kind: KindMoney},
+			{Field: "amount", Label: "Số công", Kind: KindInteger},
+			{Field: "unit", Label: "Đơn vị", Kind: KindUnit},
+			{Field: "purpose", Label: "Công việc", Kind: KindLongText},
+		},
+	},
+	{
+		Type:              TypeSpraying,
+		Label:             "Phun thuốc",
+		ConsumesInventory: true,
+		Fields: []FieldRule{
+			{Field: "object", Label: "Thuốc", Kind: KindText, Required: true},
+			{Field: "amount", Label: "Lượng thuốc", Kind: KindInteger, Required: true, Positive: true},
+			{Field: "unit", Label: "Đơn vị", Kind: KindUnit, Required: true},
+			{Field: "purpose", Label: "Đối tượng phòng trừ", Kind: KindLongText},
+			{Field: "description2", Label: "Liều lượng pha", Kind: KindLongText},
+			{Field: "money", Label: "Chi phí", Kind: KindMoney},
+		},
+	},
+	{
+		Type:              TypeFertilizing,
+		Label:             "Bón phân",
+		ConsumesInventory: true,
+		Fields: []FieldRule{
+			{Field: "object", Label: "Phân bón", Kind: KindText, Required: true},
+			{Field: "amount", Label: "Lượng phân", Kind: KindInteger, Required: true, Positive: true},
+			{Field: "unit", Label: "Đơn vị", Kind: KindUnit, Required: true},
+			{Field: "purpose", Label: "Giai đoạn bón", Kind: KindLongText},
+			{Field: "money", Label: "Chi phí", Kind: KindMoney},
+		},
+	},
+	{
+		Type:              TypeSowing,
+		Label:             "Gieo trồng",
+		ConsumesInventory: true,
+		Fields: []FieldRule{
+			{Field: "object", Label: "Giống", Kind: KindText, Required: true},
+			{Field: "amount", Label: "Lượng giống", Kind: KindInteger},
+			{Field: "unit", Label: "Đơn vị", Kind: KindUnit},
+			{Field: "money", Label: "Chi phí", Kind: KindMoney},
+		},
+	},
+	{
+		Type:  TypeHarvest,
+		Label: "Thu hoạch",
+		Fields: []FieldRule{
+			{Field: "amount", Label: "Sản lượng", Kind: KindInteger, Required: true, Positive: true},
+			{Field: "unit", Label: "Đơn vị", Kind: KindUnit, Required: true},
+			{Field: "object", Label: "Sản phẩm", Kind: KindText},
+			{Field: "description2", Label: "Chất lượng", Kind: KindText},
+		},
+	},
+	{
+		Type:  TypeReminder,
+		Label: "Nhắc việc",
+		Fields: []FieldRule{
+			{Field: "time_start", Label: "Thời gian", Kind: KindDateTime, Required: true},
+			{Field: "alert_time", Label: "Nhắc trước", Kind: KindText, Required: true},
+			{Field: "target_person", Label: "Người thực hiện", Kind: KindPerson},
+		},
+	},
+	{
+		Type:  TypeOther,
+		Label: "Khác",
+		Fields: []FieldRule{
+			{Field: "description2", Label: "Mô tả 2", Kind: KindLongText},
+			{Field: "description3", Label: "Mô tả 3", Kind: KindLongText},
+			{Field: "money", Label: "Số tiền", Kind: KindMoney},
+			{Field: "object", Label: "Đối tượng", Kind: KindText},
+			{Field: "amount", Label: "Số lượng", Kind: KindInteger},
+			{Field: "unit", Label: "Đơn vị", Kind: KindUnit},
+			{Field: "purpose", Label: "Mục đích", Kind: KindLongText},
+			{Field: "target_person", Label: "Người nhận", Kind: KindPerson},
+			{Field: "source_person", Label: "Người gửi", Kind: KindPerson},
+		},
+	},
+}
+
+// LookupType finds the schema of an activity type, ignoring case
+func LookupType(activityType string) (*TypeSchema, bool) {
+	activityType = NormalizeType(activityType)
+	for i := range ActivityTypes {
+		if ActivityTypes[i].Type == activityType {
+			return &ActivityTypes[i], true
+		}
+	}
+	return nil, false
+}
+
+// NormalizeType lowercases and trims an activity type
+func NormalizeType(activityType string) string {
+	return strings.ToLower(strings.TrimSpace(activityType))
+}
+
+// typeNames lists the registered types for error messages
+func typeNames() string {
+	names := make([]string, len(ActivityTypes))
+	for i, schema := range ActivityTypes {
+		names[i] = schema.Type
+	}
+	return strings.Join(names, ", ")
+}
+
+// contactPersons maps the contact links to the person field they name
+var contactPersons = map[string]string{
+	"target_contact_id": "target_person",
+	"source_contact_id": "source_person",
+}
+
+// allows checks if the field can be sent for this type, a contact link
+// can be sent with the person field it names
+func (s *TypeSchema) allows(field string) bool {
+	if person, ok := contactPersons[field]; ok {
+		field = person
+	}
+	for _, common := range CommonFields {
+		if common == field {
+			return true
+		}
+	}
+	for _, rule := range s.Fields {
+		if rule.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the fields sent by a request against the type, and that the
+// required fields are set once the request is applied.
+// sent holds the fields of the request, present the fields set on the resulting activity.
+func (s *TypeSchema) Validate(sent, present map[string]bool, activity *Activity) error {
+	var unused []string
+	for field := range sent {
+		if !s.allows(field) {
+			unused = append(unused, field)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return fmt.Errorf("field(s) %v are not used by activity type %s", unused, s.Type)
+	}
+
+	for _, rule := range s.Fields {
+		if rule.Required && !present[rule.Field] && !present[personContact(rule.Field)] {
+			return fmt.Errorf("%s (%s) is required for activity type %s", rule.Field, rule.Label, s.Type)
+		}
+		if rule.Positive {
+			if rule.Field == "money" && activity.Money != nil && *activity.Money <= 0 {
+				return fmt.Errorf("money must be positive for activity type %s", s.Type)
+			}
+			if rule.Field == "amount" && activity.Amount != nil && *activity.Amount <= 0 {
+				return fmt.Errorf("amount must be positive for activity type %s", s.Type)
+			}
+		}
+	}
+	return nil
+}
+
+// personContact returns the contact link of a person field, empty for other fields.
+// The person's name is taken from the contact when the activity is saved.
+func personContact(field string) string {
+	for contact, person := range contactPersons {
+		if person == field {
+			return contact
+		}
+	}
+	return ""
+}
+
+// setFields returns the JSON names of the fields set on a request or activity:
+// non-nil pointers and non-empty strings
+func setFields(v interface{}) map[string]bool {
+	fields := make(map[string]bool)
+	value := reflect.Indirect(reflect.ValueOf(v))
+	for i := 0; i < value.NumField(); i++ {
+		name := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		field := value.Field(i)
+		switch field.Kind() {
+		case reflect.Ptr:
+			if !field.IsNil() {
+				fields[name] = true
+			}
+		case reflect.String:
+			if strings.TrimSpace(field.String()) != "" {
+				fields[name] = true
+			}
+		}
+	}
+	return fields
+}
+
+// resolveType finds the schema of a type being set by a request
+func resolveType(activityType string) (*TypeSchema, error) {
+	schema, ok := LookupType(activityType)
+	if !ok {
+		return nil, errors.New("type must be one of: " + typeNames())
+	}
+	return schema, nil
+}
@@ -2,7 +2,13 @@ package activities
 
 import (
 	"errors"
+	"fmt"
 	"strings"
+	"time"
+
+	"plantheon-backend/common"
+
+	"github.com/google/uuid"
 )
 
 // ValidateCreateActivityRequest validates create activity request
@@ -79,11 +85,38 @@ func ValidateCreateActivityRequest(req *CreateActivityRequest) error {
 		return errors.New("amount must be non-negative")
 	}
 
-	return nil
+	if err := validateReferenceID("plot_id", req.PlotID); err != nil {
+		return err
+	}
+
+	if err := validateReferenceID("season_id", req.SeasonID); err != nil {
+		return err
+	}
+
+	if err := validateReferenceID("target_contact_id", req.TargetContactID); err != nil {
+		return err
+	}
+
+	if err := validateReferenceID("source_contact_id", req.SourceContactID); err != nil {
+		return err
+	}
+
+	if err := validateTimeRange(req.TimeStart, req.TimeEnd); err != nil {
+		return err
+	}
+
+	// Check the type-specific fields against the activity type registry
+	req.Type = NormalizeType(req.Type)
+	schema, err := resolveType(req.Type)
+	if err != nil {
+		return err
+	}
+	sent := setFields(req)
+	return schema.Validate(sent, sent, &Activity{Money: req.Money, Amount: req.Amount})
 }
 
-// ValidateUpdateActivityRequest validates update activity request
-func ValidateUpdateActivityRequest(req *UpdateActivityRequest) error {
+// ValidateUpdateActivityRequest validates update activity request against the activity it changes
+func ValidateUpdateActivityRequest(req *UpdateActivityRequest, activity *Activity) error {
 	if req.Title != nil {
 		if strings.TrimSpace(*req.Title) == "" {
 			return errors.New("title cannot be empty")
@@ -153,6 +186,369 @@ func ValidateUpdateActivityRequest(req *UpdateActivityRequest) error {
 		return errors.New("amount must be non-negative")
 	}
 
+	if err := validateReferenceID("plot_id", req.PlotID); err != nil {
+		return err
+	}
+
+	if err := validateReferenceID("season_id", req.SeasonID); err != nil {
+		return err
+	}
+
+	if err := validateReferenceID("target_contact_id", req.TargetContactID); err != nil {
+		return err
+	}
+
+	if err := validateReferenceID("source_contact_id", req.SourceContactID); err != nil {
+		return err
+	}
+
+	// Check the type-specific fields against the type the activity will have
+	merged := *activity
+	if req.TimeStart != nil {
+		merged.TimeStart = req.TimeStart
+	}
+	if req.TimeEnd != nil {
+		merged.TimeEnd = req.TimeEnd
+	}
+	if req.Money != nil {
+		merged.Money = req.Money
+	}
+	if req.Amount != nil {
+		merged.Amount = req.Amount
+	}
+	if err := validateTimeRange(merged.TimeStart, merged.TimeEnd); err != nil {
+		return err
+	}
+
+	var schema *TypeSchema
+	if req.Type != nil {
+		*req.Type = NormalizeType(*req.Type)
+		resolved, err := resolveType(*req.Type)
+		if err != nil {
+			return err
+		}
+		schema = resolved
+	} else if registered, ok := LookupType(activity.Type); ok {
+		schema = registered
+	} else {
+		// Activities created before the registry may have other types, leave them as they are
+		return nil
+	}
+
+	sent := setFields(req)
+	present := setFields(activity)
+	for field := range sent {
+		present[field] = true
+	}
+	return schema.Validate(sent, present, &merged)
+}
+
+// ValidateActivitySchema checks an activity has the fields its type requires,
+// used once a merge patch has been applied since it can clear fields
+func ValidateActivitySchema(activity *Activity) error {
+	schema, ok := LookupType(activity.Type)
+	if !ok {
+		return nil
+	}
+	if err := validateTimeRange(activity.TimeStart, activity.TimeEnd); err != nil {
+		return err
+	}
+	return schema.Validate(nil, setFields(activity), activity)
+}
+
+// validateTimeRange checks an activity does not end before it starts
+func validateTimeRange(start, end *time.Time) error {
+	if start != nil && end != nil && end.Before(*start) {
+		return errors.New("time_end must be after time_start")
+	}
+	return nil
+}
+
+// validateReferenceID checks a reference to another record is a UUID,
+// ownership is checked against the database by the handlers
+func validateReferenceID(field string, id *string) error {
+	if id != nil {
+		if _, err := uuid.Parse(*id); err != nil {
+			return errors.New(field + " must be a valid UUID")
+		}
+	}
+	return nil
+}
+
+// ApplyActivityPatch validates a JSON merge patch with the update rules and applies it.
+// Optional fields set to null are cleared, type and title cannot be null.
+func ApplyActivityPatch(activity *Activity, patch common.MergePatch) error {
+	if err := patch.CheckFields(
+		"plot_id", "season_id", "description", "description2", "description3", "time_start", "time_end", "day", "money",
+		"type", "title", "is_repeat", "repeat", "end_repeat_day", "alert_time", "object", "amount",
+		"unit", "purpose", "target_person", "source_person", "target_contact_id", "source_contact_id",
+		"attached_link", "note", "version",
+	); err != nil {
+		return err
+	}
+	if err := patch.CheckNotNull("type", "title"); err != nil {
+		return err
+	}
+
+	var req UpdateActivityRequest
+	if err := patch.Decode(&req); err != nil {
+		return errors.New("invalid field type in merge patch")
+	}
+	if err := ValidateUpdateActivityRequest(&req, activity); err != nil {
+		return err
+	}
+
+	if patch.Has("type") {
+		if strings.TrimSpace(*req.Type) == "" {
+			return errors.New("type cannot be empty")
+		}
+		activity.Type = *req.Type
+	}
+	if patch.Has("title") {
+		activity.Title = *req.Title
+	}
+
+	// Nullable fields take the patched value, nil when the patch sets null
+	if patch.Has("plot_id") {
+		activity.PlotID = req.PlotID
+	}
+	if patch.Has("season_id") {
+		activity.SeasonID = req.SeasonID
+	}
+	if patch.Has("description") {
+		activity.Description = req.Description
+	}
+	if patch.Has("description2") {
+		activity.Description2 = req.Description2
+	}
+	if patch.Has("description3") {
+		activity.Description3 = req.Description3
+	}
+	if patch.Has("time_start") {
+		activity.TimeStart = req.TimeStart
+	}
+	if patch.Has("time_end") {
+		activity.TimeEnd = req.TimeEnd
+	}
+	if patch.Has("day") {
+		activity.Day = req.Day
+	}
+	if patch.Has("money") {
+		activity.Money = req.Money
+	}
+	if patch.Has("is_repeat") {
+		activity.IsRepeat = req.IsRepeat
+	}
+	if patch.Has("repeat") {
+		activity.Repeat = req.Repeat
+	}
+	if patch.Has("end_repeat_day") {
+		activity.EndRepeatDay = req.EndRepeatDay
+	}
+	if patch.Has("alert_time") {
+		activity.AlertTime = req.AlertTime
+	}
+	if patch.Has("object") {
+		activity.Object = req.Object
+	}
+	if patch.Has("amount") {
+		activity.Amount = req.Amount
+	}
+	if patch.Has("unit") {
+		activity.Unit = req.Unit
+	}
+	if patch.Has("purpose") {
+		activity.Purpose = req.Purpose
+	}
+	if patch.Has("target_person") {
+		activity.TargetPerson = req.TargetPerson
+	}
+	if patch.Has("source_person") {
+		activity.SourcePerson = req.SourcePerson
+	}
+	if patch.Has("target_contact_id") {
+		activity.TargetContactID = req.TargetContactID
+	}
+	if patch.Has("source_contact_id") {
+		activity.SourceContactID = req.SourceContactID
+	}
+	if patch.Has("attached_link") {
+		activity.AttachedLink = req.AttachedLink
+	}
+	if patch.Has("note") {
+		activity.Note = req.Note
+	}
+
+	return ValidateActivitySchema(activity)
+}
+
+// ValidateDuplicateActivityRequest validates the new place of a duplicated activity
+func ValidateDuplicateActivityRequest(req *DuplicateActivityRequest) error {
+	if err := validateReferenceID("plot_id", req.PlotID); err != nil {
+		return err
+	}
+	return validateReferenceID("season_id", req.SeasonID)
+}
+
+// ValidateBulkCreateRequest checks the batch size, the activities themselves are validated one by one
+func ValidateBulkCreateRequest(req *BulkCreateRequest) error {
+	if len(req.Activities) == 0 {
+		return errors.New("activities is required")
+	}
+	if len(req.Activities) > MaxBulkItems {
+		return fmt.Errorf("at most %d activities can be created at once", MaxBulkItems)
+	}
+	return nil
+}
+
+// ValidateBulkUpdateRequest validates the selection and the change applied to it
+func ValidateBulkUpdateRequest(req *BulkUpdateRequest) error {
+	if err := validateBulkSelection(req.IDs, req.Filter); err != nil {
+		return err
+	}
+	if len(req.Patch) == 0 && req.Shift == nil {
+		return errors.New("patch or shift is required")
+	}
+	// Versions differ between activities, a bulk update cannot expect one
+	if req.Patch.Has("version") {
+		return errors.New("version cannot be set in a bulk update")
+	}
+	if req.Shift != nil {
+		if req.Shift.Days == 0 && req.Shift.Hours == 0 && req.Shift.Minutes == 0 {
+			return errors.New("shift must move the activities by days, hours or minutes")
+		}
+		if req.Patch.Has("time_start") || req.Patch.Has("time_end") || req.Patch.Has("end_repeat_day") {
+			return errors.New("shift cannot be combined with time_start, time_end or end_repeat_day in patch")
+		}
+	}
+	return nil
+}
+
+// ValidateBulkDeleteRequest validates the selection of activities to delete
+func ValidateBulkDeleteRequest(req *BulkDeleteRequest) error {
+	return validateBulkSelection(req.IDs, req.Filter)
+}
+
+// validateBulkSelection checks exactly one of ids and filter is given. A filter must narrow
+// the selection, sort and order alone would select every activity.
+func validateBulkSelection(ids []string, filter map[string]string) error {
+	if len(ids) > 0 && len(filter) > 0 {
+		return errors.New("send either ids or filter, not both")
+	}
+	if len(ids) > 0 {
+		if len(ids) > MaxBulkItems {
+			return fmt.Errorf("at most %d activities can be changed at once", MaxBulkItems)
+		}
+		seen := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			if _, err := uuid.Parse(id); err != nil {
+				return fmt.Errorf("invalid id %q", id)
+			}
+			if seen[id] {
+				return fmt.Errorf("id %s is listed more than once", id)
+			}
+			seen[id] = true
+		}
+		return nil
+	}
+	for name, value := range filter {
+		if name != "sort" && name != "order" && strings.TrimSpace(value) != "" {
+			return nil
+		}
+	}
+	return errors.New("ids or filter is required")
+}
+
+// ValidStatuses lists the statuses a client can set, overdue is computed
+var ValidStatuses = []string{StatusPlanned, StatusInProgress, StatusDone, StatusSkipped}
+
+// ValidateUpdateStatusRequest validates a status change, the note is kept for done and skipped activities
+func ValidateUpdateStatusRequest(req *UpdateStatusRequest) error {
+	req.Status = strings.ToLower(strings.TrimSpace(req.Status))
+	if !isStatus(req.Status) {
+		return errors.New("status must be one of " + strings.Join(ValidStatuses, ", "))
+	}
+	if req.Note != nil {
+		if req.Status != StatusDone && req.Status != StatusSkipped {
+			return errors.New("note can only be given when the activity is done or skipped")
+		}
+		if len(*req.Note) > 1000 {
+			return errors.New("note must be less than 1000 characters")
+		}
+	}
+	return nil
+}
+
+// isStatus checks the status is one a client can set
+func isStatus(status string) bool {
+	for _, valid := range ValidStatuses {
+		if status == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateSetAssigneesRequest checks every assignee names exactly one user or worker, once
+func ValidateSetAssigneesRequest(req *SetAssigneesRequest) error {
+	if len(req.Assignees) > MaxAssignees {
+		return fmt.Errorf("an activity has at most %d assignees", MaxAssignees)
+	}
+	seen := make(map[string]bool, len(req.Assignees))
+	for i, assignee := range req.Assignees {
+		if (assignee.UserID == nil) == (assignee.WorkerID == nil) {
+			return fmt.Errorf("assignee %d: send either user_id or worker_id", i)
+		}
+		if err := validateReferenceID("user_id", assignee.UserID); err != nil {
+			return fmt.Errorf("assignee %d: %v", i, err)
+		}
+		if err := validateReferenceID("worker_id", assignee.WorkerID); err != nil {
+			return fmt.Errorf("assignee %d: %v", i, err)
+		}
+		var key string
+		if assignee.UserID != nil {
+			key = "user:" + *assignee.UserID
+		} else {
+			key = "worker:" + *assignee.WorkerID
+		}
+		if seen[key] {
+			return fmt.Errorf("assignee %d is listed more than once", i)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// ValidateCreateChecklistItemRequest validates checklist item creation request
+func ValidateCreateChecklistItemRequest(req *CreateChecklistItemRequest) error {
+	return validateChecklistTitle(&req.Title)
+}
+
+// ValidateUpdateChecklistItemRequest validates checklist item update request
+func ValidateUpdateChecklistItemRequest(req *UpdateChecklistItemRequest) error {
+	if req.Title == nil && req.Done == nil && req.Position == nil {
+		return errors.New("title, done or position is required")
+	}
+	if req.Title != nil {
+		if err := validateChecklistTitle(req.Title); err != nil {
+			return err
+		}
+	}
+	if req.Position != nil && *req.Position < 0 {
+		return errors.New("position must be non-negative")
+	}
+	return nil
+}
+
+// validateChecklistTitle trims the title and checks its length
+func validateChecklistTitle(title *string) error {
+	*title = strings.TrimSpace(*title)
+	if *title == "" {
+		return errors.New("title is required")
+	}
+	if len(*title) > 255 {
+		return errors.New("title must be less than 255 characters")
+	}
 	return nil
 }
 
@@ -0,0 +1,39 @@
+package contacts
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Contact roles
+const (
+	RoleBuyer    = "buyer"    // Buys the farm's produce
+	RoleSupplier = "supplier" // Sells inputs such as seed, fertilizer or pesticide
+	RoleWorker   = "worker"   // Paid for work on the farm
+	RoleAdvisor  = "advisor"  // Extension officer, agronomist...
+)
+
+// Contact is a person or business the user deals with. Activities name them in
+// target_person and source_person and link them with target_contact_id and source_contact_id.
+type Contact struct {
+	ID        string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    string         `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name      string         `json:"name" gorm:"type:varchar(255);not null;index"`
+	Phone     *string        `json:"phone" gorm:"type:varchar(50)"`
+	Role      string         `json:"role" gorm:"type:varchar(20);not null;index"`
+	Note      *string        `json:"note" gorm:"type:text"`
+	Version   int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (c *Contact) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package contacts
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"plantheon-backend/common"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateContactHandler adds a contact to the current user's directory
+func CreateContactHandler(c *gin.Context) {
+	var req CreateContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateContactRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	contact := &Contact{
+		UserID: c.GetString("user_id"),
+		Name:   req.Name,
+		Phone:  req.Phone,
+		Role:   req.Role,
+		Note:   req.Note,
+	}
+
+	if err := CreateContactRecord(contact); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create contact",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Contact created successfully",
+		"data":    contact.ToContactResponse(),
+	})
+}
+
+// GetContactsHandler lists the current user's contacts with pagination
+// Query: GET /api/v1/contacts?search=...&role=buyer&page=1&limit=10
+func GetContactsHandler(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		limit = 10
+	}
+
+	// Validate pagination
+	page, limit, _ = ValidatePaginationParams(page, limit)
+	offset := (page - 1) * limit
+
+	role, ok := roleParam(c)
+	if !ok {
+		return
+	}
+
+	contacts, total, err := GetContactsByUser(c.GetString("user_id"), strings.TrimSpace(c.Query("search")), role, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get contacts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": ToContactsListResponse(contacts, total, page, limit),
+	})
+}
+
+// AutocompleteContactsHandler suggests contacts while a person is typed on an activity,
+// with the names already typed on activities that are not linked to a contact yet
+// GET /api/v1/contacts/autocomplete?q=Ba&role=worker&limit=10
+func AutocompleteContactsHandler(c *gin.Context) {
+	text := strings.TrimSpace(c.Query("q"))
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	role, ok := roleParam(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetString("user_id")
+	contacts, err := SearchContacts(userID, text, role, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to search contacts",
+		})
+		return
+	}
+	names, err := GetUnlinkedPersonNames(userID, text, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to search contacts",
+		})
+		return
+	}
+
+	response := AutocompleteResponse{
+		Contacts: make([]ContactSuggestion, 0, len(contacts)),
+		Names:    names,
+	}
+	if response.Names == nil {
+		response.Names = []string{}
+	}
+	for i := range contacts {
+		response.Contacts = append(response.Contacts, contacts[i].ToContactSuggestion())
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// GetContactHandler handles getting one of the current user's contacts
+func GetContactHandler(c *gin.Context) {
+	contact, ok := loadUserContact(c)
+	if !ok {
+		return
+	}
+
+	c.Header("ETag", common.ETag(contact.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"data": contact.ToContactResponse(),
+	})
+}
+
+// UpdateContactHandler handles contact update. A new name is not copied to the
+// person names already typed on linked activities.
+func UpdateContactHandler(c *gin.Context) {
+	contact, ok := loadUserContact(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateUpdateContactRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Reject the update if the client edited an outdated version
+	precondition, err := common.ParsePrecondition(c, req.Version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(contact.Version) {
+		respondContactConflict(c, precondition, contact)
+		return
+	}
+
+	// Update contact fields if provided
+	if req.Name != nil {
+		contact.Name = *req.Name
+	}
+	if req.Phone != nil {
+		contact.Phone = req.Phone
+	}
+	if req.Role != nil {
+		contact.Role = *req.Role
+	}
+	if req.Note != nil {
+		contact.Note = req.Note
+	}
+
+	if err := UpdateContact(contact); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetContactByID(contact.ID); err == nil {
+				respondContactConflict(c, precondition, current)
+				return
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update contact",
+		})
+		return
+	}
+
+	c.Header("ETag", common.ETag(contact.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Contact updated successfully",
+		"data":    contact.ToContactResponse(),
+	})
+}
+
+// DeleteContactHandler handles contact deletion, activities keep the person names but are unlinked
+func DeleteContactHandler(c *gin.Context) {
+	contact, ok := loadUserContact(c)
+	if !ok {
+		return
+	}
+
+	if err := DeleteContact(contact.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete contact",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Contact deleted successfully",
+	})
+}
+
+// LinkContactActivitiesHandler links the current user's activities whose target_person or
+// source_person is the contact's name (or the given name) to the contact
+// POST /api/v1/contacts/:id/link-activities
+func LinkContactActivitiesHandler(c *gin.Context) {
+	contact, ok := loadUserContact(c)
+	if !ok {
+		return
+	}
+
+	// The body is optional, without it the contact's name is matched
+	var req LinkActivitiesRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+			})
+			return
+		}
+	}
+	if err := ValidateLinkActivitiesRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	name := contact.Name
+	if req.Name != nil {
+		name = *req.Name
+	}
+
+	linked, err := LinkActivities(contact, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to link activities",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Activities linked successfully",
+		"data":    linked,
+	})
+}
+
+// GetContactStatementHandler returns what was paid to and received from a contact,
+// from the money of the activities linked to them
+// GET /api/v1/contacts/:id/statement?from=2025-01-01&to=2025-12-31
+func GetContactStatementHandler(c *gin.Context) {
+	contact, ok := loadUserContact(c)
+	if !ok {
+		return
+	}
+
+	from, to, err := common.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	acts, err := GetStatementActivities(contact, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get statement",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": BuildStatement(contact, acts, from, to),
+	})
+}
+
+// roleParam reads the optional role query parameter,
+// writing the error response and returning false when it is unknown
+func roleParam(c *gin.Context) (string, bool) {
+	role := c.Query("role")
+	if role == "" {
+		return "", true
+	}
+	if err := validateRole(&role); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return "", false
+	}
+	return role, true
+}
+
+// loadUserContact loads the contact from the :id parameter if it belongs to the current user,
+// writing the error response and returning false otherwise
+func loadUserContact(c *gin.Context) (*Contact, bool) {
+	contact, err := GetUserContact(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Contact not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get contact",
+		})
+		return nil, false
+	}
+	return contact, true
+}
+
+// respondContactConflict returns the current representation so the client can merge and retry
+func respondContactConflict(c *gin.Context, precondition *common.Precondition, current *Contact) {
+	c.Header("ETag", common.ETag(current.Version))
+	c.JSON(precondition.ConflictStatus(), gin.H{
+		"error": "Contact was modified by someone else",
+		"data":  current.ToContactResponse(),
+	})
+}
@@ -0,0 +1,188 @@
+package contacts
+
+import (
+	"time"
+
+	"plantheon-backend/models/activities"
+)
+
+// ContactResponse represents contact response
+type ContactResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Phone     *string   `json:"phone"`
+	Role      string    `json:"role"`
+	Note      *string   `json:"note"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ContactsListResponse represents paginated contacts list response
+type ContactsListResponse struct {
+	Contacts   []ContactResponse `json:"contacts"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	TotalPages int               `json:"total_pages"`
+}
+
+// ContactSuggestion represents a contact proposed by the autocomplete
+type ContactSuggestion struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Phone *string `json:"phone"`
+	Role  string  `json:"role"`
+}
+
+// AutocompleteResponse lists the matching contacts and the person names typed on
+// activities that are not linked to a contact yet
+type AutocompleteResponse struct {
+	Contacts []ContactSuggestion `json:"contacts"`
+	Names    []string            `json:"names"`
+}
+
+// CreateContactRequest represents contact creation request
+type CreateContactRequest struct {
+	Name  string  `json:"name" binding:"required"`
+	Phone *string `json:"phone"`
+	Role  string  `json:"role" binding:"required"`
+	Note  *string `json:"note"`
+}
+
+// UpdateContactRequest represents contact update request
+type UpdateContactRequest struct {
+	Name    *string `json:"name"`
+	Phone   *string `json:"phone"`
+	Role    *string `json:"role"`
+	Note    *string `json:"note"`
+	Version *int    `json:"version"` // Expected version when If-Match is not sent
+}
+
+// LinkActivitiesRequest links the activities naming the contact, defaults to the contact's name
+type LinkActivitiesRequest struct {
+	Name *string `json:"name"`
+}
+
+// LinkActivitiesResponse counts the person fields linked to the contact
+type LinkActivitiesResponse struct {
+	Target int64 `json:"target"`
+	Source int64 `json:"source"`
+}
+
+// StatementEntry is an activity with money on a contact's statement
+type StatementEntry struct {
+	ActivityID string    `json:"activity_id"`
+	Date       time.Time `json:"date"` // time_start, or creation time without one
+	Type       string    `json:"type"`
+	Title      string    `json:"title"`
+	Paid       float64   `json:"paid"`
+	Received   float64   `json:"received"`
+	Balance    float64   `json:"balance"` // Received minus paid so far in the period
+}
+
+// StatementResponse represents what was paid to and received from a contact over a period
+type StatementResponse struct {
+	Contact  ContactResponse          `json:"contact"`
+	From     *time.Time               `json:"from"`
+	To       *time.Time               `json:"to"`
+	Paid     float64                  `json:"paid"`
+	Received float64                  `json:"received"`
+	Net      float64                  `json:"net"` // Received minus paid
+	ByType   []activities.MoneyByType `json:"by_type"`
+	Entries  []StatementEntry         `json:"entries"`
+}
+
+// ToContactResponse converts Contact to ContactResponse
+func (c *Contact) ToContactResponse() ContactResponse {
+	return ContactResponse{
+		ID:        c.ID,
+		Name:      c.Name,
+		Phone:     c.Phone,
+		Role:      c.Role,
+		Note:      c.Note,
+		Version:   c.Version,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// ToContactSuggestion converts Contact to ContactSuggestion
+func (c *Contact) ToContactSuggestion() ContactSuggestion {
+	return ContactSuggestion{
+		ID:    c.ID,
+		Name:  c.Name,
+		Phone: c.Phone,
+		Role:  c.Role,
+	}
+}
+
+// ToContactsListResponse converts contacts list to paginated response
+func ToContactsListResponse(contacts []Contact, total int64, page, limit int) ContactsListResponse {
+	response := make([]ContactResponse, 0, len(contacts))
+	for i := range contacts {
+		response = append(response, contacts[i].ToContactResponse())
+	}
+
+	totalPages := int(total) / limit
+	if int(total)%limit > 0 {
+		totalPages++
+	}
+
+	return ContactsListResponse{
+		Contacts:   response,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}
+}
+
+// BuildStatement sums the money of the contact's activities, in date order. Money of
+// income types is received from the contact, money of the other types is paid.
+func BuildStatement(contact *Contact, acts []activities.Activity, from, to *time.Time) StatementResponse {
+	statement := StatementResponse{
+		Contact: contact.ToContactResponse(),
+		From:    from,
+		To:      to,
+		ByType:  []activities.MoneyByType{},
+		Entries: make([]StatementEntry, 0, len(acts)),
+	}
+
+	byType := make(map[string]int)
+	for i := range acts {
+		a := &acts[i]
+		if a.Money == nil {
+			continue
+		}
+		entry := StatementEntry{
+			ActivityID: a.ID,
+			Date:       a.CreatedAt,
+			Type:       a.Type,
+			Title:      a.Title,
+		}
+		if a.TimeStart != nil {
+			entry.Date = *a.TimeStart
+		}
+		if schema, ok := activities.LookupType(a.Type); ok && schema.Income {
+			entry.Received = *a.Money
+		} else {
+			entry.Paid = *a.Money
+		}
+		statement.Paid += entry.Paid
+		statement.Received += entry.Received
+		entry.Balance = statement.Received - statement.Paid
+		statement.Entries = append(statement.Entries, entry)
+
+		index, ok := byType[a.Type]
+		if !ok {
+			index = len(statement.ByType)
+			byType[a.Type] = index
+			statement.ByType = append(statement.ByType, activities.MoneyByType{Type: a.Type})
+		}
+		statement.ByType[index].Total += *a.Money
+		statement.ByType[index].Count++
+	}
+	statement.Net = statement.Received - statement.Paid
+	return statement
+}
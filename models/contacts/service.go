@@ -0,0 +1,193 @@
+package contacts
+
+import (
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/activities"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ContactService handles all database operations for contacts
+type ContactService struct {
+	db *gorm.DB
+}
+
+// NewContactService creates a new contact service instance
+func NewContactService() *ContactService {
+	return &ContactService{
+		db: common.GetDB(),
+	}
+}
+
+// CreateContactRecord creates a new contact
+func CreateContactRecord(contact *Contact) error {
+	service := NewContactService()
+	return service.db.Create(contact).Error
+}
+
+// GetContactByID finds contact by ID
+func GetContactByID(id string) (*Contact, error) {
+	service := NewContactService()
+	var contact Contact
+	err := service.db.Where("id = ?", id).First(&contact).Error
+	return &contact, err
+}
+
+// GetUserContact finds a contact owned by the user
+func GetUserContact(id, userID string) (*Contact, error) {
+	service := NewContactService()
+	var contact Contact
+	err := service.db.Where("id = ? AND user_id = ?", id, userID).First(&contact).Error
+	return &contact, err
+}
+
+// GetContactsByUser lists the user's contacts by name, optionally filtered by name or phone and role
+func GetContactsByUser(userID, search, role string, offset, limit int) ([]Contact, int64, error) {
+	service := NewContactService()
+	var contacts []Contact
+	var total int64
+
+	query := service.db.Model(&Contact{}).Where("user_id = ?", userID)
+	if search != "" {
+		searchQuery := "%" + search + "%"
+		query = query.Where("name ILIKE ? OR phone ILIKE ?", searchQuery, searchQuery)
+	}
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	// Count total records
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	err := query.Order("name ASC").Offset(offset).Limit(limit).Find(&contacts).Error
+	return contacts, total, err
+}
+
+// SearchContacts finds the user's contacts whose name or phone contains text,
+// names starting with it first
+func SearchContacts(userID, text, role string, limit int) ([]Contact, error) {
+	service := NewContactService()
+	var contacts []Contact
+
+	searchQuery := "%" + text + "%"
+	query := service.db.Where("user_id = ? AND (name ILIKE ? OR phone ILIKE ?)", userID, searchQuery, searchQuery)
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	err := query.Clauses(clause.OrderBy{Expression: gorm.Expr("name ILIKE ? DESC, name ASC", text+"%")}).
+		Limit(limit).Find(&contacts).Error
+	return contacts, err
+}
+
+// GetUnlinkedPersonNames returns the person names containing text typed on the user's activities
+// without a contact link, leaving out the names of existing contacts
+func GetUnlinkedPersonNames(userID, text string, limit int) ([]string, error) {
+	service := NewContactService()
+	var names []string
+	searchQuery := "%" + text + "%"
+	err := service.db.Raw(`SELECT name FROM (
+			SELECT TRIM(target_person) AS name FROM activities
+			WHERE user_id = ? AND deleted_at IS NULL AND target_contact_id IS NULL AND target_person ILIKE ?
+			UNION
+			SELECT TRIM(source_person) AS name FROM activities
+			WHERE user_id = ? AND deleted_at IS NULL AND source_contact_id IS NULL AND source_person ILIKE ?
+		) persons
+		WHERE name <> '' AND LOWER(name) NOT IN (
+			SELECT LOWER(name) FROM contacts WHERE user_id = ? AND deleted_at IS NULL
+		)
+		ORDER BY name ASC LIMIT ?`,
+		userID, searchQuery, userID, searchQuery, userID, limit).Scan(&names).Error
+	return names, err
+}
+
+// UpdateContact updates contact information.
+// The update only applies if the stored version still equals contact.Version,
+// otherwise common.ErrVersionConflict is returned. On success the version is incremented.
+func UpdateContact(contact *Contact) error {
+	service := NewContactService()
+	expected := contact.Version
+	contact.Version = expected + 1
+
+	result := service.db.Model(contact).
+		Where("version = ?", expected).
+		Select("*").Omit("id", "created_at").
+		Updates(contact)
+	if result.Error != nil {
+		contact.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		contact.Version = expected
+		return common.ErrVersionConflict
+	}
+	return nil
+}
+
+// DeleteContact soft-deletes contact by ID, its activities keep the person names but are unlinked
+func DeleteContact(id string) error {
+	service := NewContactService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		for _, column := range []string{"target_contact_id", "source_contact_id"} {
+			if _, err := setActivityContact(tx.Where(column+" = ?", id), column, nil); err != nil {
+				return err
+			}
+		}
+		return tx.Where("id = ?", id).Delete(&Contact{}).Error
+	})
+}
+
+// LinkActivities links the owner's activities naming the person, ignoring case and spaces,
+// to the contact. Activities already linked to a contact are left alone.
+func LinkActivities(contact *Contact, name string) (LinkActivitiesResponse, error) {
+	service := NewContactService()
+	var linked LinkActivitiesResponse
+	err := service.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		linked.Target, err = setActivityContact(tx.Where(
+			"user_id = ? AND target_contact_id IS NULL AND LOWER(TRIM(target_person)) = LOWER(?)", contact.UserID, name,
+		), "target_contact_id", contact.ID)
+		if err != nil {
+			return err
+		}
+		linked.Source, err = setActivityContact(tx.Where(
+			"user_id = ? AND source_contact_id IS NULL AND LOWER(TRIM(source_person)) = LOWER(?)", contact.UserID, name,
+		), "source_contact_id", contact.ID)
+		return err
+	})
+	return linked, err
+}
+
+// setActivityContact sets a contact column of the activities matching query, bumping
+// their version so clients holding them see the change
+func setActivityContact(query *gorm.DB, column string, contactID interface{}) (int64, error) {
+	result := query.Model(&activities.Activity{}).Updates(map[string]interface{}{
+		column:       contactID,
+		"version":    gorm.Expr("version + 1"),
+		"updated_at": time.Now(),
+	})
+	return result.RowsAffected, result.Error
+}
+
+// GetStatementActivities returns the owner's activities linked to the contact with money,
+// optionally limited to [from, to) by time_start (or creation time without one), oldest first
+func GetStatementActivities(contact *Contact, from, to *time.Time) ([]activities.Activity, error) {
+	service := NewContactService()
+	var acts []activities.Activity
+	query := service.db.Where("user_id = ? AND (target_contact_id = ? OR source_contact_id = ?) AND money IS NOT NULL",
+		contact.UserID, contact.ID, contact.ID)
+	if from != nil {
+		query = query.Where("COALESCE(time_start, created_at) >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("COALESCE(time_start, created_at) < ?", *to)
+	}
+	err := query.Order("COALESCE(time_start, created_at) ASC").Find(&acts).Error
+	return acts, err
+}
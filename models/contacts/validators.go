@@ -0,0 +1,91 @@
+package contacts
+
+import (
+	"errors"
+	"strings"
+)
+
+// ValidRoles lists the roles of a contact
+var ValidRoles = []string{RoleBuyer, RoleSupplier, RoleWorker, RoleAdvisor}
+
+// ValidateCreateContactRequest validates contact creation request
+func ValidateCreateContactRequest(req *CreateContactRequest) error {
+	if err := validateContactName(&req.Name); err != nil {
+		return err
+	}
+	if err := validateRole(&req.Role); err != nil {
+		return err
+	}
+	return validateContactFields(req.Phone, req.Note)
+}
+
+// ValidateUpdateContactRequest validates contact update request
+func ValidateUpdateContactRequest(req *UpdateContactRequest) error {
+	if req.Name != nil {
+		if err := validateContactName(req.Name); err != nil {
+			return err
+		}
+	}
+	if req.Role != nil {
+		if err := validateRole(req.Role); err != nil {
+			return err
+		}
+	}
+	return validateContactFields(req.Phone, req.Note)
+}
+
+// ValidateLinkActivitiesRequest validates the person name to link
+func ValidateLinkActivitiesRequest(req *LinkActivitiesRequest) error {
+	if req.Name != nil {
+		return validateContactName(req.Name)
+	}
+	return nil
+}
+
+// validateContactName trims the name and checks its length
+func validateContactName(name *string) error {
+	*name = strings.TrimSpace(*name)
+	if *name == "" {
+		return errors.New("contact name is required")
+	}
+	if len(*name) > 255 {
+		return errors.New("contact name must be less than 255 characters")
+	}
+	return nil
+}
+
+// validateRole normalizes the role and checks it is known
+func validateRole(role *string) error {
+	*role = strings.ToLower(strings.TrimSpace(*role))
+	for _, valid := range ValidRoles {
+		if *role == valid {
+			return nil
+		}
+	}
+	return errors.New("role must be one of " + strings.Join(ValidRoles, ", "))
+}
+
+// validateContactFields validates the optional fields shared by create and update
+func validateContactFields(phone, note *string) error {
+	if phone != nil && len(*phone) > 50 {
+		return errors.New("phone must be less than 50 characters")
+	}
+	if note != nil && len(*note) > 1000 {
+		return errors.New("note must be less than 1000 characters")
+	}
+	return nil
+}
+
+// ValidatePaginationParams validates pagination parameters
+func ValidatePaginationParams(page, limit int) (int, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return page, limit, nil
+}
@@ -17,8 +17,10 @@ type Disease struct {
 	Solution    string         `json:"solution" gorm:"type:text"`
 	ImageLink   pq.StringArray `json:"image_link" gorm:"type:text[]"`
 	PlantName   string         `json:"plant_name"`
-	CreatedAt   time.Time      `json:"created_at"`
+	Version     int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"index"`
 	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
 // BeforeCreate will set a UUID rather than numeric ID.
@@ -4,10 +4,14 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"plantheon-backend/common"
+	"plantheon-backend/models/media"
+
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 	"github.com/xuri/excelize/v2"
@@ -48,6 +52,7 @@ func CreateDiseaseHandler(c *gin.Context) {
 		})
 		return
 	}
+	syncDiseaseMedia(disease)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Disease created successfully",
@@ -79,6 +84,7 @@ func GetDisease(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", common.ETag(disease.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"data": disease.ToDiseaseResponse(),
 	})
@@ -104,6 +110,28 @@ func GetDiseases(c *gin.Context) {
 
 	// Validate pagination
 	page, limit, _ = ValidatePaginationParams(page, limit)
+
+	// Keyset pagination when a cursor is sent, page/limit otherwise
+	if common.CursorRequested(c) {
+		cursor, err := ParseDiseaseCursor(c.Query("cursor"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		diseases, cursorPage, err := GetDiseasesPage(search, diseaseType, cursor, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to get diseases",
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": ToDiseasesCursorResponse(diseases, cursorPage),
+		})
+		return
+	}
 	offset := (page - 1) * limit
 
 	var diseases []Disease
@@ -181,6 +209,24 @@ func GetAllDiseasesHandler(c *gin.Context) {
 	})
 }
 
+// GetPlantsHandler handles listing the plants covered by the disease catalog
+func GetPlantsHandler(c *gin.Context) {
+	plants, err := GetPlantNames()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get plants",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"plants": plants,
+			"count":  len(plants),
+		},
+	})
+}
+
 // GetDiseasesCountHandler handles getting diseases count only
 func GetDiseasesCountHandler(c *gin.Context) {
 	// Parse query parameters for filtering
@@ -254,6 +300,19 @@ func UpdateDiseaseHandler(c *gin.Context) {
 		return
 	}
 
+	// Reject the update if the client edited an outdated version
+	precondition, err := common.ParsePrecondition(c, req.Version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(disease.Version) {
+		respondDiseaseConflict(c, precondition, disease)
+		return
+	}
+
 	// Update disease fields if provided
 	if req.Name != "" {
 		disease.Name = req.Name
@@ -276,18 +335,117 @@ func UpdateDiseaseHandler(c *gin.Context) {
 
 	// Save updated disease
 	if err := UpdateDisease(disease); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetDiseaseByID(id); err == nil {
+				respondDiseaseConflict(c, precondition, current)
+				return
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update disease",
+		})
+		return
+	}
+	syncDiseaseMedia(disease)
+
+	c.Header("ETag", common.ETag(disease.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Disease updated successfully",
+		"data":    disease.ToDiseaseResponse(),
+	})
+}
+
+// PatchDiseaseHandler handles partial disease update with JSON merge patch (RFC 7396)
+func PatchDiseaseHandler(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Disease ID is required",
+		})
+		return
+	}
+
+	disease, err := GetDiseaseByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Disease not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get disease",
+		})
+		return
+	}
+
+	patch, err := common.ParseMergePatch(c)
+	if err != nil {
+		c.JSON(common.PatchErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Reject the patch if the client edited an outdated version
+	version, err := patch.Version()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	precondition, err := common.ParsePrecondition(c, version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(disease.Version) {
+		respondDiseaseConflict(c, precondition, disease)
+		return
+	}
+
+	if err := ApplyDiseasePatch(disease, patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := UpdateDisease(disease); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetDiseaseByID(id); err == nil {
+				respondDiseaseConflict(c, precondition, current)
+				return
+			}
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to update disease",
 		})
 		return
 	}
+	syncDiseaseMedia(disease)
 
+	c.Header("ETag", common.ETag(disease.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Disease updated successfully",
 		"data":    disease.ToDiseaseResponse(),
 	})
 }
 
+// respondDiseaseConflict returns the current representation so the client can merge and retry
+func respondDiseaseConflict(c *gin.Context, precondition *common.Precondition, current *Disease) {
+	c.Header("ETag", common.ETag(current.Version))
+	c.JSON(precondition.ConflictStatus(), gin.H{
+		"error": "Disease was modified by someone else",
+		"data":  current.ToDiseaseResponse(),
+	})
+}
+
 // DeleteDiseaseHandler handles disease deletion
 func DeleteDiseaseHandler(c *gin.Context) {
 	ClassName := c.Param("ClassName")
@@ -322,10 +480,168 @@ func DeleteDiseaseHandler(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Disease deleted successfully",
+		"message": "Disease moved to trash",
 	})
 }
 
+// GetTrashedDiseasesHandler handles listing soft-deleted diseases with pagination
+func GetTrashedDiseasesHandler(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		limit = 10
+	}
+
+	// Validate pagination
+	page, limit, _ = ValidatePaginationParams(page, limit)
+	offset := (page - 1) * limit
+
+	diseases, total, err := GetTrashedDiseases(offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get trashed diseases",
+		})
+		return
+	}
+
+	response := ToDiseasesListResponse(diseases, total, page, limit)
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// RestoreDiseaseHandler handles moving a disease out of trash
+func RestoreDiseaseHandler(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Disease ID is required",
+		})
+		return
+	}
+
+	disease, err := GetTrashedDiseaseByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Disease not found in trash",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get disease",
+		})
+		return
+	}
+
+	// A new disease may have taken the class name while this one was in trash
+	if _, err := GetDiseaseByClassName(disease.ClassName); err == nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Another disease with this class name already exists",
+		})
+		return
+	}
+
+	if err := RestoreDisease(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to restore disease",
+		})
+		return
+	}
+
+	disease, err = GetDiseaseByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get disease",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Disease restored successfully",
+		"data":    disease.ToDiseaseResponse(),
+	})
+}
+
+// UploadDiseaseImageHandler handles uploading an image and appending it to the disease's image links
+func UploadDiseaseImageHandler(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Disease ID is required",
+		})
+		return
+	}
+
+	disease, err := GetDiseaseByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Disease not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get disease",
+		})
+		return
+	}
+
+	if len(disease.ImageLink) >= 20 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "image link array cannot have more than 20 items",
+		})
+		return
+	}
+
+	data, err := media.ReadUpload(c, "file")
+	if err != nil {
+		media.RespondUploadError(c, err)
+		return
+	}
+
+	m, err := media.StoreImage(c.Request.Context(), data, c.GetString("user_id"))
+	if err != nil {
+		media.RespondUploadError(c, err)
+		return
+	}
+
+	disease.ImageLink = append(disease.ImageLink, media.ContentURL(c, m.ID, ""))
+	if err := UpdateDisease(disease); err != nil {
+		if err == common.ErrVersionConflict {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Disease was modified by someone else, please retry",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update disease",
+		})
+		return
+	}
+	syncDiseaseMedia(disease)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Image uploaded successfully",
+		"data": gin.H{
+			"disease": disease.ToDiseaseResponse(),
+			"media":   m.ToMediaResponse(c),
+		},
+	})
+}
+
+// syncDiseaseMedia records which uploaded media the disease's image links use
+func syncDiseaseMedia(disease *Disease) {
+	ids := media.MediaIDsFromURLs(disease.ImageLink...)
+	if err := media.SyncReferences(media.OwnerDisease, disease.ID, ids); err != nil {
+		log.Printf("Failed to sync media of disease %s: %v", disease.ID, err)
+	}
+}
+
 // GetDiseaseByClassNameHandler handles getting disease by class name
 func GetDiseaseByClassNameHandler(c *gin.Context) {
 	ClassName := c.Param("ClassName")
@@ -350,6 +666,7 @@ func GetDiseaseByClassNameHandler(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", common.ETag(disease.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"data": disease,
 	})
@@ -0,0 +1,216 @@
+package diseases
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/common/dbtest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const diseaseID = "5f0c3e0e-6a7d-4a8e-9a57-2f1d6c1b9e01"
+
+// expectDisease expects the disease to be loaded by ID, returning it at the version
+func expectDisease(mock sqlmock.Sqlmock, version int) {
+	mock.ExpectQuery(`SELECT \* FROM "diseases" WHERE id = \$1`).
+		WithArgs(diseaseID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "class_name", "type", "description", "version"}).
+			AddRow(diseaseID, "Đạo ôn", "rice_blast", "fungus", "Vết bệnh hình thoi", version))
+}
+
+func TestUpdateDiseaseHandlerPreconditions(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		body    string
+		stored  int // version saved by someone else between read and write, 0 when the update applies
+		status  int
+		etag    string
+	}{
+		{"stale If-Match", `"2"`, `{"name": "Đạo ôn lá"}`, 0, http.StatusPreconditionFailed, `"3"`},
+		{"stale version field", "", `{"name": "Đạo ôn lá", "version": 2}`, 0, http.StatusConflict, `"3"`},
+		{"missing precondition", "", `{"name": "Đạo ôn lá"}`, 0, http.StatusPreconditionRequired, ""},
+		{"If-Match lost the race", `"3"`, `{"name": "Đạo ôn lá"}`, 4, http.StatusPreconditionFailed, `"4"`},
+		{"version field lost the race", "", `{"name": "Đạo ôn lá", "version": 3}`, 4, http.StatusConflict, `"4"`},
+		{"current If-Match", `W/"3"`, `{"name": "Đạo ôn lá"}`, 0, http.StatusOK, `"4"`},
+		{"current version field", "", `{"name": "Đạo ôn lá", "version": 3}`, 0, http.StatusOK, `"4"`},
+		{"any version", `*`, `{"name": "Đạo ôn lá"}`, 0, http.StatusOK, `"4"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := dbtest.Mock(t)
+			expectDisease(mock, 3)
+			switch {
+			case tt.stored > 0:
+				mock.ExpectExec(`UPDATE "diseases" SET .* WHERE version = \$\d+`).WillReturnResult(sqlmock.NewResult(0, 0))
+				expectDisease(mock, tt.stored)
+			case tt.status == http.StatusOK:
+				mock.ExpectExec(`UPDATE "diseases" SET .* WHERE version = \$\d+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT \* FROM "media_references"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			}
+
+			header := http.Header{}
+			if tt.ifMatch != "" {
+				header.Set("If-Match", tt.ifMatch)
+			}
+			w := dbtest.Serve(http.MethodPut, "/diseases/:id", "/diseases/"+diseaseID, tt.body, header, "", UpdateDiseaseHandler)
+			if w.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if etag := w.Header().Get("ETag"); etag != tt.etag {
+				t.Fatalf("got ETag %q, want %q", etag, tt.etag)
+			}
+
+			if tt.status == http.StatusPreconditionRequired {
+				return
+			}
+			// Conflicts return the current disease so the client can merge and retry
+			var response struct {
+				Data DiseaseResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Data.ID != diseaseID || common.ETag(response.Data.Version) != tt.etag {
+				t.Fatalf("got data %+v, want the disease at ETag %s", response.Data, tt.etag)
+			}
+		})
+	}
+}
+
+func TestPatchDiseaseHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		want        DiseaseResponse // fields checked on success
+	}{
+		{
+			name: "null clears and omitted keys are kept", body: `{"description": null, "solution": "Phun thuốc", "version": 3}`,
+			status: http.StatusOK,
+			want:   DiseaseResponse{Name: "Đạo ôn", ClassName: "rice_blast", Type: "fungus", Description: "", Solution: "Phun thuốc", Version: 4},
+		},
+		{
+			name: "empty patch changes nothing", body: `{"version": 3}`,
+			status: http.StatusOK,
+			want:   DiseaseResponse{Name: "Đạo ôn", ClassName: "rice_blast", Type: "fungus", Description: "Vết bệnh hình thoi", Version: 4},
+		},
+		{name: "required field cleared", body: `{"name": null, "version": 3}`, status: http.StatusBadRequest},
+		{name: "unknown field", body: `{"severity": 2, "version": 3}`, status: http.StatusBadRequest},
+		{name: "not an object", body: `["name"]`, status: http.StatusBadRequest},
+		{name: "json patch", contentType: "application/json-patch+json", body: `[]`, status: http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := dbtest.Mock(t)
+			expectDisease(mock, 3)
+			if tt.status == http.StatusOK {
+				mock.ExpectExec(`UPDATE "diseases" SET .* WHERE version = \$\d+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT \* FROM "media_references"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			}
+
+			header := http.Header{}
+			header.Set("Content-Type", "application/merge-patch+json")
+			if tt.contentType != "" {
+				header.Set("Content-Type", tt.contentType)
+			}
+			w := dbtest.Serve(http.MethodPatch, "/diseases/:id", "/diseases/"+diseaseID, tt.body, header, "", PatchDiseaseHandler)
+			if w.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Data DiseaseResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			got := response.Data
+			if got.Name != tt.want.Name || got.ClassName != tt.want.ClassName || got.Type != tt.want.Type ||
+				got.Description != tt.want.Description || got.Solution != tt.want.Solution || got.Version != tt.want.Version {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetDiseasesCursor(t *testing.T) {
+	newest := time.Date(2025, 3, 5, 8, 0, 0, 0, time.UTC)
+	created := map[string]time.Time{"a": newest, "b": newest.Add(-time.Hour), "c": newest.Add(-2 * time.Hour)}
+	rows := func(ids ...string) *sqlmock.Rows {
+		r := sqlmock.NewRows([]string{"id", "name", "class_name", "type", "created_at"})
+		for _, id := range ids {
+			r.AddRow(id, "Bệnh "+id, id, "fungus", created[id])
+		}
+		return r
+	}
+	type page struct {
+		Data DiseasesCursorResponse `json:"data"`
+	}
+	get := func(t *testing.T, query string) page {
+		t.Helper()
+		w := dbtest.Serve(http.MethodGet, "/diseases", "/diseases?"+query, "", nil, "", GetDiseases)
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d: %s", w.Code, w.Body)
+		}
+		var response page
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+	ids := func(p page) []string {
+		var ids []string
+		for _, d := range p.Data.Diseases {
+			ids = append(ids, d.ID)
+		}
+		return ids
+	}
+
+	mock := dbtest.Mock(t)
+
+	// The first page fetches one row more than the limit to know another page follows
+	mock.ExpectQuery(`SELECT \* FROM "diseases" WHERE "diseases"."deleted_at" IS NULL ORDER BY created_at DESC NULLS LAST,id DESC LIMIT 3`).
+		WillReturnRows(rows("a", "b", "c"))
+	first := get(t, "cursor=&limit=2")
+	if !reflect.DeepEqual(ids(first), []string{"a", "b"}) || first.Data.NextCursor == nil || first.Data.PrevCursor != nil {
+		t.Fatalf("first page: got %v next %v prev %v", ids(first), first.Data.NextCursor, first.Data.PrevCursor)
+	}
+
+	// The next page starts after the last row, newer rows are excluded by its key
+	mock.ExpectQuery(`SELECT \* FROM "diseases" WHERE \(\(created_at < \$1 OR \(created_at = \$2 AND id < \$3\) OR created_at IS NULL\)\) AND "diseases"."deleted_at" IS NULL ORDER BY created_at DESC NULLS LAST,id DESC LIMIT 3`).
+		WithArgs(created["b"], created["b"], "b").
+		WillReturnRows(rows("c"))
+	second := get(t, "cursor="+*first.Data.NextCursor+"&limit=2")
+	if !reflect.DeepEqual(ids(second), []string{"c"}) || second.Data.NextCursor != nil || second.Data.PrevCursor == nil {
+		t.Fatalf("second page: got %v next %v prev %v", ids(second), second.Data.NextCursor, second.Data.PrevCursor)
+	}
+
+	// Going back reads the list backwards from the first row and restores the order
+	mock.ExpectQuery(`SELECT \* FROM "diseases" WHERE \(\(created_at > \$1 OR \(created_at = \$2 AND id > \$3\)\)\) AND "diseases"."deleted_at" IS NULL ORDER BY created_at ASC NULLS FIRST,id ASC LIMIT 3`).
+		WithArgs(created["c"], created["c"], "c").
+		WillReturnRows(rows("b", "a"))
+	back := get(t, "cursor="+*second.Data.PrevCursor+"&limit=2")
+	if !reflect.DeepEqual(ids(back), []string{"a", "b"}) || back.Data.NextCursor == nil || back.Data.PrevCursor != nil {
+		t.Fatalf("previous page: got %v next %v prev %v", ids(back), back.Data.NextCursor, back.Data.PrevCursor)
+	}
+}
+
+func TestGetDiseasesInvalidCursor(t *testing.T) {
+	dbtest.Mock(t)
+	for _, cursor := range []string{"not-a-cursor", "eyJzIjoibmFtZTphc2MiLCJrIjoibnVsbCIsImlkIjoiYSJ9"} {
+		w := dbtest.Serve(http.MethodGet, "/diseases", "/diseases?cursor="+cursor, "", nil, "", GetDiseases)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("cursor %s: got status %d, want 400", cursor, w.Code)
+		}
+	}
+}
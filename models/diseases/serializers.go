@@ -2,6 +2,8 @@ package diseases
 
 import (
 	"time"
+
+	"plantheon-backend/common"
 )
 
 // DiseaseResponse represents disease response
@@ -14,8 +16,10 @@ type DiseaseResponse struct {
 	Solution    string    `json:"solution"`
 	ImageLink   []string  `json:"image_link"`
 	PlantName   string    `json:"plant_name"`
+	Version     int       `json:"version"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
 }
 
 // CreateDiseaseRequest represents disease creation request
@@ -38,6 +42,7 @@ type UpdateDiseaseRequest struct {
 	Solution    string   `json:"solution"`
 	ImageLink   []string `json:"image_link"`
 	PlantName   string   `json:"plant_name"`
+	Version     *int     `json:"version"` // Expected version when If-Match is not sent
 }
 
 // ExcelDiseaseRow represents a single row from Excel file
@@ -76,8 +81,19 @@ type DiseasesListResponse struct {
 	Pages    int               `json:"pages"`
 }
 
+// DiseasesCursorResponse represents a keyset paginated diseases response
+type DiseasesCursorResponse struct {
+	Diseases []DiseaseResponse `json:"diseases"`
+	common.CursorPage
+}
+
 // ToDiseaseResponse converts Disease model to DiseaseResponse
 func (d *Disease) ToDiseaseResponse() DiseaseResponse {
+	var deletedAt *time.Time
+	if d.DeletedAt.Valid {
+		deletedAt = &d.DeletedAt.Time
+	}
+
 	return DiseaseResponse{
 		ID:          d.ID,
 		Name:        d.Name,
@@ -87,8 +103,10 @@ func (d *Disease) ToDiseaseResponse() DiseaseResponse {
 		Solution:    d.Solution,
 		ImageLink:   []string(d.ImageLink),
 		PlantName:   d.PlantName,
+		Version:     d.Version,
 		CreatedAt:   d.CreatedAt,
 		UpdatedAt:   d.UpdatedAt,
+		DeletedAt:   deletedAt,
 	}
 }
 
@@ -112,3 +130,12 @@ func ToDiseasesListResponse(diseases []Disease, total int64, page, limit int) Di
 		Pages:    pages,
 	}
 }
+
+// ToDiseasesCursorResponse converts a keyset page of diseases to response
+func ToDiseasesCursorResponse(diseases []Disease, page common.CursorPage) DiseasesCursorResponse {
+	diseaseResponses := make([]DiseaseResponse, len(diseases))
+	for i, disease := range diseases {
+		diseaseResponses[i] = disease.ToDiseaseResponse()
+	}
+	return DiseasesCursorResponse{Diseases: diseaseResponses, CursorPage: page}
+}
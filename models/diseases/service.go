@@ -1,7 +1,11 @@
 package diseases
 
 import (
+	"log"
+	"time"
+
 	"plantheon-backend/common"
+	"plantheon-backend/models/media"
 
 	"gorm.io/gorm"
 )
@@ -85,6 +89,37 @@ func SearchDiseases(keyword string, offset, limit int) ([]Disease, int64, error)
 	return diseases, total, err
 }
 
+// diseaseKeyset is the order of keyset paginated disease lists, newest first
+var diseaseKeyset = common.Keyset{Column: "created_at", Desc: true}
+
+// ParseDiseaseCursor decodes a disease list cursor
+func ParseDiseaseCursor(encoded string) (*common.Cursor, error) {
+	return diseaseKeyset.ParseCursor(encoded)
+}
+
+// GetDiseasesPage gets a page of diseases after the cursor, filtered by search keyword
+// or type when they are not empty. The cursor is nil for the first page.
+func GetDiseasesPage(search, diseaseType string, cursor *common.Cursor, limit int) ([]Disease, common.CursorPage, error) {
+	service := NewDiseaseService()
+	var diseases []Disease
+
+	query := service.db
+	if search != "" {
+		searchQuery := "%" + search + "%"
+		query = query.Where("name ILIKE ? OR description ILIKE ?", searchQuery, searchQuery)
+	} else if diseaseType != "" {
+		query = query.Where("type = ?", diseaseType)
+	}
+
+	if err := diseaseKeyset.Apply(query, cursor, limit).Find(&diseases).Error; err != nil {
+		return nil, common.CursorPage{}, err
+	}
+	diseases, page := common.Page(diseases, cursor, limit, diseaseKeyset, func(d *Disease) (interface{}, string) {
+		return d.CreatedAt, d.ID
+	})
+	return diseases, page, nil
+}
+
 // GetAllDiseasesWithoutPagination gets all diseases without pagination
 func GetAllDiseasesWithoutPagination() ([]Disease, error) {
 	service := NewDiseaseService()
@@ -135,6 +170,34 @@ func SearchDiseasesCount(keyword string) (int64, error) {
 	return count, err
 }
 
+// GetPlantNames returns the distinct plant names of the disease catalog
+func GetPlantNames() ([]string, error) {
+	service := NewDiseaseService()
+	var names []string
+	err := service.db.Model(&Disease{}).
+		Where("plant_name IS NOT NULL AND plant_name <> ''").
+		Distinct("plant_name").Order("plant_name").
+		Pluck("plant_name", &names).Error
+	return names, err
+}
+
+// FindPlantName returns the catalog spelling of a plant name, matched case-insensitively,
+// or gorm.ErrRecordNotFound when no disease of the catalog concerns that plant
+func FindPlantName(name string) (string, error) {
+	service := NewDiseaseService()
+	var names []string
+	err := service.db.Model(&Disease{}).
+		Where("LOWER(plant_name) = LOWER(?)", name).
+		Limit(1).Pluck("plant_name", &names).Error
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+	return names[0], nil
+}
+
 // GetDiseaseByClassName gets disease by class name
 func GetDiseaseByClassName(className string) (*Disease, error) {
 	service := NewDiseaseService()
@@ -144,13 +207,107 @@ func GetDiseaseByClassName(className string) (*Disease, error) {
 }
 
 // UpdateDisease updates disease information
+// The update only applies if the stored version still equals disease.Version,
+// otherwise common.ErrVersionConflict is returned. On success the version is incremented.
 func UpdateDisease(disease *Disease) error {
 	service := NewDiseaseService()
-	return service.db.Save(disease).Error
+	expected := disease.Version
+	disease.Version = expected + 1
+
+	result := service.db.Model(disease).
+		Where("version = ?", expected).
+		Select("*").Omit("id", "created_at").
+		Updates(disease)
+	if result.Error != nil {
+		disease.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		disease.Version = expected
+		return common.ErrVersionConflict
+	}
+	return nil
 }
 
-// DeleteDisease deletes disease by ID
+// DeleteDisease moves disease to trash by class name
 func DeleteDisease(ClassName string) error {
 	service := NewDiseaseService()
 	return service.db.Delete(&Disease{}, "class_name = ?", ClassName).Error
+}
+
+// GetTrashedDiseases gets soft-deleted diseases with pagination, most recently deleted first
+func GetTrashedDiseases(offset, limit int) ([]Disease, int64, error) {
+	service := NewDiseaseService()
+	var diseases []Disease
+	var total int64
+
+	query := service.db.Unscoped().Where("deleted_at IS NOT NULL")
+
+	// Count total records
+	if err := query.Model(&Disease{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	err := query.Offset(offset).Limit(limit).Order("deleted_at DESC").Find(&diseases).Error
+	return diseases, total, err
+}
+
+// GetTrashedDiseaseByID finds a soft-deleted disease by ID
+func GetTrashedDiseaseByID(id string) (*Disease, error) {
+	service := NewDiseaseService()
+	var disease Disease
+	err := service.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&disease).Error
+	return &disease, err
+}
+
+// RestoreDisease moves a soft-deleted disease out of trash
+func RestoreDisease(id string) error {
+	service := NewDiseaseService()
+	return service.db.Unscoped().Model(&Disease{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil).Error
+}
+
+// PurgeTrashedDiseases permanently deletes diseases trashed before the given time
+// and cleans up their uploaded images
+func PurgeTrashedDiseases(before time.Time) (int64, error) {
+	service := NewDiseaseService()
+
+	var ids []string
+	if err := service.db.Unscoped().Model(&Disease{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := service.db.Unscoped().Where("id IN ?", ids).Delete(&Disease{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	for _, id := range ids {
+		if err := media.ReleaseOwner(media.OwnerDisease, id); err != nil {
+			log.Printf("Failed to clean up media of disease %s: %v", id, err)
+		}
+	}
+	return result.RowsAffected, nil
+}
+
+// StartTrashPurge periodically purges diseases that stayed in trash longer than retention
+func StartTrashPurge(interval, retention time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if n, err := PurgeTrashedDiseases(time.Now().Add(-retention)); err != nil {
+				log.Println("Disease trash purge failed:", err)
+			} else if n > 0 {
+				log.Printf("Disease trash purge removed %d diseases", n)
+			}
+		}
+	}()
 }
\ No newline at end of file
@@ -3,6 +3,10 @@ package diseases
 import (
 	"errors"
 	"strings"
+
+	"plantheon-backend/common"
+
+	"github.com/lib/pq"
 )
 
 // ValidateCreateDiseaseRequest validates disease creation request
@@ -120,6 +124,61 @@ func ValidateUpdateDiseaseRequest(req *UpdateDiseaseRequest) error {
 	return nil
 }
 
+// ApplyDiseasePatch validates a JSON merge patch with the update rules and applies it.
+// Optional fields set to null are cleared, required fields cannot be null or empty.
+func ApplyDiseasePatch(disease *Disease, patch common.MergePatch) error {
+	if err := patch.CheckFields("name", "class_name", "type", "description", "solution", "image_link", "plant_name", "version"); err != nil {
+		return err
+	}
+	if err := patch.CheckNotNull("name", "class_name", "type"); err != nil {
+		return err
+	}
+
+	var req UpdateDiseaseRequest
+	if err := patch.Decode(&req); err != nil {
+		return errors.New("invalid field type in merge patch")
+	}
+	if err := ValidateUpdateDiseaseRequest(&req); err != nil {
+		return err
+	}
+
+	if patch.Has("name") {
+		if strings.TrimSpace(req.Name) == "" {
+			return errors.New("disease name cannot be empty")
+		}
+		disease.Name = req.Name
+	}
+	if patch.Has("class_name") {
+		if strings.TrimSpace(req.ClassName) == "" {
+			return errors.New("class name cannot be empty")
+		}
+		disease.ClassName = req.ClassName
+	}
+	if patch.Has("type") {
+		if strings.TrimSpace(req.Type) == "" {
+			return errors.New("disease type cannot be empty")
+		}
+		disease.Type = req.Type
+	}
+	if patch.Has("description") {
+		disease.Description = req.Description
+	}
+	if patch.Has("solution") {
+		disease.Solution = req.Solution
+	}
+	if patch.Has("image_link") {
+		if req.ImageLink == nil {
+			req.ImageLink = []string{}
+		}
+		disease.ImageLink = pq.StringArray(req.ImageLink)
+	}
+	if patch.Has("plant_name") {
+		disease.PlantName = strings.TrimSpace(req.PlantName)
+	}
+
+	return nil
+}
+
 // ValidatePaginationParams validates pagination parameters
 func ValidatePaginationParams(page, limit int) (int, int, error) {
 	if page < 1 {
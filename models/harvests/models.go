@@ -0,0 +1,48 @@
+package harvests
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Harvest records a quantity of produce picked on a plot and what it sold for
+type Harvest struct {
+	ID           string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID       string         `json:"user_id" gorm:"type:uuid;not null;index"`
+	PlotID       string         `json:"plot_id" gorm:"type:uuid;not null;index"`
+	SeasonID     *string        `json:"season_id" gorm:"type:uuid;index"`
+	HarvestedAt  time.Time      `json:"harvested_at" gorm:"not null;index"`
+	Quantity     float64        `json:"quantity" gorm:"type:decimal(15,4);not null"`
+	Unit         string         `json:"unit" gorm:"type:varchar(50);not null"`
+	QualityGrade *string        `json:"quality_grade" gorm:"type:varchar(50)"`
+	UnitPrice    *float64       `json:"unit_price" gorm:"type:decimal(15,2)"`
+	Revenue      float64        `json:"revenue" gorm:"type:decimal(15,2);not null;default:0"`
+	Buyer        *string        `json:"buyer" gorm:"type:varchar(255)"`
+	Note         *string        `json:"note" gorm:"type:text"`
+	Version      int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (h *Harvest) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == "" {
+		h.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// computeRevenue prices the harvest from its unit price unless the revenue was entered directly
+func (h *Harvest) computeRevenue(revenue *float64) {
+	switch {
+	case revenue != nil:
+		h.Revenue = *revenue
+	case h.UnitPrice != nil:
+		h.Revenue = h.Quantity * *h.UnitPrice
+	default:
+		h.Revenue = 0
+	}
+}
@@ -0,0 +1,646 @@
+package harvests
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/activities"
+	"plantheon-backend/models/plots"
+	"plantheon-backend/models/seasons"
+	"plantheon-backend/models/units"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateHarvestHandler records a harvest on one of the current user's plots or seasons
+func CreateHarvestHandler(c *gin.Context) {
+	var req CreateHarvestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateHarvestRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !units.CheckUnit(c, &req.Unit, "") {
+		return
+	}
+
+	userID := c.GetString("user_id")
+	harvest := &Harvest{
+		UserID:       userID,
+		SeasonID:     req.SeasonID,
+		HarvestedAt:  time.Now(),
+		Quantity:     req.Quantity,
+		Unit:         req.Unit,
+		QualityGrade: req.QualityGrade,
+		UnitPrice:    req.UnitPrice,
+		Buyer:        req.Buyer,
+		Note:         req.Note,
+	}
+	if req.HarvestedAt != nil {
+		harvest.HarvestedAt = *req.HarvestedAt
+	}
+	harvest.computeRevenue(req.Revenue)
+
+	if req.SeasonID != nil {
+		season, ok := loadHarvestSeason(c, *req.SeasonID)
+		if !ok {
+			return
+		}
+		if req.PlotID != nil && *req.PlotID != season.PlotID {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Season belongs to another plot",
+			})
+			return
+		}
+		harvest.PlotID = season.PlotID
+		if !checkSeasonUnit(c, harvest) {
+			return
+		}
+	} else {
+		if _, err := plots.GetUserPlot(*req.PlotID, userID); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Plot not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to get plot",
+			})
+			return
+		}
+		harvest.PlotID = *req.PlotID
+	}
+
+	if err := CreateHarvestRecord(harvest); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create harvest",
+		})
+		return
+	}
+	syncSeasonYield(harvest.SeasonID, harvest.UserID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Harvest created successfully",
+		"data":    harvest.ToHarvestResponse(),
+	})
+}
+
+// GetHarvestsHandler lists the current user's harvests
+// Query: GET /api/v1/harvests?plot_id=...&season_id=...&from=YYYY-MM-DD&to=YYYY-MM-DD
+func GetHarvestsHandler(c *gin.Context) {
+	from, to, err := common.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	harvests, err := GetHarvestsByUser(c.GetString("user_id"), c.Query("plot_id"), c.Query("season_id"), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get harvests",
+		})
+		return
+	}
+
+	response := make([]HarvestResponse, len(harvests))
+	var revenue float64
+	for i, h := range harvests {
+		response[i] = h.ToHarvestResponse()
+		revenue += h.Revenue
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"harvests": response,
+			"count":    len(response),
+			"revenue":  revenue,
+		},
+	})
+}
+
+// GetHarvestHandler handles getting one of the current user's harvests
+func GetHarvestHandler(c *gin.Context) {
+	harvest, ok := loadUserHarvest(c)
+	if !ok {
+		return
+	}
+
+	c.Header("ETag", common.ETag(harvest.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"data": harvest.ToHarvestResponse(),
+	})
+}
+
+// UpdateHarvestHandler handles harvest update
+func UpdateHarvestHandler(c *gin.Context) {
+	harvest, ok := loadUserHarvest(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateHarvestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateUpdateHarvestRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !units.CheckUnit(c, req.Unit, "") {
+		return
+	}
+
+	// Reject the update if the client edited an outdated version
+	precondition, err := common.ParsePrecondition(c, req.Version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(harvest.Version) {
+		respondHarvestConflict(c, precondition, harvest)
+		return
+	}
+
+	// Update harvest fields if provided
+	if req.HarvestedAt != nil {
+		harvest.HarvestedAt = *req.HarvestedAt
+	}
+	if req.Quantity != nil {
+		harvest.Quantity = *req.Quantity
+	}
+	if req.Unit != nil {
+		harvest.Unit = *req.Unit
+	}
+	if req.QualityGrade != nil {
+		harvest.QualityGrade = req.QualityGrade
+	}
+	if req.UnitPrice != nil {
+		harvest.UnitPrice = req.UnitPrice
+	}
+	if req.Buyer != nil {
+		harvest.Buyer = req.Buyer
+	}
+	if req.Note != nil {
+		harvest.Note = req.Note
+	}
+	// Keep a directly entered revenue unless the price or quantity changed
+	if req.Revenue != nil || req.UnitPrice != nil || req.Quantity != nil {
+		harvest.computeRevenue(req.Revenue)
+	}
+
+	if req.Unit != nil && !checkSeasonUnit(c, harvest) {
+		return
+	}
+
+	if err := UpdateHarvest(harvest); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetHarvestByID(harvest.ID); err == nil {
+				respondHarvestConflict(c, precondition, current)
+				return
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update harvest",
+		})
+		return
+	}
+	syncSeasonYield(harvest.SeasonID, harvest.UserID)
+
+	c.Header("ETag", common.ETag(harvest.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Harvest updated successfully",
+		"data":    harvest.ToHarvestResponse(),
+	})
+}
+
+// DeleteHarvestHandler handles harvest deletion
+func DeleteHarvestHandler(c *gin.Context) {
+	harvest, ok := loadUserHarvest(c)
+	if !ok {
+		return
+	}
+
+	if err := DeleteHarvest(harvest.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete harvest",
+		})
+		return
+	}
+	syncSeasonYield(harvest.SeasonID, harvest.UserID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Harvest deleted successfully",
+	})
+}
+
+// GetSeasonProfitsHandler compares yield, revenue, cost and profit of the current user's seasons
+// Query: GET /api/v1/analytics/seasons?plot_id=...&plant_name=Corn&status=harvested&sort=profit&unit=t&area_unit=ha
+func GetSeasonProfitsHandler(c *gin.Context) {
+	sortBy, err := ValidateSortField(c.Query("sort"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	reportUnits, ok := units.ParseReportUnits(c)
+	if !ok {
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && !seasons.IsValidStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid status",
+		})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	userSeasons, err := seasons.GetSeasonsByUser(userID, c.Query("plot_id"), status, c.Query("plant_name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get seasons",
+		})
+		return
+	}
+
+	plotsByID, ok := loadUserPlotsByID(c, userID)
+	if !ok {
+		return
+	}
+
+	ids := make([]string, len(userSeasons))
+	for i, s := range userSeasons {
+		ids[i] = s.ID
+	}
+
+	harvestTotals, err := GetHarvestTotalsBySeason(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get harvests",
+		})
+		return
+	}
+
+	moneyTotals, err := activities.GetMoneyTotalsBySeason(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get season costs",
+		})
+		return
+	}
+
+	response := make([]ProfitResponse, 0, len(userSeasons))
+	for _, s := range userSeasons {
+		profit := ProfitResponse{
+			PlotID:     s.PlotID,
+			SeasonID:   s.ID,
+			SeasonName: s.Name,
+			PlantName:  s.PlantName,
+			Status:     s.Status,
+		}
+		if plot, ok := plotsByID[s.PlotID]; ok {
+			profit.PlotName = plot.Name
+			profit.Area, profit.AreaUnit = reportUnits.Area(plot.Area, plot.AreaUnit)
+		}
+		buildProfit(&profit, s.ID, harvestTotals, moneyTotals, reportUnits)
+		response = append(response, profit)
+	}
+	sortProfits(response, sortBy)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"seasons": response,
+			"count":   len(response),
+		},
+	})
+}
+
+// GetPlotProfitsHandler compares yield, revenue, cost and profit of the current user's plots over a period
+// Query: GET /api/v1/analytics/plots?from=YYYY-MM-DD&to=YYYY-MM-DD&sort=yield_per_area&unit=kg&area_unit=sao
+func GetPlotProfitsHandler(c *gin.Context) {
+	sortBy, err := ValidateSortField(c.Query("sort"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	reportUnits, ok := units.ParseReportUnits(c)
+	if !ok {
+		return
+	}
+
+	from, to, err := common.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	userPlots, err := plots.GetAllUserPlots(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get plots",
+		})
+		return
+	}
+
+	ids := make([]string, len(userPlots))
+	for i, p := range userPlots {
+		ids[i] = p.ID
+	}
+
+	harvestTotals, err := GetHarvestTotalsByPlot(ids, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get harvests",
+		})
+		return
+	}
+
+	moneyTotals, err := activities.GetMoneyTotalsByPlot(ids, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get plot costs",
+		})
+		return
+	}
+
+	response := make([]ProfitResponse, 0, len(userPlots))
+	for _, p := range userPlots {
+		profit := ProfitResponse{
+			PlotID:   p.ID,
+			PlotName: p.Name,
+		}
+		profit.Area, profit.AreaUnit = reportUnits.Area(p.Area, p.AreaUnit)
+		buildProfit(&profit, p.ID, harvestTotals, moneyTotals, reportUnits)
+		response = append(response, profit)
+	}
+	sortProfits(response, sortBy)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"plots": response,
+			"count": len(response),
+			"from":  from,
+			"to":    to,
+		},
+	})
+}
+
+// buildProfit fills yields, revenue, cost and margin from the totals matching key.
+// Yields are converted into the requested unit and merged when they share it.
+func buildProfit(profit *ProfitResponse, key string, harvestTotals []HarvestTotals, moneyTotals []activities.MoneyTotals, reportUnits *units.ReportUnits) {
+	for _, money := range moneyTotals {
+		if money.Key == key {
+			profit.TotalCost = money.Cost
+			profit.OtherIncome = money.Income
+		}
+	}
+
+	profit.Yields = []YieldResponse{}
+	for _, total := range harvestTotals {
+		if total.Key != key {
+			continue
+		}
+		profit.Revenue += total.Revenue
+		quantity, unit := reportUnits.Quantity(total.Quantity, total.Unit)
+
+		merged := false
+		for i := range profit.Yields {
+			if profit.Yields[i].Unit == unit {
+				profit.Yields[i].Quantity += quantity
+				profit.Yields[i].HarvestCount += total.Count
+				merged = true
+			}
+		}
+		if !merged {
+			profit.Yields = append(profit.Yields, YieldResponse{
+				Unit:         unit,
+				Quantity:     quantity,
+				HarvestCount: total.Count,
+			})
+		}
+	}
+	if profit.Area != nil && *profit.Area > 0 {
+		for i := range profit.Yields {
+			perArea := profit.Yields[i].Quantity / *profit.Area
+			profit.Yields[i].PerArea = &perArea
+		}
+	}
+	// Cost per unit only makes sense when everything was harvested in a single unit
+	if len(profit.Yields) == 1 && profit.Yields[0].Quantity > 0 {
+		costPerUnit := profit.TotalCost / profit.Yields[0].Quantity
+		profit.Yields[0].CostPerUnit = &costPerUnit
+	}
+
+	income := profit.Revenue + profit.OtherIncome
+	profit.Profit = income - profit.TotalCost
+	if income > 0 {
+		margin := profit.Profit / income
+		profit.ProfitMargin = &margin
+	}
+}
+
+// sortProfits orders rows by the sort key, highest first, rows without a value last
+func sortProfits(rows []ProfitResponse, sortBy string) {
+	value := func(p ProfitResponse) (float64, bool) {
+		switch sortBy {
+		case "profit_margin":
+			if p.ProfitMargin == nil {
+				return 0, false
+			}
+			return *p.ProfitMargin, true
+		case "revenue":
+			return p.Revenue, true
+		case "total_cost":
+			return p.TotalCost, true
+		case "yield_per_area":
+			if len(p.Yields) == 0 || p.Yields[0].PerArea == nil {
+				return 0, false
+			}
+			return *p.Yields[0].PerArea, true
+		default:
+			return p.Profit, true
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		vi, oki := value(rows[i])
+		vj, okj := value(rows[j])
+		if oki != okj {
+			return oki
+		}
+		return vi > vj
+	})
+}
+
+// syncSeasonYield stores the harvested quantity on the season after its harvests changed,
+// in the unit of the first harvest of the season
+func syncSeasonYield(seasonID *string, userID string) {
+	if seasonID == nil {
+		return
+	}
+	seasonUnit, err := GetSeasonHarvestUnit(*seasonID, "")
+	if err != nil {
+		log.Printf("Failed to get harvest unit of season %s: %v", *seasonID, err)
+		return
+	}
+	totals, err := GetHarvestTotalsBySeason([]string{*seasonID})
+	if err != nil {
+		log.Printf("Failed to sum harvests of season %s: %v", *seasonID, err)
+		return
+	}
+
+	var quantity *float64
+	var unit *string
+	if len(totals) > 0 {
+		resolver := units.NewResolver(userID)
+		var sum float64
+		for _, total := range totals {
+			converted, err := resolver.Convert(total.Quantity, total.Unit, seasonUnit)
+			if err != nil {
+				log.Printf("Failed to convert harvests of season %s: %v", *seasonID, err)
+				continue
+			}
+			sum += converted
+		}
+		quantity = &sum
+		unit = &seasonUnit
+	}
+	if err := seasons.SetSeasonYield(*seasonID, quantity, unit); err != nil {
+		log.Printf("Failed to update yield of season %s: %v", *seasonID, err)
+	}
+}
+
+// checkSeasonUnit requires the harvests of a season to use units convertible to each other
+// so its yield can be summed, writing the error response and returning false otherwise
+func checkSeasonUnit(c *gin.Context, harvest *Harvest) bool {
+	if harvest.SeasonID == nil {
+		return true
+	}
+	unit, err := GetSeasonHarvestUnit(*harvest.SeasonID, harvest.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get season harvests",
+		})
+		return false
+	}
+	if unit != "" && unit != harvest.Unit {
+		if _, err := units.NewResolver(harvest.UserID).Convert(1, harvest.Unit, unit); err != nil {
+			if units.ErrorStatus(err) == http.StatusInternalServerError {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to resolve unit",
+				})
+				return false
+			}
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "unit must be convertible to the unit of the other harvests of the season (" + unit + ")",
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// loadHarvestSeason loads a season of the current user referenced by a harvest,
+// writing the error response and returning false otherwise
+func loadHarvestSeason(c *gin.Context, seasonID string) (*seasons.Season, bool) {
+	season, err := seasons.GetUserSeason(seasonID, c.GetString("user_id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Season not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get season",
+		})
+		return nil, false
+	}
+	return season, true
+}
+
+// loadUserPlotsByID loads the current user's plots keyed by ID,
+// writing the error response and returning false otherwise
+func loadUserPlotsByID(c *gin.Context, userID string) (map[string]plots.Plot, bool) {
+	userPlots, err := plots.GetAllUserPlots(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get plots",
+		})
+		return nil, false
+	}
+	plotsByID := make(map[string]plots.Plot, len(userPlots))
+	for _, p := range userPlots {
+		plotsByID[p.ID] = p
+	}
+	return plotsByID, true
+}
+
+// loadUserHarvest loads the harvest from the :id param if the current user owns it,
+// writing the error response and returning false otherwise
+func loadUserHarvest(c *gin.Context) (*Harvest, bool) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Harvest ID is required",
+		})
+		return nil, false
+	}
+
+	harvest, err := GetUserHarvest(id, c.GetString("user_id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Harvest not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get harvest",
+		})
+		return nil, false
+	}
+	return harvest, true
+}
+
+// respondHarvestConflict returns the current representation so the client can merge and retry
+func respondHarvestConflict(c *gin.Context, precondition *common.Precondition, current *Harvest) {
+	c.Header("ETag", common.ETag(current.Version))
+	c.JSON(precondition.ConflictStatus(), gin.H{
+		"error": "Harvest was modified by someone else",
+		"data":  current.ToHarvestResponse(),
+	})
+}
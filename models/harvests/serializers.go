@@ -0,0 +1,105 @@
+package harvests
+
+import "time"
+
+// HarvestResponse represents harvest response
+type HarvestResponse struct {
+	ID           string    `json:"id"`
+	PlotID       string    `json:"plot_id"`
+	SeasonID     *string   `json:"season_id"`
+	HarvestedAt  time.Time `json:"harvested_at"`
+	Quantity     float64   `json:"quantity"`
+	Unit         string    `json:"unit"`
+	QualityGrade *string   `json:"quality_grade"`
+	UnitPrice    *float64  `json:"unit_price"`
+	Revenue      float64   `json:"revenue"`
+	Buyer        *string   `json:"buyer"`
+	Note         *string   `json:"note"`
+	Version      int       `json:"version"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CreateHarvestRequest represents harvest creation request.
+// Either plot_id or season_id is required, the plot defaults to the season's plot.
+type CreateHarvestRequest struct {
+	PlotID       *string    `json:"plot_id"`
+	SeasonID     *string    `json:"season_id"`
+	HarvestedAt  *time.Time `json:"harvested_at"` // Defaults to now
+	Quantity     float64    `json:"quantity" binding:"required"`
+	Unit         string     `json:"unit" binding:"required"`
+	QualityGrade *string    `json:"quality_grade"`
+	UnitPrice    *float64   `json:"unit_price"`
+	Revenue      *float64   `json:"revenue"` // Defaults to quantity * unit_price
+	Buyer        *string    `json:"buyer"`
+	Note         *string    `json:"note"`
+}
+
+// UpdateHarvestRequest represents harvest update request
+type UpdateHarvestRequest struct {
+	HarvestedAt  *time.Time `json:"harvested_at"`
+	Quantity     *float64   `json:"quantity"`
+	Unit         *string    `json:"unit"`
+	QualityGrade *string    `json:"quality_grade"`
+	UnitPrice    *float64   `json:"unit_price"`
+	Revenue      *float64   `json:"revenue"`
+	Buyer        *string    `json:"buyer"`
+	Note         *string    `json:"note"`
+	Version      *int       `json:"version"` // Expected version when If-Match is not sent
+}
+
+// HarvestTotals represents harvested quantity and revenue of one season or plot in one unit
+type HarvestTotals struct {
+	Key      string  `json:"-"` // Season or plot ID
+	Unit     string  `json:"unit"`
+	Quantity float64 `json:"quantity"`
+	Revenue  float64 `json:"revenue"`
+	Count    int64   `json:"count"`
+}
+
+// YieldResponse represents the yield of a season or plot in one unit
+type YieldResponse struct {
+	Unit         string   `json:"unit"`
+	Quantity     float64  `json:"quantity"`
+	PerArea      *float64 `json:"per_area"` // Quantity divided by the plot area, in area_unit
+	CostPerUnit  *float64 `json:"cost_per_unit,omitempty"`
+	HarvestCount int64    `json:"harvest_count"`
+}
+
+// ProfitResponse represents the profitability of a season or plot
+type ProfitResponse struct {
+	PlotID       string          `json:"plot_id"`
+	PlotName     string          `json:"plot_name"`
+	SeasonID     string          `json:"season_id,omitempty"`
+	SeasonName   string          `json:"season_name,omitempty"`
+	PlantName    string          `json:"plant_name,omitempty"`
+	Status       string          `json:"status,omitempty"`
+	Area         *float64        `json:"area"`
+	AreaUnit     string          `json:"area_unit"`
+	Yields       []YieldResponse `json:"yields"`
+	Revenue      float64         `json:"revenue"`      // Harvest sales
+	OtherIncome  float64         `json:"other_income"` // Income activities
+	TotalCost    float64         `json:"total_cost"`   // Expense activities
+	Profit       float64         `json:"profit"`
+	ProfitMargin *float64        `json:"profit_margin"` // Profit over revenue and other income, nil without income
+}
+
+// ToHarvestResponse converts Harvest model to HarvestResponse
+func (h *Harvest) ToHarvestResponse() HarvestResponse {
+	return HarvestResponse{
+		ID:           h.ID,
+		PlotID:       h.PlotID,
+		SeasonID:     h.SeasonID,
+		HarvestedAt:  h.HarvestedAt,
+		Quantity:     h.Quantity,
+		Unit:         h.Unit,
+		QualityGrade: h.QualityGrade,
+		UnitPrice:    h.UnitPrice,
+		Revenue:      h.Revenue,
+		Buyer:        h.Buyer,
+		Note:         h.Note,
+		Version:      h.Version,
+		CreatedAt:    h.CreatedAt,
+		UpdatedAt:    h.UpdatedAt,
+	}
+}
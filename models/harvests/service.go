@@ -0,0 +1,145 @@
+package harvests
+
+import (
+	"time"
+
+	"plantheon-backend/common"
+
+	"gorm.io/gorm"
+)
+
+// HarvestService handles all database operations for harvests
+type HarvestService struct {
+	db *gorm.DB
+}
+
+// NewHarvestService creates a new harvest service instance
+func NewHarvestService() *HarvestService {
+	return &HarvestService{
+		db: common.GetDB(),
+	}
+}
+
+// CreateHarvestRecord creates a new harvest
+func CreateHarvestRecord(harvest *Harvest) error {
+	service := NewHarvestService()
+	return service.db.Create(harvest).Error
+}
+
+// GetHarvestByID finds harvest by ID
+func GetHarvestByID(id string) (*Harvest, error) {
+	service := NewHarvestService()
+	var harvest Harvest
+	err := service.db.Where("id = ?", id).First(&harvest).Error
+	return &harvest, err
+}
+
+// GetUserHarvest finds a harvest owned by the user
+func GetUserHarvest(id, userID string) (*Harvest, error) {
+	service := NewHarvestService()
+	var harvest Harvest
+	err := service.db.Where("id = ? AND user_id = ?", id, userID).First(&harvest).Error
+	return &harvest, err
+}
+
+// GetHarvestsByUser lists the user's harvests, newest first, optionally filtered by plot, season and [from, to)
+func GetHarvestsByUser(userID, plotID, seasonID string, from, to *time.Time) ([]Harvest, error) {
+	service := NewHarvestService()
+	var harvests []Harvest
+
+	query := service.db.Where("user_id = ?", userID)
+	if plotID != "" {
+		query = query.Where("plot_id = ?", plotID)
+	}
+	if seasonID != "" {
+		query = query.Where("season_id = ?", seasonID)
+	}
+	if from != nil {
+		query = query.Where("harvested_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("harvested_at < ?", *to)
+	}
+
+	err := query.Order("harvested_at DESC").Find(&harvests).Error
+	return harvests, err
+}
+
+// GetSeasonHarvestUnit returns the unit of the harvests already recorded for a season,
+// or an empty string when there are none
+func GetSeasonHarvestUnit(seasonID, excludeID string) (string, error) {
+	service := NewHarvestService()
+	var units []string
+	query := service.db.Model(&Harvest{}).Where("season_id = ?", seasonID)
+	if excludeID != "" {
+		query = query.Where("id <> ?", excludeID)
+	}
+	err := query.Order("harvested_at").Limit(1).Pluck("unit", &units).Error
+	if err != nil || len(units) == 0 {
+		return "", err
+	}
+	return units[0], nil
+}
+
+// UpdateHarvest updates harvest information.
+// The update only applies if the stored version still equals harvest.Version,
+// otherwise common.ErrVersionConflict is returned. On success the version is incremented.
+func UpdateHarvest(harvest *Harvest) error {
+	service := NewHarvestService()
+	expected := harvest.Version
+	harvest.Version = expected + 1
+
+	result := service.db.Model(harvest).
+		Where("version = ?", expected).
+		Select("*").Omit("id", "created_at").
+		Updates(harvest)
+	if result.Error != nil {
+		harvest.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		harvest.Version = expected
+		return common.ErrVersionConflict
+	}
+	return nil
+}
+
+// DeleteHarvest soft-deletes harvest by ID
+func DeleteHarvest(id string) error {
+	service := NewHarvestService()
+	return service.db.Where("id = ?", id).Delete(&Harvest{}).Error
+}
+
+// GetHarvestTotalsBySeason sums harvested quantity and revenue of each season by unit
+func GetHarvestTotalsBySeason(seasonIDs []string) ([]HarvestTotals, error) {
+	service := NewHarvestService()
+	var totals []HarvestTotals
+	if len(seasonIDs) == 0 {
+		return totals, nil
+	}
+	err := service.db.Model(&Harvest{}).
+		Select("season_id AS key, unit, SUM(quantity) AS quantity, SUM(revenue) AS revenue, COUNT(*) AS count").
+		Where("season_id IN ?", seasonIDs).
+		Group("season_id, unit").Order("unit").Scan(&totals).Error
+	return totals, err
+}
+
+// GetHarvestTotalsByPlot sums harvested quantity and revenue of each plot by unit, optionally limited to [from, to)
+func GetHarvestTotalsByPlot(plotIDs []string, from, to *time.Time) ([]HarvestTotals, error) {
+	service := NewHarvestService()
+	var totals []HarvestTotals
+	if len(plotIDs) == 0 {
+		return totals, nil
+	}
+	query := service.db.Model(&Harvest{}).
+		Select("plot_id AS key, unit, SUM(quantity) AS quantity, SUM(revenue) AS revenue, COUNT(*) AS count").
+		Where("plot_id IN ?", plotIDs)
+	if from != nil {
+		query = query.Where("harvested_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("harvested_at < ?", *to)
+	}
+	err := query.Group("plot_id, unit").Order("unit").Scan(&totals).Error
+	return totals, err
+}
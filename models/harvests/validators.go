@@ -0,0 +1,103 @@
+package harvests
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ValidateCreateHarvestRequest validates harvest creation request
+func ValidateCreateHarvestRequest(req *CreateHarvestRequest) error {
+	if req.PlotID == nil && req.SeasonID == nil {
+		return errors.New("plot_id or season_id is required")
+	}
+	if req.PlotID != nil {
+		if _, err := uuid.Parse(*req.PlotID); err != nil {
+			return errors.New("plot_id must be a valid UUID")
+		}
+	}
+	if req.SeasonID != nil {
+		if _, err := uuid.Parse(*req.SeasonID); err != nil {
+			return errors.New("season_id must be a valid UUID")
+		}
+	}
+
+	if req.HarvestedAt != nil && req.HarvestedAt.After(time.Now()) {
+		return errors.New("harvested_at cannot be in the future")
+	}
+
+	if req.Quantity <= 0 {
+		return errors.New("quantity must be positive")
+	}
+
+	req.Unit = strings.TrimSpace(req.Unit)
+	if req.Unit == "" {
+		return errors.New("unit is required")
+	}
+	if len(req.Unit) > 50 {
+		return errors.New("unit must be less than 50 characters")
+	}
+
+	return validateSaleFields(req.QualityGrade, req.UnitPrice, req.Revenue, req.Buyer, req.Note)
+}
+
+// ValidateUpdateHarvestRequest validates harvest update request
+func ValidateUpdateHarvestRequest(req *UpdateHarvestRequest) error {
+	if req.HarvestedAt != nil && req.HarvestedAt.After(time.Now()) {
+		return errors.New("harvested_at cannot be in the future")
+	}
+
+	if req.Quantity != nil && *req.Quantity <= 0 {
+		return errors.New("quantity must be positive")
+	}
+
+	if req.Unit != nil {
+		*req.Unit = strings.TrimSpace(*req.Unit)
+		if *req.Unit == "" {
+			return errors.New("unit cannot be empty")
+		}
+		if len(*req.Unit) > 50 {
+			return errors.New("unit must be less than 50 characters")
+		}
+	}
+
+	return validateSaleFields(req.QualityGrade, req.UnitPrice, req.Revenue, req.Buyer, req.Note)
+}
+
+// validateSaleFields validates the grading and pricing fields shared by create and update
+func validateSaleFields(grade *string, unitPrice, revenue *float64, buyer, note *string) error {
+	if grade != nil && len(*grade) > 50 {
+		return errors.New("quality_grade must be less than 50 characters")
+	}
+	if unitPrice != nil && *unitPrice < 0 {
+		return errors.New("unit_price must be non-negative")
+	}
+	if revenue != nil && *revenue < 0 {
+		return errors.New("revenue must be non-negative")
+	}
+	if buyer != nil && len(*buyer) > 255 {
+		return errors.New("buyer must be less than 255 characters")
+	}
+	if note != nil && len(*note) > 1000 {
+		return errors.New("note must be less than 1000 characters")
+	}
+	return nil
+}
+
+// ValidSortFields lists the analytics sort keys
+var ValidSortFields = []string{"profit", "profit_margin", "revenue", "total_cost", "yield_per_area"}
+
+// ValidateSortField checks the analytics sort key, defaulting to profit
+func ValidateSortField(sort string) (string, error) {
+	if sort == "" {
+		return "profit", nil
+	}
+	for _, field := range ValidSortFields {
+		if field == sort {
+			return sort, nil
+		}
+	}
+	return "", errors.New("sort must be one of: " + strings.Join(ValidSortFields, ", "))
+}
@@ -0,0 +1,78 @@
+package inventory
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Movement types
+const (
+	MovementPurchase    = "purchase"    // Stock bought, increases the quantity
+	MovementConsumption = "consumption" // Stock used on the farm, decreases the quantity
+	MovementAdjustment  = "adjustment"  // Signed correction after a stock count, loss or spoilage
+)
+
+// Item is a farm input kept in stock, e.g. a fertilizer, seed or pesticide
+type Item struct {
+	ID               string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID           string         `json:"user_id" gorm:"type:uuid;not null;index"` // Owner, or creator in an organization
+	OrganizationID   *string        `json:"organization_id" gorm:"type:uuid;index"`  // Organization sharing the item
+	Name             string         `json:"name" gorm:"type:varchar(255);not null"`  // Matched against Activity.Object
+	Category         *string        `json:"category" gorm:"type:varchar(100)"`
+	Unit             string         `json:"unit" gorm:"type:varchar(50);not null"`
+	ReorderThreshold *float64       `json:"reorder_threshold" gorm:"type:decimal(15,4)"`
+	Note             *string        `json:"note" gorm:"type:text"`
+	Version          int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// TableName prefixes the table so it does not clash with other kinds of items
+func (Item) TableName() string {
+	return "inventory_items"
+}
+
+// Movement is an entry of the stock ledger of an item. Quantity is signed,
+// so the current stock is the sum of the movements.
+type Movement struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ItemID     string    `json:"item_id" gorm:"type:uuid;not null;index"`
+	UserID     string    `json:"user_id" gorm:"type:uuid;not null;index"`
+	Type       string    `json:"type" gorm:"type:varchar(20);not null"`
+	Quantity   float64   `json:"quantity" gorm:"type:decimal(15,4);not null"`
+	UnitCost   *float64  `json:"unit_cost" gorm:"type:decimal(15,2)"`
+	TotalCost  *float64  `json:"total_cost" gorm:"type:decimal(15,2)"`
+	ActivityID *string   `json:"activity_id" gorm:"type:uuid;index"` // Set for consumption recorded from an activity
+	OccurredAt time.Time `json:"occurred_at" gorm:"not null;index"`
+	Note       *string   `json:"note" gorm:"type:text"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName prefixes the table like Item
+func (Movement) TableName() string {
+	return "inventory_movements"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (i *Item) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (m *Movement) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsLowStock checks if the stock fell to the reorder threshold
+func (i *Item) IsLowStock(stock float64) bool {
+	return i.ReorderThreshold != nil && stock <= *i.ReorderThreshold
+}
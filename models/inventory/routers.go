@@ -0,0 +1,483 @@
+package inventory
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/units"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateItemHandler handles inventory item creation for the current user or organization
+func CreateItemHandler(c *gin.Context) {
+	var req CreateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateItemRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if !units.CheckUnit(c, &req.Unit, "") {
+		return
+	}
+
+	tenant := common.CurrentTenant(c)
+	if !checkItemNameAvailable(c, tenant, req.Name, "") {
+		return
+	}
+
+	item := &Item{
+		UserID:           tenant.UserID,
+		OrganizationID:   tenant.OrganizationRef(),
+		Name:             req.Name,
+		Category:         req.Category,
+		Unit:             req.Unit,
+		ReorderThreshold: req.ReorderThreshold,
+		Note:             req.Note,
+	}
+
+	if err := CreateItemRecord(item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create inventory item",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Inventory item created successfully",
+		"data":    item.ToItemResponse(StockSummary{}),
+	})
+}
+
+// GetItemsHandler lists the inventory items of the current user or organization with their stock
+// Query: GET /api/v1/inventory/items?search=...&category=...
+func GetItemsHandler(c *gin.Context) {
+	items, err := GetItemsByTenant(common.CurrentTenant(c), c.Query("search"), c.Query("category"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get inventory items",
+		})
+		return
+	}
+
+	response, ok := itemResponses(c, items)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"items": response,
+			"count": len(response),
+		},
+	})
+}
+
+// GetItemHandler handles getting one inventory item of the current user or organization
+func GetItemHandler(c *gin.Context) {
+	item, ok := loadUserItem(c)
+	if !ok {
+		return
+	}
+
+	summary, err := GetStockSummary(item.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get stock",
+		})
+		return
+	}
+
+	c.Header("ETag", common.ETag(item.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"data": item.ToItemResponse(summary),
+	})
+}
+
+// UpdateItemHandler handles inventory item update
+func UpdateItemHandler(c *gin.Context) {
+	item, ok := loadUserItem(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateUpdateItemRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Reject the update if the client edited an outdated version
+	precondition, err := common.ParsePrecondition(c, req.Version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(item.Version) {
+		respondItemConflict(c, precondition, item)
+		return
+	}
+
+	// Update item fields if provided
+	if req.Name != nil {
+		if !checkItemNameAvailable(c, common.CurrentTenant(c), *req.Name, item.ID) {
+			return
+		}
+		item.Name = *req.Name
+	}
+	if req.Category != nil {
+		item.Category = req.Category
+	}
+	if req.Unit != nil {
+		if !units.CheckUnit(c, req.Unit, "") {
+			return
+		}
+		item.Unit = *req.Unit
+	}
+	if req.ReorderThreshold != nil {
+		item.ReorderThreshold = req.ReorderThreshold
+	}
+	if req.Note != nil {
+		item.Note = req.Note
+	}
+
+	if err := UpdateItem(item); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetItemByID(item.ID); err == nil {
+				respondItemConflict(c, precondition, current)
+				return
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update inventory item",
+		})
+		return
+	}
+
+	summary, err := GetStockSummary(item.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get stock",
+		})
+		return
+	}
+
+	c.Header("ETag", common.ETag(item.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Inventory item updated successfully",
+		"data":    item.ToItemResponse(summary),
+	})
+}
+
+// DeleteItemHandler handles inventory item deletion
+func DeleteItemHandler(c *gin.Context) {
+	item, ok := loadUserItem(c)
+	if !ok {
+		return
+	}
+
+	if err := DeleteItem(item.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete inventory item",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Inventory item deleted successfully",
+	})
+}
+
+// CreateMovementHandler records a purchase, consumption or adjustment of an item
+func CreateMovementHandler(c *gin.Context) {
+	item, ok := loadUserItem(c)
+	if !ok {
+		return
+	}
+
+	var req CreateMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateMovementRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	movement := &Movement{
+		ItemID:     item.ID,
+		UserID:     c.GetString("user_id"),
+		Type:       req.Type,
+		Quantity:   req.Quantity,
+		UnitCost:   req.UnitCost,
+		TotalCost:  req.TotalCost,
+		OccurredAt: time.Now(),
+		Note:       req.Note,
+	}
+	if req.Type == MovementConsumption {
+		movement.Quantity = -req.Quantity
+	}
+	if req.OccurredAt != nil {
+		movement.OccurredAt = *req.OccurredAt
+	}
+	if movement.TotalCost == nil && movement.UnitCost != nil {
+		totalCost := req.Quantity * *movement.UnitCost
+		movement.TotalCost = &totalCost
+	}
+	if movement.UnitCost == nil && movement.TotalCost != nil {
+		unitCost := *movement.TotalCost / req.Quantity
+		movement.UnitCost = &unitCost
+	}
+
+	if err := CreateMovementRecord(movement); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to record stock movement",
+		})
+		return
+	}
+
+	summary, err := GetStockSummary(item.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get stock",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Stock movement recorded successfully",
+		"data": gin.H{
+			"movement": movement.ToMovementResponse(),
+			"item":     item.ToItemResponse(summary),
+		},
+	})
+}
+
+// GetMovementsHandler lists the stock movements of an item
+// Query: GET /api/v1/inventory/items/:id/movements?from=YYYY-MM-DD&to=YYYY-MM-DD
+func GetMovementsHandler(c *gin.Context) {
+	item, ok := loadUserItem(c)
+	if !ok {
+		return
+	}
+
+	from, to, err := common.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	movements, err := GetMovementsByItem(item.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get stock movements",
+		})
+		return
+	}
+
+	response := make([]MovementResponse, len(movements))
+	for i, m := range movements {
+		response[i] = m.ToMovementResponse()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"movements": response,
+			"count":     len(response),
+		},
+	})
+}
+
+// GetLowStockAlertsHandler lists the items whose stock fell to their reorder threshold
+func GetLowStockAlertsHandler(c *gin.Context) {
+	items, err := GetItemsByTenant(common.CurrentTenant(c), "", "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get inventory items",
+		})
+		return
+	}
+
+	response, ok := itemResponses(c, items)
+	if !ok {
+		return
+	}
+
+	alerts := make([]ItemResponse, 0)
+	for _, item := range response {
+		if item.LowStock {
+			alerts = append(alerts, item)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"items": alerts,
+			"count": len(alerts),
+		},
+	})
+}
+
+// GetValuationHandler values the stock of the current user or organization at weighted average purchase cost
+func GetValuationHandler(c *gin.Context) {
+	items, err := GetItemsByTenant(common.CurrentTenant(c), "", "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get inventory items",
+		})
+		return
+	}
+
+	response, ok := itemResponses(c, items)
+	if !ok {
+		return
+	}
+
+	valuation := ValuationResponse{ItemCount: len(response)}
+	byCategory := make(map[string]*CategoryValuation)
+	for _, item := range response {
+		valuation.TotalValue += item.Value
+		if item.LowStock {
+			valuation.LowStockCount++
+		}
+
+		category := "other"
+		if item.Category != nil && *item.Category != "" {
+			category = *item.Category
+		}
+		if byCategory[category] == nil {
+			byCategory[category] = &CategoryValuation{Category: category}
+		}
+		byCategory[category].Value += item.Value
+		byCategory[category].Items++
+	}
+
+	valuation.ByCategory = make([]CategoryValuation, 0, len(byCategory))
+	for _, category := range byCategory {
+		valuation.ByCategory = append(valuation.ByCategory, *category)
+	}
+	sort.Slice(valuation.ByCategory, func(i, j int) bool {
+		return valuation.ByCategory[i].Value > valuation.ByCategory[j].Value
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": valuation,
+	})
+}
+
+// itemResponses converts items with their stock summaries,
+// writing the error response and returning false otherwise
+func itemResponses(c *gin.Context, items []Item) ([]ItemResponse, bool) {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+
+	summaries, err := GetStockSummaries(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get stock",
+		})
+		return nil, false
+	}
+
+	response := make([]ItemResponse, len(items))
+	for i, item := range items {
+		response[i] = item.ToItemResponse(summaries[item.ID])
+	}
+	return response, true
+}
+
+// checkItemNameAvailable rejects a second item with the same name since activities match items by name,
+// writing the error response and returning false otherwise
+func checkItemNameAvailable(c *gin.Context, tenant common.Tenant, name, excludeID string) bool {
+	existing, err := GetTenantItemByName(tenant, name)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return true
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check inventory item name",
+		})
+		return false
+	}
+	if existing.ID == excludeID {
+		return true
+	}
+	c.JSON(http.StatusConflict, gin.H{
+		"error": "An inventory item with this name already exists",
+	})
+	return false
+}
+
+// loadUserItem loads the inventory item from the :id param if it belongs to the current user or organization,
+// writing the error response and returning false otherwise
+func loadUserItem(c *gin.Context) (*Item, bool) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Inventory item ID is required",
+		})
+		return nil, false
+	}
+
+	item, err := GetTenantItem(id, common.CurrentTenant(c))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Inventory item not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get inventory item",
+		})
+		return nil, false
+	}
+	return item, true
+}
+
+// respondItemConflict returns the current representation so the client can merge and retry
+func respondItemConflict(c *gin.Context, precondition *common.Precondition, current *Item) {
+	summary, _ := GetStockSummary(current.ID)
+	c.Header("ETag", common.ETag(current.Version))
+	c.JSON(precondition.ConflictStatus(), gin.H{
+		"error": "Inventory item was modified by someone else",
+		"data":  current.ToItemResponse(summary),
+	})
+}
@@ -0,0 +1,151 @@
+package inventory
+
+import (
+	"time"
+
+	"plantheon-backend/common"
+)
+
+// StockSummary represents the stock and valuation of an item, computed from its movements
+type StockSummary struct {
+	ItemID            string  `json:"-"`
+	Stock             float64 `json:"stock"`
+	PurchasedQuantity float64 `json:"-"`
+	PurchaseCost      float64 `json:"-"`
+}
+
+// ItemResponse represents inventory item response
+type ItemResponse struct {
+	ID               string    `json:"id"`
+	Name             string    `json:"name"`
+	Category         *string   `json:"category"`
+	Unit             string    `json:"unit"`
+	ReorderThreshold *float64  `json:"reorder_threshold"`
+	Note             *string   `json:"note"`
+	Stock            float64   `json:"stock"`
+	AverageCost      *float64  `json:"average_cost"` // Weighted average of the purchase unit costs
+	Value            float64   `json:"value"`        // Stock valued at the average cost
+	LowStock         bool      `json:"low_stock"`
+	Version          int       `json:"version"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// CreateItemRequest represents inventory item creation request
+type CreateItemRequest struct {
+	Name             string   `json:"name" binding:"required"`
+	Category         *string  `json:"category"`
+	Unit             string   `json:"unit" binding:"required"`
+	ReorderThreshold *float64 `json:"reorder_threshold"`
+	Note             *string  `json:"note"`
+}
+
+// UpdateItemRequest represents inventory item update request
+type UpdateItemRequest struct {
+	Name             *string  `json:"name"`
+	Category         *string  `json:"category"`
+	Unit             *string  `json:"unit"`
+	ReorderThreshold *float64 `json:"reorder_threshold"`
+	Note             *string  `json:"note"`
+	Version          *int     `json:"version"` // Expected version when If-Match is not sent
+}
+
+// MovementResponse represents stock movement response
+type MovementResponse struct {
+	ID         string    `json:"id"`
+	ItemID     string    `json:"item_id"`
+	Type       string    `json:"type"`
+	Quantity   float64   `json:"quantity"`
+	UnitCost   *float64  `json:"unit_cost"`
+	TotalCost  *float64  `json:"total_cost"`
+	ActivityID *string   `json:"activity_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Note       *string   `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CreateMovementRequest represents stock movement creation request.
+// Quantity is positive for purchases and consumptions, signed for adjustments.
+type CreateMovementRequest struct {
+	Type       string     `json:"type" binding:"required"`
+	Quantity   float64    `json:"quantity" binding:"required"`
+	UnitCost   *float64   `json:"unit_cost"`
+	TotalCost  *float64   `json:"total_cost"`  // Defaults to quantity * unit_cost
+	OccurredAt *time.Time `json:"occurred_at"` // Defaults to now
+	Note       *string    `json:"note"`
+}
+
+// CategoryValuation represents the stock value of a category
+type CategoryValuation struct {
+	Category string  `json:"category"`
+	Value    float64 `json:"value"`
+	Items    int     `json:"items"`
+}
+
+// ValuationResponse represents the value of the whole inventory
+type ValuationResponse struct {
+	TotalValue    float64             `json:"total_value"`
+	ItemCount     int                 `json:"item_count"`
+	LowStockCount int                 `json:"low_stock_count"`
+	ByCategory    []CategoryValuation `json:"by_category"`
+}
+
+// ActivityConsumption is the material an activity takes out of the tenant's stock: Amount in Unit
+// of the item named Object
+type ActivityConsumption struct {
+	Tenant     common.Tenant
+	Object     string
+	Amount     float64
+	Unit       string
+	OccurredAt time.Time
+}
+
+// ConsumptionResult describes the stock movement recorded for an activity
+type ConsumptionResult struct {
+	ItemID   string  `json:"item_id"`
+	ItemName string  `json:"item_name"`
+	Quantity float64 `json:"quantity"`
+	Stock    float64 `json:"stock"`
+	LowStock bool    `json:"low_stock"`
+}
+
+// ToItemResponse converts Item model to ItemResponse with its stock summary
+func (i *Item) ToItemResponse(summary StockSummary) ItemResponse {
+	response := ItemResponse{
+		ID:               i.ID,
+		Name:             i.Name,
+		Category:         i.Category,
+		Unit:             i.Unit,
+		ReorderThreshold: i.ReorderThreshold,
+		Note:             i.Note,
+		Stock:            summary.Stock,
+		LowStock:         i.IsLowStock(summary.Stock),
+		Version:          i.Version,
+		CreatedAt:        i.CreatedAt,
+		UpdatedAt:        i.UpdatedAt,
+	}
+	if summary.PurchasedQuantity > 0 {
+		averageCost := summary.PurchaseCost / summary.PurchasedQuantity
+		response.AverageCost = &averageCost
+		if summary.Stock > 0 {
+			response.Value = summary.Stock * averageCost
+		}
+	}
+	return response
+}
+
+// ToMovementResponse converts Movement model to MovementResponse
+func (m *Movement) ToMovementResponse() MovementResponse {
+	return MovementResponse{
+		ID:         m.ID,
+		ItemID:     m.ItemID,
+		Type:       m.Type,
+		Quantity:   m.Quantity,
+		UnitCost:   m.UnitCost,
+		TotalCost:  m.TotalCost,
+		ActivityID: m.ActivityID,
+		OccurredAt: m.OccurredAt,
+		Note:       m.Note,
+		CreatedAt:  m.CreatedAt,
+	}
+}
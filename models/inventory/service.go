@@ -0,0 +1,255 @@
+package inventory
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/units"
+
+	"gorm.io/gorm"
+)
+
+// InventoryService handles all database operations for inventory items and movements
+type InventoryService struct {
+	db *gorm.DB
+}
+
+// NewInventoryService creates a new inventory service instance
+func NewInventoryService() *InventoryService {
+	return &InventoryService{
+		db: common.GetDB(),
+	}
+}
+
+// CreateItemRecord creates a new inventory item
+func CreateItemRecord(item *Item) error {
+	service := NewInventoryService()
+	return service.db.Create(item).Error
+}
+
+// GetItemByID finds inventory item by ID
+func GetItemByID(id string) (*Item, error) {
+	service := NewInventoryService()
+	var item Item
+	err := service.db.Where("id = ?", id).First(&item).Error
+	return &item, err
+}
+
+// GetTenantItem finds an inventory item of the user's own stock or of the active organization
+func GetTenantItem(id string, tenant common.Tenant) (*Item, error) {
+	service := NewInventoryService()
+	var item Item
+	err := tenant.Scope(service.db.Where("id = ?", id)).First(&item).Error
+	return &item, err
+}
+
+// GetTenantItemByName finds the tenant's inventory item with that name, ignoring case
+func GetTenantItemByName(tenant common.Tenant, name string) (*Item, error) {
+	service := NewInventoryService()
+	var item Item
+	err := tenant.Scope(service.db.Where("LOWER(name) = LOWER(?)", strings.TrimSpace(name))).
+		First(&item).Error
+	return &item, err
+}
+
+// GetItemsByTenant lists the tenant's inventory items, optionally filtered by name and category
+func GetItemsByTenant(tenant common.Tenant, search, category string) ([]Item, error) {
+	service := NewInventoryService()
+	var items []Item
+
+	query := tenant.Scope(service.db)
+	if search != "" {
+		query = query.Where("name ILIKE ?", "%"+search+"%")
+	}
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	err := query.Order("name ASC").Find(&items).Error
+	return items, err
+}
+
+// UpdateItem updates inventory item information.
+// The update only applies if the stored version still equals item.Version,
+// otherwise common.ErrVersionConflict is returned. On success the version is incremented.
+func UpdateItem(item *Item) error {
+	service := NewInventoryService()
+	expected := item.Version
+	item.Version = expected + 1
+
+	result := service.db.Model(item).
+		Where("version = ?", expected).
+		Select("*").Omit("id", "created_at").
+		Updates(item)
+	if result.Error != nil {
+		item.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		item.Version = expected
+		return common.ErrVersionConflict
+	}
+	return nil
+}
+
+// DeleteItem soft-deletes inventory item by ID, its movements are kept
+func DeleteItem(id string) error {
+	service := NewInventoryService()
+	return service.db.Where("id = ?", id).Delete(&Item{}).Error
+}
+
+// CreateMovementRecord adds a movement to the stock ledger
+func CreateMovementRecord(movement *Movement) error {
+	service := NewInventoryService()
+	return service.db.Create(movement).Error
+}
+
+// GetMovementsByItem lists the movements of an item, newest first, optionally limited to [from, to)
+func GetMovementsByItem(itemID string, from, to *time.Time) ([]Movement, error) {
+	service := NewInventoryService()
+	var movements []Movement
+	query := service.db.Where("item_id = ?", itemID)
+	if from != nil {
+		query = query.Where("occurred_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("occurred_at < ?", *to)
+	}
+	err := query.Order("occurred_at DESC, created_at DESC").Find(&movements).Error
+	return movements, err
+}
+
+// GetStockSummaries computes stock and purchase totals of the items from their movements
+func GetStockSummaries(itemIDs []string) (map[string]StockSummary, error) {
+	service := NewInventoryService()
+	summaries := make(map[string]StockSummary, len(itemIDs))
+	if len(itemIDs) == 0 {
+		return summaries, nil
+	}
+
+	var rows []StockSummary
+	err := service.db.Model(&Movement{}).
+		Select("item_id, COALESCE(SUM(quantity), 0) AS stock, "+
+			"COALESCE(SUM(CASE WHEN type = ? AND total_cost IS NOT NULL THEN quantity ELSE 0 END), 0) AS purchased_quantity, "+
+			"COALESCE(SUM(CASE WHEN type = ? THEN total_cost ELSE 0 END), 0) AS purchase_cost",
+			MovementPurchase, MovementPurchase).
+		Where("item_id IN ?", itemIDs).
+		Group("item_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		summaries[row.ItemID] = row
+	}
+	return summaries, nil
+}
+
+// GetStockSummary computes stock and purchase totals of one item
+func GetStockSummary(itemID string) (StockSummary, error) {
+	summaries, err := GetStockSummaries([]string{itemID})
+	if err != nil {
+		return StockSummary{}, err
+	}
+	return summaries[itemID], nil
+}
+
+// RecordActivityConsumption takes the material used by an activity out of the stock of the
+// tenant's item named like the activity object, converting the amount into the item unit.
+// It returns nil when no item matches or the units cannot be converted.
+func RecordActivityConsumption(activityID string, consumption *ActivityConsumption) (*ConsumptionResult, error) {
+	item, quantity, err := consumedItem(consumption)
+	if err != nil || item == nil {
+		return nil, err
+	}
+
+	movement := consumption.movement(activityID, item, quantity)
+	if err := CreateMovementRecord(movement); err != nil {
+		return nil, err
+	}
+
+	summary, err := GetStockSummary(item.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ConsumptionResult{
+		ItemID:   item.ID,
+		ItemName: item.Name,
+		Quantity: quantity,
+		Stock:    summary.Stock,
+		LowStock: item.IsLowStock(summary.Stock),
+	}, nil
+}
+
+// SyncActivityConsumption makes the stock movement of an activity match what it consumes after a change,
+// on tx, the transaction saving the activity. The movement is moved to the item named like the object with
+// the amount converted again, created when missing, or removed when consumption is nil or no item matches.
+func SyncActivityConsumption(tx *gorm.DB, activityID string, consumption *ActivityConsumption) error {
+	var item *Item
+	var quantity float64
+	if consumption != nil {
+		var err error
+		if item, quantity, err = consumedItem(consumption); err != nil {
+			return err
+		}
+	}
+	if item == nil {
+		return RemoveActivityConsumption(tx, []string{activityID})
+	}
+
+	var current Movement
+	err := tx.Where("activity_id = ?", activityID).Order("created_at ASC").First(&current).Error
+	if err == gorm.ErrRecordNotFound {
+		return tx.Create(consumption.movement(activityID, item, quantity)).Error
+	}
+	if err != nil {
+		return err
+	}
+	movement := consumption.movement(activityID, item, quantity)
+	movement.ID = current.ID
+	movement.CreatedAt = current.CreatedAt
+	return tx.Save(movement).Error
+}
+
+// RemoveActivityConsumption gives the material of the activities back to stock by removing their
+// movements, on tx, the transaction deleting the activities
+func RemoveActivityConsumption(tx *gorm.DB, activityIDs []string) error {
+	return tx.Where("activity_id IN ?", activityIDs).Delete(&Movement{}).Error
+}
+
+// consumedItem finds the tenant's item named like the consumed object and converts the amount into
+// the item unit. The item is nil when none matches or the units cannot be converted.
+func consumedItem(consumption *ActivityConsumption) (*Item, float64, error) {
+	item, err := GetTenantItemByName(consumption.Tenant, consumption.Object)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+	amount := consumption.Amount
+	if unit := consumption.Unit; unit != "" && !strings.EqualFold(strings.TrimSpace(unit), item.Unit) {
+		converted, err := units.NewResolver(consumption.Tenant.UserID).Convert(amount, unit, item.Unit)
+		if err != nil {
+			if errors.Is(err, units.ErrUnknownUnit) || errors.Is(err, units.ErrIncompatibleUnits) {
+				return nil, 0, nil
+			}
+			return nil, 0, err
+		}
+		amount = converted
+	}
+	return item, amount, nil
+}
+
+// movement is the consumption movement of the activity taking quantity of the item
+func (c *ActivityConsumption) movement(activityID string, item *Item, quantity float64) *Movement {
+	return &Movement{
+		ItemID:     item.ID,
+		UserID:     c.Tenant.UserID,
+		Type:       MovementConsumption,
+		Quantity:   -quantity,
+		ActivityID: &activityID,
+		OccurredAt: c.OccurredAt,
+	}
+}
@@ -0,0 +1,107 @@
+package inventory
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ValidMovementTypes lists the movement types a client can record
+var ValidMovementTypes = []string{MovementPurchase, MovementConsumption, MovementAdjustment}
+
+// ValidateCreateItemRequest validates inventory item creation request
+func ValidateCreateItemRequest(req *CreateItemRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.New("item name is required")
+	}
+	if len(req.Name) > 255 {
+		return errors.New("item name must be less than 255 characters")
+	}
+
+	req.Unit = strings.TrimSpace(req.Unit)
+	if req.Unit == "" {
+		return errors.New("unit is required")
+	}
+	if len(req.Unit) > 50 {
+		return errors.New("unit must be less than 50 characters")
+	}
+
+	return validateItemFields(req.Category, req.ReorderThreshold, req.Note)
+}
+
+// ValidateUpdateItemRequest validates inventory item update request
+func ValidateUpdateItemRequest(req *UpdateItemRequest) error {
+	if req.Name != nil {
+		*req.Name = strings.TrimSpace(*req.Name)
+		if *req.Name == "" {
+			return errors.New("item name cannot be empty")
+		}
+		if len(*req.Name) > 255 {
+			return errors.New("item name must be less than 255 characters")
+		}
+	}
+
+	if req.Unit != nil {
+		*req.Unit = strings.TrimSpace(*req.Unit)
+		if *req.Unit == "" {
+			return errors.New("unit cannot be empty")
+		}
+		if len(*req.Unit) > 50 {
+			return errors.New("unit must be less than 50 characters")
+		}
+	}
+
+	return validateItemFields(req.Category, req.ReorderThreshold, req.Note)
+}
+
+// validateItemFields validates the optional fields shared by create and update
+func validateItemFields(category *string, threshold *float64, note *string) error {
+	if category != nil && len(*category) > 100 {
+		return errors.New("category must be less than 100 characters")
+	}
+	if threshold != nil && *threshold < 0 {
+		return errors.New("reorder_threshold must be non-negative")
+	}
+	if note != nil && len(*note) > 1000 {
+		return errors.New("note must be less than 1000 characters")
+	}
+	return nil
+}
+
+// ValidateCreateMovementRequest validates stock movement creation request
+func ValidateCreateMovementRequest(req *CreateMovementRequest) error {
+	req.Type = strings.ToLower(strings.TrimSpace(req.Type))
+	switch req.Type {
+	case MovementPurchase, MovementConsumption:
+		if req.Quantity <= 0 {
+			return errors.New("quantity must be positive")
+		}
+	case MovementAdjustment:
+		if req.Quantity == 0 {
+			return errors.New("quantity cannot be zero")
+		}
+	default:
+		return errors.New("type must be one of: " + strings.Join(ValidMovementTypes, ", "))
+	}
+
+	if req.UnitCost != nil && *req.UnitCost < 0 {
+		return errors.New("unit_cost must be non-negative")
+	}
+	if req.TotalCost != nil && *req.TotalCost < 0 {
+		return errors.New("total_cost must be non-negative")
+	}
+	if req.Type == MovementPurchase && req.UnitCost == nil && req.TotalCost == nil {
+		return errors.New("unit_cost or total_cost is required for a purchase")
+	}
+
+	if req.OccurredAt != nil && req.OccurredAt.After(time.Now()) {
+		return errors.New("occurred_at cannot be in the future")
+	}
+
+	if req.Note != nil && len(*req.Note) > 1000 {
+		return errors.New("note must be less than 1000 characters")
+	}
+
+	return nil
+}
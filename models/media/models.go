@@ -0,0 +1,74 @@
+package media
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Owner types that can reference uploaded media
+const (
+	OwnerDisease  = "disease"
+	OwnerUser     = "user"
+	OwnerActivity = "activity" // Private, served only through the activity's attachments
+)
+
+// privateOwnerTypes are the owner types whose media is not served by the public media routes
+var privateOwnerTypes = []string{OwnerActivity}
+
+// Media is a single uploaded file, deduplicated by the SHA-256 of its content
+type Media struct {
+	ID          string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Hash        string         `json:"hash" gorm:"type:varchar(64);uniqueIndex;not null"`
+	ContentType string         `json:"content_type" gorm:"type:varchar(100);not null"`
+	Size        int64          `json:"size" gorm:"not null"`
+	Width       int            `json:"width"`
+	Height      int            `json:"height"`
+	StorageKey  string         `json:"-" gorm:"type:varchar(255);not null"`
+	Thumbnails  pq.StringArray `json:"thumbnails" gorm:"type:text[]"` // names of generated thumbnail sizes
+	UploadedBy  *string        `json:"uploaded_by" gorm:"type:uuid"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// MediaReference links a media file to the record using it (disease, user avatar...)
+type MediaReference struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	MediaID   string    `json:"media_id" gorm:"type:uuid;not null;uniqueIndex:idx_media_reference_owner"`
+	OwnerType string    `json:"owner_type" gorm:"type:varchar(50);not null;uniqueIndex:idx_media_reference_owner;index:idx_media_reference_lookup"`
+	OwnerID   string    `json:"owner_id" gorm:"type:uuid;not null;uniqueIndex:idx_media_reference_owner;index:idx_media_reference_lookup"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName keeps "media" as the table name instead of the inflected plural
+func (Media) TableName() string {
+	return "media"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (m *Media) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (r *MediaReference) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// HasThumbnail checks if a thumbnail of the given size was generated
+func (m *Media) HasThumbnail(size string) bool {
+	for _, s := range m.Thumbnails {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ContentURL builds the public URL serving a media file or one of its thumbnails.
+func ContentURL(c *gin.Context, mediaID, size string) string {
+	url := fmt.Sprintf("%s/api/v1/media/%s/content", BaseURL(c), mediaID)
+	if size != "" {
+		url += "?size=" + size
+	}
+	return url
+}
+
+// BaseURL returns the scheme and host files are served from.
+// MEDIA_BASE_URL overrides the host taken from the current request.
+func BaseURL(c *gin.Context) string {
+	base := strings.TrimRight(os.Getenv("MEDIA_BASE_URL"), "/")
+	if base == "" && c != nil {
+		scheme := "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+		if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+			scheme = proto
+		}
+		base = scheme + "://" + c.Request.Host
+	}
+	return base
+}
+
+// ReadUpload reads the multipart file in field, enforcing the upload size limit
+func ReadUpload(c *gin.Context, field string) ([]byte, error) {
+	maxBytes := MaxUploadBytes()
+	// Leave room for multipart headers around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+(1<<20))
+
+	file, err := c.FormFile(field)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, ErrFileTooLarge
+		}
+		return nil, err
+	}
+	if file.Size > maxBytes {
+		return nil, ErrFileTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, ErrFileTooLarge
+	}
+	return data, nil
+}
+
+// RespondUploadError writes the HTTP error matching an upload failure
+func RespondUploadError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("File must be smaller than %d MB", MaxUploadBytes()>>20),
+		})
+	case errors.Is(err, ErrUnsupportedType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": "Only JPEG, PNG, GIF and WebP images are supported",
+		})
+	case errors.Is(err, ErrUnsupportedFile):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": "Only images and PDF files are supported",
+		})
+	case errors.Is(err, ErrImageTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("Image must be at most %d megapixels", MaxImagePixels()/1_000_000),
+		})
+	case errors.Is(err, ErrInvalidImage):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "File is not a valid image",
+		})
+	case errors.Is(err, http.ErrMissingFile):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "No file uploaded",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to store file",
+		})
+	}
+}
+
+// UploadMediaHandler handles uploading an image through the multipart "file" field
+func UploadMediaHandler(c *gin.Context) {
+	data, err := ReadUpload(c, "file")
+	if err != nil {
+		RespondUploadError(c, err)
+		return
+	}
+
+	m, err := StoreImage(c.Request.Context(), data, c.GetString("user_id"))
+	if err != nil {
+		RespondUploadError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "File uploaded successfully",
+		"data":    m.ToMediaResponse(c),
+	})
+}
+
+// GetMediaHandler handles getting media metadata by ID
+func GetMediaHandler(c *gin.Context) {
+	m, ok := loadPublicMedia(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": m.ToMediaResponse(c),
+	})
+}
+
+// GetMediaContentHandler streams the original file or a thumbnail (?size=small|medium|large)
+func GetMediaContentHandler(c *gin.Context) {
+	m, ok := loadPublicMedia(c)
+	if !ok {
+		return
+	}
+	ServeContent(c, m, true)
+}
+
+// ServeContent streams the original file or the thumbnail picked by ?size=.
+// Private content may only be cached by the requesting client.
+func ServeContent(c *gin.Context, m *Media, public bool) {
+	size := c.Query("size")
+	etag := fmt.Sprintf(`"%s-%s"`, m.Hash, size)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	content, contentType, err := OpenContent(c.Request.Context(), m, size)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "File not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to read file",
+		})
+		return
+	}
+	defer content.Close()
+
+	// Content is addressed by hash, so it never changes for a given URL
+	if public {
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		c.Header("Cache-Control", "private, max-age=31536000, immutable")
+	}
+	c.Header("ETag", etag)
+	c.DataFromReader(http.StatusOK, -1, contentType, content, nil)
+}
+
+// loadPublicMedia loads the media from the :id parameter, media private to its owners is reported as missing.
+// It writes the error response and returns false otherwise.
+func loadPublicMedia(c *gin.Context) (*Media, bool) {
+	m, err := GetMediaByID(c.Param("id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Media not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get media",
+		})
+		return nil, false
+	}
+
+	private, err := IsPrivate(m.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get media",
+		})
+		return nil, false
+	}
+	if private {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Media not found",
+		})
+		return nil, false
+	}
+	return m, true
+}
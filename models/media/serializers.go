@@ -0,0 +1,40 @@
+package media
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MediaResponse represents media response with resolved URLs
+type MediaResponse struct {
+	ID          string            `json:"id"`
+	URL         string            `json:"url"`
+	Thumbnails  map[string]string `json:"thumbnails"`
+	ContentType string            `json:"content_type"`
+	Size        int64             `json:"size"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	Hash        string            `json:"hash"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// ToMediaResponse converts Media model to MediaResponse
+func (m *Media) ToMediaResponse(c *gin.Context) MediaResponse {
+	thumbnails := make(map[string]string, len(m.Thumbnails))
+	for _, size := range m.Thumbnails {
+		thumbnails[size] = ContentURL(c, m.ID, size)
+	}
+
+	return MediaResponse{
+		ID:          m.ID,
+		URL:         ContentURL(c, m.ID, ""),
+		Thumbnails:  thumbnails,
+		ContentType: m.ContentType,
+		Size:        m.Size,
+		Width:       m.Width,
+		Height:      m.Height,
+		Hash:        m.Hash,
+		CreatedAt:   m.CreatedAt,
+	}
+}
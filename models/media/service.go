@@ -0,0 +1,444 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"regexp"
+	"time"
+
+	"plantheon-backend/common"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MediaService handles all database operations for media
+type MediaService struct {
+	db *gorm.DB
+}
+
+// NewMediaService creates a new media service instance
+func NewMediaService() *MediaService {
+	return &MediaService{
+		db: common.GetDB(),
+	}
+}
+
+// mediaURLPattern extracts the media ID from URLs built by ContentURL
+var mediaURLPattern = regexp.MustCompile(`/media/([0-9a-fA-F-]{36})/content`)
+
+func originalKey(hash, ext string) string {
+	return fmt.Sprintf("originals/%s/%s%s", hash[:2], hash, ext)
+}
+
+func thumbnailKey(hash, size string) string {
+	return fmt.Sprintf("thumbnails/%s/%s_%s.jpg", hash[:2], hash, size)
+}
+
+// StoreImage validates, deduplicates and stores an uploaded image with its thumbnails.
+// Uploading the same content twice returns the existing media record.
+func StoreImage(ctx context.Context, data []byte, uploadedBy string) (*Media, error) {
+	contentType, err := ValidateImageUpload(data)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	if existing, err := reuseMedia(hash); err == nil {
+		return existing, nil
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	// Check the declared dimensions before decoding allocates the pixels
+	if err := ValidateImageDimensions(data); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	storage := GetStorage()
+	m := &Media{
+		Hash:        hash,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		StorageKey:  originalKey(hash, AllowedImageTypes[contentType]),
+	}
+	if uploadedBy != "" {
+		m.UploadedBy = &uploadedBy
+	}
+
+	if err := storage.Put(ctx, m.StorageKey, bytes.NewReader(data), m.Size, contentType); err != nil {
+		return nil, err
+	}
+
+	for _, size := range ThumbnailSizes {
+		thumb, err := generateThumbnail(img, size.MaxSide)
+		if err != nil {
+			return nil, err
+		}
+		if err := storage.Put(ctx, thumbnailKey(hash, size.Name), bytes.NewReader(thumb), int64(len(thumb)), "image/jpeg"); err != nil {
+			return nil, err
+		}
+		m.Thumbnails = append(m.Thumbnails, size.Name)
+	}
+
+	return createMedia(m)
+}
+
+// StoreFile stores an uploaded attachment: images get thumbnails through StoreImage,
+// documents such as PDFs are stored as is. Uploading the same content twice returns the existing media record.
+func StoreFile(ctx context.Context, data []byte, uploadedBy string) (*Media, error) {
+	contentType, err := ValidateFileUpload(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := AllowedImageTypes[contentType]; ok {
+		return StoreImage(ctx, data, uploadedBy)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	if existing, err := reuseMedia(hash); err == nil {
+		return existing, nil
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	m := &Media{
+		Hash:        hash,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		StorageKey:  originalKey(hash, AllowedDocumentTypes[contentType]),
+	}
+	if uploadedBy != "" {
+		m.UploadedBy = &uploadedBy
+	}
+
+	if err := GetStorage().Put(ctx, m.StorageKey, bytes.NewReader(data), m.Size, contentType); err != nil {
+		return nil, err
+	}
+	return createMedia(m)
+}
+
+// createMedia saves the record of a stored file
+func createMedia(m *Media) (*Media, error) {
+	service := NewMediaService()
+	result := service.db.Clauses(clause.OnConflict{DoNothing: true}).Create(m)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	// Another request stored the same content concurrently, reuse its record
+	if result.RowsAffected == 0 {
+		return reuseMedia(m.Hash)
+	}
+	return m, nil
+}
+
+// reuseMedia returns the media already stored with the content hash for a new upload.
+// The row is locked and touched in one transaction, so deleteOrphans either removed it
+// before (gorm.ErrRecordNotFound) or keeps it for reuseWindow until the uploader references it.
+func reuseMedia(hash string) (*Media, error) {
+	service := NewMediaService()
+	var m Media
+	err := service.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("hash = ?", hash).First(&m).Error; err != nil {
+			return err
+		}
+		return tx.Model(&m).Update("updated_at", time.Now()).Error
+	})
+	return &m, err
+}
+
+// GetMediaByID finds media by ID
+func GetMediaByID(id string) (*Media, error) {
+	service := NewMediaService()
+	var m Media
+	err := service.db.Where("id = ?", id).First(&m).Error
+	return &m, err
+}
+
+// GetMediaByIDs maps the IDs of the given media to their records
+func GetMediaByIDs(ids []string) (map[string]*Media, error) {
+	found := make(map[string]*Media, len(ids))
+	if len(ids) == 0 {
+		return found, nil
+	}
+	service := NewMediaService()
+	var list []Media
+	if err := service.db.Where("id IN ?", ids).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for i := range list {
+		found[list[i].ID] = &list[i]
+	}
+	return found, nil
+}
+
+// IsPrivate checks if the media is only referenced by private owners, such as activity attachments
+func IsPrivate(mediaID string) (bool, error) {
+	service := NewMediaService()
+	var count int64
+	err := service.db.Model(&MediaReference{}).
+		Where("media_id = ? AND owner_type IN ?", mediaID, privateOwnerTypes).
+		Where("NOT EXISTS (SELECT 1 FROM media_references r WHERE r.media_id = ? AND r.owner_type NOT IN ?)", mediaID, privateOwnerTypes).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetMediaByHash finds media by content hash
+func GetMediaByHash(hash string) (*Media, error) {
+	service := NewMediaService()
+	var m Media
+	err := service.db.Where("hash = ?", hash).First(&m).Error
+	return &m, err
+}
+
+// OpenContent opens the original file or one of its thumbnails
+func OpenContent(ctx context.Context, m *Media, size string) (io.ReadCloser, string, error) {
+	if size == "" || size == "original" {
+		r, err := GetStorage().Get(ctx, m.StorageKey)
+		return r, m.ContentType, err
+	}
+	if !m.HasThumbnail(size) {
+		return nil, "", ErrObjectNotFound
+	}
+	r, err := GetStorage().Get(ctx, thumbnailKey(m.Hash, size))
+	return r, "image/jpeg", err
+}
+
+// MediaIDsFromURLs returns the IDs of media served by this API among the given URLs.
+// External URLs are ignored.
+func MediaIDsFromURLs(urls ...string) []string {
+	var ids []string
+	for _, u := range urls {
+		if match := mediaURLPattern.FindStringSubmatch(u); match != nil {
+			ids = append(ids, match[1])
+		}
+	}
+	return ids
+}
+
+// SyncReferences makes the owner reference exactly the given media IDs,
+// releasing (and cleaning up) media it no longer uses
+func SyncReferences(ownerType, ownerID string, mediaIDs []string) error {
+	service := NewMediaService()
+
+	var current []MediaReference
+	if err := service.db.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Find(&current).Error; err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool, len(mediaIDs))
+	for _, id := range mediaIDs {
+		wanted[id] = true
+	}
+
+	var released []string
+	for _, ref := range current {
+		if wanted[ref.MediaID] {
+			delete(wanted, ref.MediaID)
+			continue
+		}
+		released = append(released, ref.MediaID)
+	}
+
+	if len(wanted) > 0 {
+		err := service.db.Transaction(func(tx *gorm.DB) error {
+			for id := range wanted {
+				// Only reference media that actually exists
+				if _, err := addReference(tx, ownerType, ownerID, id); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(released) > 0 {
+		if err := service.db.Where("owner_type = ? AND owner_id = ? AND media_id IN ?", ownerType, ownerID, released).
+			Delete(&MediaReference{}).Error; err != nil {
+			return err
+		}
+		return deleteIfUnreferenced(released)
+	}
+	return nil
+}
+
+// AddReference makes the owner reference the media, adding an existing reference again is a no-op.
+// It returns gorm.ErrRecordNotFound when the media does not exist (anymore).
+func AddReference(ownerType, ownerID, mediaID string) error {
+	service := NewMediaService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		found, err := addReference(tx, ownerType, ownerID, mediaID)
+		if err == nil && !found {
+			return gorm.ErrRecordNotFound
+		}
+		return err
+	})
+}
+
+// addReference inserts the reference in tx if the media exists. The media row is share locked
+// until tx ends, so deleteOrphans cannot remove it between the check and the insert.
+func addReference(tx *gorm.DB, ownerType, ownerID, mediaID string) (bool, error) {
+	var ids []string
+	if err := tx.Model(&Media{}).Clauses(clause.Locking{Strength: "SHARE"}).
+		Where("id = ?", mediaID).Pluck("id", &ids).Error; err != nil {
+		return false, err
+	}
+	if len(ids) == 0 {
+		return false, nil
+	}
+	ref := &MediaReference{MediaID: mediaID, OwnerType: ownerType, OwnerID: ownerID}
+	return true, tx.Clauses(clause.OnConflict{DoNothing: true}).Create(ref).Error
+}
+
+// ReleaseReference removes one reference of the owner and deletes the media if it is left orphaned
+func ReleaseReference(ownerType, ownerID, mediaID string) error {
+	service := NewMediaService()
+	if err := service.db.Where("owner_type = ? AND owner_id = ? AND media_id = ?", ownerType, ownerID, mediaID).
+		Delete(&MediaReference{}).Error; err != nil {
+		return err
+	}
+	return deleteIfUnreferenced([]string{mediaID})
+}
+
+// ReleaseOwner removes all references held by the owner and deletes media left orphaned
+func ReleaseOwner(ownerType, ownerID string) error {
+	service := NewMediaService()
+
+	var mediaIDs []string
+	if err := service.db.Model(&MediaReference{}).
+		Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).
+		Pluck("media_id", &mediaIDs).Error; err != nil {
+		return err
+	}
+
+	if err := service.db.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).
+		Delete(&MediaReference{}).Error; err != nil {
+		return err
+	}
+
+	return deleteIfUnreferenced(mediaIDs)
+}
+
+// CleanupOrphans deletes media that has no references and was last stored or reused
+// by an upload before the grace period, e.g. uploads never attached to a disease or profile
+func CleanupOrphans(grace time.Duration) (int, error) {
+	service := NewMediaService()
+	cutoff := time.Now().Add(-grace)
+
+	var mediaIDs []string
+	err := service.db.Model(&Media{}).
+		Where("updated_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM media_references r WHERE r.media_id = media.id)").
+		Pluck("id", &mediaIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return deleteOrphans(mediaIDs, cutoff)
+}
+
+// StartOrphanCleanup runs CleanupOrphans periodically in the background
+func StartOrphanCleanup(interval, grace time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if n, err := CleanupOrphans(grace); err != nil {
+				log.Println("Media orphan cleanup failed:", err)
+			} else if n > 0 {
+				log.Printf("Media orphan cleanup removed %d files", n)
+			}
+		}
+	}()
+}
+
+// reuseWindow is how long media stored or reused by an upload is kept without references,
+// giving the uploader time to reference it. CleanupOrphans removes it afterwards.
+const reuseWindow = time.Hour
+
+// deleteIfUnreferenced removes the media rows and stored files that no longer have references.
+// Media stored or reused by an upload within reuseWindow is left to CleanupOrphans.
+func deleteIfUnreferenced(mediaIDs []string) error {
+	_, err := deleteOrphans(mediaIDs, time.Now().Add(-reuseWindow))
+	return err
+}
+
+// deleteOrphans removes the media without references that was last stored or reused before
+// the given time, returning how many were removed. Each row is locked and its references are
+// counted again before it is deleted, so a concurrent reuse or reference keeps the media.
+func deleteOrphans(mediaIDs []string, before time.Time) (int, error) {
+	service := NewMediaService()
+	ctx := context.Background()
+	storage := GetStorage()
+
+	removed := 0
+	for _, id := range mediaIDs {
+		deleted := false
+		err := service.db.Transaction(func(tx *gorm.DB) error {
+			var m Media
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ? AND updated_at < ?", id, before).First(&m).Error
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			var references int64
+			if err := tx.Model(&MediaReference{}).Where("media_id = ?", id).Count(&references).Error; err != nil {
+				return err
+			}
+			if references > 0 {
+				return nil
+			}
+
+			if err := tx.Delete(&Media{}, "id = ?", id).Error; err != nil {
+				return err
+			}
+			// The files go while the row is still locked, an upload of the same content
+			// waiting in reuseMedia then stores them again instead of losing them afterwards
+			deleteFiles(ctx, storage, &m)
+			deleted = true
+			return nil
+		})
+		if err != nil {
+			return removed, err
+		}
+		if deleted {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// deleteFiles removes the original file and the thumbnails of the media from storage
+func deleteFiles(ctx context.Context, storage Storage, m *Media) {
+	if err := storage.Delete(ctx, m.StorageKey); err != nil {
+		log.Printf("Failed to delete media file %s: %v", m.StorageKey, err)
+	}
+	for _, size := range m.Thumbnails {
+		if err := storage.Delete(ctx, thumbnailKey(m.Hash, size)); err != nil {
+			log.Printf("Failed to delete thumbnail %s of media %s: %v", size, m.ID, err)
+		}
+	}
+}
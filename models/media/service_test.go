@@ -0,0 +1,146 @@
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"plantheon-backend/common/dbtest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/gorm"
+)
+
+const storedID = "5d0f7a52-8c1e-4b8e-9a3f-2f6c1d7e9b01"
+
+// recordingStorage remembers the keys written and deleted instead of storing anything
+type recordingStorage struct {
+	put     []string
+	deleted []string
+}
+
+func (s *recordingStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	s.put = append(s.put, key)
+	return nil
+}
+
+func (s *recordingStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return nil, ErrObjectNotFound
+}
+
+func (s *recordingStorage) Delete(ctx context.Context, key string) error {
+	s.deleted = append(s.deleted, key)
+	return nil
+}
+
+// useStorage swaps the storage backend for the duration of the test
+func useStorage(t *testing.T) *recordingStorage {
+	previous := store
+	recording := &recordingStorage{}
+	store = recording
+	t.Cleanup(func() { store = previous })
+	return recording
+}
+
+func TestStoreFileReusesStoredContent(t *testing.T) {
+	storage := useStorage(t)
+	mock := dbtest.Mock(t)
+
+	data := []byte("%PDF-1.4\n% Nhật ký bón phân\n")
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	// The stored row is claimed under a lock, so a concurrent cleanup waits and then keeps it
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "media" WHERE hash = \$1 .* FOR UPDATE`).
+		WithArgs(hash).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hash", "content_type", "storage_key"}).
+			AddRow(storedID, hash, "application/pdf", originalKey(hash, ".pdf")))
+	mock.ExpectExec(`UPDATE "media" SET "updated_at"=\$1 WHERE "id" = \$2`).
+		WithArgs(sqlmock.AnyArg(), storedID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	m, err := StoreFile(context.Background(), data, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ID != storedID {
+		t.Fatalf("got media %s, want the stored %s", m.ID, storedID)
+	}
+	if len(storage.put) > 0 {
+		t.Fatalf("stored %v again", storage.put)
+	}
+}
+
+func TestReleaseReferenceDeletesOnlyOrphans(t *testing.T) {
+	const hash = "ab12cd34"
+
+	tests := []struct {
+		name       string
+		reused     bool
+		references int
+		deleted    []string
+	}{
+		{name: "referenced by another owner", references: 1},
+		{name: "reused by an upload", reused: true},
+		{name: "orphan", deleted: []string{originalKey(hash, ".jpg"), thumbnailKey(hash, "small")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := useStorage(t)
+			mock := dbtest.Mock(t)
+
+			mock.ExpectExec(`DELETE FROM "media_references" WHERE owner_type = \$1 AND owner_id = \$2 AND media_id = \$3`).
+				WithArgs(OwnerUser, "owner", storedID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectBegin()
+			rows := sqlmock.NewRows([]string{"id", "hash", "storage_key", "thumbnails"})
+			if !tt.reused {
+				rows.AddRow(storedID, hash, originalKey(hash, ".jpg"), "{small}")
+			}
+			// Media touched by an upload within the reuse window is not selected
+			mock.ExpectQuery(`SELECT \* FROM "media" WHERE id = \$1 AND updated_at < \$2 .* FOR UPDATE`).
+				WithArgs(storedID, sqlmock.AnyArg()).
+				WillReturnRows(rows)
+			if !tt.reused {
+				mock.ExpectQuery(`SELECT count\(\*\) FROM "media_references" WHERE media_id = \$1`).
+					WithArgs(storedID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.references))
+			}
+			if len(tt.deleted) > 0 {
+				mock.ExpectExec(`DELETE FROM "media" WHERE id = \$1`).
+					WithArgs(storedID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectCommit()
+
+			if err := ReleaseReference(OwnerUser, "owner", storedID); err != nil {
+				t.Fatal(err)
+			}
+			if len(storage.deleted) != len(tt.deleted) {
+				t.Fatalf("got deleted files %v, want %v", storage.deleted, tt.deleted)
+			}
+			for i, key := range tt.deleted {
+				if storage.deleted[i] != key {
+					t.Fatalf("got deleted files %v, want %v", storage.deleted, tt.deleted)
+				}
+			}
+		})
+	}
+}
+
+func TestAddReferenceToDeletedMedia(t *testing.T) {
+	mock := dbtest.Mock(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "media" WHERE id = \$1 FOR SHARE`).
+		WithArgs(storedID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	if err := AddReference(OwnerActivity, "activity-1", storedID); err != gorm.ErrRecordNotFound {
+		t.Fatalf("got %v, want gorm.ErrRecordNotFound", err)
+	}
+}
@@ -0,0 +1,60 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	_ "image/gif" // register GIF decoder
+	"image/jpeg"
+	_ "image/png" // register PNG decoder
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register WebP decoder
+)
+
+// ThumbnailSize describes a generated thumbnail that fits within MaxSide x MaxSide
+type ThumbnailSize struct {
+	Name    string
+	MaxSide int
+}
+
+// ThumbnailSizes are generated for every uploaded image
+var ThumbnailSizes = []ThumbnailSize{
+	{Name: "small", MaxSide: 150},
+	{Name: "medium", MaxSide: 480},
+	{Name: "large", MaxSide: 1024},
+}
+
+// generateThumbnail scales img to fit within maxSide and encodes it as JPEG.
+// Images already smaller than maxSide are re-encoded without upscaling.
+func generateThumbnail(img image.Image, maxSide int) ([]byte, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			height = height * maxSide / width
+			width = maxSide
+		} else {
+			width = width * maxSide / height
+			height = maxSide
+		}
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	// JPEG has no alpha channel, so flatten transparent images onto white
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
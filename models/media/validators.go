@@ -0,0 +1,106 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+var (
+	ErrFileTooLarge       = errors.New("file is too large")
+	ErrUnsupportedType    = errors.New("unsupported file type")
+	ErrInvalidImage       = errors.New("file is not a valid image")
+	ErrUnsupportedFile    = errors.New("unsupported attachment type")
+	ErrImageTooLarge      = errors.New("image dimensions are too large")
+	defaultMaxUploadBytes = int64(10 << 20)
+	defaultMaxImagePixels = 40_000_000
+)
+
+// AllowedImageTypes are the sniffed MIME types accepted for image uploads
+var AllowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// AllowedDocumentTypes are the sniffed MIME types accepted for attachments besides images
+var AllowedDocumentTypes = map[string]string{
+	"application/pdf": ".pdf",
+}
+
+// MaxUploadBytes returns the upload size limit, configurable with MEDIA_MAX_UPLOAD_MB
+func MaxUploadBytes() int64 {
+	if mb, err := strconv.Atoi(os.Getenv("MEDIA_MAX_UPLOAD_MB")); err == nil && mb > 0 {
+		return int64(mb) << 20
+	}
+	return defaultMaxUploadBytes
+}
+
+// MaxImagePixels returns the largest width×height decoded, configurable with MEDIA_MAX_IMAGE_MEGAPIXELS.
+// A small compressed file can declare huge dimensions and take gigabytes once decoded.
+func MaxImagePixels() int {
+	if mp, err := strconv.Atoi(os.Getenv("MEDIA_MAX_IMAGE_MEGAPIXELS")); err == nil && mp > 0 {
+		return mp * 1_000_000
+	}
+	return defaultMaxImagePixels
+}
+
+// ValidateImageDimensions reads the image header and rejects images too large to decode
+func ValidateImageDimensions(data []byte) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ErrInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return ErrInvalidImage
+	}
+	if int64(config.Width)*int64(config.Height) > int64(MaxImagePixels()) {
+		return ErrImageTooLarge
+	}
+	return nil
+}
+
+// SniffContentType detects the MIME type from the file content, ignoring
+// whatever Content-Type or extension the client claimed
+func SniffContentType(data []byte) string {
+	return http.DetectContentType(data)
+}
+
+// ValidateImageUpload checks size limits and the sniffed type of an image upload
+func ValidateImageUpload(data []byte) (string, error) {
+	if int64(len(data)) > MaxUploadBytes() {
+		return "", ErrFileTooLarge
+	}
+	if len(data) == 0 {
+		return "", ErrInvalidImage
+	}
+
+	contentType := SniffContentType(data)
+	if _, ok := AllowedImageTypes[contentType]; !ok {
+		return "", ErrUnsupportedType
+	}
+	return contentType, nil
+}
+
+// ValidateFileUpload checks size limits and the sniffed type of an attachment, an image or a document
+func ValidateFileUpload(data []byte) (string, error) {
+	if int64(len(data)) > MaxUploadBytes() {
+		return "", ErrFileTooLarge
+	}
+	if len(data) == 0 {
+		return "", ErrUnsupportedFile
+	}
+
+	contentType := SniffContentType(data)
+	if _, ok := AllowedImageTypes[contentType]; ok {
+		return contentType, nil
+	}
+	if _, ok := AllowedDocumentTypes[contentType]; ok {
+		return contentType, nil
+	}
+	return "", ErrUnsupportedFile
+}
@@ -0,0 +1,113 @@
+package organizations
+
+import (
+	"net/http"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/users"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TenantMiddleware switches the request to the organization named by the X-Organization-ID
+// header when the current user is a member of it, see common.CurrentTenant. Viewers can only read.
+// Users whose role grants the readAny permission read any organization as viewers, empty for none.
+// It runs after the authentication middleware, requests without the header work on the user's own data.
+func TenantMiddleware(readAny string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		organizationID := c.GetHeader(common.OrganizationHeader)
+		if organizationID == "" {
+			c.Next()
+			return
+		}
+
+		userID := c.GetString("user_id")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Authentication is required to work in an organization",
+			})
+			c.Abort()
+			return
+		}
+		if _, err := uuid.Parse(organizationID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid " + common.OrganizationHeader + " header",
+			})
+			c.Abort()
+			return
+		}
+
+		membership, err := GetMembership(organizationID, userID)
+		if err == gorm.ErrRecordNotFound && readAny != "" && isRead(c) {
+			membership, err = readerMembership(c, organizationID, readAny)
+		}
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "You are not a member of this organization",
+				})
+				c.Abort()
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check organization membership",
+			})
+			c.Abort()
+			return
+		}
+
+		if membership.Role == common.OrgRoleViewer && !isRead(c) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Viewers cannot make changes in this organization",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("organization_id", membership.OrganizationID)
+		c.Set("organization_role", membership.Role)
+		c.Next()
+	}
+}
+
+// isRead checks if the request only reads data
+func isRead(c *gin.Context) bool {
+	return c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+}
+
+// readerMembership lets a user whose role grants the permission read an organization that
+// exists as a viewer, gorm.ErrRecordNotFound is returned otherwise
+func readerMembership(c *gin.Context, organizationID, permission string) (*Membership, error) {
+	allowed, err := users.HasPermission(c, permission)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if _, err := GetOrganizationByID(organizationID); err != nil {
+		return nil, err
+	}
+	return &Membership{
+		OrganizationID: organizationID,
+		UserID:         c.GetString("user_id"),
+		Role:           common.OrgRoleViewer,
+	}, nil
+}
+
+// RequireOrgRole requires at least the given role in the organization of the request,
+// users always pass on their own data. It runs after TenantMiddleware.
+func RequireOrgRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !common.CurrentTenant(c).Allows(role) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "This action requires the " + role + " role in the organization",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,86 @@
+package organizations
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Invitation statuses
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationRevoked  = "revoked"
+)
+
+// Organization is a farm shared by its members. Plots, inventory items and activities
+// created in it belong to the organization instead of the user who created them.
+type Organization struct {
+	ID        string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name      string         `json:"name" gorm:"type:varchar(255);not null"`
+	OwnerID   string         `json:"owner_id" gorm:"type:uuid;not null;index"`
+	Version   int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (o *Organization) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == "" {
+		o.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Membership gives a user a role in an organization, see common.OrgRoleOwner
+type Membership struct {
+	ID             string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	OrganizationID string    `json:"organization_id" gorm:"type:uuid;not null;uniqueIndex:idx_membership_user"`
+	UserID         string    `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_membership_user;index"`
+	Role           string    `json:"role" gorm:"type:varchar(20);not null"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName names the table after the organizations it belongs to
+func (Membership) TableName() string {
+	return "organization_members"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (m *Membership) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Invitation asks a user, known by account or only by email, to join an organization.
+// Invitations by email are matched to the account registered with it when answered.
+type Invitation struct {
+	ID             string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	OrganizationID string     `json:"organization_id" gorm:"type:uuid;not null;index"`
+	Email          *string    `json:"email" gorm:"type:varchar(255);index"`
+	UserID         *string    `json:"user_id" gorm:"type:uuid;index"`
+	Role           string     `json:"role" gorm:"type:varchar(20);not null"`
+	InvitedBy      string     `json:"invited_by" gorm:"type:uuid;not null"`
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	CreatedAt      time.Time  `json:"created_at"`
+	RespondedAt    *time.Time `json:"responded_at"`
+}
+
+// TableName names the table after the organizations it belongs to
+func (Invitation) TableName() string {
+	return "organization_invitations"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (i *Invitation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
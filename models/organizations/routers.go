@@ -0,0 +1,635 @@
+package organizations
+
+import (
+	"net/http"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/users"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateOrganizationHandler creates an organization owned by the current user
+func CreateOrganizationHandler(c *gin.Context) {
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateOrganizationRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	organization := &Organization{
+		Name:    req.Name,
+		OwnerID: c.GetString("user_id"),
+	}
+	if err := CreateOrganizationRecord(organization); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create organization",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Organization created successfully",
+		"data":    organization.ToOrganizationResponse(common.OrgRoleOwner),
+	})
+}
+
+// GetOrganizationsHandler lists the organizations the current user is a member of
+func GetOrganizationsHandler(c *gin.Context) {
+	organizations, err := GetUserOrganizations(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get organizations",
+		})
+		return
+	}
+
+	response := make([]OrganizationResponse, len(organizations))
+	for i := range organizations {
+		response[i] = organizations[i].ToOrganizationResponse(organizations[i].Role)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// GetOrganizationHandler returns one of the current user's organizations
+func GetOrganizationHandler(c *gin.Context) {
+	organization, membership, ok := loadMembership(c)
+	if !ok {
+		return
+	}
+
+	c.Header("ETag", common.ETag(organization.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"data": organization.ToOrganizationResponse(membership.Role),
+	})
+}
+
+// UpdateOrganizationHandler renames an organization, allowed to its owner
+func UpdateOrganizationHandler(c *gin.Context) {
+	organization, membership, ok := loadMembership(c)
+	if !ok || !requireRole(c, membership, common.OrgRoleOwner) {
+		return
+	}
+
+	var req UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateUpdateOrganizationRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Reject the update if the client edited an outdated version
+	precondition, err := common.ParsePrecondition(c, req.Version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(organization.Version) {
+		respondOrganizationConflict(c, precondition, organization, membership.Role)
+		return
+	}
+
+	if req.Name != nil {
+		organization.Name = *req.Name
+	}
+
+	if err := UpdateOrganization(organization); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetOrganizationByID(organization.ID); err == nil {
+				respondOrganizationConflict(c, precondition, current, membership.Role)
+				return
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update organization",
+		})
+		return
+	}
+
+	c.Header("ETag", common.ETag(organization.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Organization updated successfully",
+		"data":    organization.ToOrganizationResponse(membership.Role),
+	})
+}
+
+// DeleteOrganizationHandler deletes an organization, allowed to its owner once its
+// plots, inventory items and activities are deleted or moved
+func DeleteOrganizationHandler(c *gin.Context) {
+	organization, membership, ok := loadMembership(c)
+	if !ok || !requireRole(c, membership, common.OrgRoleOwner) {
+		return
+	}
+
+	if err := DeleteOrganization(organization.ID); err != nil {
+		if err == ErrOrganizationInUse {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete organization",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Organization deleted successfully",
+	})
+}
+
+// GetMembersHandler lists the members of an organization
+func GetMembersHandler(c *gin.Context) {
+	organization, _, ok := loadMembership(c)
+	if !ok {
+		return
+	}
+
+	members, err := GetMembers(organization.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get members",
+		})
+		return
+	}
+
+	response := make([]MemberResponse, len(members))
+	for i := range members {
+		response[i] = members[i].ToMemberResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// UpdateMemberHandler changes the role of a member. The owner manages every other member,
+// managers only workers and viewers.
+// PUT /api/v1/organizations/:id/members/:user_id
+func UpdateMemberHandler(c *gin.Context) {
+	organization, membership, ok := loadMembership(c)
+	if !ok || !requireRole(c, membership, common.OrgRoleManager) {
+		return
+	}
+	member, ok := loadMember(c, organization.ID)
+	if !ok {
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateUpdateMemberRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !canManageRole(membership.Role, member.Role) || !canManageRole(membership.Role, req.Role) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You cannot give or change this role",
+		})
+		return
+	}
+
+	if err := UpdateMembershipRole(member, req.Role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update member",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member updated successfully",
+		"data":    member,
+	})
+}
+
+// RemoveMemberHandler removes a member from an organization. Members may leave, except the owner,
+// the owner removes every other member and managers remove workers and viewers.
+// DELETE /api/v1/organizations/:id/members/:user_id
+func RemoveMemberHandler(c *gin.Context) {
+	organization, membership, ok := loadMembership(c)
+	if !ok {
+		return
+	}
+	member, ok := loadMember(c, organization.ID)
+	if !ok {
+		return
+	}
+
+	if member.UserID == membership.UserID {
+		if member.Role == common.OrgRoleOwner {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "The owner cannot leave the organization",
+			})
+			return
+		}
+	} else if !canManageRole(membership.Role, member.Role) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You cannot remove this member",
+		})
+		return
+	}
+
+	if err := DeleteMembership(member); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to remove member",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member removed successfully",
+	})
+}
+
+// CreateInvitationHandler invites a user to an organization by email or username.
+// Managers invite workers and viewers, the owner any role but owner.
+// POST /api/v1/organizations/:id/invitations
+func CreateInvitationHandler(c *gin.Context) {
+	organization, membership, ok := loadMembership(c)
+	if !ok || !requireRole(c, membership, common.OrgRoleManager) {
+		return
+	}
+
+	var req CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateInvitationRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !canManageRole(membership.Role, req.Role) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You cannot invite members with this role",
+		})
+		return
+	}
+
+	invitation := &Invitation{
+		OrganizationID: organization.ID,
+		Email:          req.Email,
+		Role:           req.Role,
+		InvitedBy:      membership.UserID,
+		Status:         InvitationPending,
+	}
+
+	// Link the invitation to the account when the user is already registered
+	var invitee *users.User
+	var err error
+	if req.Username != nil {
+		invitee, err = users.GetUserByUsername(*req.Username)
+	} else {
+		invitee, err = users.GetUserByEmail(*req.Email)
+	}
+	if err != nil && err != gorm.ErrRecordNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to find user",
+		})
+		return
+	}
+	if err == gorm.ErrRecordNotFound && req.Username != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+	if err == nil {
+		invitation.UserID = &invitee.ID
+		if _, err := GetMembership(organization.ID, invitee.ID); err == nil {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "User is already a member of this organization",
+			})
+			return
+		} else if err != gorm.ErrRecordNotFound {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check organization membership",
+			})
+			return
+		}
+	}
+
+	pending, err := HasPendingInvitation(organization.ID, invitation.UserID, invitation.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check invitations",
+		})
+		return
+	}
+	if pending {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "User is already invited to this organization",
+		})
+		return
+	}
+
+	if err := CreateInvitationRecord(invitation); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create invitation",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Invitation created successfully",
+		"data":    invitation.ToInvitationResponse(organization.Name),
+	})
+}
+
+// GetInvitationsHandler lists the invitations of an organization, allowed to managers
+// GET /api/v1/organizations/:id/invitations?status=pending
+func GetInvitationsHandler(c *gin.Context) {
+	organization, membership, ok := loadMembership(c)
+	if !ok || !requireRole(c, membership, common.OrgRoleManager) {
+		return
+	}
+
+	invitations, err := GetInvitations(organization.ID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get invitations",
+		})
+		return
+	}
+
+	response := make([]InvitationResponse, len(invitations))
+	for i := range invitations {
+		response[i] = invitations[i].ToInvitationResponse(organization.Name)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// RevokeInvitationHandler cancels a pending invitation of an organization,
+// allowed to the members who may invite with its role
+// DELETE /api/v1/organizations/:id/invitations/:invitation_id
+func RevokeInvitationHandler(c *gin.Context) {
+	organization, membership, ok := loadMembership(c)
+	if !ok || !requireRole(c, membership, common.OrgRoleManager) {
+		return
+	}
+
+	invitation, err := GetInvitation(organization.ID, c.Param("invitation_id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Invitation not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get invitation",
+		})
+		return
+	}
+	if !canManageRole(membership.Role, invitation.Role) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You cannot revoke this invitation",
+		})
+		return
+	}
+
+	if err := RevokeInvitation(invitation); err != nil {
+		if err == ErrInvitationAnswered {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke invitation",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invitation revoked successfully",
+		"data":    invitation.ToInvitationResponse(organization.Name),
+	})
+}
+
+// GetMyInvitationsHandler lists the pending invitations sent to the current user's account or email
+// GET /api/v1/organizations/invitations
+func GetMyInvitationsHandler(c *gin.Context) {
+	user, ok := users.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	invitations, err := GetUserInvitations(user.ID, user.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get invitations",
+		})
+		return
+	}
+	ids := make([]string, len(invitations))
+	for i := range invitations {
+		ids[i] = invitations[i].OrganizationID
+	}
+	names, err := GetOrganizationNames(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get invitations",
+		})
+		return
+	}
+
+	response := make([]InvitationResponse, len(invitations))
+	for i := range invitations {
+		response[i] = invitations[i].ToInvitationResponse(names[invitations[i].OrganizationID])
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// AcceptInvitationHandler joins the organization of an invitation with its role
+// POST /api/v1/organizations/invitations/:invitation_id/accept
+func AcceptInvitationHandler(c *gin.Context) {
+	respondInvitation(c, true)
+}
+
+// DeclineInvitationHandler declines an invitation
+// POST /api/v1/organizations/invitations/:invitation_id/decline
+func DeclineInvitationHandler(c *gin.Context) {
+	respondInvitation(c, false)
+}
+
+// respondInvitation answers an invitation sent to the current user's account or email
+func respondInvitation(c *gin.Context, accept bool) {
+	user, ok := users.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	invitation, err := GetUserInvitation(c.Param("invitation_id"), user.ID, user.Email)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Invitation not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get invitation",
+		})
+		return
+	}
+	organization, err := GetOrganizationByID(invitation.OrganizationID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Invitation not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get organization",
+		})
+		return
+	}
+
+	if err := RespondInvitation(invitation, user.ID, accept); err != nil {
+		if err == ErrInvitationAnswered {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to answer invitation",
+		})
+		return
+	}
+
+	message := "Invitation declined"
+	if accept {
+		message = "Invitation accepted"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    invitation.ToInvitationResponse(organization.Name),
+	})
+}
+
+// canManageRole checks if a member with role actor may give, change or remove the role target:
+// the owner manages every role but owner, managers manage workers and viewers
+func canManageRole(actor, target string) bool {
+	switch actor {
+	case common.OrgRoleOwner:
+		return target != common.OrgRoleOwner
+	case common.OrgRoleManager:
+		return target == common.OrgRoleWorker || target == common.OrgRoleViewer
+	}
+	return false
+}
+
+// requireRole writes the error response and returns false when the membership role is below role
+func requireRole(c *gin.Context, membership *Membership, role string) bool {
+	if common.OrgRoleAtLeast(membership.Role, role) {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{
+		"error": "This action requires the " + role + " role in the organization",
+	})
+	return false
+}
+
+// loadMembership loads the organization from the :id parameter with the current user's membership,
+// writing the error response and returning false when the user is not a member
+func loadMembership(c *gin.Context) (*Organization, *Membership, bool) {
+	membership, err := GetMembership(c.Param("id"), c.GetString("user_id"))
+	if err == nil {
+		var organization *Organization
+		organization, err = GetOrganizationByID(membership.OrganizationID)
+		if err == nil {
+			return organization, membership, true
+		}
+	}
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Organization not found",
+		})
+		return nil, nil, false
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Failed to get organization",
+	})
+	return nil, nil, false
+}
+
+// loadMember loads the membership of the :user_id parameter in the organization,
+// writing the error response and returning false when the user is not a member
+func loadMember(c *gin.Context, organizationID string) (*Membership, bool) {
+	member, err := GetMembership(organizationID, c.Param("user_id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Member not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get member",
+		})
+		return nil, false
+	}
+	return member, true
+}
+
+// respondOrganizationConflict returns the current representation so the client can merge and retry
+func respondOrganizationConflict(c *gin.Context, precondition *common.Precondition, current *Organization, role string) {
+	c.Header("ETag", common.ETag(current.Version))
+	c.JSON(precondition.ConflictStatus(), gin.H{
+		"error": "Organization was modified by someone else",
+		"data":  current.ToOrganizationResponse(role),
+	})
+}
@@ -0,0 +1,104 @@
+package organizations
+
+import (
+	"time"
+)
+
+// OrganizationResponse represents an organization with the current user's role in it
+type OrganizationResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   string    `json:"owner_id"`
+	Role      string    `json:"role"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MemberResponse represents a member of an organization
+type MemberResponse struct {
+	UserID   string    `json:"user_id"`
+	Username string    `json:"username"`
+	FullName string    `json:"full_name"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// InvitationResponse represents an invitation to join an organization
+type InvitationResponse struct {
+	ID               string     `json:"id"`
+	OrganizationID   string     `json:"organization_id"`
+	OrganizationName string     `json:"organization_name"`
+	Email            *string    `json:"email"`
+	UserID           *string    `json:"user_id"`
+	Role             string     `json:"role"`
+	InvitedBy        string     `json:"invited_by"`
+	Status           string     `json:"status"`
+	CreatedAt        time.Time  `json:"created_at"`
+	RespondedAt      *time.Time `json:"responded_at"`
+}
+
+// CreateOrganizationRequest represents organization creation request
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// UpdateOrganizationRequest represents organization update request
+type UpdateOrganizationRequest struct {
+	Name    *string `json:"name"`
+	Version *int    `json:"version"` // Expected version when If-Match is not sent
+}
+
+// UpdateMemberRequest changes the role of a member
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// CreateInvitationRequest invites a user by email or username
+type CreateInvitationRequest struct {
+	Email    *string `json:"email"`
+	Username *string `json:"username"`
+	Role     string  `json:"role" binding:"required"`
+}
+
+// ToOrganizationResponse converts an Organization to OrganizationResponse with the member's role
+func (o *Organization) ToOrganizationResponse(role string) OrganizationResponse {
+	return OrganizationResponse{
+		ID:        o.ID,
+		Name:      o.Name,
+		OwnerID:   o.OwnerID,
+		Role:      role,
+		Version:   o.Version,
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+}
+
+// ToInvitationResponse converts an Invitation to InvitationResponse
+func (i *Invitation) ToInvitationResponse(organizationName string) InvitationResponse {
+	return InvitationResponse{
+		ID:               i.ID,
+		OrganizationID:   i.OrganizationID,
+		OrganizationName: organizationName,
+		Email:            i.Email,
+		UserID:           i.UserID,
+		Role:             i.Role,
+		InvitedBy:        i.InvitedBy,
+		Status:           i.Status,
+		CreatedAt:        i.CreatedAt,
+		RespondedAt:      i.RespondedAt,
+	}
+}
+
+// ToMemberResponse converts a Member to MemberResponse
+func (m *Member) ToMemberResponse() MemberResponse {
+	return MemberResponse{
+		UserID:   m.UserID,
+		Username: m.Username,
+		FullName: m.FullName,
+		Email:    m.Email,
+		Role:     m.Role,
+		JoinedAt: m.CreatedAt,
+	}
+}
@@ -0,0 +1,313 @@
+package organizations
+
+import (
+	"errors"
+	"time"
+
+	"plantheon-backend/common"
+
+	"gorm.io/gorm"
+)
+
+// ErrOrganizationInUse is returned when deleting an organization that still has data
+var ErrOrganizationInUse = errors.New("organization still has plots, inventory items or activities")
+
+// ErrInvitationAnswered is returned when answering an invitation that is no longer pending
+var ErrInvitationAnswered = errors.New("invitation is no longer pending")
+
+// OrganizationService handles all database operations for organizations
+type OrganizationService struct {
+	db *gorm.DB
+}
+
+// NewOrganizationService creates a new organization service instance
+func NewOrganizationService() *OrganizationService {
+	return &OrganizationService{
+		db: common.GetDB(),
+	}
+}
+
+// CreateOrganizationRecord creates an organization with its owner as first member
+func CreateOrganizationRecord(organization *Organization) error {
+	service := NewOrganizationService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			return err
+		}
+		return tx.Create(&Membership{
+			OrganizationID: organization.ID,
+			UserID:         organization.OwnerID,
+			Role:           common.OrgRoleOwner,
+		}).Error
+	})
+}
+
+// GetOrganizationByID finds organization by ID
+func GetOrganizationByID(id string) (*Organization, error) {
+	service := NewOrganizationService()
+	var organization Organization
+	err := service.db.Where("id = ?", id).First(&organization).Error
+	return &organization, err
+}
+
+// UserOrganization is an organization with the role of a member
+type UserOrganization struct {
+	Organization
+	Role string
+}
+
+// GetUserOrganizations lists the organizations the user is a member of by name
+func GetUserOrganizations(userID string) ([]UserOrganization, error) {
+	service := NewOrganizationService()
+	var organizations []UserOrganization
+	err := service.db.Model(&Organization{}).
+		Select("organizations.*, organization_members.role").
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ?", userID).
+		Order("organizations.name ASC").
+		Find(&organizations).Error
+	return organizations, err
+}
+
+// UpdateOrganization updates organization information.
+// The update only applies if the stored version still equals organization.Version,
+// otherwise common.ErrVersionConflict is returned. On success the version is incremented.
+func UpdateOrganization(organization *Organization) error {
+	service := NewOrganizationService()
+	expected := organization.Version
+	organization.Version = expected + 1
+
+	result := service.db.Model(organization).
+		Where("version = ?", expected).
+		Select("*").Omit("id", "owner_id", "created_at").
+		Updates(organization)
+	if result.Error != nil {
+		organization.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		organization.Version = expected
+		return common.ErrVersionConflict
+	}
+	return nil
+}
+
+// DeleteOrganization soft-deletes an organization without plots, inventory items or
+// activities and removes its members and invitations. ErrOrganizationInUse is returned otherwise,
+// trashed records do not count, they become unreachable with the members.
+func DeleteOrganization(id string) error {
+	service := NewOrganizationService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range []string{"plots", "inventory_items", "activities"} {
+			var count int64
+			if err := tx.Table(table).Where("organization_id = ? AND deleted_at IS NULL", id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrOrganizationInUse
+			}
+		}
+		if err := tx.Where("organization_id = ?", id).Delete(&Membership{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ?", id).Delete(&Invitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&Organization{}).Error
+	})
+}
+
+// GetMembership finds the membership of the user in an organization that is not deleted
+func GetMembership(organizationID, userID string) (*Membership, error) {
+	service := NewOrganizationService()
+	var membership Membership
+	err := service.db.
+		Joins("JOIN organizations ON organizations.id = organization_members.organization_id AND organizations.deleted_at IS NULL").
+		Where("organization_members.organization_id = ? AND organization_members.user_id = ?", organizationID, userID).
+		First(&membership).Error
+	return &membership, err
+}
+
+// Member is a membership with the member's account
+type Member struct {
+	UserID    string
+	Username  string
+	FullName  string
+	Email     string
+	Role      string
+	CreatedAt time.Time
+}
+
+// GetMembers lists the members of an organization, owner first then by username
+func GetMembers(organizationID string) ([]Member, error) {
+	service := NewOrganizationService()
+	var members []Member
+	err := service.db.Table("organization_members").
+		Select("organization_members.user_id, users.username, users.full_name, users.email, organization_members.role, organization_members.created_at").
+		Joins("JOIN users ON users.id = organization_members.user_id").
+		Where("organization_members.organization_id = ?", organizationID).
+		Order("organization_members.role = 'owner' DESC, users.username ASC").
+		Find(&members).Error
+	return members, err
+}
+
+// UpdateMembershipRole changes the role of a member
+func UpdateMembershipRole(membership *Membership, role string) error {
+	service := NewOrganizationService()
+	membership.Role = role
+	return service.db.Model(membership).Update("role", role).Error
+}
+
+// DeleteMembership removes a member, the records they created stay in the organization
+func DeleteMembership(membership *Membership) error {
+	service := NewOrganizationService()
+	return service.db.Delete(membership).Error
+}
+
+// CreateInvitationRecord creates a new invitation
+func CreateInvitationRecord(invitation *Invitation) error {
+	service := NewOrganizationService()
+	return service.db.Create(invitation).Error
+}
+
+// HasPendingInvitation checks if the organization already invited the user or the email
+func HasPendingInvitation(organizationID string, userID, email *string) (bool, error) {
+	service := NewOrganizationService()
+	invitee := service.db
+	if userID != nil {
+		invitee = invitee.Or("user_id = ?", *userID)
+	}
+	if email != nil {
+		invitee = invitee.Or("email = ?", *email)
+	}
+	var count int64
+	err := service.db.Model(&Invitation{}).
+		Where("organization_id = ? AND status = ?", organizationID, InvitationPending).
+		Where(invitee).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetInvitations lists the invitations of an organization, newest first
+func GetInvitations(organizationID, status string) ([]Invitation, error) {
+	service := NewOrganizationService()
+	var invitations []Invitation
+	query := service.db.Where("organization_id = ?", organizationID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+// GetInvitation finds an invitation of the organization
+func GetInvitation(organizationID, id string) (*Invitation, error) {
+	service := NewOrganizationService()
+	var invitation Invitation
+	err := service.db.Where("id = ? AND organization_id = ?", id, organizationID).First(&invitation).Error
+	return &invitation, err
+}
+
+// forInvitee limits the query to the invitations of the user's account or email
+func forInvitee(query *gorm.DB, userID, email string) *gorm.DB {
+	return query.Where("user_id = ? OR LOWER(email) = LOWER(?)", userID, email)
+}
+
+// GetUserInvitations lists the pending invitations of the user's account or email, newest first
+func GetUserInvitations(userID, email string) ([]Invitation, error) {
+	service := NewOrganizationService()
+	var invitations []Invitation
+	query := service.db.Where("status = ?", InvitationPending).
+		Where("organization_id IN (?)", service.db.Model(&Organization{}).Select("id"))
+	err := forInvitee(query, userID, email).Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+// GetUserInvitation finds an invitation sent to the user's account or email
+func GetUserInvitation(id, userID, email string) (*Invitation, error) {
+	service := NewOrganizationService()
+	var invitation Invitation
+	err := forInvitee(service.db.Where("id = ?", id), userID, email).First(&invitation).Error
+	return &invitation, err
+}
+
+// GetOrganizationNames maps organization IDs to their names
+func GetOrganizationNames(ids []string) (map[string]string, error) {
+	service := NewOrganizationService()
+	var organizations []Organization
+	names := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+	if err := service.db.Select("id", "name").Where("id IN ?", ids).Find(&organizations).Error; err != nil {
+		return nil, err
+	}
+	for _, organization := range organizations {
+		names[organization.ID] = organization.Name
+	}
+	return names, nil
+}
+
+// RespondInvitation accepts or declines a pending invitation for the user.
+// Accepting adds the user to the organization with the invited role, keeping
+// the current role of a user who already joined. ErrInvitationAnswered is
+// returned when the invitation was answered or revoked meanwhile.
+func RespondInvitation(invitation *Invitation, userID string, accept bool) error {
+	service := NewOrganizationService()
+	now := time.Now()
+	status := InvitationDeclined
+	if accept {
+		status = InvitationAccepted
+	}
+
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(invitation).
+			Where("status = ?", InvitationPending).
+			Updates(map[string]interface{}{"status": status, "user_id": userID, "responded_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvitationAnswered
+		}
+		invitation.Status = status
+		invitation.UserID = &userID
+		invitation.RespondedAt = &now
+		if !accept {
+			return nil
+		}
+
+		var count int64
+		if err := tx.Model(&Membership{}).
+			Where("organization_id = ? AND user_id = ?", invitation.OrganizationID, userID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		return tx.Create(&Membership{
+			OrganizationID: invitation.OrganizationID,
+			UserID:         userID,
+			Role:           invitation.Role,
+		}).Error
+	})
+}
+
+// RevokeInvitation cancels a pending invitation, ErrInvitationAnswered is returned otherwise
+func RevokeInvitation(invitation *Invitation) error {
+	service := NewOrganizationService()
+	now := time.Now()
+	result := service.db.Model(invitation).
+		Where("status = ?", InvitationPending).
+		Updates(map[string]interface{}{"status": InvitationRevoked, "responded_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvitationAnswered
+	}
+	invitation.Status = InvitationRevoked
+	invitation.RespondedAt = &now
+	return nil
+}
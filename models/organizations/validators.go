@@ -0,0 +1,73 @@
+package organizations
+
+import (
+	"errors"
+	"strings"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/users"
+)
+
+// ValidateCreateOrganizationRequest validates organization creation request
+func ValidateCreateOrganizationRequest(req *CreateOrganizationRequest) error {
+	return validateOrganizationName(&req.Name)
+}
+
+// ValidateUpdateOrganizationRequest validates organization update request
+func ValidateUpdateOrganizationRequest(req *UpdateOrganizationRequest) error {
+	if req.Name != nil {
+		return validateOrganizationName(req.Name)
+	}
+	return nil
+}
+
+// ValidateUpdateMemberRequest validates the new role of a member, there is only one owner
+func ValidateUpdateMemberRequest(req *UpdateMemberRequest) error {
+	return validateMemberRole(&req.Role)
+}
+
+// ValidateCreateInvitationRequest validates invitation request, exactly one of email and username is required
+func ValidateCreateInvitationRequest(req *CreateInvitationRequest) error {
+	if req.Email != nil {
+		*req.Email = strings.ToLower(strings.TrimSpace(*req.Email))
+		if *req.Email == "" {
+			req.Email = nil
+		}
+	}
+	if req.Username != nil {
+		*req.Username = strings.TrimSpace(*req.Username)
+		if *req.Username == "" {
+			req.Username = nil
+		}
+	}
+	if (req.Email == nil) == (req.Username == nil) {
+		return errors.New("either email or username is required")
+	}
+	if req.Email != nil {
+		if err := users.ValidateEmail(*req.Email); err != nil {
+			return err
+		}
+	}
+	return validateMemberRole(&req.Role)
+}
+
+// validateOrganizationName trims the name and checks its length
+func validateOrganizationName(name *string) error {
+	*name = strings.TrimSpace(*name)
+	if *name == "" {
+		return errors.New("organization name is required")
+	}
+	if len(*name) > 255 {
+		return errors.New("organization name must be less than 255 characters")
+	}
+	return nil
+}
+
+// validateMemberRole normalizes the role and checks it can be given to a member
+func validateMemberRole(role *string) error {
+	*role = strings.ToLower(strings.TrimSpace(*role))
+	if !common.IsOrgRole(*role) || *role == common.OrgRoleOwner {
+		return errors.New("role must be one of: manager, worker, viewer")
+	}
+	return nil
+}
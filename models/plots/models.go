@@ -0,0 +1,56 @@
+package plots
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Plot is a field or garden bed owned by a farmer
+type Plot struct {
+	ID             string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID         string         `json:"user_id" gorm:"type:uuid;not null;index"` // Owner, or creator in an organization
+	OrganizationID *string        `json:"organization_id" gorm:"type:uuid;index"`  // Organization sharing the plot
+	Name           string         `json:"name" gorm:"type:varchar(255);not null"`
+	Area           *float64       `json:"area" gorm:"type:decimal(15,4)"`
+	AreaUnit       string         `json:"area_unit" gorm:"type:varchar(50);not null;default:'m2'"`
+	CurrentCrop    *string        `json:"current_crop" gorm:"type:varchar(255)"`
+	Boundary       *string        `json:"boundary" gorm:"type:jsonb"` // GeoJSON Polygon or MultiPolygon
+	Note           *string        `json:"note" gorm:"type:text"`
+	Version        int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// Diagnosis records a disease detected on a plot, e.g. from the scan feature of the app
+type Diagnosis struct {
+	ID               string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID           string         `json:"user_id" gorm:"type:uuid;not null;index"`
+	PlotID           string         `json:"plot_id" gorm:"type:uuid;not null;index"`
+	DiseaseClassName string         `json:"disease_class_name" gorm:"type:varchar(255);not null"`
+	Confidence       *float64       `json:"confidence" gorm:"type:decimal(5,4)"`
+	ImageLink        *string        `json:"image_link" gorm:"type:text"`
+	Note             *string        `json:"note" gorm:"type:text"`
+	DiagnosedAt      time.Time      `json:"diagnosed_at" gorm:"not null;index"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (p *Plot) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (d *Diagnosis) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,483 @@
+package plots
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/activities"
+	"plantheon-backend/models/diseases"
+	"plantheon-backend/models/units"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreatePlotHandler handles plot creation for the current user
+func CreatePlotHandler(c *gin.Context) {
+	var req CreatePlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreatePlotRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !units.CheckUnit(c, &req.AreaUnit, units.DimensionArea) {
+		return
+	}
+
+	plot, err := req.ToPlot(common.CurrentTenant(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := CreatePlotRecord(plot); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create plot",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Plot created successfully",
+		"data":    plot.ToPlotResponse(),
+	})
+}
+
+// GetPlotsHandler handles listing the plots of the current user or organization with pagination
+func GetPlotsHandler(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		limit = 10
+	}
+
+	// Validate pagination
+	page, limit, _ = ValidatePaginationParams(page, limit)
+	offset := (page - 1) * limit
+
+	plots, total, err := GetPlotsByTenant(common.CurrentTenant(c), c.Query("search"), offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get plots",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": ToPlotsListResponse(plots, total, page, limit),
+	})
+}
+
+// GetPlotHandler handles getting one plot of the current user or organization
+func GetPlotHandler(c *gin.Context) {
+	plot, ok := loadUserPlot(c)
+	if !ok {
+		return
+	}
+
+	c.Header("ETag", common.ETag(plot.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"data": plot.ToPlotResponse(),
+	})
+}
+
+// UpdatePlotHandler handles plot update
+func UpdatePlotHandler(c *gin.Context) {
+	plot, ok := loadUserPlot(c)
+	if !ok {
+		return
+	}
+
+	var req UpdatePlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateUpdatePlotRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !units.CheckUnit(c, req.AreaUnit, units.DimensionArea) {
+		return
+	}
+
+	// Reject the update if the client edited an outdated version
+	precondition, err := common.ParsePrecondition(c, req.Version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(plot.Version) {
+		respondPlotConflict(c, precondition, plot)
+		return
+	}
+
+	// Update plot fields if provided
+	if req.Name != nil {
+		plot.Name = *req.Name
+	}
+	if req.Area != nil {
+		plot.Area = req.Area
+	}
+	if req.AreaUnit != nil {
+		plot.AreaUnit = *req.AreaUnit
+	}
+	if req.CurrentCrop != nil {
+		plot.CurrentCrop = req.CurrentCrop
+	}
+	if req.Note != nil {
+		plot.Note = req.Note
+	}
+	if len(req.Boundary) > 0 {
+		if string(req.Boundary) == "null" {
+			plot.Boundary = nil
+		} else {
+			boundary, _, err := NormalizeBoundary(req.Boundary)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err.Error(),
+				})
+				return
+			}
+			plot.Boundary = &boundary
+		}
+	}
+
+	if err := UpdatePlot(plot); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetPlotByID(plot.ID); err == nil {
+				respondPlotConflict(c, precondition, current)
+				return
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update plot",
+		})
+		return
+	}
+
+	c.Header("ETag", common.ETag(plot.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Plot updated successfully",
+		"data":    plot.ToPlotResponse(),
+	})
+}
+
+// DeletePlotHandler handles plot deletion
+func DeletePlotHandler(c *gin.Context) {
+	plot, ok := loadUserPlot(c)
+	if !ok {
+		return
+	}
+
+	if err := DeletePlot(plot.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete plot",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Plot deleted successfully",
+	})
+}
+
+// GetPlotTimelineHandler returns activities and diagnoses of a plot, newest first
+// Query: GET /api/v1/plots/:id/timeline?from=YYYY-MM-DD&to=YYYY-MM-DD
+func GetPlotTimelineHandler(c *gin.Context) {
+	plot, ok := loadUserPlot(c)
+	if !ok {
+		return
+	}
+
+	from, to, err := common.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	acts, err := activities.GetActivitiesByPlot(plot.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activities",
+		})
+		return
+	}
+
+	diagnoses, err := GetDiagnosesByPlot(plot.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get diagnoses",
+		})
+		return
+	}
+
+	items := make([]PlotTimelineItem, 0, len(acts)+len(diagnoses))
+	for _, a := range acts {
+		response := a.ToActivityResponse()
+		at := a.CreatedAt
+		if a.TimeStart != nil {
+			at = *a.TimeStart
+		}
+		items = append(items, PlotTimelineItem{Kind: "activity", Time: at, Activity: &response})
+	}
+	for _, d := range diagnoses {
+		response := d.ToDiagnosisResponse()
+		items = append(items, PlotTimelineItem{Kind: "diagnosis", Time: d.DiagnosedAt, Diagnosis: &response})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Time.After(items[j].Time)
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"plot":  plot.ToPlotResponse(),
+			"items": items,
+			"count": len(items),
+		},
+	})
+}
+
+// GetPlotCostsHandler returns money totals of the activities done on a plot
+// Query: GET /api/v1/plots/:id/costs?from=YYYY-MM-DD&to=YYYY-MM-DD
+func GetPlotCostsHandler(c *gin.Context) {
+	plot, ok := loadUserPlot(c)
+	if !ok {
+		return
+	}
+
+	from, to, err := common.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	totals, err := activities.GetMoneyByTypeForPlot(plot.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get plot costs",
+		})
+		return
+	}
+
+	response := PlotCostsResponse{
+		PlotID: plot.ID,
+		From:   from,
+		To:     to,
+		ByType: totals,
+	}
+	for _, total := range totals {
+		if total.Type == activities.TypeIncome {
+			response.TotalIncome += total.Total
+		} else {
+			response.TotalCost += total.Total
+		}
+	}
+	response.Balance = response.TotalIncome - response.TotalCost
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// CreateDiagnosisHandler records a disease diagnosed on a plot
+func CreateDiagnosisHandler(c *gin.Context) {
+	plot, ok := loadUserPlot(c)
+	if !ok {
+		return
+	}
+
+	var req CreateDiagnosisRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateDiagnosisRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// The diagnosed disease must come from the catalog
+	disease, err := diseases.GetDiseaseByClassName(req.DiseaseClassName)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Unknown disease class name",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get disease",
+		})
+		return
+	}
+
+	diagnosis := &Diagnosis{
+		UserID:           c.GetString("user_id"),
+		PlotID:           plot.ID,
+		DiseaseClassName: disease.ClassName,
+		Confidence:       req.Confidence,
+		ImageLink:        req.ImageLink,
+		Note:             req.Note,
+		DiagnosedAt:      time.Now(),
+	}
+	if req.DiagnosedAt != nil {
+		diagnosis.DiagnosedAt = *req.DiagnosedAt
+	}
+
+	if err := CreateDiagnosisRecord(diagnosis); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create diagnosis",
+		})
+		return
+	}
+
+	response := diagnosis.ToDiagnosisResponse()
+	response.DiseaseName = disease.Name
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Diagnosis created successfully",
+		"data":    response,
+	})
+}
+
+// GetPlotDiagnosesHandler lists diagnoses of a plot
+func GetPlotDiagnosesHandler(c *gin.Context) {
+	plot, ok := loadUserPlot(c)
+	if !ok {
+		return
+	}
+
+	from, to, err := common.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	diagnoses, err := GetDiagnosesByPlot(plot.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get diagnoses",
+		})
+		return
+	}
+
+	response := make([]DiagnosisResponse, len(diagnoses))
+	for i, d := range diagnoses {
+		response[i] = d.ToDiagnosisResponse()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"diagnoses": response,
+			"count":     len(response),
+		},
+	})
+}
+
+// DeleteDiagnosisHandler handles diagnosis deletion
+func DeleteDiagnosisHandler(c *gin.Context) {
+	plot, ok := loadUserPlot(c)
+	if !ok {
+		return
+	}
+
+	diagnosis, err := GetDiagnosisByID(c.Param("diagnosisId"))
+	if err != nil || diagnosis.PlotID != plot.ID {
+		if err == nil || err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Diagnosis not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get diagnosis",
+		})
+		return
+	}
+
+	if err := DeleteDiagnosis(diagnosis.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete diagnosis",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Diagnosis deleted successfully",
+	})
+}
+
+// loadUserPlot loads the plot from the :id param if it belongs to the current user or organization,
+// writing the error response and returning false otherwise
+func loadUserPlot(c *gin.Context) (*Plot, bool) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Plot ID is required",
+		})
+		return nil, false
+	}
+
+	plot, err := GetTenantPlot(id, common.CurrentTenant(c))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Plot not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get plot",
+		})
+		return nil, false
+	}
+	return plot, true
+}
+
+// respondPlotConflict returns the current representation so the client can merge and retry
+func respondPlotConflict(c *gin.Context, precondition *common.Precondition, current *Plot) {
+	c.Header("ETag", common.ETag(current.Version))
+	c.JSON(precondition.ConflictStatus(), gin.H{
+		"error": "Plot was modified by someone else",
+		"data":  current.ToPlotResponse(),
+	})
+}
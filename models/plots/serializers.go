@@ -0,0 +1,192 @@
+package plots
+
+import (
+	"encoding/json"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/activities"
+)
+
+// PlotResponse represents plot response
+type PlotResponse struct {
+	ID             string          `json:"id"`
+	UserID         string          `json:"user_id"`
+	OrganizationID *string         `json:"organization_id"`
+	Name           string          `json:"name"`
+	Area           *float64        `json:"area"`
+	AreaUnit       string          `json:"area_unit"`
+	CurrentCrop    *string         `json:"current_crop"`
+	Boundary       json.RawMessage `json:"boundary"`
+	Note           *string         `json:"note"`
+	Version        int             `json:"version"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// CreatePlotRequest represents plot creation request
+type CreatePlotRequest struct {
+	Name        string          `json:"name" binding:"required"`
+	Area        *float64        `json:"area"`
+	AreaUnit    string          `json:"area_unit"`
+	CurrentCrop *string         `json:"current_crop"`
+	Boundary    json.RawMessage `json:"boundary"` // GeoJSON Polygon, MultiPolygon or a Feature wrapping one
+	Note        *string         `json:"note"`
+}
+
+// UpdatePlotRequest represents plot update request
+type UpdatePlotRequest struct {
+	Name        *string         `json:"name"`
+	Area        *float64        `json:"area"`
+	AreaUnit    *string         `json:"area_unit"`
+	CurrentCrop *string         `json:"current_crop"`
+	Boundary    json.RawMessage `json:"boundary"`
+	Note        *string         `json:"note"`
+	Version     *int            `json:"version"` // Expected version when If-Match is not sent
+}
+
+// PlotsListResponse represents paginated plots list response
+type PlotsListResponse struct {
+	Plots      []PlotResponse `json:"plots"`
+	Total      int64          `json:"total"`
+	Page       int            `json:"page"`
+	Limit      int            `json:"limit"`
+	TotalPages int            `json:"total_pages"`
+}
+
+// DiagnosisResponse represents diagnosis response
+type DiagnosisResponse struct {
+	ID               string    `json:"id"`
+	PlotID           string    `json:"plot_id"`
+	DiseaseClassName string    `json:"disease_class_name"`
+	DiseaseName      string    `json:"disease_name,omitempty"`
+	Confidence       *float64  `json:"confidence"`
+	ImageLink        *string   `json:"image_link"`
+	Note             *string   `json:"note"`
+	DiagnosedAt      time.Time `json:"diagnosed_at"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// CreateDiagnosisRequest represents diagnosis creation request
+type CreateDiagnosisRequest struct {
+	DiseaseClassName string     `json:"disease_class_name" binding:"required"`
+	Confidence       *float64   `json:"confidence"`
+	ImageLink        *string    `json:"image_link"`
+	Note             *string    `json:"note"`
+	DiagnosedAt      *time.Time `json:"diagnosed_at"` // Defaults to now
+}
+
+// PlotTimelineItem is one entry of the plot timeline, either an activity or a diagnosis
+type PlotTimelineItem struct {
+	Kind      string                       `json:"kind"` // "activity" or "diagnosis"
+	Time      time.Time                    `json:"time"`
+	Activity  *activities.ActivityResponse `json:"activity,omitempty"`
+	Diagnosis *DiagnosisResponse           `json:"diagnosis,omitempty"`
+}
+
+// PlotCostsResponse represents money totals of the activities done on a plot
+type PlotCostsResponse struct {
+	PlotID      string                   `json:"plot_id"`
+	From        *time.Time               `json:"from"`
+	To          *time.Time               `json:"to"`
+	TotalCost   float64                  `json:"total_cost"`
+	TotalIncome float64                  `json:"total_income"`
+	Balance     float64                  `json:"balance"`
+	ByType      []activities.MoneyByType `json:"by_type"`
+}
+
+// ToPlotResponse converts Plot model to PlotResponse
+func (p *Plot) ToPlotResponse() PlotResponse {
+	var boundary json.RawMessage
+	if p.Boundary != nil {
+		boundary = json.RawMessage(*p.Boundary)
+	}
+
+	return PlotResponse{
+		ID:             p.ID,
+		UserID:         p.UserID,
+		OrganizationID: p.OrganizationID,
+		Name:           p.Name,
+		Area:           p.Area,
+		AreaUnit:       p.AreaUnit,
+		CurrentCrop:    p.CurrentCrop,
+		Boundary:       boundary,
+		Note:           p.Note,
+		Version:        p.Version,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+	}
+}
+
+// ToPlot builds a new plot of the tenant from the request.
+// A boundary is normalized and gives the area when none was entered.
+func (req *CreatePlotRequest) ToPlot(tenant common.Tenant) (*Plot, error) {
+	plot := &Plot{
+		UserID:         tenant.UserID,
+		OrganizationID: tenant.OrganizationRef(),
+		Name:           req.Name,
+		Area:           req.Area,
+		AreaUnit:       req.AreaUnit,
+		CurrentCrop:    req.CurrentCrop,
+		Note:           req.Note,
+	}
+	if plot.AreaUnit == "" {
+		plot.AreaUnit = "m2"
+	}
+
+	if len(req.Boundary) > 0 && string(req.Boundary) != "null" {
+		boundary, area, err := NormalizeBoundary(req.Boundary)
+		if err != nil {
+			return nil, err
+		}
+		plot.Boundary = &boundary
+		// Measure the area from the polygon when the farmer did not enter it
+		if plot.Area == nil {
+			plot.Area = &area
+			plot.AreaUnit = "m2"
+		}
+	}
+	return plot, nil
+}
+
+// ToPlotsListResponse converts plots list to paginated response
+func ToPlotsListResponse(plots []Plot, total int64, page, limit int) PlotsListResponse {
+	response := make([]PlotResponse, len(plots))
+	for i, plot := range plots {
+		response[i] = plot.ToPlotResponse()
+	}
+
+	totalPages := int(total) / limit
+	if int(total)%limit > 0 {
+		totalPages++
+	}
+
+	return PlotsListResponse{
+		Plots:      response,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}
+}
+
+// ToDiagnosisResponse converts Diagnosis model to DiagnosisResponse
+func (d *Diagnosis) ToDiagnosisResponse() DiagnosisResponse {
+	return DiagnosisResponse{
+		ID:               d.ID,
+		PlotID:           d.PlotID,
+		DiseaseClassName: d.DiseaseClassName,
+		Confidence:       d.Confidence,
+		ImageLink:        d.ImageLink,
+		Note:             d.Note,
+		DiagnosedAt:      d.DiagnosedAt,
+		CreatedAt:        d.CreatedAt,
+	}
+}
+
+// DiagnosisCount represents how often a disease was diagnosed on a plot
+type DiagnosisCount struct {
+	DiseaseClassName string    `json:"disease_class_name"`
+	Count            int64     `json:"count"`
+	LastDiagnosedAt  time.Time `json:"last_diagnosed_at"`
+}
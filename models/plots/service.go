@@ -0,0 +1,154 @@
+package plots
+
+import (
+	"time"
+
+	"plantheon-backend/common"
+
+	"gorm.io/gorm"
+)
+
+// PlotService handles all database operations for plots
+type PlotService struct {
+	db *gorm.DB
+}
+
+// NewPlotService creates a new plot service instance
+func NewPlotService() *PlotService {
+	return &PlotService{
+		db: common.GetDB(),
+	}
+}
+
+// CreatePlotRecord creates a new plot
+func CreatePlotRecord(plot *Plot) error {
+	service := NewPlotService()
+	return service.db.Create(plot).Error
+}
+
+// GetPlotByID finds plot by ID
+func GetPlotByID(id string) (*Plot, error) {
+	service := NewPlotService()
+	var plot Plot
+	err := service.db.Where("id = ?", id).First(&plot).Error
+	return &plot, err
+}
+
+// GetUserPlot finds a plot owned by the user outside of any organization
+func GetUserPlot(id, userID string) (*Plot, error) {
+	return GetTenantPlot(id, common.Tenant{UserID: userID})
+}
+
+// GetTenantPlot finds a plot of the user's own data or of the active organization
+func GetTenantPlot(id string, tenant common.Tenant) (*Plot, error) {
+	service := NewPlotService()
+	var plot Plot
+	err := tenant.Scope(service.db.Where("id = ?", id)).First(&plot).Error
+	return &plot, err
+}
+
+// GetPlotsByTenant gets the tenant's plots with pagination, optionally filtered by name or crop
+func GetPlotsByTenant(tenant common.Tenant, search string, offset, limit int) ([]Plot, int64, error) {
+	service := NewPlotService()
+	var plots []Plot
+	var total int64
+
+	query := tenant.Scope(service.db)
+	if search != "" {
+		searchQuery := "%" + search + "%"
+		query = query.Where("name ILIKE ? OR current_crop ILIKE ?", searchQuery, searchQuery)
+	}
+
+	// Count total records
+	if err := query.Model(&Plot{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	err := query.Offset(offset).Limit(limit).Order("name ASC").Find(&plots).Error
+	return plots, total, err
+}
+
+// GetAllUserPlots gets all the user's plots outside of any organization without pagination
+func GetAllUserPlots(userID string) ([]Plot, error) {
+	service := NewPlotService()
+	var plots []Plot
+	err := common.Tenant{UserID: userID}.Scope(service.db).Order("name ASC").Find(&plots).Error
+	return plots, err
+}
+
+// UpdatePlot updates plot information.
+// The update only applies if the stored version still equals plot.Version,
+// otherwise common.ErrVersionConflict is returned. On success the version is incremented.
+func UpdatePlot(plot *Plot) error {
+	service := NewPlotService()
+	expected := plot.Version
+	plot.Version = expected + 1
+
+	result := service.db.Model(plot).
+		Where("version = ?", expected).
+		Select("*").Omit("id", "created_at").
+		Updates(plot)
+	if result.Error != nil {
+		plot.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		plot.Version = expected
+		return common.ErrVersionConflict
+	}
+	return nil
+}
+
+// DeletePlot soft-deletes plot by ID
+func DeletePlot(id string) error {
+	service := NewPlotService()
+	return service.db.Where("id = ?", id).Delete(&Plot{}).Error
+}
+
+// CreateDiagnosisRecord creates a new diagnosis
+func CreateDiagnosisRecord(diagnosis *Diagnosis) error {
+	service := NewPlotService()
+	return service.db.Create(diagnosis).Error
+}
+
+// GetDiagnosisByID finds diagnosis by ID
+func GetDiagnosisByID(id string) (*Diagnosis, error) {
+	service := NewPlotService()
+	var diagnosis Diagnosis
+	err := service.db.Where("id = ?", id).First(&diagnosis).Error
+	return &diagnosis, err
+}
+
+// GetDiagnosesByPlot returns diagnoses of a plot, optionally limited to [from, to)
+func GetDiagnosesByPlot(plotID string, from, to *time.Time) ([]Diagnosis, error) {
+	service := NewPlotService()
+	var diagnoses []Diagnosis
+	query := service.db.Where("plot_id = ?", plotID)
+	if from != nil {
+		query = query.Where("diagnosed_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("diagnosed_at < ?", *to)
+	}
+	err := query.Order("diagnosed_at DESC").Find(&diagnoses).Error
+	return diagnoses, err
+}
+
+// DeleteDiagnosis soft-deletes diagnosis by ID
+func DeleteDiagnosis(id string) error {
+	service := NewPlotService()
+	return service.db.Where("id = ?", id).Delete(&Diagnosis{}).Error
+}
+
+// GetDiagnosisCountsByPlot counts diagnoses of a plot in [from, to) grouped by disease
+func GetDiagnosisCountsByPlot(plotID string, from, to time.Time) ([]DiagnosisCount, error) {
+	service := NewPlotService()
+	var counts []DiagnosisCount
+	err := service.db.Model(&Diagnosis{}).
+		Select("disease_class_name, COUNT(*) AS count, MAX(diagnosed_at) AS last_diagnosed_at").
+		Where("plot_id = ? AND diagnosed_at >= ? AND diagnosed_at < ?", plotID, from, to).
+		Group("disease_class_name").Order("count DESC, disease_class_name").
+		Scan(&counts).Error
+	return counts, err
+}
@@ -0,0 +1,269 @@
+package plots
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"plantheon-backend/common"
+)
+
+// ValidateCreatePlotRequest validates plot creation request
+func ValidateCreatePlotRequest(req *CreatePlotRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.New("plot name is required")
+	}
+	if len(req.Name) > 255 {
+		return errors.New("plot name must be less than 255 characters")
+	}
+
+	if req.Area != nil && *req.Area < 0 {
+		return errors.New("area must be non-negative")
+	}
+
+	req.AreaUnit = strings.TrimSpace(req.AreaUnit)
+	if len(req.AreaUnit) > 50 {
+		return errors.New("area_unit must be less than 50 characters")
+	}
+
+	if req.CurrentCrop != nil && len(*req.CurrentCrop) > 255 {
+		return errors.New("current_crop must be less than 255 characters")
+	}
+
+	if req.Note != nil && len(*req.Note) > 1000 {
+		return errors.New("note must be less than 1000 characters")
+	}
+
+	return nil
+}
+
+// ValidateUpdatePlotRequest validates plot update request
+func ValidateUpdatePlotRequest(req *UpdatePlotRequest) error {
+	if req.Name != nil {
+		*req.Name = strings.TrimSpace(*req.Name)
+		if *req.Name == "" {
+			return errors.New("plot name cannot be empty")
+		}
+		if len(*req.Name) > 255 {
+			return errors.New("plot name must be less than 255 characters")
+		}
+	}
+
+	if req.Area != nil && *req.Area < 0 {
+		return errors.New("area must be non-negative")
+	}
+
+	if req.AreaUnit != nil {
+		*req.AreaUnit = strings.TrimSpace(*req.AreaUnit)
+		if *req.AreaUnit == "" {
+			return errors.New("area_unit cannot be empty")
+		}
+		if len(*req.AreaUnit) > 50 {
+			return errors.New("area_unit must be less than 50 characters")
+		}
+	}
+
+	if req.CurrentCrop != nil && len(*req.CurrentCrop) > 255 {
+		return errors.New("current_crop must be less than 255 characters")
+	}
+
+	if req.Note != nil && len(*req.Note) > 1000 {
+		return errors.New("note must be less than 1000 characters")
+	}
+
+	return nil
+}
+
+// ApplyPlotPatch validates a JSON merge patch with the update rules and applies it.
+// Optional fields set to null are cleared, name and area_unit cannot be null.
+func ApplyPlotPatch(plot *Plot, patch common.MergePatch) error {
+	if err := patch.CheckFields("name", "area", "area_unit", "current_crop", "boundary", "note", "version"); err != nil {
+		return err
+	}
+	if err := patch.CheckNotNull("name", "area_unit"); err != nil {
+		return err
+	}
+
+	var req UpdatePlotRequest
+	if err := patch.Decode(&req); err != nil {
+		return errors.New("invalid field type in merge patch")
+	}
+	if err := ValidateUpdatePlotRequest(&req); err != nil {
+		return err
+	}
+
+	if patch.Has("name") {
+		plot.Name = *req.Name
+	}
+	if patch.Has("area_unit") {
+		plot.AreaUnit = *req.AreaUnit
+	}
+	if patch.Has("area") {
+		plot.Area = req.Area
+	}
+	if patch.Has("current_crop") {
+		plot.CurrentCrop = req.CurrentCrop
+	}
+	if patch.Has("note") {
+		plot.Note = req.Note
+	}
+	if patch.Has("boundary") {
+		if patch.IsNull("boundary") {
+			plot.Boundary = nil
+		} else {
+			boundary, _, err := NormalizeBoundary(patch["boundary"])
+			if err != nil {
+				return err
+			}
+			plot.Boundary = &boundary
+		}
+	}
+	return nil
+}
+
+// ValidateCreateDiagnosisRequest validates diagnosis creation request
+func ValidateCreateDiagnosisRequest(req *CreateDiagnosisRequest) error {
+	req.DiseaseClassName = strings.TrimSpace(req.DiseaseClassName)
+	if req.DiseaseClassName == "" {
+		return errors.New("disease_class_name is required")
+	}
+	if len(req.DiseaseClassName) > 255 {
+		return errors.New("disease_class_name must be less than 255 characters")
+	}
+
+	if req.Confidence != nil && (*req.Confidence < 0 || *req.Confidence > 1) {
+		return errors.New("confidence must be between 0 and 1")
+	}
+
+	if req.ImageLink != nil && len(*req.ImageLink) > 1000 {
+		return errors.New("image_link must be less than 1000 characters")
+	}
+
+	if req.Note != nil && len(*req.Note) > 1000 {
+		return errors.New("note must be less than 1000 characters")
+	}
+
+	return nil
+}
+
+// geoJSONGeometry is the subset of GeoJSON accepted for plot boundaries
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    json.RawMessage `json:"geometry"` // set when a Feature is sent
+}
+
+// NormalizeBoundary validates a GeoJSON Polygon or MultiPolygon (optionally wrapped
+// in a Feature) and returns the bare geometry with its area in square meters
+func NormalizeBoundary(raw json.RawMessage) (string, float64, error) {
+	var geometry geoJSONGeometry
+	if err := json.Unmarshal(raw, &geometry); err != nil {
+		return "", 0, errors.New("boundary must be a GeoJSON object")
+	}
+
+	if geometry.Type == "Feature" {
+		if err := json.Unmarshal(geometry.Geometry, &geometry); err != nil {
+			return "", 0, errors.New("boundary feature must contain a geometry")
+		}
+	}
+
+	var polygons [][][][]float64
+	switch geometry.Type {
+	case "Polygon":
+		var polygon [][][]float64
+		if err := json.Unmarshal(geometry.Coordinates, &polygon); err != nil {
+			return "", 0, errors.New("invalid Polygon coordinates")
+		}
+		polygons = [][][][]float64{polygon}
+	case "MultiPolygon":
+		if err := json.Unmarshal(geometry.Coordinates, &polygons); err != nil {
+			return "", 0, errors.New("invalid MultiPolygon coordinates")
+		}
+	default:
+		return "", 0, errors.New("boundary must be a GeoJSON Polygon or MultiPolygon")
+	}
+
+	if len(polygons) == 0 {
+		return "", 0, errors.New("boundary must have at least one polygon")
+	}
+
+	var area float64
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			return "", 0, errors.New("polygon must have an outer ring")
+		}
+		for i, ring := range polygon {
+			if err := validateRing(ring); err != nil {
+				return "", 0, err
+			}
+			// The first ring is the outer boundary, the others are holes
+			if i == 0 {
+				area += math.Abs(ringArea(ring))
+			} else {
+				area -= math.Abs(ringArea(ring))
+			}
+		}
+	}
+
+	normalized, err := json.Marshal(struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}{geometry.Type, geometry.Coordinates})
+	if err != nil {
+		return "", 0, err
+	}
+	return string(normalized), area, nil
+}
+
+// validateRing checks a linear ring is closed and has valid longitude/latitude positions
+func validateRing(ring [][]float64) error {
+	if len(ring) < 4 {
+		return errors.New("polygon rings must have at least 4 positions")
+	}
+	for _, position := range ring {
+		if len(position) < 2 {
+			return errors.New("positions must have longitude and latitude")
+		}
+		if position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+			return fmt.Errorf("position [%v, %v] is out of range", position[0], position[1])
+		}
+	}
+	first, last := ring[0], ring[len(ring)-1]
+	if first[0] != last[0] || first[1] != last[1] {
+		return errors.New("polygon rings must be closed (first and last positions equal)")
+	}
+	return nil
+}
+
+// ringArea approximates the area of a ring on the WGS84 sphere in square meters
+func ringArea(ring [][]float64) float64 {
+	const earthRadius = 6378137.0
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	n := len(ring)
+	var total float64
+	for i := 0; i < n; i++ {
+		p1 := ring[i]
+		p2 := ring[(i+1)%n]
+		p3 := ring[(i+2)%n]
+		total += (rad(p3[0]) - rad(p1[0])) * math.Sin(rad(p2[1]))
+	}
+	return total * earthRadius * earthRadius / 2
+}
+
+// ValidatePaginationParams validates pagination parameters
+func ValidatePaginationParams(page, limit int) (int, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return page, limit, nil
+}
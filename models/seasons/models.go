@@ -0,0 +1,52 @@
+package seasons
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Season statuses
+const (
+	StatusPlanned   = "planned"
+	StatusGrowing   = "growing"
+	StatusHarvested = "harvested"
+	StatusFailed    = "failed"
+)
+
+// Season is one planting cycle of a crop on a plot, from sowing to harvest
+type Season struct {
+	ID                  string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID              string         `json:"user_id" gorm:"type:uuid;not null;index"`
+	PlotID              string         `json:"plot_id" gorm:"type:uuid;not null;index"`
+	Name                string         `json:"name" gorm:"type:varchar(255);not null"`
+	PlantName           string         `json:"plant_name" gorm:"type:varchar(255);not null"` // From the disease catalog
+	SowingDate          time.Time      `json:"sowing_date" gorm:"not null"`
+	ExpectedHarvestDate *time.Time     `json:"expected_harvest_date"`
+	ActualHarvestDate   *time.Time     `json:"actual_harvest_date"`
+	Status              string         `json:"status" gorm:"type:varchar(20);not null;default:'planned';index"`
+	YieldQuantity       *float64       `json:"yield_quantity" gorm:"type:decimal(15,4)"`
+	YieldUnit           *string        `json:"yield_unit" gorm:"type:varchar(50)"`
+	Note                *string        `json:"note" gorm:"type:text"`
+	Version             int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (s *Season) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// EndDate is the actual harvest date, or now while the season is still running
+func (s *Season) EndDate() time.Time {
+	if s.ActualHarvestDate != nil {
+		return *s.ActualHarvestDate
+	}
+	return time.Now()
+}
@@ -0,0 +1,481 @@
+package seasons
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/activities"
+	"plantheon-backend/models/diseases"
+	"plantheon-backend/models/plots"
+	"plantheon-backend/models/units"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateSeasonHandler handles season creation on one of the current user's plots
+func CreateSeasonHandler(c *gin.Context) {
+	var req CreateSeasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateSeasonRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if _, err := plots.GetUserPlot(req.PlotID, userID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Plot not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get plot",
+		})
+		return
+	}
+
+	plantName, ok := lookupPlantName(c, req.PlantName)
+	if !ok {
+		return
+	}
+
+	season := &Season{
+		UserID:              userID,
+		PlotID:              req.PlotID,
+		Name:                req.Name,
+		PlantName:           plantName,
+		SowingDate:          req.SowingDate,
+		ExpectedHarvestDate: req.ExpectedHarvestDate,
+		Status:              req.Status,
+		Note:                req.Note,
+	}
+	if season.Name == "" {
+		season.Name = fmt.Sprintf("%s %d", plantName, req.SowingDate.Year())
+	}
+	if season.Status == "" {
+		season.Status = StatusPlanned
+		if !req.SowingDate.After(time.Now()) {
+			season.Status = StatusGrowing
+		}
+	}
+	applyStatusDates(season)
+
+	if err := ValidateSeasonDates(season); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := CreateSeasonRecord(season); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create season",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Season created successfully",
+		"data":    season.ToSeasonResponse(),
+	})
+}
+
+// GetSeasonsHandler handles listing the current user's seasons
+// Query: GET /api/v1/seasons?plot_id=...&status=growing&plant_name=Corn
+func GetSeasonsHandler(c *gin.Context) {
+	status := c.Query("status")
+	if status != "" && !IsValidStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid status",
+		})
+		return
+	}
+
+	seasons, err := GetSeasonsByUser(c.GetString("user_id"), c.Query("plot_id"), status, c.Query("plant_name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get seasons",
+		})
+		return
+	}
+
+	response := make([]SeasonResponse, len(seasons))
+	for i, s := range seasons {
+		response[i] = s.ToSeasonResponse()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"seasons": response,
+			"count":   len(response),
+		},
+	})
+}
+
+// GetSeasonHandler handles getting one of the current user's seasons
+func GetSeasonHandler(c *gin.Context) {
+	season, ok := loadUserSeason(c)
+	if !ok {
+		return
+	}
+
+	c.Header("ETag", common.ETag(season.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"data": season.ToSeasonResponse(),
+	})
+}
+
+// UpdateSeasonHandler handles season update
+func UpdateSeasonHandler(c *gin.Context) {
+	season, ok := loadUserSeason(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateSeasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateUpdateSeasonRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Reject the update if the client edited an outdated version
+	precondition, err := common.ParsePrecondition(c, req.Version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(season.Version) {
+		respondSeasonConflict(c, precondition, season)
+		return
+	}
+
+	// Update season fields if provided
+	if req.Name != nil {
+		season.Name = *req.Name
+	}
+	if req.PlantName != nil {
+		plantName, ok := lookupPlantName(c, *req.PlantName)
+		if !ok {
+			return
+		}
+		season.PlantName = plantName
+	}
+	if req.SowingDate != nil {
+		season.SowingDate = *req.SowingDate
+	}
+	if req.ExpectedHarvestDate != nil {
+		season.ExpectedHarvestDate = req.ExpectedHarvestDate
+	}
+	if req.ActualHarvestDate != nil {
+		season.ActualHarvestDate = req.ActualHarvestDate
+	}
+	if req.Status != nil {
+		season.Status = *req.Status
+	}
+	if req.YieldQuantity != nil {
+		season.YieldQuantity = req.YieldQuantity
+	}
+	if req.YieldUnit != nil {
+		season.YieldUnit = req.YieldUnit
+	}
+	if req.Note != nil {
+		season.Note = req.Note
+	}
+	applyStatusDates(season)
+
+	if err := ValidateSeasonDates(season); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := UpdateSeason(season); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetSeasonByID(season.ID); err == nil {
+				respondSeasonConflict(c, precondition, current)
+				return
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update season",
+		})
+		return
+	}
+
+	c.Header("ETag", common.ETag(season.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Season updated successfully",
+		"data":    season.ToSeasonResponse(),
+	})
+}
+
+// DeleteSeasonHandler handles season deletion
+func DeleteSeasonHandler(c *gin.Context) {
+	season, ok := loadUserSeason(c)
+	if !ok {
+		return
+	}
+
+	if err := DeleteSeason(season.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete season",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Season deleted successfully",
+	})
+}
+
+// GetSeasonActivitiesHandler lists the activities linked to a season
+func GetSeasonActivitiesHandler(c *gin.Context) {
+	season, ok := loadUserSeason(c)
+	if !ok {
+		return
+	}
+
+	acts, err := activities.GetActivitiesBySeason(season.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activities",
+		})
+		return
+	}
+
+	response := make([]activities.ActivityResponse, len(acts))
+	for i, a := range acts {
+		response[i] = a.ToActivityResponse()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"activities": response,
+			"count":      len(response),
+		},
+	})
+}
+
+// GetSeasonDashboardHandler aggregates money, labour, inputs, diseases and yield of a season
+// Query: GET /api/v1/seasons/:id/dashboard?unit=kg&area_unit=sao
+func GetSeasonDashboardHandler(c *gin.Context) {
+	season, ok := loadUserSeason(c)
+	if !ok {
+		return
+	}
+
+	reportUnits, ok := units.ParseReportUnits(c)
+	if !ok {
+		return
+	}
+
+	plot, err := plots.GetPlotByID(season.PlotID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get plot",
+		})
+		return
+	}
+
+	totals, err := activities.GetMoneyByTypeForSeason(season.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get season costs",
+		})
+		return
+	}
+
+	inputs, err := activities.GetInputsUsedForSeason(season.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get season inputs",
+		})
+		return
+	}
+
+	// Diseases are not linked to seasons, take those diagnosed on the plot while the crop was in the ground
+	end := season.EndDate()
+	diagnosed, err := plots.GetDiagnosisCountsByPlot(season.PlotID, season.SowingDate, end.Add(time.Nanosecond))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get diagnoses",
+		})
+		return
+	}
+
+	response := SeasonDashboardResponse{
+		Season:   season.ToSeasonResponse(),
+		Plot:     plot.ToPlotResponse(),
+		Days:     int(math.Max(0, end.Sub(season.SowingDate).Hours()/24)),
+		Inputs:   convertInputs(inputs, reportUnits),
+		Diseases: diagnosed,
+		Money:    SeasonMoney{ByType: totals},
+		Yield: SeasonYield{
+			Quantity: season.YieldQuantity,
+			Unit:     season.YieldUnit,
+		},
+	}
+	if season.YieldQuantity != nil && season.YieldUnit != nil {
+		quantity, unit := reportUnits.Quantity(*season.YieldQuantity, *season.YieldUnit)
+		response.Yield.Quantity = &quantity
+		response.Yield.Unit = &unit
+	}
+	area, areaUnit := reportUnits.Area(plot.Area, plot.AreaUnit)
+	response.Yield.AreaUnit = areaUnit
+	for _, total := range totals {
+		if total.Type == activities.TypeIncome {
+			response.Money.TotalIncome += total.Total
+		} else {
+			response.Money.TotalCost += total.Total
+		}
+		if total.Type == activities.TypeLabor {
+			response.Labour = SeasonLabour{Count: total.Count, Money: total.Total}
+		}
+	}
+	response.Money.Balance = response.Money.TotalIncome - response.Money.TotalCost
+
+	if yield := response.Yield.Quantity; yield != nil && *yield > 0 {
+		if area != nil && *area > 0 {
+			perArea := *yield / *area
+			response.Yield.PerArea = &perArea
+		}
+		costPerUnit := response.Money.TotalCost / *yield
+		response.Yield.CostPerUnit = &costPerUnit
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// convertInputs expresses the inputs used in the requested unit, merging materials
+// entered in different units once they are converted to the same one
+func convertInputs(inputs []activities.InputUsage, reportUnits *units.ReportUnits) []activities.InputUsage {
+	if reportUnits.Unit == "" {
+		return inputs
+	}
+
+	converted := make([]activities.InputUsage, 0, len(inputs))
+	for _, input := range inputs {
+		if input.Unit != nil {
+			amount, unit := reportUnits.Quantity(input.Amount, *input.Unit)
+			input.Amount = amount
+			input.Unit = &unit
+		}
+
+		merged := false
+		for i := range converted {
+			if strings.EqualFold(converted[i].Object, input.Object) && sameUnit(converted[i].Unit, input.Unit) {
+				converted[i].Amount += input.Amount
+				converted[i].Money += input.Money
+				converted[i].Count += input.Count
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			converted = append(converted, input)
+		}
+	}
+	return converted
+}
+
+// sameUnit compares optional units
+func sameUnit(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// applyStatusDates records the harvest date when a season is marked harvested
+func applyStatusDates(season *Season) {
+	if season.Status == StatusHarvested && season.ActualHarvestDate == nil {
+		now := time.Now()
+		season.ActualHarvestDate = &now
+	}
+}
+
+// lookupPlantName resolves a plant name against the disease catalog,
+// writing the error response and returning false otherwise
+func lookupPlantName(c *gin.Context, name string) (string, bool) {
+	plantName, err := diseases.FindPlantName(name)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Unknown plant name, see GET /api/v1/diseases/plants",
+			})
+			return "", false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get plant",
+		})
+		return "", false
+	}
+	return plantName, true
+}
+
+// loadUserSeason loads the season from the :id param if the current user owns it,
+// writing the error response and returning false otherwise
+func loadUserSeason(c *gin.Context) (*Season, bool) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Season ID is required",
+		})
+		return nil, false
+	}
+
+	season, err := GetUserSeason(id, c.GetString("user_id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Season not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get season",
+		})
+		return nil, false
+	}
+	return season, true
+}
+
+// respondSeasonConflict returns the current representation so the client can merge and retry
+func respondSeasonConflict(c *gin.Context, precondition *common.Precondition, current *Season) {
+	c.Header("ETag", common.ETag(current.Version))
+	c.JSON(precondition.ConflictStatus(), gin.H{
+		"error": "Season was modified by someone else",
+		"data":  current.ToSeasonResponse(),
+	})
+}
@@ -0,0 +1,106 @@
+package seasons
+
+import (
+	"time"
+
+	"plantheon-backend/models/activities"
+	"plantheon-backend/models/plots"
+)
+
+// SeasonResponse represents season response
+type SeasonResponse struct {
+	ID                  string     `json:"id"`
+	PlotID              string     `json:"plot_id"`
+	Name                string     `json:"name"`
+	PlantName           string     `json:"plant_name"`
+	SowingDate          time.Time  `json:"sowing_date"`
+	ExpectedHarvestDate *time.Time `json:"expected_harvest_date"`
+	ActualHarvestDate   *time.Time `json:"actual_harvest_date"`
+	Status              string     `json:"status"`
+	YieldQuantity       *float64   `json:"yield_quantity"`
+	YieldUnit           *string    `json:"yield_unit"`
+	Note                *string    `json:"note"`
+	Version             int        `json:"version"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// CreateSeasonRequest represents season creation request
+type CreateSeasonRequest struct {
+	PlotID              string     `json:"plot_id" binding:"required"`
+	Name                string     `json:"name"` // Defaults to "<plant> <sowing year>"
+	PlantName           string     `json:"plant_name" binding:"required"`
+	SowingDate          time.Time  `json:"sowing_date" binding:"required"`
+	ExpectedHarvestDate *time.Time `json:"expected_harvest_date"`
+	Status              string     `json:"status"`
+	Note                *string    `json:"note"`
+}
+
+// UpdateSeasonRequest represents season update request
+type UpdateSeasonRequest struct {
+	Name                *string    `json:"name"`
+	PlantName           *string    `json:"plant_name"`
+	SowingDate          *time.Time `json:"sowing_date"`
+	ExpectedHarvestDate *time.Time `json:"expected_harvest_date"`
+	ActualHarvestDate   *time.Time `json:"actual_harvest_date"`
+	Status              *string    `json:"status"`
+	YieldQuantity       *float64   `json:"yield_quantity"`
+	YieldUnit           *string    `json:"yield_unit"`
+	Note                *string    `json:"note"`
+	Version             *int       `json:"version"` // Expected version when If-Match is not sent
+}
+
+// SeasonMoney represents money totals of a season's activities
+type SeasonMoney struct {
+	TotalCost   float64                  `json:"total_cost"`
+	TotalIncome float64                  `json:"total_income"`
+	Balance     float64                  `json:"balance"`
+	ByType      []activities.MoneyByType `json:"by_type"`
+}
+
+// SeasonLabour represents the labour activities of a season
+type SeasonLabour struct {
+	Count int64   `json:"count"`
+	Money float64 `json:"money"`
+}
+
+// SeasonYield represents the harvest of a season
+type SeasonYield struct {
+	Quantity    *float64 `json:"quantity"`
+	Unit        *string  `json:"unit"`
+	PerArea     *float64 `json:"per_area"` // Quantity divided by the plot area
+	AreaUnit    string   `json:"area_unit"`
+	CostPerUnit *float64 `json:"cost_per_unit"`
+}
+
+// SeasonDashboardResponse aggregates what a season cost and yielded
+type SeasonDashboardResponse struct {
+	Season   SeasonResponse          `json:"season"`
+	Plot     plots.PlotResponse      `json:"plot"`
+	Days     int                     `json:"days"` // From sowing to harvest, or to today
+	Money    SeasonMoney             `json:"money"`
+	Labour   SeasonLabour            `json:"labour"`
+	Inputs   []activities.InputUsage `json:"inputs"`
+	Diseases []plots.DiagnosisCount  `json:"diseases"`
+	Yield    SeasonYield             `json:"yield"`
+}
+
+// ToSeasonResponse converts Season model to SeasonResponse
+func (s *Season) ToSeasonResponse() SeasonResponse {
+	return SeasonResponse{
+		ID:                  s.ID,
+		PlotID:              s.PlotID,
+		Name:                s.Name,
+		PlantName:           s.PlantName,
+		SowingDate:          s.SowingDate,
+		ExpectedHarvestDate: s.ExpectedHarvestDate,
+		ActualHarvestDate:   s.ActualHarvestDate,
+		Status:              s.Status,
+		YieldQuantity:       s.YieldQuantity,
+		YieldUnit:           s.YieldUnit,
+		Note:                s.Note,
+		Version:             s.Version,
+		CreatedAt:           s.CreatedAt,
+		UpdatedAt:           s.UpdatedAt,
+	}
+}
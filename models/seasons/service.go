@@ -0,0 +1,110 @@
+package seasons
+
+import (
+	"time"
+
+	"plantheon-backend/common"
+
+	"gorm.io/gorm"
+)
+
+// SeasonService handles all database operations for crop seasons
+type SeasonService struct {
+	db *gorm.DB
+}
+
+// NewSeasonService creates a new season service instance
+func NewSeasonService() *SeasonService {
+	return &SeasonService{
+		db: common.GetDB(),
+	}
+}
+
+// CreateSeasonRecord creates a new season
+func CreateSeasonRecord(season *Season) error {
+	service := NewSeasonService()
+	return service.db.Create(season).Error
+}
+
+// GetSeasonByID finds season by ID
+func GetSeasonByID(id string) (*Season, error) {
+	service := NewSeasonService()
+	var season Season
+	err := service.db.Where("id = ?", id).First(&season).Error
+	return &season, err
+}
+
+// GetUserSeason finds a season owned by the user
+func GetUserSeason(id, userID string) (*Season, error) {
+	service := NewSeasonService()
+	var season Season
+	err := service.db.Where("id = ? AND user_id = ?", id, userID).First(&season).Error
+	return &season, err
+}
+
+// GetSeasonsByUser lists the user's seasons, newest sowing first, optionally filtered by plot, status and plant
+func GetSeasonsByUser(userID, plotID, status, plantName string) ([]Season, error) {
+	service := NewSeasonService()
+	var seasons []Season
+
+	query := service.db.Where("user_id = ?", userID)
+	if plotID != "" {
+		query = query.Where("plot_id = ?", plotID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if plantName != "" {
+		query = query.Where("LOWER(plant_name) = LOWER(?)", plantName)
+	}
+
+	err := query.Order("sowing_date DESC").Find(&seasons).Error
+	return seasons, err
+}
+
+// UpdateSeason updates season information.
+// The update only applies if the stored version still equals season.Version,
+// otherwise common.ErrVersionConflict is returned. On success the version is incremented.
+func UpdateSeason(season *Season) error {
+	service := NewSeasonService()
+	expected := season.Version
+	season.Version = expected + 1
+
+	result := service.db.Model(season).
+		Where("version = ?", expected).
+		Select("*").Omit("id", "created_at").
+		Updates(season)
+	if result.Error != nil {
+		season.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		season.Version = expected
+		return common.ErrVersionConflict
+	}
+	return nil
+}
+
+// SetSeasonYield stores the harvested quantity of a season and bumps its version
+func SetSeasonYield(id string, quantity *float64, unit *string) error {
+	service := NewSeasonService()
+	return service.db.Model(&Season{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"yield_quantity": quantity,
+			"yield_unit":     unit,
+			"version":        gorm.Expr("version + 1"),
+			"updated_at":     time.Now(),
+		}).Error
+}
+
+// DeleteSeason soft-deletes season by ID, its activities are kept but unlinked
+func DeleteSeason(id string) error {
+	service := NewSeasonService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("activities").Where("season_id = ?", id).
+			Update("season_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&Season{}).Error
+	})
+}
@@ -0,0 +1,115 @@
+package seasons
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ValidStatuses lists the season statuses, in lifecycle order
+var ValidStatuses = []string{StatusPlanned, StatusGrowing, StatusHarvested, StatusFailed}
+
+// IsValidStatus checks if the status is a known season status
+func IsValidStatus(status string) bool {
+	for _, s := range ValidStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateCreateSeasonRequest validates season creation request
+func ValidateCreateSeasonRequest(req *CreateSeasonRequest) error {
+	if _, err := uuid.Parse(req.PlotID); err != nil {
+		return errors.New("plot_id must be a valid UUID")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if len(req.Name) > 255 {
+		return errors.New("season name must be less than 255 characters")
+	}
+
+	req.PlantName = strings.TrimSpace(req.PlantName)
+	if req.PlantName == "" {
+		return errors.New("plant_name is required")
+	}
+
+	if req.SowingDate.IsZero() {
+		return errors.New("sowing_date is required")
+	}
+
+	if req.ExpectedHarvestDate != nil && req.ExpectedHarvestDate.Before(req.SowingDate) {
+		return errors.New("expected_harvest_date must be after sowing_date")
+	}
+
+	if req.Status != "" && !IsValidStatus(req.Status) {
+		return errors.New("status must be one of: " + strings.Join(ValidStatuses, ", "))
+	}
+
+	if req.Note != nil && len(*req.Note) > 1000 {
+		return errors.New("note must be less than 1000 characters")
+	}
+
+	return nil
+}
+
+// ValidateUpdateSeasonRequest validates season update request
+func ValidateUpdateSeasonRequest(req *UpdateSeasonRequest) error {
+	if req.Name != nil {
+		*req.Name = strings.TrimSpace(*req.Name)
+		if *req.Name == "" {
+			return errors.New("season name cannot be empty")
+		}
+		if len(*req.Name) > 255 {
+			return errors.New("season name must be less than 255 characters")
+		}
+	}
+
+	if req.PlantName != nil {
+		*req.PlantName = strings.TrimSpace(*req.PlantName)
+		if *req.PlantName == "" {
+			return errors.New("plant_name cannot be empty")
+		}
+	}
+
+	if req.SowingDate != nil && req.SowingDate.IsZero() {
+		return errors.New("sowing_date cannot be empty")
+	}
+
+	if req.Status != nil && !IsValidStatus(*req.Status) {
+		return errors.New("status must be one of: " + strings.Join(ValidStatuses, ", "))
+	}
+
+	if req.YieldQuantity != nil && *req.YieldQuantity < 0 {
+		return errors.New("yield_quantity must be non-negative")
+	}
+
+	if req.YieldUnit != nil && len(*req.YieldUnit) > 50 {
+		return errors.New("yield_unit must be less than 50 characters")
+	}
+
+	if req.Note != nil && len(*req.Note) > 1000 {
+		return errors.New("note must be less than 1000 characters")
+	}
+
+	return nil
+}
+
+// ValidateSeasonDates checks the dates of a season are in order once all changes are applied
+func ValidateSeasonDates(season *Season) error {
+	if season.ExpectedHarvestDate != nil && season.ExpectedHarvestDate.Before(season.SowingDate) {
+		return errors.New("expected_harvest_date must be after sowing_date")
+	}
+	if season.ActualHarvestDate != nil {
+		if season.ActualHarvestDate.Before(season.SowingDate) {
+			return errors.New("actual_harvest_date must be after sowing_date")
+		}
+		if season.ActualHarvestDate.After(time.Now()) {
+			return errors.New("actual_harvest_date cannot be in the future")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package sync
+
+import (
+	"time"
+
+	"plantheon-backend/common"
+)
+
+// Operations a client can push for a record
+const (
+	OpUpsert = "upsert"
+	OpDelete = "delete"
+)
+
+// Outcomes of a pushed change
+const (
+	StatusCreated  = "created"
+	StatusUpdated  = "updated"
+	StatusDeleted  = "deleted"
+	StatusConflict = "conflict" // the server copy wins, it is returned with the result
+	StatusRejected = "rejected" // the change is invalid and was not applied
+	StatusError    = "error"
+)
+
+// Reasons of a conflict
+const (
+	ReasonVersionMismatch = "version_mismatch" // the record changed on the server since the client's version
+	ReasonDeletedOnServer = "deleted_on_server"
+)
+
+// Record types that can be synced
+const (
+	EntityActivity = "activity"
+	EntityPlot     = "plot"
+	EntityDisease  = "disease"
+)
+
+// Change is a client-side create, update or delete of one record.
+// New records carry the UUID generated by the client. Version is the server version
+// the client edited, it must match for updates and deletes of existing records.
+// Data is the full record for creates and a JSON merge patch for updates.
+type Change struct {
+	Op      string            `json:"op"`
+	ID      string            `json:"id"`
+	Version *int              `json:"version"`
+	Data    common.MergePatch `json:"data"`
+}
+
+// Tombstone marks a record deleted on the server
+type Tombstone struct {
+	ID        string    `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
@@ -0,0 +1,150 @@
+package sync
+
+import (
+	"net/http"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/activities"
+	"plantheon-backend/models/diseases"
+	"plantheon-backend/models/plots"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PullHandler returns the changes to the activities and plots of the tenant, the user's own or
+// those of the organization picked with X-Organization-ID, and to the disease catalog since the
+// sync token, with tombstones for deletes and the token for the next pull. Tokens cover one tenant.
+// Without a token, or with one older than deletes are kept, everything is returned with reset set.
+// GET /api/v1/sync?token=...
+func PullHandler(c *gin.Context) {
+	tenant := common.CurrentTenant(c)
+
+	// Issue the next token before reading so changes saved meanwhile are pulled next time
+	issued := time.Now().UTC()
+	var since *time.Time
+	if token := c.Query("token"); token != "" {
+		tokenIssued, err := DecodeToken(token, tenant)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		since = ChangesSince(tokenIssued)
+	}
+
+	response := PullResponse{
+		SyncToken: EncodeToken(issued, tenant),
+		Reset:     since == nil,
+		Activities: ActivityChanges{
+			Updated: []activities.ActivityResponse{},
+			Deleted: []Tombstone{},
+		},
+		Plots: PlotChanges{
+			Updated: []plots.PlotResponse{},
+			Deleted: []Tombstone{},
+		},
+		Diseases: DiseaseChanges{
+			Updated: []diseases.DiseaseResponse{},
+			Deleted: []Tombstone{},
+		},
+	}
+
+	changedActivities, err := GetActivityChanges(tenant, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activity changes",
+		})
+		return
+	}
+	for _, activity := range changedActivities {
+		if activity.DeletedAt.Valid {
+			response.Activities.Deleted = append(response.Activities.Deleted, Tombstone{ID: activity.ID, DeletedAt: activity.DeletedAt.Time})
+			continue
+		}
+		response.Activities.Updated = append(response.Activities.Updated, activity.ToActivityResponse())
+	}
+
+	changedPlots, err := GetPlotChanges(tenant, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get plot changes",
+		})
+		return
+	}
+	for _, plot := range changedPlots {
+		if plot.DeletedAt.Valid {
+			response.Plots.Deleted = append(response.Plots.Deleted, Tombstone{ID: plot.ID, DeletedAt: plot.DeletedAt.Time})
+			continue
+		}
+		response.Plots.Updated = append(response.Plots.Updated, plot.ToPlotResponse())
+	}
+
+	changedDiseases, err := GetDiseaseChanges(since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get disease changes",
+		})
+		return
+	}
+	for _, disease := range changedDiseases {
+		if disease.DeletedAt.Valid {
+			response.Diseases.Deleted = append(response.Diseases.Deleted, Tombstone{ID: disease.ID, DeletedAt: disease.DeletedAt.Time})
+			continue
+		}
+		response.Diseases.Updated = append(response.Diseases.Updated, disease.ToDiseaseResponse())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// PushHandler applies a batch of client-side changes to the plots and activities of the tenant.
+// Each change is applied on its own and reported in the results, a conflict keeps the
+// server copy and returns it so the client can replace its local record.
+// POST /api/v1/sync
+func PushHandler(c *gin.Context) {
+	var req PushRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	if err := ValidatePushRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tenant := common.CurrentTenant(c)
+	response := PushResponse{Results: make([]PushResult, 0, len(req.Plots)+len(req.Activities))}
+	for i := range req.Plots {
+		response.add(ApplyPlotChange(tenant, &req.Plots[i]))
+	}
+	for i := range req.Activities {
+		response.add(ApplyActivityChange(tenant, &req.Activities[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Changes pushed",
+		"data":    response,
+	})
+}
+
+// add appends a result and counts it
+func (r *PushResponse) add(result PushResult) {
+	r.Results = append(r.Results, result)
+	switch result.Status {
+	case StatusCreated, StatusUpdated, StatusDeleted:
+		r.Applied++
+	case StatusConflict:
+		r.Conflicts++
+	default:
+		r.Rejected++
+	}
+}
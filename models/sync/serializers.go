@@ -0,0 +1,63 @@
+package sync
+
+import (
+	"plantheon-backend/models/activities"
+	"plantheon-backend/models/diseases"
+	"plantheon-backend/models/plots"
+)
+
+// PullResponse holds the changes since the client's sync token.
+// Reset tells the client to replace its local copy, the token was missing or too old for tombstones.
+type PullResponse struct {
+	SyncToken  string          `json:"sync_token"`
+	Reset      bool            `json:"reset"`
+	Activities ActivityChanges `json:"activities"`
+	Plots      PlotChanges     `json:"plots"`
+	Diseases   DiseaseChanges  `json:"diseases"`
+}
+
+// ActivityChanges are the user's created or updated and deleted activities
+type ActivityChanges struct {
+	Updated []activities.ActivityResponse `json:"updated"`
+	Deleted []Tombstone                   `json:"deleted"`
+}
+
+// PlotChanges are the user's created or updated and deleted plots
+type PlotChanges struct {
+	Updated []plots.PlotResponse `json:"updated"`
+	Deleted []Tombstone          `json:"deleted"`
+}
+
+// DiseaseChanges are the created or updated and deleted entries of the disease catalog
+type DiseaseChanges struct {
+	Updated []diseases.DiseaseResponse `json:"updated"`
+	Deleted []Tombstone                `json:"deleted"`
+}
+
+// PushRequest is a batch of client-side changes, plots are applied before activities
+// so new activities can reference plots created in the same batch
+type PushRequest struct {
+	Plots      []Change `json:"plots"`
+	Activities []Change `json:"activities"`
+}
+
+// PushResult reports what happened to one pushed change.
+// Server holds the current server copy after a conflict, nil if it was deleted.
+type PushResult struct {
+	Entity  string      `json:"entity"`
+	ID      string      `json:"id"`
+	Status  string      `json:"status"`
+	Version int         `json:"version,omitempty"`
+	Reason  string      `json:"reason,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	Server  interface{} `json:"server,omitempty"`
+}
+
+// PushResponse lists the result of each change in request order,
+// Rejected counts both invalid changes and changes that failed
+type PushResponse struct {
+	Results   []PushResult `json:"results"`
+	Applied   int          `json:"applied"`
+	Conflicts int          `json:"conflicts"`
+	Rejected  int          `json:"rejected"`
+}
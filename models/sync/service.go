@@ -0,0 +1,403 @@
+package sync
+
+import (
+	"encoding/base64"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/activities"
+	"plantheon-backend/models/diseases"
+	"plantheon-backend/models/inventory"
+	"plantheon-backend/models/plots"
+	"plantheon-backend/models/units"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidToken is returned when a sync token was not issued by the server
+var ErrInvalidToken = errors.New("invalid sync token")
+
+// tokenOverlap re-sends changes saved shortly before a token was issued, since a slower
+// transaction can commit a row with an earlier timestamp. Clients apply changes by version
+// so receiving a record twice is harmless.
+const tokenOverlap = 5 * time.Second
+
+// tombstoneRetention is how long deleted records are kept before they are purged,
+// older tokens may miss deletes so the client has to reset. Zero keeps them forever.
+var tombstoneRetention time.Duration
+
+// SetTombstoneRetention sets how long deleted records are kept, see StartTrashPurge of diseases and activities
+func SetTombstoneRetention(retention time.Duration) {
+	tombstoneRetention = retention
+}
+
+// SyncService handles all database operations for sync
+type SyncService struct {
+	db *gorm.DB
+}
+
+// NewSyncService creates a new sync service instance
+func NewSyncService() *SyncService {
+	return &SyncService{
+		db: common.GetDB(),
+	}
+}
+
+// ErrTokenOfOtherTenant is returned when a sync token was issued for another organization,
+// or for the user's own data. Clients keep one token per organization.
+var ErrTokenOfOtherTenant = errors.New("sync token was issued for another organization")
+
+// EncodeToken creates the opaque sync token for changes of the tenant up to t
+func EncodeToken(t time.Time, tenant common.Tenant) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("v2:" + tenant.OrganizationID + ":" + strconv.FormatInt(t.UnixMicro(), 10)))
+}
+
+// DecodeToken reads the time a sync token of the tenant was issued.
+// Tokens issued before organizations were synced cover the user's own data.
+func DecodeToken(token string, tenant common.Tenant) (time.Time, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return time.Time{}, ErrInvalidToken
+	}
+	var organizationID, value string
+	switch parts := strings.Split(string(data), ":"); {
+	case len(parts) == 2 && parts[0] == "v1":
+		value = parts[1]
+	case len(parts) == 3 && parts[0] == "v2":
+		organizationID, value = parts[1], parts[2]
+	default:
+		return time.Time{}, ErrInvalidToken
+	}
+	micros, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, ErrInvalidToken
+	}
+	if organizationID != tenant.OrganizationID {
+		return time.Time{}, ErrTokenOfOtherTenant
+	}
+	issued := time.UnixMicro(micros).UTC()
+	if issued.After(time.Now().Add(time.Minute)) {
+		return time.Time{}, ErrInvalidToken
+	}
+	return issued, nil
+}
+
+// ChangesSince returns the time changes must be read from for a token issued at issued,
+// nil when the client has to reset because deletes since then may have been purged
+func ChangesSince(issued time.Time) *time.Time {
+	if tombstoneRetention > 0 && time.Since(issued) > tombstoneRetention {
+		return nil
+	}
+	since := issued.Add(-tokenOverlap)
+	return &since
+}
+
+// changedSince limits a query to rows created, updated or deleted since the time,
+// deleted rows are only included when since is set
+func changedSince(query *gorm.DB, since *time.Time) *gorm.DB {
+	if since == nil {
+		return query
+	}
+	return query.Unscoped().Where("updated_at >= ? OR deleted_at >= ?", *since, *since)
+}
+
+// GetActivityChanges gets the activities of the tenant changed since the time, all of them when nil
+func GetActivityChanges(tenant common.Tenant, since *time.Time) ([]activities.Activity, error) {
+	service := NewSyncService()
+	var changed []activities.Activity
+	err := changedSince(tenant.Scope(service.db), since).Order("updated_at ASC").Find(&changed).Error
+	return changed, err
+}
+
+// GetPlotChanges gets the plots of the tenant changed since the time, all of them when nil
+func GetPlotChanges(tenant common.Tenant, since *time.Time) ([]plots.Plot, error) {
+	service := NewSyncService()
+	var changed []plots.Plot
+	err := changedSince(tenant.Scope(service.db), since).Order("updated_at ASC").Find(&changed).Error
+	return changed, err
+}
+
+// GetDiseaseChanges gets the catalog entries changed since the time, the whole catalog when nil
+func GetDiseaseChanges(since *time.Time) ([]diseases.Disease, error) {
+	service := NewSyncService()
+	var changed []diseases.Disease
+	err := changedSince(service.db, since).Order("updated_at ASC").Find(&changed).Error
+	return changed, err
+}
+
+// ApplyPlotChange applies one pushed plot change in the tenant, plots of an organization
+// are managed by its managers. Conflicts are resolved in favor of the server copy.
+func ApplyPlotChange(tenant common.Tenant, change *Change) PushResult {
+	service := NewSyncService()
+	result := PushResult{Entity: EntityPlot, ID: change.ID}
+	if !tenant.Allows(common.OrgRoleManager) {
+		return rejected(result, "managing plots requires the manager role in the organization")
+	}
+
+	var plot plots.Plot
+	err := service.db.Unscoped().Where("id = ?", change.ID).First(&plot).Error
+	if err == gorm.ErrRecordNotFound {
+		if change.Op == OpDelete {
+			// Created and deleted offline, or already purged
+			result.Status = StatusDeleted
+			return result
+		}
+		return createPlot(tenant, change, result)
+	}
+	if err != nil {
+		return failed(result, err)
+	}
+	if !tenant.Owns(plot.UserID, plot.OrganizationID) {
+		return rejected(result, "id is already used by another record")
+	}
+	if plot.DeletedAt.Valid {
+		if change.Op == OpDelete {
+			result.Status = StatusDeleted
+			return result
+		}
+		return conflict(result, ReasonDeletedOnServer, 0, nil)
+	}
+	if change.Version == nil || *change.Version != plot.Version {
+		return conflict(result, ReasonVersionMismatch, plot.Version, plot.ToPlotResponse())
+	}
+
+	if change.Op == OpDelete {
+		deleted := service.db.Where("id = ? AND version = ?", plot.ID, plot.Version).Delete(&plots.Plot{})
+		if deleted.Error != nil {
+			return failed(result, deleted.Error)
+		}
+		if deleted.RowsAffected == 0 {
+			return plotConflict(result, plot.ID)
+		}
+		result.Status = StatusDeleted
+		return result
+	}
+
+	if err := plots.ApplyPlotPatch(&plot, change.Data); err != nil {
+		return rejected(result, err.Error())
+	}
+	if change.Data.Has("area_unit") {
+		if err := units.NormalizeUnit(tenant.UserID, &plot.AreaUnit, units.DimensionArea); err != nil {
+			return unitRejected(result, err)
+		}
+	}
+	if err := plots.UpdatePlot(&plot); err != nil {
+		if err == common.ErrVersionConflict {
+			return plotConflict(result, plot.ID)
+		}
+		return failed(result, err)
+	}
+	result.Status = StatusUpdated
+	result.Version = plot.Version
+	return result
+}
+
+// createPlot creates a plot pushed by the client with the client's UUID
+func createPlot(tenant common.Tenant, change *Change, result PushResult) PushResult {
+	var req plots.CreatePlotRequest
+	if err := change.Data.Decode(&req); err != nil {
+		return rejected(result, "invalid field type in data")
+	}
+	if err := plots.ValidateCreatePlotRequest(&req); err != nil {
+		return rejected(result, err.Error())
+	}
+	if err := units.NormalizeUnit(tenant.UserID, &req.AreaUnit, units.DimensionArea); err != nil {
+		return unitRejected(result, err)
+	}
+
+	plot, err := req.ToPlot(tenant)
+	if err != nil {
+		return rejected(result, err.Error())
+	}
+	plot.ID = change.ID
+	if err := plots.CreatePlotRecord(plot); err != nil {
+		return failed(result, err)
+	}
+	result.Status = StatusCreated
+	result.Version = plot.Version
+	return result
+}
+
+// plotConflict reports a plot changed by someone else between our read and write
+func plotConflict(result PushResult, id string) PushResult {
+	current, err := plots.GetPlotByID(id)
+	if err == gorm.ErrRecordNotFound {
+		return conflict(result, ReasonDeletedOnServer, 0, nil)
+	}
+	if err != nil {
+		return failed(result, err)
+	}
+	return conflict(result, ReasonVersionMismatch, current.Version, current.ToPlotResponse())
+}
+
+// ApplyActivityChange applies one pushed activity change in the tenant.
+// Conflicts are resolved in favor of the server copy.
+func ApplyActivityChange(tenant common.Tenant, change *Change) PushResult {
+	service := NewSyncService()
+	result := PushResult{Entity: EntityActivity, ID: change.ID}
+
+	var activity activities.Activity
+	err := service.db.Unscoped().Where("id = ?", change.ID).First(&activity).Error
+	if err == gorm.ErrRecordNotFound {
+		if change.Op == OpDelete {
+			// Created and deleted offline, or already purged
+			result.Status = StatusDeleted
+			return result
+		}
+		return createActivity(tenant, change, result)
+	}
+	if err != nil {
+		return failed(result, err)
+	}
+	if activity.UserID == nil || !tenant.Owns(*activity.UserID, activity.OrganizationID) {
+		return rejected(result, "id is already used by another record")
+	}
+	if activity.DeletedAt.Valid {
+		if change.Op == OpDelete {
+			result.Status = StatusDeleted
+			return result
+		}
+		return conflict(result, ReasonDeletedOnServer, 0, nil)
+	}
+	if change.Version == nil || *change.Version != activity.Version {
+		return conflict(result, ReasonVersionMismatch, activity.Version, activity.ToActivityResponse())
+	}
+
+	if change.Op == OpDelete {
+		paid, err := activities.GetPaymentActivityIDs([]string{activity.ID})
+		if err != nil {
+			return failed(result, err)
+		}
+		if paid[activity.ID] {
+			return rejected(result, activities.ErrPaymentActivity.Error())
+		}
+		// The material of the activity goes back to stock with it
+		var deleted int64
+		err = service.db.Transaction(func(tx *gorm.DB) error {
+			trashed := tx.Where("id = ? AND version = ?", activity.ID, activity.Version).Delete(&activities.Activity{})
+			if trashed.Error != nil || trashed.RowsAffected == 0 {
+				return trashed.Error
+			}
+			deleted = trashed.RowsAffected
+			return inventory.RemoveActivityConsumption(tx, []string{activity.ID})
+		})
+		if err != nil {
+			return failed(result, err)
+		}
+		if deleted == 0 {
+			return activityConflict(result, activity.ID)
+		}
+		result.Status = StatusDeleted
+		return result
+	}
+
+	if err := activities.ApplyActivityPatch(&activity, change.Data); err != nil {
+		return rejected(result, err.Error())
+	}
+	if activities.LinksChanged(change.Data.Has) {
+		if err := activities.ResolveActivityLinks(&activity, tenant.UserID); err != nil {
+			return linkRejected(result, err)
+		}
+	}
+	if change.Data.Has("unit") {
+		if err := units.NormalizeUnit(tenant.UserID, activity.Unit, ""); err != nil {
+			return unitRejected(result, err)
+		}
+	}
+	if err := activities.UpdateActivity(&activity); err != nil {
+		if err == common.ErrVersionConflict {
+			return activityConflict(result, activity.ID)
+		}
+		return failed(result, err)
+	}
+	result.Status = StatusUpdated
+	result.Version = activity.Version
+	return result
+}
+
+// createActivity creates an activity pushed by the client with the client's UUID
+func createActivity(tenant common.Tenant, change *Change, result PushResult) PushResult {
+	var req activities.CreateActivityRequest
+	if err := change.Data.Decode(&req); err != nil {
+		return rejected(result, "invalid field type in data")
+	}
+	if err := activities.ValidateCreateActivityRequest(&req); err != nil {
+		return rejected(result, err.Error())
+	}
+	if err := units.NormalizeUnit(tenant.UserID, req.Unit, ""); err != nil {
+		return unitRejected(result, err)
+	}
+
+	activity := req.ToActivity()
+	activity.ID = change.ID
+	activity.UserID = &tenant.UserID
+	activity.OrganizationID = tenant.OrganizationRef()
+	if err := activities.ResolveActivityLinks(activity, tenant.UserID); err != nil {
+		return linkRejected(result, err)
+	}
+	if err := activities.CreateActivityRecord(activity); err != nil {
+		return failed(result, err)
+	}
+	activities.RecordInventoryConsumption(activity)
+
+	result.Status = StatusCreated
+	result.Version = activity.Version
+	return result
+}
+
+// activityConflict reports an activity changed by someone else between our read and write
+func activityConflict(result PushResult, id string) PushResult {
+	current, err := activities.GetActivityByID(id)
+	if err == gorm.ErrRecordNotFound {
+		return conflict(result, ReasonDeletedOnServer, 0, nil)
+	}
+	if err != nil {
+		return failed(result, err)
+	}
+	return conflict(result, ReasonVersionMismatch, current.Version, current.ToActivityResponse())
+}
+
+// conflict reports the server copy won, server is nil when it was deleted
+func conflict(result PushResult, reason string, version int, server interface{}) PushResult {
+	result.Status = StatusConflict
+	result.Reason = reason
+	result.Version = version
+	result.Server = server
+	return result
+}
+
+// rejected reports a change that was not applied because it is invalid
+func rejected(result PushResult, message string) PushResult {
+	result.Status = StatusRejected
+	result.Error = message
+	return result
+}
+
+// unitRejected reports an unknown or incompatible unit, hiding database errors
+func unitRejected(result PushResult, err error) PushResult {
+	if !errors.Is(err, units.ErrUnknownUnit) && !errors.Is(err, units.ErrIncompatibleUnits) {
+		return failed(result, err)
+	}
+	return rejected(result, err.Error())
+}
+
+// linkRejected reports a plot, season or contact the user does not own, hiding database errors
+func linkRejected(result PushResult, err error) PushResult {
+	switch err {
+	case activities.ErrSeasonNotFound, activities.ErrSeasonOnOtherPlot, activities.ErrPlotNotFound, activities.ErrContactNotFound:
+		return rejected(result, err.Error())
+	}
+	return failed(result, err)
+}
+
+// failed reports a database error, the client should retry the change later
+func failed(result PushResult, err error) PushResult {
+	log.Printf("Failed to apply pushed %s %s: %v", result.Entity, result.ID, err)
+	result.Status = StatusError
+	result.Error = "Failed to apply change"
+	return result
+}
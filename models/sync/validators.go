@@ -0,0 +1,55 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// MaxPushChanges is the largest batch a client can push at once
+const MaxPushChanges = 500
+
+// ValidatePushRequest validates the shape of a pushed batch, the changes themselves
+// are validated one by one when applied
+func ValidatePushRequest(req *PushRequest) error {
+	total := len(req.Plots) + len(req.Activities)
+	if total == 0 {
+		return errors.New("no changes to push")
+	}
+	if total > MaxPushChanges {
+		return fmt.Errorf("at most %d changes can be pushed at once", MaxPushChanges)
+	}
+
+	seen := make(map[string]bool, total)
+	for _, group := range []struct {
+		entity  string
+		changes []Change
+	}{{EntityPlot, req.Plots}, {EntityActivity, req.Activities}} {
+		for i, change := range group.changes {
+			if err := validateChange(&change); err != nil {
+				return fmt.Errorf("%s change %d: %v", group.entity, i, err)
+			}
+			key := group.entity + ":" + change.ID
+			if seen[key] {
+				return fmt.Errorf("%s %s is changed more than once in the batch", group.entity, change.ID)
+			}
+			seen[key] = true
+		}
+	}
+	return nil
+}
+
+// validateChange checks a change has a known operation, a UUID and data to upsert
+func validateChange(change *Change) error {
+	if change.Op != OpUpsert && change.Op != OpDelete {
+		return errors.New("op must be upsert or delete")
+	}
+	if _, err := uuid.Parse(change.ID); err != nil {
+		return errors.New("id must be a valid UUID")
+	}
+	if change.Op == OpUpsert && change.Data == nil {
+		return errors.New("data is required to upsert")
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package templates
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Template is a reusable routine of activities, e.g. the fertilizing of a season.
+// Each item is placed a number of days after the date the template is applied at.
+type Template struct {
+	ID          string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID      string         `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name        string         `json:"name" gorm:"type:varchar(255);not null"`
+	Description *string        `json:"description" gorm:"type:text"`
+	Items       []TemplateItem `json:"items" gorm:"foreignKey:TemplateID;constraint:OnDelete:CASCADE"`
+	Version     int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// ItemFields are the schedule and the activity content of a template item
+type ItemFields struct {
+	DayOffset       int      `json:"day_offset" gorm:"not null;default:0"` // Days after the start date
+	StartTime       *string  `json:"start_time" gorm:"type:varchar(5)"`    // HH:MM, all-day or untimed when empty
+	DurationMinutes *int     `json:"duration_minutes" gorm:"type:integer"` // Sets time_end after time_start
+	RepeatDays      *int     `json:"repeat_days" gorm:"type:integer"`      // end_repeat_day is this many days after the item's date
+	Day             *bool    `json:"day" gorm:"type:boolean"`
+	Type            string   `json:"type" gorm:"type:varchar(255);not null"`
+	Title           string   `json:"title" gorm:"type:varchar(255);not null"`
+	Description     *string  `json:"description" gorm:"type:text"`
+	Description2    *string  `json:"description2" gorm:"type:text"`
+	Description3    *string  `json:"description3" gorm:"type:text"`
+	Money           *float64 `json:"money" gorm:"type:decimal(15,2)"`
+	IsRepeat        *string  `json:"is_repeat" gorm:"type:varchar(50)"`
+	Repeat          *string  `json:"repeat" gorm:"type:varchar(50)"`
+	AlertTime       *string  `json:"alert_time" gorm:"type:varchar(50)"`
+	Object          *string  `json:"object" gorm:"type:varchar(255)"`
+	Amount          *int     `json:"amount" gorm:"type:integer"`
+	Unit            *string  `json:"unit" gorm:"type:varchar(50)"`
+	Purpose         *string  `json:"purpose" gorm:"type:text"`
+	TargetPerson    *string  `json:"target_person" gorm:"type:varchar(255)"`
+	SourcePerson    *string  `json:"source_person" gorm:"type:varchar(255)"`
+	AttachedLink    *string  `json:"attached_link" gorm:"type:text"`
+	Note            *string  `json:"note" gorm:"type:text"`
+}
+
+// TemplateItem is one activity of a template
+type TemplateItem struct {
+	ID         string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TemplateID string `json:"template_id" gorm:"type:uuid;not null;index"`
+	Position   int    `json:"position" gorm:"not null;default:0"`
+	ItemFields `gorm:"embedded"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (t *Template) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (i *TemplateItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package templates
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/activities"
+	"plantheon-backend/models/units"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateTemplateHandler creates a template of the current user from item definitions
+func CreateTemplateHandler(c *gin.Context) {
+	var req CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateTemplateRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	for i := range req.Items {
+		if !units.CheckUnit(c, req.Items[i].Unit, "") {
+			return
+		}
+	}
+
+	template := &Template{
+		UserID:      c.GetString("user_id"),
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	for i, item := range req.Items {
+		template.Items = append(template.Items, TemplateItem{Position: i, ItemFields: item})
+	}
+	saveTemplate(c, template)
+}
+
+// CreateTemplateFromActivitiesHandler saves some of the current user's activities as a template.
+// Day offsets are counted from the day of the earliest activity in the tz parameter,
+// the user's timezone or UTC, start times are kept as wall-clock times there.
+// POST /api/v1/templates/from-activities
+func CreateTemplateFromActivitiesHandler(c *gin.Context) {
+	var req CreateTemplateFromActivitiesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateTemplateFromActivitiesRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	loc, err := common.RequestTimezone(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	found, err := activities.GetActivitiesByIDs(req.ActivityIDs, common.CurrentTenant(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activities",
+		})
+		return
+	}
+	byID := make(map[string]*activities.Activity, len(found))
+	for i := range found {
+		if found[i].UserID != nil && *found[i].UserID == userID {
+			byID[found[i].ID] = &found[i]
+		}
+	}
+
+	// Keep the request order for activities without a start time
+	selected := make([]*activities.Activity, 0, len(req.ActivityIDs))
+	for _, id := range req.ActivityIDs {
+		activity, ok := byID[id]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Activity " + id + " not found",
+			})
+			return
+		}
+		selected = append(selected, activity)
+	}
+	sort.SliceStable(selected, func(a, b int) bool {
+		if selected[a].TimeStart == nil || selected[b].TimeStart == nil {
+			return selected[b].TimeStart == nil && selected[a].TimeStart != nil
+		}
+		return selected[a].TimeStart.Before(*selected[b].TimeStart)
+	})
+
+	var first time.Time
+	if selected[0].TimeStart != nil {
+		first = common.StartOfDay(*selected[0].TimeStart, loc)
+	}
+	template := &Template{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	for i, activity := range selected {
+		template.Items = append(template.Items, TemplateItem{Position: i, ItemFields: ItemFromActivity(activity, first, loc)})
+	}
+	saveTemplate(c, template)
+}
+
+// saveTemplate creates the template and writes the response
+func saveTemplate(c *gin.Context, template *Template) {
+	if err := CreateTemplateRecord(template); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create template",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Template created successfully",
+		"data":    template.ToTemplateResponse(),
+	})
+}
+
+// GetTemplatesHandler handles listing the current user's templates with pagination
+func GetTemplatesHandler(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		limit = 10
+	}
+
+	// Validate pagination
+	page, limit, _ = ValidatePaginationParams(page, limit)
+	offset := (page - 1) * limit
+
+	templates, total, err := GetTemplatesByUser(c.GetString("user_id"), c.Query("search"), offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get templates",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": ToTemplatesListResponse(templates, total, page, limit),
+	})
+}
+
+// GetTemplateHandler handles getting one of the current user's templates
+func GetTemplateHandler(c *gin.Context) {
+	template, ok := loadUserTemplate(c)
+	if !ok {
+		return
+	}
+
+	c.Header("ETag", common.ETag(template.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"data": template.ToTemplateResponse(),
+	})
+}
+
+// DeleteTemplateHandler handles template deletion, activities created from it are kept
+func DeleteTemplateHandler(c *gin.Context) {
+	template, ok := loadUserTemplate(c)
+	if !ok {
+		return
+	}
+
+	if err := DeleteTemplate(template.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete template",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Template deleted successfully",
+	})
+}
+
+// ApplyTemplateHandler creates the activities of a template from a start date onto a plot or season,
+// in one transaction like the bulk create endpoint (?dry_run=true previews them, ?strict=true
+// rejects schedule conflicts). Dates and start times are taken in the tz parameter,
+// the user's timezone or UTC.
+// POST /api/v1/templates/:id/apply
+func ApplyTemplateHandler(c *gin.Context) {
+	template, ok := loadUserTemplate(c)
+	if !ok {
+		return
+	}
+
+	var req ApplyTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+	if err := ValidateApplyTemplateRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	loc, err := common.RequestTimezone(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	start, _ := time.ParseInLocation(common.DateLayout, req.StartDate, loc)
+
+	requests := make([]activities.CreateActivityRequest, 0, len(template.Items))
+	for i := range template.Items {
+		activity := template.Items[i].ToActivityRequest(start, loc)
+		activity.PlotID = req.PlotID
+		activity.SeasonID = req.SeasonID
+		requests = append(requests, activity)
+	}
+	activities.RespondBulkCreate(c, requests)
+}
+
+// loadUserTemplate loads the template from the :id parameter if it belongs to the current user,
+// writing the error response and returning false otherwise
+func loadUserTemplate(c *gin.Context) (*Template, bool) {
+	template, err := GetUserTemplate(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Template not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get template",
+		})
+		return nil, false
+	}
+	return template, true
+}
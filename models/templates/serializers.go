@@ -0,0 +1,199 @@
+package templates
+
+import (
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/activities"
+)
+
+// startTimeLayout is the layout of ItemFields.StartTime
+const startTimeLayout = "15:04"
+
+// TemplateResponse represents template response
+type TemplateResponse struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Description *string                `json:"description"`
+	Items       []TemplateItemResponse `json:"items"`
+	Version     int                    `json:"version"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// TemplateItemResponse represents one activity of a template
+type TemplateItemResponse struct {
+	ID       string `json:"id"`
+	Position int    `json:"position"`
+	ItemFields
+}
+
+// TemplatesListResponse represents paginated templates list response
+type TemplatesListResponse struct {
+	Templates  []TemplateResponse `json:"templates"`
+	Total      int64              `json:"total"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	TotalPages int                `json:"total_pages"`
+}
+
+// CreateTemplateRequest represents template creation from item definitions
+type CreateTemplateRequest struct {
+	Name        string       `json:"name" binding:"required"`
+	Description *string      `json:"description"`
+	Items       []ItemFields `json:"items"`
+}
+
+// CreateTemplateFromActivitiesRequest saves existing activities as a template,
+// their day offsets counted from the day of the earliest one
+type CreateTemplateFromActivitiesRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description *string  `json:"description"`
+	ActivityIDs []string `json:"activity_ids"`
+}
+
+// ApplyTemplateRequest creates the activities of a template from a start date onto a plot or season
+type ApplyTemplateRequest struct {
+	StartDate string  `json:"start_date" binding:"required"` // YYYY-MM-DD
+	PlotID    *string `json:"plot_id"`
+	SeasonID  *string `json:"season_id"`
+}
+
+// ToTemplateResponse converts Template to TemplateResponse
+func (t *Template) ToTemplateResponse() TemplateResponse {
+	items := make([]TemplateItemResponse, 0, len(t.Items))
+	for _, item := range t.Items {
+		items = append(items, TemplateItemResponse{
+			ID:         item.ID,
+			Position:   item.Position,
+			ItemFields: item.ItemFields,
+		})
+	}
+	return TemplateResponse{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		Items:       items,
+		Version:     t.Version,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+// ToTemplatesListResponse converts templates list to paginated response
+func ToTemplatesListResponse(templates []Template, total int64, page, limit int) TemplatesListResponse {
+	response := make([]TemplateResponse, 0, len(templates))
+	for i := range templates {
+		response = append(response, templates[i].ToTemplateResponse())
+	}
+
+	totalPages := int(total) / limit
+	if int(total)%limit > 0 {
+		totalPages++
+	}
+
+	return TemplatesListResponse{
+		Templates:  response,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}
+}
+
+// ToActivityRequest builds the activity of the item for a template applied at start,
+// a midnight in loc. Times are taken in loc.
+func (f *ItemFields) ToActivityRequest(start time.Time, loc *time.Location) activities.CreateActivityRequest {
+	date := start.AddDate(0, 0, f.DayOffset)
+	req := activities.CreateActivityRequest{
+		Description:  f.Description,
+		Description2: f.Description2,
+		Description3: f.Description3,
+		Day:          f.Day,
+		Money:        f.Money,
+		Type:         f.Type,
+		Title:        f.Title,
+		IsRepeat:     f.IsRepeat,
+		Repeat:       f.Repeat,
+		AlertTime:    f.AlertTime,
+		Object:       f.Object,
+		Amount:       f.Amount,
+		Unit:         f.Unit,
+		Purpose:      f.Purpose,
+		TargetPerson: f.TargetPerson,
+		SourcePerson: f.SourcePerson,
+		AttachedLink: f.AttachedLink,
+		Note:         f.Note,
+	}
+
+	if f.StartTime != nil {
+		clock, _ := time.Parse(startTimeLayout, *f.StartTime)
+		timeStart := time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+		req.TimeStart = &timeStart
+	} else if f.Day != nil && *f.Day {
+		timeStart := date
+		req.TimeStart = &timeStart
+	}
+	if req.TimeStart != nil && f.DurationMinutes != nil {
+		timeEnd := req.TimeStart.Add(time.Duration(*f.DurationMinutes) * time.Minute)
+		req.TimeEnd = &timeEnd
+	}
+	if f.RepeatDays != nil {
+		endRepeat := date.AddDate(0, 0, *f.RepeatDays)
+		req.EndRepeatDay = &endRepeat
+	}
+	return req
+}
+
+// ItemFromActivity takes the content and schedule of an activity, its day offset counted
+// from first, a midnight in loc. Activities without time_start are placed on day 0.
+func ItemFromActivity(a *activities.Activity, first time.Time, loc *time.Location) ItemFields {
+	item := ItemFields{
+		Day:          a.Day,
+		Type:         a.Type,
+		Title:        a.Title,
+		Description:  a.Description,
+		Description2: a.Description2,
+		Description3: a.Description3,
+		Money:        a.Money,
+		IsRepeat:     a.IsRepeat,
+		Repeat:       a.Repeat,
+		AlertTime:    a.AlertTime,
+		Object:       a.Object,
+		Amount:       a.Amount,
+		Unit:         a.Unit,
+		Purpose:      a.Purpose,
+		TargetPerson: a.TargetPerson,
+		SourcePerson: a.SourcePerson,
+		AttachedLink: a.AttachedLink,
+		Note:         a.Note,
+	}
+	if a.TimeStart == nil {
+		return item
+	}
+
+	date := common.StartOfDay(*a.TimeStart, loc)
+	item.DayOffset = daysBetween(first, date)
+	if a.Day == nil || !*a.Day {
+		startTime := a.TimeStart.In(loc).Format(startTimeLayout)
+		item.StartTime = &startTime
+	}
+	if a.TimeEnd != nil && a.TimeEnd.After(*a.TimeStart) {
+		minutes := int(a.TimeEnd.Sub(*a.TimeStart) / time.Minute)
+		item.DurationMinutes = &minutes
+	}
+	if a.EndRepeatDay != nil {
+		repeatDays := daysBetween(date, *a.EndRepeatDay)
+		if repeatDays >= 0 {
+			item.RepeatDays = &repeatDays
+		}
+	}
+	return item
+}
+
+// daysBetween counts calendar days between the dates of two times
+func daysBetween(first, last time.Time) int {
+	a := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
@@ -0,0 +1,67 @@
+package templates
+
+import (
+	"plantheon-backend/common"
+
+	"gorm.io/gorm"
+)
+
+// TemplateService handles all database operations for templates
+type TemplateService struct {
+	db *gorm.DB
+}
+
+// NewTemplateService creates a new template service instance
+func NewTemplateService() *TemplateService {
+	return &TemplateService{
+		db: common.GetDB(),
+	}
+}
+
+// CreateTemplateRecord creates a template with its items
+func CreateTemplateRecord(template *Template) error {
+	service := NewTemplateService()
+	return service.db.Create(template).Error
+}
+
+// GetUserTemplate finds a template owned by the user with its items in order
+func GetUserTemplate(id, userID string) (*Template, error) {
+	service := NewTemplateService()
+	var template Template
+	err := service.db.Preload("Items", orderItems).
+		Where("id = ? AND user_id = ?", id, userID).First(&template).Error
+	return &template, err
+}
+
+// GetTemplatesByUser lists the user's templates with their items, newest first
+func GetTemplatesByUser(userID, search string, offset, limit int) ([]Template, int64, error) {
+	service := NewTemplateService()
+	var templates []Template
+	var total int64
+
+	query := service.db.Model(&Template{}).Where("user_id = ?", userID)
+	if search != "" {
+		query = query.Where("name ILIKE ?", "%"+search+"%")
+	}
+
+	// Count total records
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	err := query.Preload("Items", orderItems).
+		Order("created_at DESC").Offset(offset).Limit(limit).Find(&templates).Error
+	return templates, total, err
+}
+
+// DeleteTemplate soft-deletes template by ID, activities created from it are kept
+func DeleteTemplate(id string) error {
+	service := NewTemplateService()
+	return service.db.Where("id = ?", id).Delete(&Template{}).Error
+}
+
+// orderItems preloads template items in their position order
+func orderItems(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
@@ -0,0 +1,137 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/activities"
+
+	"github.com/google/uuid"
+)
+
+// MaxTemplateItems is the largest number of activities in a template
+const MaxTemplateItems = 100
+
+// maxDayOffset bounds day offsets and repeat days to about ten years
+const maxDayOffset = 3660
+
+// ValidateCreateTemplateRequest validates template creation request
+func ValidateCreateTemplateRequest(req *CreateTemplateRequest) error {
+	if err := validateTemplateName(&req.Name); err != nil {
+		return err
+	}
+	if len(req.Items) == 0 {
+		return errors.New("items is required")
+	}
+	if len(req.Items) > MaxTemplateItems {
+		return fmt.Errorf("a template has at most %d items", MaxTemplateItems)
+	}
+	for i := range req.Items {
+		if err := validateItem(&req.Items[i]); err != nil {
+			return fmt.Errorf("item %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// ValidateCreateTemplateFromActivitiesRequest validates saving activities as a template
+func ValidateCreateTemplateFromActivitiesRequest(req *CreateTemplateFromActivitiesRequest) error {
+	if err := validateTemplateName(&req.Name); err != nil {
+		return err
+	}
+	if len(req.ActivityIDs) == 0 {
+		return errors.New("activity_ids is required")
+	}
+	if len(req.ActivityIDs) > MaxTemplateItems {
+		return fmt.Errorf("a template has at most %d items", MaxTemplateItems)
+	}
+	seen := make(map[string]bool, len(req.ActivityIDs))
+	for _, id := range req.ActivityIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("invalid activity id %q", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("activity %s is listed more than once", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// ValidateApplyTemplateRequest validates the start date and the plot or season of an applied template
+func ValidateApplyTemplateRequest(req *ApplyTemplateRequest) error {
+	if _, err := time.Parse(common.DateLayout, req.StartDate); err != nil {
+		return errors.New("start_date must be a date (YYYY-MM-DD)")
+	}
+	if req.PlotID != nil {
+		if _, err := uuid.Parse(*req.PlotID); err != nil {
+			return errors.New("plot_id must be a valid UUID")
+		}
+	}
+	if req.SeasonID != nil {
+		if _, err := uuid.Parse(*req.SeasonID); err != nil {
+			return errors.New("season_id must be a valid UUID")
+		}
+	}
+	return nil
+}
+
+// validateTemplateName trims the name and checks its length
+func validateTemplateName(name *string) error {
+	*name = strings.TrimSpace(*name)
+	if *name == "" {
+		return errors.New("name is required")
+	}
+	if len(*name) > 255 {
+		return errors.New("name must be less than 255 characters")
+	}
+	return nil
+}
+
+// validateItem checks the schedule of an item and its activity with the activity creation rules
+func validateItem(item *ItemFields) error {
+	if item.DayOffset < 0 || item.DayOffset > maxDayOffset {
+		return fmt.Errorf("day_offset must be between 0 and %d", maxDayOffset)
+	}
+	if item.StartTime != nil {
+		if _, err := time.Parse(startTimeLayout, *item.StartTime); err != nil {
+			return errors.New("start_time must be HH:MM")
+		}
+	}
+	if item.DurationMinutes != nil {
+		if *item.DurationMinutes <= 0 || *item.DurationMinutes > 31*24*60 {
+			return errors.New("duration_minutes must be between 1 and 44640")
+		}
+		if item.StartTime == nil && (item.Day == nil || !*item.Day) {
+			return errors.New("duration_minutes requires start_time or day")
+		}
+	}
+	if item.RepeatDays != nil && (*item.RepeatDays < 0 || *item.RepeatDays > maxDayOffset) {
+		return fmt.Errorf("repeat_days must be between 0 and %d", maxDayOffset)
+	}
+
+	// Build the activity at an arbitrary date to check its content
+	req := item.ToActivityRequest(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), time.UTC)
+	if err := activities.ValidateCreateActivityRequest(&req); err != nil {
+		return err
+	}
+	item.Type = req.Type
+	return nil
+}
+
+// ValidatePaginationParams validates pagination parameters
+func ValidatePaginationParams(page, limit int) (int, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return page, limit, nil
+}
@@ -0,0 +1,39 @@
+package units
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Dimensions of the units, quantities convert only within a dimension
+const (
+	DimensionMass   = "mass"   // Base unit kg
+	DimensionVolume = "volume" // Base unit l
+	DimensionArea   = "area"   // Base unit m2
+	DimensionCount  = "count"  // Base unit piece
+)
+
+// CustomUnit is a packaging unit defined by a farmer, e.g. "bao 50kg" is 50 kg
+type CustomUnit struct {
+	ID        string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    string         `json:"user_id" gorm:"type:uuid;not null;index"`
+	Code      string         `json:"code" gorm:"type:varchar(50);not null"`
+	Name      string         `json:"name" gorm:"type:varchar(255);not null"`
+	BaseUnit  string         `json:"base_unit" gorm:"type:varchar(50);not null"`  // Code of a built-in unit
+	Quantity  float64        `json:"quantity" gorm:"type:decimal(15,6);not null"` // How many base units one custom unit holds
+	Aliases   pq.StringArray `json:"aliases" gorm:"type:text[]"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (u *CustomUnit) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == "" {
+		u.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnknownUnit is returned when a unit is neither built-in nor one of the user's custom units
+	ErrUnknownUnit = errors.New("unknown unit")
+	// ErrIncompatibleUnits is returned when converting between different dimensions
+	ErrIncompatibleUnits = errors.New("units cannot be converted")
+)
+
+// Definition describes a unit and how it converts to the base unit of its dimension
+type Definition struct {
+	ID        string   `json:"id,omitempty"` // Set for custom units
+	Code      string   `json:"code"`
+	Name      string   `json:"name"`
+	Dimension string   `json:"dimension"`
+	Factor    float64  `json:"factor"` // Base units in one of this unit
+	Aliases   []string `json:"aliases"`
+	Custom    bool     `json:"custom"`
+}
+
+// BuiltinUnits are the units every user can use. Local Vietnamese units follow the
+// northern convention (1 sào = 360 m2, 1 mẫu = 3600 m2) and the southern công of 1000 m2.
+var BuiltinUnits = []Definition{
+	{Code: "mg", Name: "milligram", Dimension: DimensionMass, Factor: 0.000001, Aliases: []string{"milligram", "miligam"}},
+	{Code: "g", Name: "gram", Dimension: DimensionMass, Factor: 0.001, Aliases: []string{"gram", "gam", "gr"}},
+	{Code: "kg", Name: "kilogram", Dimension: DimensionMass, Factor: 1, Aliases: []string{"kilogram", "kilo", "ký", "kí", "cân", "kgs"}},
+	{Code: "yen", Name: "yến", Dimension: DimensionMass, Factor: 10, Aliases: []string{"yến"}},
+	{Code: "ta", Name: "tạ", Dimension: DimensionMass, Factor: 100, Aliases: []string{"tạ", "quintal"}},
+	{Code: "t", Name: "tấn", Dimension: DimensionMass, Factor: 1000, Aliases: []string{"tấn", "tan", "ton", "tonne"}},
+	{Code: "lb", Name: "pound", Dimension: DimensionMass, Factor: 0.45359237, Aliases: []string{"pound", "lbs"}},
+
+	{Code: "ml", Name: "millilitre", Dimension: DimensionVolume, Factor: 0.001, Aliases: []string{"millilitre", "milliliter", "mililit", "cc"}},
+	{Code: "l", Name: "litre", Dimension: DimensionVolume, Factor: 1, Aliases: []string{"litre", "liter", "lít", "lit"}},
+	{Code: "m3", Name: "cubic metre", Dimension: DimensionVolume, Factor: 1000, Aliases: []string{"m³", "khối", "mét khối"}},
+
+	{Code: "m2", Name: "square metre", Dimension: DimensionArea, Factor: 1, Aliases: []string{"m²", "mét vuông", "sqm"}},
+	{Code: "sao", Name: "sào", Dimension: DimensionArea, Factor: 360, Aliases: []string{"sào", "sào bắc bộ"}},
+	{Code: "sao_trung_bo", Name: "sào Trung Bộ", Dimension: DimensionArea, Factor: 500, Aliases: []string{"sào trung bộ"}},
+	{Code: "cong", Name: "công", Dimension: DimensionArea, Factor: 1000, Aliases: []string{"công"}},
+	{Code: "mau", Name: "mẫu", Dimension: DimensionArea, Factor: 3600, Aliases: []string{"mẫu"}},
+	{Code: "ha", Name: "hectare", Dimension: DimensionArea, Factor: 10000, Aliases: []string{"hectare", "hecta", "héc ta", "hec ta"}},
+	{Code: "km2", Name: "square kilometre", Dimension: DimensionArea, Factor: 1000000, Aliases: []string{"km²"}},
+
+	{Code: "piece", Name: "piece", Dimension: DimensionCount, Factor: 1, Aliases: []string{"pcs", "cái", "chiếc", "con", "cây", "quả", "trái"}},
+	{Code: "chuc", Name: "chục", Dimension: DimensionCount, Factor: 10, Aliases: []string{"chục"}},
+	{Code: "dozen", Name: "dozen", Dimension: DimensionCount, Factor: 12, Aliases: []string{"tá"}},
+}
+
+// builtinIndex maps normalized codes and aliases to built-in units
+var builtinIndex = func() map[string]Definition {
+	index := make(map[string]Definition)
+	for _, unit := range BuiltinUnits {
+		index[normalizeKey(unit.Code)] = unit
+		for _, alias := range unit.Aliases {
+			index[normalizeKey(alias)] = unit
+		}
+	}
+	return index
+}()
+
+// normalizeKey lowercases and collapses spaces so "Kg", " kg " and "KG" match
+func normalizeKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// LookupBuiltin finds a built-in unit by code or alias
+func LookupBuiltin(name string) (Definition, bool) {
+	unit, ok := builtinIndex[normalizeKey(name)]
+	return unit, ok
+}
+
+// Convert converts a quantity between two resolved units
+func Convert(value float64, from, to Definition) (float64, error) {
+	if from.Dimension != to.Dimension {
+		return 0, fmt.Errorf("%w: %s is %s, %s is %s", ErrIncompatibleUnits, from.Code, from.Dimension, to.Code, to.Dimension)
+	}
+	return value * from.Factor / to.Factor, nil
+}
+
+// ToDefinition resolves a custom unit against the built-in unit it is made of
+func (u *CustomUnit) ToDefinition() (Definition, error) {
+	base, ok := LookupBuiltin(u.BaseUnit)
+	if !ok {
+		return Definition{}, ErrUnknownUnit
+	}
+	return Definition{
+		ID:        u.ID,
+		Code:      u.Code,
+		Name:      u.Name,
+		Dimension: base.Dimension,
+		Factor:    u.Quantity * base.Factor,
+		Aliases:   u.Aliases,
+		Custom:    true,
+	}, nil
+}
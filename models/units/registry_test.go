@@ -0,0 +1,124 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestBuiltinUnitsAreUnique(t *testing.T) {
+	seen := make(map[string]string)
+	for _, unit := range BuiltinUnits {
+		for _, name := range append([]string{unit.Code}, unit.Aliases...) {
+			key := normalizeKey(name)
+			if code, ok := seen[key]; ok && code != unit.Code {
+				t.Fatalf("%q names both %s and %s", name, code, unit.Code)
+			}
+			seen[key] = unit.Code
+		}
+	}
+}
+
+func TestLookupBuiltin(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"kg", "kg", true},
+		{" KG ", "kg", true},
+		{"Ký", "kg", true},
+		{"mét  vuông", "m2", true},
+		{"Sào Bắc Bộ", "sao", true},
+		{"m³", "m3", true},
+		{"bao", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unit, ok := LookupBuiltin(tt.name)
+			if ok != tt.ok || unit.Code != tt.want {
+				t.Fatalf("got %q %v, want %q %v", unit.Code, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	unit := func(code string) Definition {
+		definition, ok := LookupBuiltin(code)
+		if !ok {
+			t.Fatalf("unknown unit %s", code)
+		}
+		return definition
+	}
+	bag := CustomUnit{Code: "bao", BaseUnit: "kg", Quantity: 50}
+	bagUnit, err := bag.ToDefinition()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value float64
+		from  Definition
+		to    Definition
+		want  float64
+		err   error
+	}{
+		{"same unit", 3, unit("kg"), unit("kg"), 3, nil},
+		{"tấn to kg", 1.5, unit("t"), unit("kg"), 1500, nil},
+		{"g to kg", 250, unit("g"), unit("kg"), 0.25, nil},
+		{"tạ to yến", 2, unit("ta"), unit("yen"), 20, nil},
+		{"pound to kg", 10, unit("lb"), unit("kg"), 4.5359237, nil},
+		{"ha to sào", 1, unit("ha"), unit("sao"), 10000.0 / 360, nil},
+		{"mẫu to sào", 1, unit("mau"), unit("sao"), 10, nil},
+		{"công to m2", 2.5, unit("cong"), unit("m2"), 2500, nil},
+		{"m3 to l", 0.2, unit("m3"), unit("l"), 200, nil},
+		{"chục to piece", 3, unit("chuc"), unit("piece"), 30, nil},
+		{"custom to base", 4, bagUnit, unit("kg"), 200, nil},
+		{"base to custom", 1, unit("t"), bagUnit, 20, nil},
+		{"mass to area", 1, unit("kg"), unit("ha"), 0, ErrIncompatibleUnits},
+		{"volume to mass", 1, unit("l"), unit("kg"), 0, ErrIncompatibleUnits},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.value, tt.from, tt.to)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomUnitToDefinition(t *testing.T) {
+	tests := []struct {
+		name      string
+		unit      CustomUnit
+		dimension string
+		factor    float64
+		err       error
+	}{
+		{"bag of kg", CustomUnit{Code: "bao", BaseUnit: "kg", Quantity: 50}, DimensionMass, 50, nil},
+		{"can of ml", CustomUnit{Code: "can", BaseUnit: "ml", Quantity: 500}, DimensionVolume, 0.5, nil},
+		{"base by alias", CustomUnit{Code: "thung", BaseUnit: "Chục", Quantity: 3}, DimensionCount, 30, nil},
+		{"unknown base", CustomUnit{Code: "bao", BaseUnit: "bao"}, "", 0, ErrUnknownUnit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definition, err := tt.unit.ToDefinition()
+			if err != tt.err {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if definition.Dimension != tt.dimension || math.Abs(definition.Factor-tt.factor) > 1e-9 || !definition.Custom || definition.Code != tt.unit.Code {
+				t.Fatalf("got %+v, want %s with factor %v", definition, tt.dimension, tt.factor)
+			}
+		})
+	}
+}
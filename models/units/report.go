@@ -0,0 +1,61 @@
+package units
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// ReportUnits holds the units a report was asked to express its quantities in
+type ReportUnits struct {
+	resolver *Resolver
+	Unit     string // Target unit of quantities, empty keeps the stored units
+	AreaUnit string // Target unit of areas, empty keeps the plot units
+}
+
+// ParseReportUnits reads the unit and area_unit query params of a report,
+// writing the error response and returning false when one is unknown
+func ParseReportUnits(c *gin.Context) (*ReportUnits, bool) {
+	report := &ReportUnits{resolver: NewResolver(c.GetString("user_id"))}
+
+	if unit := c.Query("unit"); unit != "" {
+		definition, err := report.resolver.Resolve(unit)
+		if err != nil {
+			respondUnitError(c, err)
+			return nil, false
+		}
+		report.Unit = definition.Code
+	}
+
+	if areaUnit := c.Query("area_unit"); areaUnit != "" {
+		if !CheckUnit(c, &areaUnit, DimensionArea) {
+			return nil, false
+		}
+		report.AreaUnit = areaUnit
+	}
+
+	return report, true
+}
+
+// Quantity converts a quantity into the requested unit. Quantities that cannot be
+// converted, e.g. litres when kg were requested, are returned unchanged.
+func (r *ReportUnits) Quantity(value float64, unit string) (float64, string) {
+	if r.Unit == "" || unit == r.Unit {
+		return value, unit
+	}
+	converted, err := r.resolver.Convert(value, unit, r.Unit)
+	if err != nil {
+		return value, unit
+	}
+	return converted, r.Unit
+}
+
+// Area converts an area into the requested area unit
+func (r *ReportUnits) Area(area *float64, unit string) (*float64, string) {
+	if area == nil || r.AreaUnit == "" || unit == r.AreaUnit {
+		return area, unit
+	}
+	converted, err := r.resolver.Convert(*area, unit, r.AreaUnit)
+	if err != nil {
+		return area, unit
+	}
+	return &converted, r.AreaUnit
+}
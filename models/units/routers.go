@@ -0,0 +1,197 @@
+package units
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetUnitsHandler lists the built-in units and, with a token, the current user's custom units
+// Query: GET /api/v1/units?dimension=mass
+func GetUnitsHandler(c *gin.Context) {
+	dimension := c.Query("dimension")
+
+	definitions := make([]Definition, 0, len(BuiltinUnits))
+	for _, unit := range BuiltinUnits {
+		if dimension == "" || unit.Dimension == dimension {
+			definitions = append(definitions, unit)
+		}
+	}
+
+	if userID := c.GetString("user_id"); userID != "" {
+		customUnits, err := GetCustomUnitsByUser(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to get custom units",
+			})
+			return
+		}
+		for _, custom := range customUnits {
+			definition, err := custom.ToDefinition()
+			if err != nil {
+				continue
+			}
+			if dimension == "" || definition.Dimension == dimension {
+				definitions = append(definitions, definition)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"units":      definitions,
+			"count":      len(definitions),
+			"dimensions": []string{DimensionMass, DimensionVolume, DimensionArea, DimensionCount},
+		},
+	})
+}
+
+// ConvertHandler converts a quantity between two units
+// Query: GET /api/v1/units/convert?value=2&from=sào&to=m2
+func ConvertHandler(c *gin.Context) {
+	value, err := strconv.ParseFloat(c.Query("value"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "value must be a number",
+		})
+		return
+	}
+
+	resolver := NewResolver(c.GetString("user_id"))
+	from, err := resolver.Resolve(c.Query("from"))
+	if err != nil {
+		respondUnitError(c, err)
+		return
+	}
+	to, err := resolver.Resolve(c.Query("to"))
+	if err != nil {
+		respondUnitError(c, err)
+		return
+	}
+
+	result, err := Convert(value, from, to)
+	if err != nil {
+		respondUnitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": ConversionResponse{
+			Value:  value,
+			From:   from,
+			To:     to,
+			Result: result,
+		},
+	})
+}
+
+// CreateCustomUnitHandler handles custom packaging unit creation for the current user
+func CreateCustomUnitHandler(c *gin.Context) {
+	var req CreateCustomUnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateCustomUnitRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	for _, name := range append([]string{req.Code}, req.Aliases...) {
+		if _, err := FindCustomUnit(userID, name); err == nil {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "A custom unit named \"" + name + "\" already exists",
+			})
+			return
+		} else if err != gorm.ErrRecordNotFound {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check custom units",
+			})
+			return
+		}
+	}
+
+	base, _ := LookupBuiltin(req.BaseUnit)
+	unit := &CustomUnit{
+		UserID:   userID,
+		Code:     req.Code,
+		Name:     req.Name,
+		BaseUnit: base.Code,
+		Quantity: req.Quantity,
+		Aliases:  req.Aliases,
+	}
+
+	if err := CreateCustomUnitRecord(unit); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create custom unit",
+		})
+		return
+	}
+
+	definition, _ := unit.ToDefinition()
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Custom unit created successfully",
+		"data":    definition,
+	})
+}
+
+// DeleteCustomUnitHandler handles custom unit deletion.
+// Records already using the unit keep its code but can no longer be converted.
+func DeleteCustomUnitHandler(c *gin.Context) {
+	unit, err := GetUserCustomUnit(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Custom unit not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get custom unit",
+		})
+		return
+	}
+
+	if err := DeleteCustomUnit(unit.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete custom unit",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Custom unit deleted successfully",
+	})
+}
+
+// CheckUnit replaces a unit entered by the current user with its registry code,
+// optionally requiring a dimension. It writes the error response and returns false
+// when the unit is unknown or of another dimension.
+func CheckUnit(c *gin.Context, unit *string, dimension string) bool {
+	if err := NormalizeUnit(c.GetString("user_id"), unit, dimension); err != nil {
+		respondUnitError(c, err)
+		return false
+	}
+	return true
+}
+
+// respondUnitError writes unit lookup errors, hiding database errors from the client
+func respondUnitError(c *gin.Context, err error) {
+	status := ErrorStatus(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		message = "Failed to resolve unit"
+	}
+	c.JSON(status, gin.H{
+		"error": message,
+	})
+}
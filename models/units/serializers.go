@@ -0,0 +1,18 @@
+package units
+
+// CreateCustomUnitRequest represents custom unit creation request
+type CreateCustomUnitRequest struct {
+	Code     string   `json:"code" binding:"required"` // e.g. "bao 50kg"
+	Name     string   `json:"name"`
+	BaseUnit string   `json:"base_unit" binding:"required"` // e.g. "kg"
+	Quantity float64  `json:"quantity" binding:"required"`  // e.g. 50
+	Aliases  []string `json:"aliases"`
+}
+
+// ConversionResponse represents the result of a unit conversion
+type ConversionResponse struct {
+	Value  float64    `json:"value"`
+	From   Definition `json:"from"`
+	To     Definition `json:"to"`
+	Result float64    `json:"result"`
+}
@@ -0,0 +1,150 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"plantheon-backend/common"
+
+	"gorm.io/gorm"
+)
+
+// UnitService handles all database operations for custom units
+type UnitService struct {
+	db *gorm.DB
+}
+
+// NewUnitService creates a new unit service instance
+func NewUnitService() *UnitService {
+	return &UnitService{
+		db: common.GetDB(),
+	}
+}
+
+// CreateCustomUnitRecord creates a new custom unit
+func CreateCustomUnitRecord(unit *CustomUnit) error {
+	service := NewUnitService()
+	return service.db.Create(unit).Error
+}
+
+// GetCustomUnitsByUser lists the user's custom units
+func GetCustomUnitsByUser(userID string) ([]CustomUnit, error) {
+	service := NewUnitService()
+	var customUnits []CustomUnit
+	err := service.db.Where("user_id = ?", userID).Order("code ASC").Find(&customUnits).Error
+	return customUnits, err
+}
+
+// GetUserCustomUnit finds a custom unit owned by the user
+func GetUserCustomUnit(id, userID string) (*CustomUnit, error) {
+	service := NewUnitService()
+	var unit CustomUnit
+	err := service.db.Where("id = ? AND user_id = ?", id, userID).First(&unit).Error
+	return &unit, err
+}
+
+// FindCustomUnit finds the user's custom unit by code or alias, ignoring case
+func FindCustomUnit(userID, name string) (*CustomUnit, error) {
+	service := NewUnitService()
+	var unit CustomUnit
+	key := normalizeKey(name)
+	err := service.db.Where("user_id = ? AND (LOWER(code) = ? OR ? = ANY(aliases))", userID, key, key).
+		First(&unit).Error
+	return &unit, err
+}
+
+// DeleteCustomUnit soft-deletes custom unit by ID
+func DeleteCustomUnit(id string) error {
+	service := NewUnitService()
+	return service.db.Where("id = ?", id).Delete(&CustomUnit{}).Error
+}
+
+// Resolve finds a unit by code or alias among the built-in units, then the user's custom units
+func Resolve(userID, name string) (Definition, error) {
+	if unit, ok := LookupBuiltin(name); ok {
+		return unit, nil
+	}
+	if userID == "" {
+		return Definition{}, fmt.Errorf("%w %q", ErrUnknownUnit, name)
+	}
+
+	custom, err := FindCustomUnit(userID, name)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return Definition{}, fmt.Errorf("%w %q", ErrUnknownUnit, name)
+		}
+		return Definition{}, err
+	}
+	return custom.ToDefinition()
+}
+
+// Resolver resolves units of one user, caching lookups while a report converts many rows
+type Resolver struct {
+	userID string
+	cache  map[string]Definition
+}
+
+// NewResolver creates a resolver for the user's units
+func NewResolver(userID string) *Resolver {
+	return &Resolver{
+		userID: userID,
+		cache:  make(map[string]Definition),
+	}
+}
+
+// Resolve finds a unit by code or alias
+func (r *Resolver) Resolve(name string) (Definition, error) {
+	key := normalizeKey(name)
+	if unit, ok := r.cache[key]; ok {
+		return unit, nil
+	}
+	unit, err := Resolve(r.userID, name)
+	if err != nil {
+		return Definition{}, err
+	}
+	r.cache[key] = unit
+	return unit, nil
+}
+
+// Convert converts a quantity between two units given by code or alias
+func (r *Resolver) Convert(value float64, from, to string) (float64, error) {
+	fromUnit, err := r.Resolve(from)
+	if err != nil {
+		return 0, err
+	}
+	toUnit, err := r.Resolve(to)
+	if err != nil {
+		return 0, err
+	}
+	return Convert(value, fromUnit, toUnit)
+}
+
+// NormalizeUnit replaces a unit entered by the user with its registry code,
+// optionally requiring a dimension. A nil unit is left as is, a blank one is emptied.
+func NormalizeUnit(userID string, unit *string, dimension string) error {
+	if unit == nil {
+		return nil
+	}
+	if normalizeKey(*unit) == "" {
+		*unit = ""
+		return nil
+	}
+	definition, err := Resolve(userID, *unit)
+	if err != nil {
+		return err
+	}
+	if dimension != "" && definition.Dimension != dimension {
+		return fmt.Errorf("%w: %s is not a %s unit", ErrIncompatibleUnits, definition.Code, dimension)
+	}
+	*unit = definition.Code
+	return nil
+}
+
+// ErrorStatus maps errors from Resolve, Convert and NormalizeUnit to HTTP statuses
+func ErrorStatus(err error) int {
+	if errors.Is(err, ErrUnknownUnit) || errors.Is(err, ErrIncompatibleUnits) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
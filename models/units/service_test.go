@@ -0,0 +1,92 @@
+package units
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// Without a user NormalizeUnit and Resolve only use the built-in units and never reach the database
+func TestNormalizeUnit(t *testing.T) {
+	tests := []struct {
+		name      string
+		unit      *string
+		dimension string
+		want      *string
+		err       error
+	}{
+		{"nil", nil, "", nil, nil},
+		{"empty", strPtr(""), "", strPtr(""), nil},
+		{"blank", strPtr("  "), DimensionMass, strPtr(""), nil},
+		{"code", strPtr("kg"), "", strPtr("kg"), nil},
+		{"alias", strPtr(" Ký "), DimensionMass, strPtr("kg"), nil},
+		{"vietnamese area", strPtr("Héc Ta"), DimensionArea, strPtr("ha"), nil},
+		{"other dimension", strPtr("lít"), DimensionMass, strPtr("lít"), ErrIncompatibleUnits},
+		{"unknown", strPtr("bao"), "", strPtr("bao"), ErrUnknownUnit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NormalizeUnit("", tt.unit, tt.dimension)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if (tt.unit == nil) != (tt.want == nil) || (tt.unit != nil && *tt.unit != *tt.want) {
+				t.Fatalf("got unit %v, want %v", tt.unit, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolverConvert(t *testing.T) {
+	resolver := NewResolver("")
+
+	tests := []struct {
+		name  string
+		value float64
+		from  string
+		to    string
+		want  float64
+		err   error
+	}{
+		{"aliases", 2, "tấn", "Ký", 2000, nil},
+		{"cached again", 3, "tấn", "kg", 3000, nil},
+		{"area", 1, "ha", "công", 10, nil},
+		{"incompatible", 1, "kg", "l", 0, ErrIncompatibleUnits},
+		{"unknown from", 1, "bao", "kg", 0, ErrUnknownUnit},
+		{"unknown to", 1, "kg", "bao", 0, ErrUnknownUnit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.Convert(tt.value, tt.from, tt.to)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"unknown", NormalizeUnit("", strPtr("bao"), ""), http.StatusBadRequest},
+		{"incompatible", NormalizeUnit("", strPtr("kg"), DimensionArea), http.StatusBadRequest},
+		{"database", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorStatus(tt.err); got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func strPtr(v string) *string {
+	return &v
+}
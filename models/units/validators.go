@@ -0,0 +1,59 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ValidateCreateCustomUnitRequest validates custom unit creation request
+// and normalizes its aliases for lookups
+func ValidateCreateCustomUnitRequest(req *CreateCustomUnitRequest) error {
+	req.Code = strings.Join(strings.Fields(req.Code), " ")
+	if req.Code == "" {
+		return errors.New("code is required")
+	}
+	if len(req.Code) > 50 {
+		return errors.New("code must be less than 50 characters")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		req.Name = req.Code
+	}
+	if len(req.Name) > 255 {
+		return errors.New("name must be less than 255 characters")
+	}
+
+	if _, ok := LookupBuiltin(req.BaseUnit); !ok {
+		return errors.New("base_unit must be a built-in unit")
+	}
+
+	if req.Quantity <= 0 {
+		return errors.New("quantity must be positive")
+	}
+
+	if len(req.Aliases) > 20 {
+		return errors.New("a unit can have at most 20 aliases")
+	}
+	aliases := make([]string, 0, len(req.Aliases))
+	for _, alias := range req.Aliases {
+		alias = normalizeKey(alias)
+		if alias == "" {
+			continue
+		}
+		if len(alias) > 50 {
+			return errors.New("aliases must be less than 50 characters")
+		}
+		aliases = append(aliases, alias)
+	}
+	req.Aliases = aliases
+
+	for _, name := range append([]string{req.Code}, req.Aliases...) {
+		if _, ok := LookupBuiltin(name); ok {
+			return fmt.Errorf("%q is already a built-in unit", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,274 @@
+package users
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"os"
+
+	"plantheon-backend/common"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// minSetupTokenLength rejects setup tokens short enough to be guessed
+const minSetupTokenLength = 32
+
+// SetupAdminHandler creates the first admin with the one-time ADMIN_SETUP_TOKEN.
+// It is disabled without the token and once an admin exists.
+// POST /api/v1/auth/setup-admin
+func SetupAdminHandler(c *gin.Context) {
+	setupToken := os.Getenv("ADMIN_SETUP_TOKEN")
+	if len(setupToken) < minSetupTokenLength {
+		if setupToken != "" {
+			log.Printf("ADMIN_SETUP_TOKEN is ignored, it must be at least %d characters", minSetupTokenLength)
+		}
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Admin setup is disabled",
+		})
+		return
+	}
+
+	var req SetupAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(req.Token), []byte(setupToken)) != 1 {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Invalid setup token",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateRegisterRequest(&req.RegisterRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !checkAccountAvailable(c, req.Email, req.Username) {
+		return
+	}
+
+	user := &User{
+		Email:    req.Email,
+		Username: req.Username,
+		Password: req.Password,
+		FullName: req.FullName,
+	}
+	if err := CreateFirstAdmin(user, RoleChangeSetup); err != nil {
+		if err == ErrAdminExists {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "An admin already exists, admin setup is disabled",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create admin",
+		})
+		return
+	}
+	log.Printf("First admin %s created with the setup token, ADMIN_SETUP_TOKEN can be removed", user.Email)
+
+	token, err := common.GenerateJWT(user.ID, user.Email, string(user.Role))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Admin created successfully",
+		"data": LoginResponse{
+			User:  user.ToUserResponse(),
+			Token: token,
+		},
+	})
+}
+
+// UpdateUserRoleHandler promotes or demotes a user and records the change. The current user
+// must hold every permission of both the old and the new role, only admins give or remove admin.
+// PUT /api/v1/admin/users/:id/role
+func UpdateUserRoleHandler(c *gin.Context) {
+	user, ok := loadUser(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateUpdateUserRoleRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if UserRole(req.Role) == user.Role {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "User already has this role",
+		})
+		return
+	}
+
+	role, err := GetRoleByName(req.Role)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Role does not exist",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get role",
+		})
+		return
+	}
+	current, err := GetRoleByName(string(user.Role))
+	if err == gorm.ErrRecordNotFound {
+		// An unknown role grants nothing
+		current, err = &Role{Name: string(user.Role)}, nil
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get role",
+		})
+		return
+	}
+	for _, r := range []*Role{current, role} {
+		allowed, err := canAssignRole(c, r)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check permissions",
+			})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You cannot give or remove the role " + r.Name,
+			})
+			return
+		}
+	}
+
+	actorID := c.GetString("user_id")
+	change := &RoleChange{
+		ActorID: &actorID,
+		Reason:  req.Reason,
+		Source:  RoleChangeAPI,
+	}
+	if err := ChangeUserRole(user, UserRole(role.Name), change); err != nil {
+		switch err {
+		case ErrLastAdmin:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		case common.ErrVersionConflict:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "User role was changed by someone else, please retry",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update user role",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User role updated successfully",
+		"data": gin.H{
+			"user":   user.ToUserResponse(),
+			"change": change.ToRoleChangeResponse(),
+		},
+	})
+}
+
+// GetUserRoleChangesHandler lists the role changes of a user, newest first
+// GET /api/v1/admin/users/:id/role-changes
+func GetUserRoleChangesHandler(c *gin.Context) {
+	user, ok := loadUser(c)
+	if !ok {
+		return
+	}
+
+	changes, err := GetRoleChanges(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get role changes",
+		})
+		return
+	}
+
+	response := make([]RoleChangeResponse, len(changes))
+	for i := range changes {
+		response[i] = changes[i].ToRoleChangeResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// canAssignRole checks if the current user may give or remove the role: admins may give any role,
+// others only roles whose permissions they all hold, so nobody can raise their own rights
+func canAssignRole(c *gin.Context, role *Role) (bool, error) {
+	if role.Name == string(RoleAdmin) {
+		user, exists := GetCurrentUser(c)
+		return exists && user.IsAdmin(), nil
+	}
+	for _, permission := range role.Permissions {
+		allowed, err := HasPermission(c, permission)
+		if err != nil || !allowed {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// checkAccountAvailable writes a conflict response and returns false when the email or username is taken
+func checkAccountAvailable(c *gin.Context, email, username string) bool {
+	if _, err := GetUserByEmail(email); err == nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Email already exists",
+		})
+		return false
+	}
+	if _, err := GetUserByUsername(username); err == nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Username already exists",
+		})
+		return false
+	}
+	return true
+}
+
+// loadUser loads the user from the :id parameter,
+// writing the error response and returning false when it does not exist
+func loadUser(c *gin.Context) (*User, bool) {
+	user, err := GetUserByID(c.Param("id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get user",
+		})
+		return nil, false
+	}
+	return user, true
+}
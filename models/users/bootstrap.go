@@ -0,0 +1,89 @@
+package users
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// RunCreateAdminCommand creates an admin from the command line, or promotes the user registered
+// with the email. The password of a new admin is read from ADMIN_PASSWORD or standard input so it
+// does not end up in the shell history.
+//
+//	go run main.go create-admin -email admin@example.com -username admin -full-name "Quản trị"
+func RunCreateAdminCommand(args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the admin (required)")
+	username := flags.String("username", "", "username of a new admin")
+	fullName := flags.String("full-name", "", "full name of a new admin")
+	reason := flags.String("reason", "", "reason recorded with the role change")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	*email = strings.TrimSpace(*email)
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+	var note *string
+	if trimmed := strings.TrimSpace(*reason); trimmed != "" {
+		note = &trimmed
+	}
+
+	user, err := GetUserByEmail(*email)
+	if err == nil {
+		if user.IsAdmin() {
+			fmt.Printf("%s is already an admin\n", user.Email)
+			return nil
+		}
+		if err := ChangeUserRole(user, RoleAdmin, &RoleChange{Reason: note, Source: RoleChangeCLI}); err != nil {
+			return err
+		}
+		fmt.Printf("%s promoted to admin\n", user.Email)
+		return nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	password, err := readAdminPassword()
+	if err != nil {
+		return err
+	}
+	req := RegisterRequest{Email: *email, Username: *username, Password: password, FullName: *fullName}
+	if err := ValidateRegisterRequest(&req); err != nil {
+		return err
+	}
+	if _, err := GetUserByUsername(req.Username); err == nil {
+		return errors.New("username already exists")
+	}
+
+	user = &User{
+		Email:    req.Email,
+		Username: req.Username,
+		Password: req.Password,
+		FullName: req.FullName,
+	}
+	if err := CreateAdminUser(user, RoleChangeCLI); err != nil {
+		return err
+	}
+	fmt.Printf("Admin %s created\n", user.Email)
+	return nil
+}
+
+// readAdminPassword reads the password from ADMIN_PASSWORD, or asks for it on standard input
+func readAdminPassword() (string, error) {
+	if password := os.Getenv("ADMIN_PASSWORD"); password != "" {
+		return password, nil
+	}
+	fmt.Print("Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("password is required, set ADMIN_PASSWORD or type it")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -7,6 +7,7 @@ import (
 	"plantheon-backend/common"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // AuthMiddleware validates JWT token
@@ -64,94 +65,28 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("user", user)
 		c.Set("user_id", user.ID)
 		c.Set("user_role", string(user.Role))
+		c.Set("user_timezone", user.Timezone)
 		c.Next()
 	}
 }
 
-// RequireAdmin middleware that requires admin role
-func RequireAdmin() gin.HandlerFunc {
+// OptionalAuthMiddleware sets the current user when a valid token is sent,
+// and lets anonymous requests through. A malformed or invalid token is still rejected.
+func OptionalAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// First check if user is authenticated
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header is required",
-			})
-			c.Abort()
-			return
-		}
-
-		// Check if header starts with "Bearer "
-		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid authorization header format",
-			})
-			c.Abort()
-			return
-		}
-
-		// Extract token
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Token is required",
-			})
-			c.Abort()
-			return
-		}
-
-		// Validate token
-		claims, err := common.ValidateJWT(tokenString)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid token",
-			})
-			c.Abort()
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
 			return
 		}
-
-		// Check if user has admin role
-		if claims.Role != string(RoleAdmin) {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Admin access required",
-			})
-			c.Abort()
-			return
-		}
-
-		// Get user from database
-		user, err := GetUserByID(claims.UserID)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "User not found",
-			})
-			c.Abort()
-			return
-		}
-
-		// Double check user role from database
-		if !user.IsAdmin() {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Admin access required",
-			})
-			c.Abort()
-			return
-		}
-
-		// Set user in context
-		c.Set("user", user)
-		c.Set("user_id", user.ID)
-		c.Set("user_role", string(user.Role))
-		c.Next()
+		AuthMiddleware()(c)
 	}
 }
 
-// RequireRole middleware that requires specific role
-func RequireRole(role UserRole) gin.HandlerFunc {
+// RequirePermission requires the current user's role to grant every given permission.
+// It runs after AuthMiddleware, the role is read from the database so changes apply at once.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// First check if user is authenticated
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		if _, exists := GetCurrentUser(c); !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Authorization header is required",
 			})
@@ -159,69 +94,51 @@ func RequireRole(role UserRole) gin.HandlerFunc {
 			return
 		}
 
-		// Check if header starts with "Bearer "
-		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid authorization header format",
-			})
-			c.Abort()
-			return
-		}
-
-		// Extract token
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Token is required",
-			})
-			c.Abort()
-			return
+		for _, permission := range permissions {
+			allowed, err := HasPermission(c, permission)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to check permissions",
+				})
+				c.Abort()
+				return
+			}
+			if !allowed {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Permission " + permission + " is required",
+				})
+				c.Abort()
+				return
+			}
 		}
+		c.Next()
+	}
+}
 
-		// Validate token
-		claims, err := common.ValidateJWT(tokenString)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid token",
-			})
-			c.Abort()
-			return
-		}
+// HasPermission checks if the role of the current user grants the permission,
+// false for anonymous requests. The role is loaded once per request.
+func HasPermission(c *gin.Context, permission string) (bool, error) {
+	user, exists := GetCurrentUser(c)
+	if !exists {
+		return false, nil
+	}
 
-		// Check if user has required role
-		if claims.Role != string(role) {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Insufficient permissions",
-			})
-			c.Abort()
-			return
+	var role *Role
+	if cached, ok := c.Get("user_role_permissions"); ok {
+		role = cached.(*Role)
+	} else {
+		found, err := GetRoleByName(string(user.Role))
+		if err == gorm.ErrRecordNotFound {
+			// Users of an unknown role have no permission
+			found, err = &Role{Name: string(user.Role)}, nil
 		}
-
-		// Get user from database
-		user, err := GetUserByID(claims.UserID)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "User not found",
-			})
-			c.Abort()
-			return
-		}
-
-		// Double check user role from database
-		if user.Role != role {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Insufficient permissions",
-			})
-			c.Abort()
-			return
+			return false, err
 		}
-
-		// Set user in context
-		c.Set("user", user)
-		c.Set("user_id", user.ID)
-		c.Set("user_role", string(user.Role))
-		c.Next()
+		role = found
+		c.Set("user_role_permissions", role)
 	}
+	return role.HasPermission(permission), nil
 }
 
 // GetCurrentUser gets current user from context
@@ -4,15 +4,17 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
-// UserRole enum for user roles
+// UserRole is the name of the Role of a user
 type UserRole string
 
+// Built-in roles, created at startup
 const (
-	RoleUser  UserRole = "user"
-	RoleAdmin UserRole = "admin"
+	RoleUser  UserRole = "user"  // Given at registration
+	RoleAdmin UserRole = "admin" // Always has every permission
 )
 
 type User struct {
@@ -23,7 +25,9 @@ type User struct {
 	FullName  string    `json:"full_name"`
 	Avatar    string    `json:"avatar"`
 	Role      UserRole  `json:"role" gorm:"type:varchar(20);default:'user';not null"`
-	CreatedAt time.Time `json:"created_at"`
+	Timezone  string    `json:"timezone" gorm:"type:varchar(64);not null;default:''"` // IANA name, empty for UTC
+	Version   int       `json:"version" gorm:"not null;default:1"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
@@ -44,3 +48,59 @@ func (u *User) IsAdmin() bool {
 func (u *User) IsUser() bool {
 	return u.Role == RoleUser
 }
+
+// Role is a named set of permissions given to users, see Permissions
+type Role struct {
+	Name        string         `json:"name" gorm:"primaryKey;type:varchar(20)"`
+	Description string         `json:"description" gorm:"type:text"`
+	Permissions pq.StringArray `json:"permissions" gorm:"type:text[]"`
+	System      bool           `json:"system" gorm:"not null;default:false"` // Built-in, cannot be deleted
+	Version     int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// HasPermission checks if the role grants the permission, admins have every permission
+func (r *Role) HasPermission(permission string) bool {
+	if r.Name == string(RoleAdmin) {
+		return true
+	}
+	for _, granted := range r.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Sources of role changes
+const (
+	RoleChangeAPI   = "api"   // By a user manager through the admin endpoints
+	RoleChangeCLI   = "cli"   // By the create-admin command
+	RoleChangeSetup = "setup" // With the one-time ADMIN_SETUP_TOKEN
+)
+
+// RoleChange records a change of the role of a user, including the creation of admins
+type RoleChange struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    string    `json:"user_id" gorm:"type:uuid;not null;index"`
+	ActorID   *string   `json:"actor_id" gorm:"type:uuid"`                 // Nil for the command line and the setup token
+	OldRole   string    `json:"old_role" gorm:"type:varchar(20);not null"` // Empty for a new user
+	NewRole   string    `json:"new_role" gorm:"type:varchar(20);not null"`
+	Reason    *string   `json:"reason" gorm:"type:text"`
+	Source    string    `json:"source" gorm:"type:varchar(10);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName prefixes the table with the users it belongs to
+func (RoleChange) TableName() string {
+	return "user_role_changes"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (r *RoleChange) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package users
+
+// Permissions granted by roles, see Role
+const (
+	PermDiseaseWrite    = "disease:write"     // Create and edit diseases and their images
+	PermDiseasePublish  = "disease:publish"   // Import the catalog, move diseases to and from trash
+	PermUserManage      = "user:manage"       // List users
+	PermRoleManage      = "role:manage"       // Create, edit and delete roles
+	PermActivityReadAny = "activity:read:any" // Read the activities of organizations without being a member
+)
+
+// Permissions lists every permission with its description
+var Permissions = []PermissionResponse{
+	{Name: PermDiseaseWrite, Description: "Create and edit diseases and their images"},
+	{Name: PermDiseasePublish, Description: "Import the disease catalog, move diseases to and from trash"},
+	{Name: PermUserManage, Description: "List users"},
+	{Name: PermRoleManage, Description: "Create, edit and delete roles"},
+	{Name: PermActivityReadAny, Description: "Read the activities of organizations without being a member"},
+}
+
+// IsPermission checks if name is a known permission
+func IsPermission(name string) bool {
+	for _, permission := range Permissions {
+		if permission.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AllPermissions returns the names of every permission, granted to the admin role
+func AllPermissions() []string {
+	names := make([]string, len(Permissions))
+	for i, permission := range Permissions {
+		names[i] = permission.Name
+	}
+	return names
+}
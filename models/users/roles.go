@@ -0,0 +1,286 @@
+package users
+
+import (
+	"net/http"
+
+	"plantheon-backend/common"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetPermissionsHandler lists the permissions roles can grant
+// GET /api/v1/admin/permissions
+func GetPermissionsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": Permissions,
+	})
+}
+
+// GetRolesHandler lists the roles with their permissions
+// GET /api/v1/admin/roles
+func GetRolesHandler(c *gin.Context) {
+	roles, err := GetRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get roles",
+		})
+		return
+	}
+
+	response := make([]RoleResponse, len(roles))
+	for i := range roles {
+		response[i] = roles[i].ToRoleResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// GetRoleHandler returns a role
+// GET /api/v1/admin/roles/:name
+func GetRoleHandler(c *gin.Context) {
+	role, ok := loadRole(c)
+	if !ok {
+		return
+	}
+
+	c.Header("ETag", common.ETag(role.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"data": role.ToRoleResponse(),
+	})
+}
+
+// CreateRoleHandler creates a role granting the given permissions, which the current user must hold
+// POST /api/v1/admin/roles
+func CreateRoleHandler(c *gin.Context) {
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateRoleRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if !checkPermissionChange(c, nil, req.Permissions) {
+		return
+	}
+
+	if _, err := GetRoleByName(req.Name); err == nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Role already exists",
+		})
+		return
+	} else if err != gorm.ErrRecordNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get role",
+		})
+		return
+	}
+
+	role := &Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: uniquePermissions(req.Permissions),
+	}
+	if err := CreateRoleRecord(role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create role",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Role created successfully",
+		"data":    role.ToRoleResponse(),
+	})
+}
+
+// UpdateRoleHandler changes the description and permissions of a role. The current user must hold
+// the permissions granted or removed, and the permissions of the admin role cannot change
+// PUT /api/v1/admin/roles/:name
+func UpdateRoleHandler(c *gin.Context) {
+	role, ok := loadRole(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateUpdateRoleRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if req.Permissions != nil && role.Name == string(RoleAdmin) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "The admin role always has every permission",
+		})
+		return
+	}
+	if req.Permissions != nil && !checkPermissionChange(c, role.Permissions, *req.Permissions) {
+		return
+	}
+
+	// Reject the update if the client edited an outdated version
+	precondition, err := common.ParsePrecondition(c, req.Version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(role.Version) {
+		respondRoleConflict(c, precondition, role)
+		return
+	}
+
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+	if req.Permissions != nil {
+		role.Permissions = uniquePermissions(*req.Permissions)
+	}
+
+	if err := UpdateRole(role); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetRoleByName(role.Name); err == nil {
+				respondRoleConflict(c, precondition, current)
+				return
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update role",
+		})
+		return
+	}
+
+	c.Header("ETag", common.ETag(role.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+		"data":    role.ToRoleResponse(),
+	})
+}
+
+// DeleteRoleHandler deletes a role that is not built in and no user has
+// DELETE /api/v1/admin/roles/:name
+func DeleteRoleHandler(c *gin.Context) {
+	role, ok := loadRole(c)
+	if !ok {
+		return
+	}
+	if role.System {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Built-in roles cannot be deleted",
+		})
+		return
+	}
+
+	if err := DeleteRole(role.Name); err != nil {
+		if err == ErrRoleInUse {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete role",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role deleted successfully",
+	})
+}
+
+// checkPermissionChange checks the current user holds every permission granted or removed by
+// changing a role from before to after, like canAssignRole, so a role manager cannot give their
+// own role more rights. The error response is written and false returned otherwise.
+func checkPermissionChange(c *gin.Context, before, after []string) bool {
+	had := make(map[string]bool, len(before))
+	for _, permission := range before {
+		had[permission] = true
+	}
+	has := make(map[string]bool, len(after))
+	for _, permission := range after {
+		has[permission] = true
+	}
+	for _, permission := range AllPermissions() {
+		if had[permission] == has[permission] {
+			continue
+		}
+		allowed, err := HasPermission(c, permission)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check permissions",
+			})
+			return false
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You cannot grant or remove the permission " + permission,
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// uniquePermissions removes repeated permissions, keeping their order
+func uniquePermissions(permissions []string) []string {
+	unique := make([]string, 0, len(permissions))
+	seen := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		if !seen[permission] {
+			seen[permission] = true
+			unique = append(unique, permission)
+		}
+	}
+	return unique
+}
+
+// loadRole loads the role from the :name parameter,
+// writing the error response and returning false when it does not exist
+func loadRole(c *gin.Context) (*Role, bool) {
+	role, err := GetRoleByName(c.Param("name"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Role not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get role",
+		})
+		return nil, false
+	}
+	return role, true
+}
+
+// respondRoleConflict returns the current representation so the client can merge and retry
+func respondRoleConflict(c *gin.Context, precondition *common.Precondition, current *Role) {
+	c.Header("ETag", common.ETag(current.Version))
+	c.JSON(precondition.ConflictStatus(), gin.H{
+		"error": "Role was modified by someone else",
+		"data":  current.ToRoleResponse(),
+	})
+}
@@ -0,0 +1,107 @@
+package users
+
+import (
+	"net/http"
+	"testing"
+
+	"plantheon-backend/common/dbtest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+// expectRole expects the role to be loaded by name
+func expectRole(mock sqlmock.Sqlmock, name string, permissions string) {
+	mock.ExpectQuery(`SELECT \* FROM "roles" WHERE name = \$1`).
+		WithArgs(name).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "permissions", "version"}).AddRow(name, permissions, 1))
+}
+
+// asUser runs the handler as a user of the role, like AuthMiddleware does
+func asUser(role UserRole, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("user", &User{ID: "caller", Role: role})
+		handler(c)
+	}
+}
+
+func TestRoleHandlersPermissionChanges(t *testing.T) {
+	const manager = "{role:manage,disease:write}"
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		caller  UserRole
+		expect  func(mock sqlmock.Sqlmock)
+		status  int
+		handler gin.HandlerFunc
+	}{
+		{
+			name: "manager creates a role granting user:manage", method: http.MethodPost, target: "/roles",
+			body: `{"name": "support", "permissions": ["disease:write", "user:manage"]}`, caller: "manager",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectRole(mock, "manager", manager)
+			},
+			status: http.StatusForbidden, handler: CreateRoleHandler,
+		},
+		{
+			name: "manager creates a role with their own permissions", method: http.MethodPost, target: "/roles",
+			body: `{"name": "editor", "permissions": ["disease:write"]}`, caller: "manager",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectRole(mock, "manager", manager)
+				mock.ExpectQuery(`SELECT \* FROM "roles" WHERE name = \$1`).
+					WithArgs("editor").
+					WillReturnRows(sqlmock.NewRows([]string{"name"}))
+				mock.ExpectExec(`INSERT INTO "roles"`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			status: http.StatusCreated, handler: CreateRoleHandler,
+		},
+		{
+			name: "manager grants user:manage to their own role", method: http.MethodPut, target: "/roles/manager",
+			body: `{"permissions": ["role:manage", "disease:write", "user:manage"], "version": 1}`, caller: "manager",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectRole(mock, "manager", manager)
+				expectRole(mock, "manager", manager)
+			},
+			status: http.StatusForbidden, handler: UpdateRoleHandler,
+		},
+		{
+			name: "manager removes user:manage from another role", method: http.MethodPut, target: "/roles/support",
+			body: `{"permissions": ["disease:write"], "version": 1}`, caller: "manager",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectRole(mock, "support", "{disease:write,user:manage}")
+				expectRole(mock, "manager", manager)
+			},
+			status: http.StatusForbidden, handler: UpdateRoleHandler,
+		},
+		{
+			name: "admin grants user:manage", method: http.MethodPut, target: "/roles/manager",
+			body: `{"permissions": ["role:manage", "disease:write", "user:manage"], "version": 1}`, caller: RoleAdmin,
+			expect: func(mock sqlmock.Sqlmock) {
+				expectRole(mock, "manager", manager)
+				expectRole(mock, "admin", "{}")
+				mock.ExpectExec(`UPDATE "roles" SET .* WHERE version = \$\d+ AND "name" = \$\d+`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			status: http.StatusOK, handler: UpdateRoleHandler,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := dbtest.Mock(t)
+			tt.expect(mock)
+
+			pattern := "/roles"
+			if tt.method == http.MethodPut {
+				pattern = "/roles/:name"
+			}
+			w := dbtest.Serve(tt.method, pattern, tt.target, tt.body, nil, "caller", asUser(tt.caller, tt.handler))
+			if w.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
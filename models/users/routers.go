@@ -1,10 +1,13 @@
 package users
 
 import (
+	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"plantheon-backend/common"
+	"plantheon-backend/models/media"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -50,7 +53,7 @@ func Register(c *gin.Context) {
 		Username: req.Username,
 		Password: req.Password,
 		FullName: req.FullName,
-		Role:     UserRole(req.Role), // Set role from request
+		Role:     RoleUser, // Roles are given by user managers, see UpdateUserRoleHandler
 	}
 
 	if err := CreateUser(user); err != nil {
@@ -162,11 +165,70 @@ func GetProfile(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", common.ETag(user.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"data": user.ToUserResponse(),
 	})
 }
 
+// GetAllUsers handles listing users for admins, with page/limit or cursor pagination
+func GetAllUsers(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		limit = 10
+	}
+	page, limit, _ = ValidatePaginationParams(page, limit)
+
+	search := strings.TrimSpace(c.Query("search"))
+	role := c.Query("role")
+	if role != "" {
+		if err := ValidateRole(role); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+
+	// Keyset pagination when a cursor is sent, page/limit otherwise
+	if common.CursorRequested(c) {
+		cursor, err := ParseUserCursor(c.Query("cursor"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		users, cursorPage, err := GetUsersPage(search, role, cursor, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to get users",
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": ToUsersCursorResponse(users, cursorPage),
+		})
+		return
+	}
+
+	users, total, err := GetUsers(search, role, (page-1)*limit, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get users",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": ToUsersListResponse(users, total, page, limit),
+	})
+}
+
 // UpdateProfile updates current user profile
 func UpdateProfile(c *gin.Context) {
 	user, exists := GetCurrentUser(c)
@@ -185,6 +247,19 @@ func UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	// Reject the update if the client edited an outdated version
+	precondition, err := common.ParsePrecondition(c, req.Version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(user.Version) {
+		respondUserConflict(c, precondition, user)
+		return
+	}
+
 	// Update user fields if provided
 	if req.Username != "" {
 		if err := ValidateUsername(req.Username); err != nil {
@@ -204,16 +279,168 @@ func UpdateProfile(c *gin.Context) {
 		user.Avatar = req.Avatar
 	}
 
+	if req.Timezone != "" {
+		if err := ValidateTimezone(req.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		user.Timezone = req.Timezone
+	}
+
 	// Save updated user
 	if err := UpdateUser(user); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetUserByID(user.ID); err == nil {
+				respondUserConflict(c, precondition, current)
+				return
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update user",
+		})
+		return
+	}
+
+	syncAvatarMedia(user)
+
+	c.Header("ETag", common.ETag(user.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Profile updated successfully",
+		"data":    user.ToUserResponse(),
+	})
+}
+
+// PatchProfile partially updates current user profile with JSON merge patch (RFC 7396)
+func PatchProfile(c *gin.Context) {
+	user, exists := GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found in context",
+		})
+		return
+	}
+
+	patch, err := common.ParseMergePatch(c)
+	if err != nil {
+		c.JSON(common.PatchErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Reject the patch if the client edited an outdated version
+	version, err := patch.Version()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	precondition, err := common.ParsePrecondition(c, version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(user.Version) {
+		respondUserConflict(c, precondition, user)
+		return
+	}
+
+	if err := ApplyProfilePatch(user, patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := UpdateUser(user); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetUserByID(user.ID); err == nil {
+				respondUserConflict(c, precondition, current)
+				return
+			}
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to update user",
 		})
 		return
 	}
 
+	syncAvatarMedia(user)
+
+	c.Header("ETag", common.ETag(user.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Profile updated successfully",
 		"data":    user.ToUserResponse(),
 	})
 }
+
+// respondUserConflict returns the current representation so the client can merge and retry
+func respondUserConflict(c *gin.Context, precondition *common.Precondition, current *User) {
+	c.Header("ETag", common.ETag(current.Version))
+	c.JSON(precondition.ConflictStatus(), gin.H{
+		"error": "Profile was modified by someone else",
+		"data":  current.ToUserResponse(),
+	})
+}
+
+// UploadAvatarHandler handles uploading an image and setting it as the current user's avatar
+func UploadAvatarHandler(c *gin.Context) {
+	user, exists := GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found in context",
+		})
+		return
+	}
+
+	data, err := media.ReadUpload(c, "file")
+	if err != nil {
+		media.RespondUploadError(c, err)
+		return
+	}
+
+	m, err := media.StoreImage(c.Request.Context(), data, user.ID)
+	if err != nil {
+		media.RespondUploadError(c, err)
+		return
+	}
+
+	user.Avatar = media.ContentURL(c, m.ID, "medium")
+	if err := UpdateUser(user); err != nil {
+		if err == common.ErrVersionConflict {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Profile was modified by someone else, please retry",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update user",
+		})
+		return
+	}
+
+	syncAvatarMedia(user)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Avatar updated successfully",
+		"data": gin.H{
+			"user":  user.ToUserResponse(),
+			"media": m.ToMediaResponse(c),
+		},
+	})
+}
+
+// syncAvatarMedia records whether the user's avatar is an uploaded media file
+func syncAvatarMedia(user *User) {
+	ids := media.MediaIDsFromURLs(user.Avatar)
+	if err := media.SyncReferences(media.OwnerUser, user.ID, ids); err != nil {
+		log.Printf("Failed to sync avatar media of user %s: %v", user.ID, err)
+	}
+}
@@ -1,6 +1,10 @@
 package users
 
-import "time"
+import (
+	"time"
+
+	"plantheon-backend/common"
+)
 
 // UserResponse represents user response without sensitive data
 type UserResponse struct {
@@ -10,6 +14,8 @@ type UserResponse struct {
 	FullName  string    `json:"full_name"`
 	Avatar    string    `json:"avatar"`
 	Role      UserRole  `json:"role"`
+	Timezone  string    `json:"timezone"`
+	Version   int       `json:"version"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -26,7 +32,6 @@ type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3"`
 	Password string `json:"password" binding:"required,min=6"`
 	FullName string `json:"full_name" binding:"required"`
-	Role     string `json:"role"` // Optional, defaults to "user"
 }
 
 // LoginRequest represents login request
@@ -40,6 +45,109 @@ type UpdateUserRequest struct {
 	Username string `json:"username"`
 	FullName string `json:"full_name"`
 	Avatar   string `json:"avatar"`
+	Timezone string `json:"timezone"` // IANA name such as Asia/Ho_Chi_Minh
+	Version  *int   `json:"version"` // Expected version when If-Match is not sent
+}
+
+// UsersListResponse represents paginated users response
+type UsersListResponse struct {
+	Users []UserResponse `json:"users"`
+	Total int64          `json:"total"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+	Pages int            `json:"pages"`
+}
+
+// UsersCursorResponse represents a keyset paginated users response
+type UsersCursorResponse struct {
+	Users []UserResponse `json:"users"`
+	common.CursorPage
+}
+
+// PermissionResponse describes a permission
+type PermissionResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// RoleResponse represents a role with its permissions
+type RoleResponse struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	System      bool      `json:"system"`
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateRoleRequest represents role creation request
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleRequest represents role update request, the permissions replace the current ones
+type UpdateRoleRequest struct {
+	Description *string   `json:"description"`
+	Permissions *[]string `json:"permissions"`
+	Version     *int      `json:"version"` // Expected version when If-Match is not sent
+}
+
+// SetupAdminRequest creates the first admin with the ADMIN_SETUP_TOKEN
+type SetupAdminRequest struct {
+	Token string `json:"token" binding:"required"`
+	RegisterRequest
+}
+
+// UpdateUserRoleRequest promotes or demotes a user
+type UpdateUserRoleRequest struct {
+	Role   string  `json:"role" binding:"required"`
+	Reason *string `json:"reason"`
+}
+
+// RoleChangeResponse represents a recorded role change
+type RoleChangeResponse struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	ActorID   *string   `json:"actor_id"`
+	OldRole   string    `json:"old_role"`
+	NewRole   string    `json:"new_role"`
+	Reason    *string   `json:"reason"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ToRoleChangeResponse converts RoleChange model to RoleChangeResponse
+func (r *RoleChange) ToRoleChangeResponse() RoleChangeResponse {
+	return RoleChangeResponse{
+		ID:        r.ID,
+		UserID:    r.UserID,
+		ActorID:   r.ActorID,
+		OldRole:   r.OldRole,
+		NewRole:   r.NewRole,
+		Reason:    r.Reason,
+		Source:    r.Source,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// ToRoleResponse converts Role model to RoleResponse
+func (r *Role) ToRoleResponse() RoleResponse {
+	permissions := []string(r.Permissions)
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return RoleResponse{
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: permissions,
+		System:      r.System,
+		Version:     r.Version,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
 }
 
 // ToUserResponse converts User model to UserResponse
@@ -51,7 +159,39 @@ func (u *User) ToUserResponse() UserResponse {
 		FullName:  u.FullName,
 		Avatar:    u.Avatar,
 		Role:      u.Role,
+		Timezone:  u.Timezone,
+		Version:   u.Version,
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
 	}
 }
+
+// ToUsersListResponse converts users slice to paginated response
+func ToUsersListResponse(users []User, total int64, page, limit int) UsersListResponse {
+	userResponses := make([]UserResponse, len(users))
+	for i, user := range users {
+		userResponses[i] = user.ToUserResponse()
+	}
+
+	pages := int(total) / limit
+	if int(total)%limit != 0 {
+		pages++
+	}
+
+	return UsersListResponse{
+		Users: userResponses,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+		Pages: pages,
+	}
+}
+
+// ToUsersCursorResponse converts a keyset page of users to response
+func ToUsersCursorResponse(users []User, page common.CursorPage) UsersCursorResponse {
+	userResponses := make([]UserResponse, len(users))
+	for i, user := range users {
+		userResponses[i] = user.ToUserResponse()
+	}
+	return UsersCursorResponse{Users: userResponses, CursorPage: page}
+}
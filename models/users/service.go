@@ -1,9 +1,13 @@
 package users
 
 import (
+	"errors"
+
 	"plantheon-backend/common"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserService handles all database operations for users
@@ -59,14 +63,266 @@ func GetUserByID(id string) (*User, error) {
 	return &user, err
 }
 
+// userKeyset is the order of keyset paginated user lists, newest first
+var userKeyset = common.Keyset{Column: "created_at", Desc: true}
+
+// ParseUserCursor decodes a user list cursor
+func ParseUserCursor(encoded string) (*common.Cursor, error) {
+	return userKeyset.ParseCursor(encoded)
+}
+
+// filterUsers matches the search keyword against email, username and full name, and the role
+func filterUsers(query *gorm.DB, search, role string) *gorm.DB {
+	if search != "" {
+		searchQuery := "%" + search + "%"
+		query = query.Where("email ILIKE ? OR username ILIKE ? OR full_name ILIKE ?", searchQuery, searchQuery, searchQuery)
+	}
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	return query
+}
+
+// GetUsers gets users with pagination, filtered by search keyword and role when they are not empty
+func GetUsers(search, role string, offset, limit int) ([]User, int64, error) {
+	service := NewUserService()
+	var users []User
+	var total int64
+
+	query := filterUsers(service.db.Model(&User{}), search, role)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC").Order("id DESC").Offset(offset).Limit(limit).Find(&users).Error
+	return users, total, err
+}
+
+// GetUsersPage gets a page of users after the cursor, the cursor is nil for the first page
+func GetUsersPage(search, role string, cursor *common.Cursor, limit int) ([]User, common.CursorPage, error) {
+	service := NewUserService()
+	var users []User
+
+	query := filterUsers(service.db, search, role)
+	if err := userKeyset.Apply(query, cursor, limit).Find(&users).Error; err != nil {
+		return nil, common.CursorPage{}, err
+	}
+	users, page := common.Page(users, cursor, limit, userKeyset, func(u *User) (interface{}, string) {
+		return u.CreatedAt, u.ID
+	})
+	return users, page, nil
+}
+
 // UpdateUser updates user information
+// The update only applies if the stored version still equals user.Version,
+// otherwise common.ErrVersionConflict is returned. On success the version is incremented.
 func UpdateUser(user *User) error {
 	service := NewUserService()
-	return service.db.Save(user).Error
+	expected := user.Version
+	user.Version = expected + 1
+
+	result := service.db.Model(user).
+		Where("version = ?", expected).
+		Select("*").Omit("id", "created_at").
+		Updates(user)
+	if result.Error != nil {
+		user.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		user.Version = expected
+		return common.ErrVersionConflict
+	}
+	return nil
 }
 
 // DeleteUser deletes user by ID
 func DeleteUser(id string) error {
 	service := NewUserService()
 	return service.db.Delete(&User{}, "id = ?", id).Error
-}
\ No newline at end of file
+}
+
+// ErrRoleInUse is returned when deleting a role still given to users
+var ErrRoleInUse = errors.New("role is still given to users")
+
+// SeedRoles creates the built-in roles if missing and grants every permission to admins
+func SeedRoles() error {
+	service := NewUserService()
+	builtIn := []Role{
+		{Name: string(RoleUser), Description: "Registered user", Permissions: pq.StringArray{}, System: true},
+		{Name: string(RoleAdmin), Description: "Administrator", Permissions: AllPermissions(), System: true},
+	}
+	for i := range builtIn {
+		role := builtIn[i]
+		if err := service.db.Where("name = ?", role.Name).Attrs(role).FirstOrCreate(&role).Error; err != nil {
+			return err
+		}
+		if role.Name == string(RoleAdmin) {
+			if err := service.db.Model(&role).Update("permissions", AllPermissions()).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetRoles lists the roles by name
+func GetRoles() ([]Role, error) {
+	service := NewUserService()
+	var roles []Role
+	err := service.db.Order("name ASC").Find(&roles).Error
+	return roles, err
+}
+
+// GetRoleByName finds role by name
+func GetRoleByName(name string) (*Role, error) {
+	service := NewUserService()
+	var role Role
+	err := service.db.Where("name = ?", name).First(&role).Error
+	return &role, err
+}
+
+// CreateRoleRecord creates a new role
+func CreateRoleRecord(role *Role) error {
+	service := NewUserService()
+	return service.db.Create(role).Error
+}
+
+// UpdateRole updates the description and permissions of a role.
+// The update only applies if the stored version still equals role.Version,
+// otherwise common.ErrVersionConflict is returned. On success the version is incremented.
+func UpdateRole(role *Role) error {
+	service := NewUserService()
+	expected := role.Version
+	role.Version = expected + 1
+
+	result := service.db.Model(role).
+		Where("version = ?", expected).
+		Select("description", "permissions", "version", "updated_at").
+		Updates(role)
+	if result.Error != nil {
+		role.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		role.Version = expected
+		return common.ErrVersionConflict
+	}
+	return nil
+}
+
+// DeleteRole deletes a role no user has, ErrRoleInUse is returned otherwise
+func DeleteRole(name string) error {
+	service := NewUserService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&User{}).Where("role = ?", name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrRoleInUse
+		}
+		return tx.Where("name = ?", name).Delete(&Role{}).Error
+	})
+}
+
+// ErrLastAdmin is returned when demoting the only admin
+var ErrLastAdmin = errors.New("the last admin cannot be demoted")
+
+// ErrAdminExists is returned by the one-time setup once an admin exists
+var ErrAdminExists = errors.New("an admin already exists")
+
+// ChangeUserRole gives the user a new role and records the change. The update only applies
+// if the user still has the role that was read, otherwise common.ErrVersionConflict is returned.
+// Demoting the only admin returns ErrLastAdmin.
+func ChangeUserRole(user *User, role UserRole, change *RoleChange) error {
+	service := NewUserService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		if user.Role == RoleAdmin && role != RoleAdmin {
+			// Lock the admins so two demotions cannot both pass the check
+			var adminIDs []string
+			if err := tx.Model(&User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("role = ?", RoleAdmin).Pluck("id", &adminIDs).Error; err != nil {
+				return err
+			}
+			if len(adminIDs) <= 1 {
+				return ErrLastAdmin
+			}
+		}
+
+		result := tx.Model(&User{}).
+			Where("id = ? AND role = ?", user.ID, user.Role).
+			Updates(map[string]interface{}{"role": role, "version": gorm.Expr("version + 1")})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return common.ErrVersionConflict
+		}
+
+		change.UserID = user.ID
+		change.OldRole = string(user.Role)
+		change.NewRole = string(role)
+		if err := tx.Create(change).Error; err != nil {
+			return err
+		}
+		user.Role = role
+		user.Version++
+		return nil
+	})
+}
+
+// CreateAdminUser creates a user with the admin role and records it, see CreateUser
+func CreateAdminUser(user *User, source string) error {
+	service := NewUserService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		return createAdmin(tx, user, source)
+	})
+}
+
+// CreateFirstAdmin creates the first admin, ErrAdminExists is returned once there is one
+func CreateFirstAdmin(user *User, source string) error {
+	service := NewUserService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the admin role so concurrent setups run one after the other
+		var role Role
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("name = ?", RoleAdmin).First(&role).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&User{}).Where("role = ?", RoleAdmin).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAdminExists
+		}
+		return createAdmin(tx, user, source)
+	})
+}
+
+// createAdmin hashes the password, creates the admin and records the role change in tx
+func createAdmin(tx *gorm.DB, user *User, source string) error {
+	hashedPassword, err := common.HashPassword(user.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	user.Role = RoleAdmin
+	if err := tx.Create(user).Error; err != nil {
+		return err
+	}
+	return tx.Create(&RoleChange{
+		UserID:  user.ID,
+		NewRole: string(RoleAdmin),
+		Source:  source,
+	}).Error
+}
+
+// GetRoleChanges lists the role changes of a user, newest first
+func GetRoleChanges(userID string) ([]RoleChange, error) {
+	service := NewUserService()
+	var changes []RoleChange
+	err := service.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&changes).Error
+	return changes, err
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"regexp"
 	"strings"
+
+	"plantheon-backend/common"
 )
 
 // ValidateEmail validates email format
@@ -62,14 +64,79 @@ func ValidatePassword(password string) error {
 	return nil
 }
 
-// ValidateRole validates user role
+// roleNameRegex matches the role names, they are stored in users.role
+var roleNameRegex = regexp.MustCompile(`^[a-z0-9_-]{2,20}$`)
+
+// ValidateRole validates a role name: 2 to 20 lowercase letters, digits, '-' or '_'
 func ValidateRole(role string) error {
-	if role != "user" && role != "admin" {
-		return errors.New("role must be either 'user' or 'admin'")
+	if !roleNameRegex.MatchString(role) {
+		return errors.New("role must be 2 to 20 lowercase letters, digits, '-' or '_'")
+	}
+	return nil
+}
+
+// ValidateCreateRoleRequest validates role creation request
+func ValidateCreateRoleRequest(req *CreateRoleRequest) error {
+	req.Name = strings.ToLower(strings.TrimSpace(req.Name))
+	if err := ValidateRole(req.Name); err != nil {
+		return err
+	}
+	return validateRoleFields(&req.Description, req.Permissions)
+}
+
+// ValidateUpdateRoleRequest validates role update request
+func ValidateUpdateRoleRequest(req *UpdateRoleRequest) error {
+	if req.Description != nil {
+		if err := validateRoleFields(req.Description, nil); err != nil {
+			return err
+		}
+	}
+	if req.Permissions != nil {
+		return validateRoleFields(nil, *req.Permissions)
 	}
 	return nil
 }
 
+// ValidateUpdateUserRoleRequest validates the new role of a user
+func ValidateUpdateUserRoleRequest(req *UpdateUserRoleRequest) error {
+	req.Role = strings.ToLower(strings.TrimSpace(req.Role))
+	if err := ValidateRole(req.Role); err != nil {
+		return err
+	}
+	if req.Reason != nil {
+		*req.Reason = strings.TrimSpace(*req.Reason)
+		if len(*req.Reason) > 500 {
+			return errors.New("reason must be less than 500 characters")
+		}
+	}
+	return nil
+}
+
+// validateRoleFields trims the description and checks every permission is known
+func validateRoleFields(description *string, permissions []string) error {
+	if description != nil {
+		*description = strings.TrimSpace(*description)
+		if len(*description) > 500 {
+			return errors.New("description must be less than 500 characters")
+		}
+	}
+	for _, permission := range permissions {
+		if !IsPermission(permission) {
+			return errors.New("unknown permission: " + permission)
+		}
+	}
+	return nil
+}
+
+// ValidateTimezone validates an IANA timezone name, empty means UTC
+func ValidateTimezone(timezone string) error {
+	if len(timezone) > 64 {
+		return errors.New("timezone must be less than 64 characters")
+	}
+	_, err := common.LoadTimezone(timezone)
+	return err
+}
+
 // ValidateRegisterRequest validates registration request
 func ValidateRegisterRequest(req *RegisterRequest) error {
 	if err := ValidateEmail(req.Email); err != nil {
@@ -96,3 +163,60 @@ func ValidateRegisterRequest(req *RegisterRequest) error {
 	
 	return nil
 }
+
+
+// ApplyProfilePatch validates a JSON merge patch of the profile and applies it.
+// full_name, avatar and timezone set to null are cleared, username cannot be cleared.
+func ApplyProfilePatch(user *User, patch common.MergePatch) error {
+	if err := patch.CheckFields("username", "full_name", "avatar", "timezone", "version"); err != nil {
+		return err
+	}
+	if err := patch.CheckNotNull("username"); err != nil {
+		return err
+	}
+
+	var req UpdateUserRequest
+	if err := patch.Decode(&req); err != nil {
+		return errors.New("invalid field type in merge patch")
+	}
+
+	if patch.Has("username") {
+		if err := ValidateUsername(req.Username); err != nil {
+			return err
+		}
+		user.Username = req.Username
+	}
+	if patch.Has("full_name") {
+		req.FullName = strings.TrimSpace(req.FullName)
+		if len(req.FullName) > 100 {
+			return errors.New("full name must be less than 100 characters")
+		}
+		user.FullName = req.FullName
+	}
+	if patch.Has("avatar") {
+		user.Avatar = strings.TrimSpace(req.Avatar)
+	}
+	if patch.Has("timezone") {
+		req.Timezone = strings.TrimSpace(req.Timezone)
+		if err := ValidateTimezone(req.Timezone); err != nil {
+			return err
+		}
+		user.Timezone = req.Timezone
+	}
+
+	return nil
+}
+
+// ValidatePaginationParams validates pagination parameters
+func ValidatePaginationParams(page, limit int) (int, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return page, limit, nil
+}
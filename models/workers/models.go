@@ -0,0 +1,136 @@
+package workers
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Worker is a person working on the user's farm, e.g. a hired labourer or a family member.
+// A worker with an account of their own is linked to it, so they see the tasks assigned to them.
+type Worker struct {
+	ID           string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID       string         `json:"user_id" gorm:"type:uuid;not null;index"` // Farm owner
+	Name         string         `json:"name" gorm:"type:varchar(255);not null"`
+	Phone        *string        `json:"phone" gorm:"type:varchar(50)"`
+	LinkedUserID *string        `json:"linked_user_id" gorm:"type:uuid;index"` // The worker's own account
+	Note         *string        `json:"note" gorm:"type:text"`
+	HourlyRate   *float64       `json:"hourly_rate" gorm:"type:decimal(15,2)"` // Default rate of hourly sessions
+	PieceRate    *float64       `json:"piece_rate" gorm:"type:decimal(15,2)"`  // Default rate of piece-rate sessions
+	Version      int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// Pay bases of a work session
+const (
+	BasisHourly = "hourly" // Paid hours * rate
+	BasisPiece  = "piece"  // Paid quantity * rate, e.g. per kg picked
+)
+
+// WorkSession is a stretch of work of a worker on a day. Its amount is fixed when it is
+// recorded, a session paid by a payment cannot be changed any more.
+type WorkSession struct {
+	ID         string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID     string         `json:"user_id" gorm:"type:uuid;not null;index"` // Farm owner
+	WorkerID   string         `json:"worker_id" gorm:"type:uuid;not null;index"`
+	Date       time.Time      `json:"date" gorm:"type:date;not null;index"`
+	Basis      string         `json:"basis" gorm:"type:varchar(20);not null"`
+	Hours      *float64       `json:"hours" gorm:"type:decimal(10,2)"`
+	Quantity   *float64       `json:"quantity" gorm:"type:decimal(15,4)"`
+	Unit       *string        `json:"unit" gorm:"type:varchar(50)"` // Unit of the piece-rate quantity
+	Rate       float64        `json:"rate" gorm:"type:decimal(15,2);not null"`
+	Amount     float64        `json:"amount" gorm:"type:decimal(15,2);not null"`
+	ActivityID *string        `json:"activity_id" gorm:"type:uuid;index"` // Work done for this activity
+	PlotID     *string        `json:"plot_id" gorm:"type:uuid;index"`
+	PaymentID  *string        `json:"payment_id" gorm:"type:uuid;index"` // Set once paid
+	Note       *string        `json:"note" gorm:"type:text"`
+	Version    int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// Payment pays the unpaid sessions of a worker in a pay period. It is recorded in the
+// ledger as a labor activity.
+type Payment struct {
+	ID         string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID     string         `json:"user_id" gorm:"type:uuid;not null;index"` // Farm owner
+	WorkerID   string         `json:"worker_id" gorm:"type:uuid;not null;index"`
+	PeriodFrom time.Time      `json:"period_from" gorm:"type:date;not null"`
+	PeriodTo   time.Time      `json:"period_to" gorm:"type:date;not null"` // Last day of the period
+	Amount     float64        `json:"amount" gorm:"type:decimal(15,2);not null"`
+	Sessions   int            `json:"sessions" gorm:"not null"`
+	PaidAt     time.Time      `json:"paid_at" gorm:"not null;index"`
+	ActivityID string         `json:"activity_id" gorm:"type:uuid;not null"` // Ledger activity
+	Note       *string        `json:"note" gorm:"type:text"`
+	CreatedAt  time.Time      `json:"created_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// TableName prefixes the table so it does not clash with other kinds of payments
+func (Payment) TableName() string {
+	return "worker_payments"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (w *Worker) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (s *WorkSession) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (p *Payment) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// PayPeriod is a range of whole days, To is the last day of the period
+type PayPeriod struct {
+	From time.Time
+	To   time.Time
+}
+
+// End returns the exclusive end of the period
+func (p *PayPeriod) End() time.Time {
+	return p.To.AddDate(0, 0, 1)
+}
+
+// roundMoney rounds to the cents stored by the decimal columns
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// DefaultRate returns the worker's default rate of the pay basis
+func (w *Worker) DefaultRate(basis string) *float64 {
+	if basis == BasisPiece {
+		return w.PieceRate
+	}
+	return w.HourlyRate
+}
+
+// ComputeAmount sets the payable amount of the session from its rate
+func (s *WorkSession) ComputeAmount() {
+	units := 0.0
+	if s.Basis == BasisPiece && s.Quantity != nil {
+		units = *s.Quantity
+	} else if s.Hours != nil {
+		units = *s.Hours
+	}
+	s.Amount = roundMoney(units * s.Rate)
+}
@@ -0,0 +1,761 @@
+package workers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/activities"
+	"plantheon-backend/models/users"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateWorkerHandler adds a worker to the current user's farm
+func CreateWorkerHandler(c *gin.Context) {
+	var req CreateWorkerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateWorkerRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if req.LinkedUserID != nil && !checkLinkedUser(c, userID, *req.LinkedUserID, "") {
+		return
+	}
+
+	worker := &Worker{
+		UserID:       userID,
+		Name:         req.Name,
+		Phone:        req.Phone,
+		LinkedUserID: req.LinkedUserID,
+		Note:         req.Note,
+		HourlyRate:   req.HourlyRate,
+		PieceRate:    req.PieceRate,
+	}
+
+	if err := CreateWorkerRecord(worker); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create worker",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Worker created successfully",
+		"data":    worker.ToWorkerResponse(),
+	})
+}
+
+// GetWorkersHandler lists the workers of the current user's farm
+// Query: GET /api/v1/workers?search=...
+func GetWorkersHandler(c *gin.Context) {
+	workers, err := GetWorkersByUser(c.GetString("user_id"), strings.TrimSpace(c.Query("search")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get workers",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": ToWorkersResponse(workers),
+	})
+}
+
+// GetWorkerHandler handles getting one worker of the current user's farm
+func GetWorkerHandler(c *gin.Context) {
+	worker, ok := loadUserWorker(c)
+	if !ok {
+		return
+	}
+
+	c.Header("ETag", common.ETag(worker.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"data": worker.ToWorkerResponse(),
+	})
+}
+
+// UpdateWorkerHandler handles worker update
+func UpdateWorkerHandler(c *gin.Context) {
+	worker, ok := loadUserWorker(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateWorkerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateUpdateWorkerRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Reject the update if the client edited an outdated version
+	precondition, err := common.ParsePrecondition(c, req.Version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(worker.Version) {
+		respondWorkerConflict(c, precondition, worker)
+		return
+	}
+
+	// Update worker fields if provided
+	if req.Name != nil {
+		worker.Name = *req.Name
+	}
+	if req.Phone != nil {
+		worker.Phone = req.Phone
+	}
+	if req.LinkedUserID != nil {
+		if *req.LinkedUserID == "" {
+			worker.LinkedUserID = nil
+		} else {
+			if !checkLinkedUser(c, worker.UserID, *req.LinkedUserID, worker.ID) {
+				return
+			}
+			worker.LinkedUserID = req.LinkedUserID
+		}
+	}
+	if req.Note != nil {
+		worker.Note = req.Note
+	}
+	if req.HourlyRate != nil {
+		worker.HourlyRate = req.HourlyRate
+	}
+	if req.PieceRate != nil {
+		worker.PieceRate = req.PieceRate
+	}
+
+	if err := UpdateWorker(worker); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved between our read and write
+			if current, err := GetWorkerByID(worker.ID); err == nil {
+				respondWorkerConflict(c, precondition, current)
+				return
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update worker",
+		})
+		return
+	}
+
+	c.Header("ETag", common.ETag(worker.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Worker updated successfully",
+		"data":    worker.ToWorkerResponse(),
+	})
+}
+
+// DeleteWorkerHandler handles worker deletion, the worker is unassigned from their tasks
+func DeleteWorkerHandler(c *gin.Context) {
+	worker, ok := loadUserWorker(c)
+	if !ok {
+		return
+	}
+
+	if err := DeleteWorker(worker.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete worker",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Worker deleted successfully",
+	})
+}
+
+// checkLinkedUser verifies the account exists and no other worker of the farm is linked to it,
+// writing the error response and returning false otherwise
+func checkLinkedUser(c *gin.Context, userID, linkedUserID, excludeID string) bool {
+	if _, err := users.GetUserByID(linkedUserID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Linked user not found",
+			})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get linked user",
+		})
+		return false
+	}
+
+	taken, err := LinkedUserTaken(userID, linkedUserID, excludeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check linked user",
+		})
+		return false
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Another worker is already linked to this user",
+		})
+		return false
+	}
+	return true
+}
+
+// loadUserWorker loads the worker from the :id parameter if they work on the current user's farm,
+// writing the error response and returning false otherwise
+func loadUserWorker(c *gin.Context) (*Worker, bool) {
+	worker, err := GetUserWorker(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Worker not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get worker",
+		})
+		return nil, false
+	}
+	return worker, true
+}
+
+// respondWorkerConflict returns the current representation so the client can merge and retry
+func respondWorkerConflict(c *gin.Context, precondition *common.Precondition, current *Worker) {
+	c.Header("ETag", common.ETag(current.Version))
+	c.JSON(precondition.ConflictStatus(), gin.H{
+		"error": "Worker was modified by someone else",
+		"data":  current.ToWorkerResponse(),
+	})
+}
+
+// GetWorkSessionsHandler lists the sessions of a worker with their totals
+// Query: GET /api/v1/workers/:id/sessions?from=YYYY-MM-DD&to=YYYY-MM-DD&unpaid=true
+func GetWorkSessionsHandler(c *gin.Context) {
+	worker, ok := loadUserWorker(c)
+	if !ok {
+		return
+	}
+
+	var period *PayPeriod
+	if c.Query("from") != "" || c.Query("to") != "" {
+		var err error
+		if period, err = ParsePayPeriod(c.Query("from"), c.Query("to")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+
+	sessions, err := GetWorkSessions(worker.ID, period, c.Query("unpaid") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get work sessions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": ToWorkSessionsResponse(sessions),
+	})
+}
+
+// CreateWorkSessionHandler records a work session of a worker
+func CreateWorkSessionHandler(c *gin.Context) {
+	worker, ok := loadUserWorker(c)
+	if !ok {
+		return
+	}
+
+	var req CreateWorkSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateCreateWorkSessionRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	date, _ := parseSessionDate(req.Date)
+	session := &WorkSession{
+		UserID:     worker.UserID,
+		WorkerID:   worker.ID,
+		Date:       date,
+		Basis:      req.Basis,
+		Hours:      req.Hours,
+		Quantity:   req.Quantity,
+		Unit:       req.Unit,
+		ActivityID: req.ActivityID,
+		PlotID:     req.PlotID,
+		Note:       req.Note,
+	}
+	if err := ValidateWorkSession(session); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !applyRate(c, session, req.Rate, worker) || !checkSessionLinks(c, session) {
+		return
+	}
+
+	if err := CreateWorkSessionRecord(session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create work session",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Work session created successfully",
+		"data":    session.ToWorkSessionResponse(),
+	})
+}
+
+// UpdateWorkSessionHandler handles work session update, paid sessions cannot be changed
+func UpdateWorkSessionHandler(c *gin.Context) {
+	worker, ok := loadUserWorker(c)
+	if !ok {
+		return
+	}
+	session, ok := loadUnpaidSession(c, worker)
+	if !ok {
+		return
+	}
+
+	var req UpdateWorkSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if err := ValidateUpdateWorkSessionRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Reject the update if the client edited an outdated version
+	precondition, err := common.ParsePrecondition(c, req.Version)
+	if err != nil {
+		c.JSON(common.PreconditionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !precondition.Matches(session.Version) {
+		respondSessionConflict(c, precondition, session)
+		return
+	}
+
+	// Update session fields if provided, switching the basis drops the units of the other basis
+	if req.Date != nil {
+		session.Date, _ = parseSessionDate(*req.Date)
+	}
+	rate := req.Rate
+	if req.Basis != nil && *req.Basis != session.Basis {
+		session.Basis = *req.Basis
+		if session.Basis == BasisPiece {
+			session.Hours = nil
+		} else {
+			session.Quantity = nil
+			session.Unit = nil
+		}
+		if rate == nil {
+			rate = worker.DefaultRate(session.Basis)
+			if rate == nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "rate is required when the worker has no default rate for the basis",
+				})
+				return
+			}
+		}
+	}
+	if req.Hours != nil {
+		session.Hours = req.Hours
+	}
+	if req.Quantity != nil {
+		session.Quantity = req.Quantity
+	}
+	if req.Unit != nil {
+		session.Unit = req.Unit
+	}
+	if req.ActivityID != nil {
+		session.ActivityID = emptyToNil(req.ActivityID)
+	}
+	if req.PlotID != nil {
+		session.PlotID = emptyToNil(req.PlotID)
+	}
+	if req.Note != nil {
+		session.Note = req.Note
+	}
+	if err := ValidateWorkSession(session); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if rate == nil {
+		rate = &session.Rate
+	}
+	if !applyRate(c, session, rate, worker) {
+		return
+	}
+	if (req.ActivityID != nil || req.PlotID != nil) && !checkSessionLinks(c, session) {
+		return
+	}
+
+	if err := UpdateWorkSession(session); err != nil {
+		if err == common.ErrVersionConflict {
+			// Someone else saved or paid the session between our read and write
+			if current, err := GetWorkSessionByID(session.ID); err == nil {
+				respondSessionConflict(c, precondition, current)
+				return
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update work session",
+		})
+		return
+	}
+
+	c.Header("ETag", common.ETag(session.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Work session updated successfully",
+		"data":    session.ToWorkSessionResponse(),
+	})
+}
+
+// DeleteWorkSessionHandler handles work session deletion, paid sessions cannot be deleted
+func DeleteWorkSessionHandler(c *gin.Context) {
+	worker, ok := loadUserWorker(c)
+	if !ok {
+		return
+	}
+	session, ok := loadUnpaidSession(c, worker)
+	if !ok {
+		return
+	}
+
+	deleted, err := DeleteWorkSession(session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete work session",
+		})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Work session is already paid",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Work session deleted successfully",
+	})
+}
+
+// GetPayrollHandler sums up the pay of every worker of the farm in a pay period
+// Query: GET /api/v1/workers/payroll?from=YYYY-MM-DD&to=YYYY-MM-DD
+func GetPayrollHandler(c *gin.Context) {
+	period, err := ParsePayPeriod(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	lines, err := GetPayrollLines(userID, period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get payroll",
+		})
+		return
+	}
+	names, err := GetWorkerNames(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get workers",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": BuildPayroll(lines, names, period),
+	})
+}
+
+// CreatePaymentHandler pays the unpaid sessions of a worker in a pay period
+// and records the payment as a labor expense
+func CreatePaymentHandler(c *gin.Context) {
+	worker, ok := loadUserWorker(c)
+	if !ok {
+		return
+	}
+
+	var req CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	period, err := ValidateCreatePaymentRequest(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	paidAt := time.Now()
+	if req.PaidAt != nil {
+		paidAt = *req.PaidAt
+	}
+
+	payment, err := PayWorker(worker, period, paidAt, req.Note)
+	if err != nil {
+		if err == ErrNothingToPay {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "No unpaid work sessions in this period",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create payment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Payment created successfully",
+		"data":    payment.ToPaymentResponse(),
+	})
+}
+
+// GetPaymentsHandler lists the payments of a worker
+func GetPaymentsHandler(c *gin.Context) {
+	worker, ok := loadUserWorker(c)
+	if !ok {
+		return
+	}
+
+	payments, err := GetWorkerPayments(worker.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get payments",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": ToPaymentsResponse(payments),
+	})
+}
+
+// DeletePaymentHandler cancels a payment, its sessions become payable again
+// and its labor activity is moved to the trash
+func DeletePaymentHandler(c *gin.Context) {
+	worker, ok := loadUserWorker(c)
+	if !ok {
+		return
+	}
+
+	payment, err := GetWorkerPayment(c.Param("payment_id"), worker.ID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Payment not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get payment",
+		})
+		return
+	}
+
+	if err := CancelPayment(payment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete payment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payment deleted successfully",
+	})
+}
+
+// GetPayslipHandler exports the sessions of a worker in a pay period as an XLSX payslip
+// Query: GET /api/v1/workers/:id/payslip?from=YYYY-MM-DD&to=YYYY-MM-DD
+func GetPayslipHandler(c *gin.Context) {
+	worker, ok := loadUserWorker(c)
+	if !ok {
+		return
+	}
+
+	period, err := ParsePayPeriod(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	sessions, err := GetWorkSessions(worker.ID, period, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get work sessions",
+		})
+		return
+	}
+
+	file, err := activities.WriteExcelRows(payslipRows(worker, period, sessions))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to build payslip",
+		})
+		return
+	}
+	defer file.Close()
+
+	filename := fmt.Sprintf("payslip-%s-%s.xlsx", period.From.Format("20060102"), period.To.Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	if err := file.Write(c.Writer); err != nil {
+		c.Status(http.StatusInternalServerError)
+	}
+}
+
+// applyRate sets the rate of the session, falling back to the worker's default rate,
+// and computes its amount. It writes the error response and returns false if there is no rate.
+func applyRate(c *gin.Context, session *WorkSession, rate *float64, worker *Worker) bool {
+	if rate == nil {
+		rate = worker.DefaultRate(session.Basis)
+	}
+	if rate == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "rate is required when the worker has no default rate for the basis",
+		})
+		return false
+	}
+	session.Rate = *rate
+	session.ComputeAmount()
+	return true
+}
+
+// checkSessionLinks verifies the linked activity and plot belong to the farm,
+// the session takes the plot of its activity when none is given.
+// It writes the error response and returns false otherwise.
+func checkSessionLinks(c *gin.Context, session *WorkSession) bool {
+	if session.ActivityID != nil {
+		activity, err := activities.GetTenantActivityByID(*session.ActivityID, common.Tenant{UserID: session.UserID})
+		if err != nil && err != gorm.ErrRecordNotFound {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to get activity",
+			})
+			return false
+		}
+		if err != nil || activity.UserID == nil || *activity.UserID != session.UserID {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Activity not found",
+			})
+			return false
+		}
+		if session.PlotID == nil {
+			session.PlotID = activity.PlotID
+		}
+	}
+
+	if session.PlotID != nil {
+		ok, err := activities.PlotBelongsToTenant(*session.PlotID, common.Tenant{UserID: session.UserID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check plot",
+			})
+			return false
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Plot not found",
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// loadUnpaidSession loads the session from the :session_id parameter if it is an unpaid session of the worker,
+// writing the error response and returning false otherwise
+func loadUnpaidSession(c *gin.Context, worker *Worker) (*WorkSession, bool) {
+	session, err := GetWorkerSession(c.Param("session_id"), worker.ID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Work session not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get work session",
+		})
+		return nil, false
+	}
+	if session.PaymentID != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Work session is already paid",
+		})
+		return nil, false
+	}
+	return session, true
+}
+
+// respondSessionConflict returns the current representation so the client can merge and retry
+func respondSessionConflict(c *gin.Context, precondition *common.Precondition, current *WorkSession) {
+	c.Header("ETag", common.ETag(current.Version))
+	c.JSON(precondition.ConflictStatus(), gin.H{
+		"error": "Work session was modified by someone else",
+		"data":  current.ToWorkSessionResponse(),
+	})
+}
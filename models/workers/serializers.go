@@ -0,0 +1,310 @@
+package workers
+
+import (
+	"strconv"
+	"time"
+
+	"plantheon-backend/common"
+)
+
+// WorkerResponse represents worker response
+type WorkerResponse struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Phone        *string   `json:"phone"`
+	LinkedUserID *string   `json:"linked_user_id"`
+	Note         *string   `json:"note"`
+	HourlyRate   *float64  `json:"hourly_rate"`
+	PieceRate    *float64  `json:"piece_rate"`
+	Version      int       `json:"version"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CreateWorkerRequest represents worker creation request
+type CreateWorkerRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	Phone        *string  `json:"phone"`
+	LinkedUserID *string  `json:"linked_user_id"`
+	Note         *string  `json:"note"`
+	HourlyRate   *float64 `json:"hourly_rate"`
+	PieceRate    *float64 `json:"piece_rate"`
+}
+
+// UpdateWorkerRequest represents worker update request, an empty linked_user_id unlinks the account
+type UpdateWorkerRequest struct {
+	Name         *string  `json:"name"`
+	Phone        *string  `json:"phone"`
+	LinkedUserID *string  `json:"linked_user_id"`
+	Note         *string  `json:"note"`
+	HourlyRate   *float64 `json:"hourly_rate"`
+	PieceRate    *float64 `json:"piece_rate"`
+	Version      *int     `json:"version"` // Expected version when If-Match is not sent
+}
+
+// ToWorkerResponse converts Worker to WorkerResponse
+func (w *Worker) ToWorkerResponse() WorkerResponse {
+	return WorkerResponse{
+		ID:           w.ID,
+		Name:         w.Name,
+		Phone:        w.Phone,
+		LinkedUserID: w.LinkedUserID,
+		Note:         w.Note,
+		HourlyRate:   w.HourlyRate,
+		PieceRate:    w.PieceRate,
+		Version:      w.Version,
+		CreatedAt:    w.CreatedAt,
+		UpdatedAt:    w.UpdatedAt,
+	}
+}
+
+// ToWorkersResponse converts a list of workers
+func ToWorkersResponse(workers []Worker) []WorkerResponse {
+	response := make([]WorkerResponse, 0, len(workers))
+	for i := range workers {
+		response = append(response, workers[i].ToWorkerResponse())
+	}
+	return response
+}
+
+// WorkSessionResponse represents work session response
+type WorkSessionResponse struct {
+	ID         string    `json:"id"`
+	WorkerID   string    `json:"worker_id"`
+	Date       string    `json:"date"`
+	Basis      string    `json:"basis"`
+	Hours      *float64  `json:"hours"`
+	Quantity   *float64  `json:"quantity"`
+	Unit       *string   `json:"unit"`
+	Rate       float64   `json:"rate"`
+	Amount     float64   `json:"amount"`
+	ActivityID *string   `json:"activity_id"`
+	PlotID     *string   `json:"plot_id"`
+	PaymentID  *string   `json:"payment_id"`
+	Paid       bool      `json:"paid"`
+	Note       *string   `json:"note"`
+	Version    int       `json:"version"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CreateWorkSessionRequest represents work session creation request,
+// the rate defaults to the worker's rate of the basis
+type CreateWorkSessionRequest struct {
+	Date       string   `json:"date" binding:"required"` // YYYY-MM-DD
+	Basis      string   `json:"basis" binding:"required"`
+	Hours      *float64 `json:"hours"`
+	Quantity   *float64 `json:"quantity"`
+	Unit       *string  `json:"unit"`
+	Rate       *float64 `json:"rate"`
+	ActivityID *string  `json:"activity_id"`
+	PlotID     *string  `json:"plot_id"`
+	Note       *string  `json:"note"`
+}
+
+// UpdateWorkSessionRequest represents work session update request, only unpaid sessions can be updated
+type UpdateWorkSessionRequest struct {
+	Date       *string  `json:"date"`
+	Basis      *string  `json:"basis"`
+	Hours      *float64 `json:"hours"`
+	Quantity   *float64 `json:"quantity"`
+	Unit       *string  `json:"unit"`
+	Rate       *float64 `json:"rate"`
+	ActivityID *string  `json:"activity_id"` // Empty unlinks the activity
+	PlotID     *string  `json:"plot_id"`     // Empty unlinks the plot
+	Note       *string  `json:"note"`
+	Version    *int     `json:"version"` // Expected version when If-Match is not sent
+}
+
+// WorkSessionsResponse lists the sessions of a worker with their totals
+type WorkSessionsResponse struct {
+	Sessions []WorkSessionResponse `json:"sessions"`
+	Hours    float64               `json:"hours"`
+	Amount   float64               `json:"amount"`
+	Unpaid   float64               `json:"unpaid"`
+}
+
+// PayrollLine is the pay of one worker in a pay period
+type PayrollLine struct {
+	WorkerID   string  `json:"worker_id"`
+	WorkerName string  `json:"worker_name"`
+	Sessions   int     `json:"sessions"`
+	Hours      float64 `json:"hours"`
+	Amount     float64 `json:"amount"`
+	Paid       float64 `json:"paid"`
+	Payable    float64 `json:"payable"` // Amount of the unpaid sessions
+}
+
+// PayrollResponse represents the payroll of the farm for a pay period
+type PayrollResponse struct {
+	From    string        `json:"from"`
+	To      string        `json:"to"`
+	Amount  float64       `json:"amount"`
+	Paid    float64       `json:"paid"`
+	Payable float64       `json:"payable"`
+	Workers []PayrollLine `json:"workers"`
+}
+
+// CreatePaymentRequest pays the unpaid sessions of a worker from from to to inclusive
+type CreatePaymentRequest struct {
+	From   string     `json:"from" binding:"required"` // YYYY-MM-DD
+	To     string     `json:"to" binding:"required"`   // YYYY-MM-DD
+	PaidAt *time.Time `json:"paid_at"`                 // Defaults to now
+	Note   *string    `json:"note"`
+}
+
+// PaymentResponse represents worker payment response
+type PaymentResponse struct {
+	ID         string    `json:"id"`
+	WorkerID   string    `json:"worker_id"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Amount     float64   `json:"amount"`
+	Sessions   int       `json:"sessions"`
+	PaidAt     time.Time `json:"paid_at"`
+	ActivityID string    `json:"activity_id"`
+	Note       *string   `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ToWorkSessionResponse converts WorkSession to WorkSessionResponse
+func (s *WorkSession) ToWorkSessionResponse() WorkSessionResponse {
+	return WorkSessionResponse{
+		ID:         s.ID,
+		WorkerID:   s.WorkerID,
+		Date:       s.Date.Format(common.DateLayout),
+		Basis:      s.Basis,
+		Hours:      s.Hours,
+		Quantity:   s.Quantity,
+		Unit:       s.Unit,
+		Rate:       s.Rate,
+		Amount:     s.Amount,
+		ActivityID: s.ActivityID,
+		PlotID:     s.PlotID,
+		PaymentID:  s.PaymentID,
+		Paid:       s.PaymentID != nil,
+		Note:       s.Note,
+		Version:    s.Version,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+// ToWorkSessionsResponse converts a list of sessions and sums them up
+func ToWorkSessionsResponse(sessions []WorkSession) WorkSessionsResponse {
+	response := WorkSessionsResponse{
+		Sessions: make([]WorkSessionResponse, 0, len(sessions)),
+	}
+	for i := range sessions {
+		session := &sessions[i]
+		response.Sessions = append(response.Sessions, session.ToWorkSessionResponse())
+		if session.Hours != nil {
+			response.Hours += *session.Hours
+		}
+		response.Amount += session.Amount
+		if session.PaymentID == nil {
+			response.Unpaid += session.Amount
+		}
+	}
+	response.Hours = roundMoney(response.Hours)
+	response.Amount = roundMoney(response.Amount)
+	response.Unpaid = roundMoney(response.Unpaid)
+	return response
+}
+
+// ToPaymentResponse converts Payment to PaymentResponse
+func (p *Payment) ToPaymentResponse() PaymentResponse {
+	return PaymentResponse{
+		ID:         p.ID,
+		WorkerID:   p.WorkerID,
+		From:       p.PeriodFrom.Format(common.DateLayout),
+		To:         p.PeriodTo.Format(common.DateLayout),
+		Amount:     p.Amount,
+		Sessions:   p.Sessions,
+		PaidAt:     p.PaidAt,
+		ActivityID: p.ActivityID,
+		Note:       p.Note,
+		CreatedAt:  p.CreatedAt,
+	}
+}
+
+// ToPaymentsResponse converts a list of payments
+func ToPaymentsResponse(payments []Payment) []PaymentResponse {
+	response := make([]PaymentResponse, 0, len(payments))
+	for i := range payments {
+		response = append(response, payments[i].ToPaymentResponse())
+	}
+	return response
+}
+
+// BuildPayroll names the payroll lines and sums them up
+func BuildPayroll(lines []PayrollLine, names map[string]string, period *PayPeriod) PayrollResponse {
+	response := PayrollResponse{
+		From:    period.From.Format(common.DateLayout),
+		To:      period.To.Format(common.DateLayout),
+		Workers: make([]PayrollLine, 0, len(lines)),
+	}
+	for _, line := range lines {
+		line.WorkerName = names[line.WorkerID]
+		line.Hours = roundMoney(line.Hours)
+		line.Amount = roundMoney(line.Amount)
+		line.Payable = roundMoney(line.Payable)
+		line.Paid = roundMoney(line.Amount - line.Payable)
+		response.Amount += line.Amount
+		response.Paid += line.Paid
+		response.Payable += line.Payable
+		response.Workers = append(response.Workers, line)
+	}
+	response.Amount = roundMoney(response.Amount)
+	response.Paid = roundMoney(response.Paid)
+	response.Payable = roundMoney(response.Payable)
+	return response
+}
+
+// payslipHeader are the session columns of a payslip
+var payslipHeader = []string{
+	"date", "basis", "hours", "quantity", "unit", "rate", "amount", "activity_id", "plot_id", "paid", "note",
+}
+
+// payslipRows lays out a payslip: the worker and period, one row per session, then the totals
+func payslipRows(worker *Worker, period *PayPeriod, sessions []WorkSession) [][]string {
+	str := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+	number := func(value *float64) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', -1, 64)
+	}
+	money := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	}
+
+	rows := [][]string{
+		{"worker", worker.Name},
+		{"from", period.From.Format(common.DateLayout)},
+		{"to", period.To.Format(common.DateLayout)},
+		{},
+		payslipHeader,
+	}
+	for i := range sessions {
+		s := &sessions[i]
+		rows = append(rows, []string{
+			s.Date.Format(common.DateLayout), s.Basis, number(s.Hours), number(s.Quantity), str(s.Unit),
+			money(s.Rate), money(s.Amount), str(s.ActivityID), str(s.PlotID), strconv.FormatBool(s.PaymentID != nil), str(s.Note),
+		})
+	}
+
+	totals := ToWorkSessionsResponse(sessions)
+	return append(rows,
+		[]string{},
+		[]string{"hours", number(&totals.Hours)},
+		[]string{"amount", money(totals.Amount)},
+		[]string{"paid", money(roundMoney(totals.Amount - totals.Unpaid))},
+		[]string{"payable", money(totals.Unpaid)},
+	)
+}
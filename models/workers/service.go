@@ -0,0 +1,322 @@
+package workers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"plantheon-backend/common"
+	"plantheon-backend/models/activities"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WorkerService handles all database operations for workers
+type WorkerService struct {
+	db *gorm.DB
+}
+
+// NewWorkerService creates a new worker service instance
+func NewWorkerService() *WorkerService {
+	return &WorkerService{
+		db: common.GetDB(),
+	}
+}
+
+// CreateWorkerRecord creates a new worker
+func CreateWorkerRecord(worker *Worker) error {
+	service := NewWorkerService()
+	return service.db.Create(worker).Error
+}
+
+// GetWorkerByID finds worker by ID
+func GetWorkerByID(id string) (*Worker, error) {
+	service := NewWorkerService()
+	var worker Worker
+	err := service.db.Where("id = ?", id).First(&worker).Error
+	return &worker, err
+}
+
+// GetUserWorker finds a worker of the user's farm
+func GetUserWorker(id, userID string) (*Worker, error) {
+	service := NewWorkerService()
+	var worker Worker
+	err := service.db.Where("id = ? AND user_id = ?", id, userID).First(&worker).Error
+	return &worker, err
+}
+
+// GetWorkersByUser lists the workers of the user's farm by name, optionally filtered by name or phone
+func GetWorkersByUser(userID, search string) ([]Worker, error) {
+	service := NewWorkerService()
+	var workers []Worker
+
+	query := service.db.Where("user_id = ?", userID)
+	if search != "" {
+		searchQuery := "%" + search + "%"
+		query = query.Where("name ILIKE ? OR phone ILIKE ?", searchQuery, searchQuery)
+	}
+
+	err := query.Order("name ASC").Find(&workers).Error
+	return workers, err
+}
+
+// LinkedUserTaken checks if another worker of the farm is already linked to the account
+func LinkedUserTaken(userID, linkedUserID, excludeID string) (bool, error) {
+	service := NewWorkerService()
+	var count int64
+	query := service.db.Model(&Worker{}).Where("user_id = ? AND linked_user_id = ?", userID, linkedUserID)
+	if excludeID != "" {
+		query = query.Where("id <> ?", excludeID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateWorker updates worker information.
+// The update only applies if the stored version still equals worker.Version,
+// otherwise common.ErrVersionConflict is returned. On success the version is incremented.
+func UpdateWorker(worker *Worker) error {
+	service := NewWorkerService()
+	expected := worker.Version
+	worker.Version = expected + 1
+
+	result := service.db.Model(worker).
+		Where("version = ?", expected).
+		Select("*").Omit("id", "created_at").
+		Updates(worker)
+	if result.Error != nil {
+		worker.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		worker.Version = expected
+		return common.ErrVersionConflict
+	}
+	return nil
+}
+
+// DeleteWorker soft-deletes worker by ID and unassigns them from their tasks
+func DeleteWorker(id string) error {
+	service := NewWorkerService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM activity_assignees WHERE worker_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&Worker{}).Error
+	})
+}
+
+// ErrNothingToPay is returned when a worker has no unpaid session in the pay period
+var ErrNothingToPay = errors.New("no unpaid work sessions in this period")
+
+// GetWorkerNames maps the IDs of the farm's workers to their names, deleted workers included
+func GetWorkerNames(userID string) (map[string]string, error) {
+	service := NewWorkerService()
+	var workers []Worker
+	if err := service.db.Unscoped().Select("id", "name").Where("user_id = ?", userID).Find(&workers).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(workers))
+	for _, worker := range workers {
+		names[worker.ID] = worker.Name
+	}
+	return names, nil
+}
+
+// CreateWorkSessionRecord creates a new work session
+func CreateWorkSessionRecord(session *WorkSession) error {
+	service := NewWorkerService()
+	return service.db.Create(session).Error
+}
+
+// GetWorkSessionByID finds work session by ID
+func GetWorkSessionByID(id string) (*WorkSession, error) {
+	service := NewWorkerService()
+	var session WorkSession
+	err := service.db.Where("id = ?", id).First(&session).Error
+	return &session, err
+}
+
+// GetWorkerSession finds a session of the worker
+func GetWorkerSession(id, workerID string) (*WorkSession, error) {
+	service := NewWorkerService()
+	var session WorkSession
+	err := service.db.Where("id = ? AND worker_id = ?", id, workerID).First(&session).Error
+	return &session, err
+}
+
+// GetWorkSessions lists the sessions of the worker by date, optionally in a pay period or unpaid only
+func GetWorkSessions(workerID string, period *PayPeriod, unpaid bool) ([]WorkSession, error) {
+	service := NewWorkerService()
+	var sessions []WorkSession
+
+	query := service.db.Where("worker_id = ?", workerID)
+	if period != nil {
+		query = inPeriod(query, period)
+	}
+	if unpaid {
+		query = query.Where("payment_id IS NULL")
+	}
+
+	err := query.Order("date ASC, created_at ASC").Find(&sessions).Error
+	return sessions, err
+}
+
+// inPeriod keeps the sessions of the pay period
+func inPeriod(query *gorm.DB, period *PayPeriod) *gorm.DB {
+	return query.Where("date >= ? AND date < ?", period.From, period.End())
+}
+
+// UpdateWorkSession updates an unpaid work session.
+// The update only applies if the stored version still equals session.Version,
+// otherwise common.ErrVersionConflict is returned. On success the version is incremented.
+func UpdateWorkSession(session *WorkSession) error {
+	service := NewWorkerService()
+	expected := session.Version
+	session.Version = expected + 1
+
+	result := service.db.Model(session).
+		Where("version = ? AND payment_id IS NULL", expected).
+		Select("*").Omit("id", "created_at").
+		Updates(session)
+	if result.Error != nil {
+		session.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		session.Version = expected
+		return common.ErrVersionConflict
+	}
+	return nil
+}
+
+// DeleteWorkSession soft-deletes an unpaid work session, returning false if it was paid meanwhile
+func DeleteWorkSession(id string) (bool, error) {
+	service := NewWorkerService()
+	result := service.db.Where("id = ? AND payment_id IS NULL", id).Delete(&WorkSession{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetPayrollLines sums up the sessions of the farm's workers in the pay period
+func GetPayrollLines(userID string, period *PayPeriod) ([]PayrollLine, error) {
+	service := NewWorkerService()
+	var lines []PayrollLine
+	err := inPeriod(service.db.Model(&WorkSession{}), period).
+		Select("worker_id, COUNT(*) AS sessions, COALESCE(SUM(hours), 0) AS hours, COALESCE(SUM(amount), 0) AS amount, "+
+			"COALESCE(SUM(CASE WHEN payment_id IS NULL THEN amount ELSE 0 END), 0) AS payable").
+		Where("user_id = ?", userID).
+		Group("worker_id").
+		Scan(&lines).Error
+	return lines, err
+}
+
+// PayWorker pays the unpaid sessions of the worker in the pay period. The payment is recorded
+// in the ledger as a done labor activity and the sessions are marked paid, all in one transaction.
+func PayWorker(worker *Worker, period *PayPeriod, paidAt time.Time, note *string) (*Payment, error) {
+	service := NewWorkerService()
+	var payment *Payment
+	err := service.db.Transaction(func(tx *gorm.DB) error {
+		var sessions []WorkSession
+		err := inPeriod(tx.Clauses(clause.Locking{Strength: "UPDATE"}), period).
+			Where("worker_id = ? AND payment_id IS NULL", worker.ID).
+			Find(&sessions).Error
+		if err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			return ErrNothingToPay
+		}
+
+		ids := make([]string, 0, len(sessions))
+		amount := 0.0
+		for _, session := range sessions {
+			ids = append(ids, session.ID)
+			amount += session.Amount
+		}
+		amount = roundMoney(amount)
+
+		activity := &activities.Activity{
+			UserID:       &worker.UserID,
+			PlotID:       sharedPlot(sessions),
+			TimeStart:    &paidAt,
+			Money:        &amount,
+			Type:         activities.TypeLabor,
+			Title:        fmt.Sprintf("Tiền công %s (%s - %s)", worker.Name, period.From.Format(common.DateLayout), period.To.Format(common.DateLayout)),
+			TargetPerson: &worker.Name,
+			Note:         note,
+			Status:       activities.StatusDone,
+			CompletedAt:  &paidAt,
+		}
+		if err := tx.Create(activity).Error; err != nil {
+			return err
+		}
+
+		payment = &Payment{
+			UserID:     worker.UserID,
+			WorkerID:   worker.ID,
+			PeriodFrom: period.From,
+			PeriodTo:   period.To,
+			Amount:     amount,
+			Sessions:   len(sessions),
+			PaidAt:     paidAt,
+			ActivityID: activity.ID,
+			Note:       note,
+		}
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+		return setSessionsPayment(tx.Where("id IN ?", ids), payment.ID)
+	})
+	return payment, err
+}
+
+// sharedPlot returns the plot of the sessions if they all have the same one
+func sharedPlot(sessions []WorkSession) *string {
+	plotID := sessions[0].PlotID
+	for _, session := range sessions[1:] {
+		if plotID == nil || session.PlotID == nil || *session.PlotID != *plotID {
+			return nil
+		}
+	}
+	return plotID
+}
+
+// setSessionsPayment marks the matching sessions paid by the payment, or unpaid for nil
+func setSessionsPayment(query *gorm.DB, paymentID interface{}) error {
+	return query.Model(&WorkSession{}).Updates(map[string]interface{}{
+		"payment_id": paymentID,
+		"version":    gorm.Expr("version + 1"),
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// GetWorkerPayments lists the payments of the worker, newest first
+func GetWorkerPayments(workerID string) ([]Payment, error) {
+	service := NewWorkerService()
+	var payments []Payment
+	err := service.db.Where("worker_id = ?", workerID).Order("paid_at DESC").Find(&payments).Error
+	return payments, err
+}
+
+// GetWorkerPayment finds a payment of the worker
+func GetWorkerPayment(id, workerID string) (*Payment, error) {
+	service := NewWorkerService()
+	var payment Payment
+	err := service.db.Where("id = ? AND worker_id = ?", id, workerID).First(&payment).Error
+	return &payment, err
+}
+
+// CancelPayment undoes a payment: its sessions become unpaid again and its ledger activity is deleted
+func CancelPayment(payment *Payment) error {
+	service := NewWorkerService()
+	return service.db.Transaction(func(tx *gorm.DB) error {
+		if err := setSessionsPayment(tx.Where("payment_id = ?", payment.ID), nil); err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", payment.ActivityID).Delete(&activities.Activity{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", payment.ID).Delete(&Payment{}).Error
+	})
+}
@@ -0,0 +1,242 @@
+package workers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"plantheon-backend/common"
+
+	"github.com/google/uuid"
+)
+
+// ValidateCreateWorkerRequest validates worker creation request
+func ValidateCreateWorkerRequest(req *CreateWorkerRequest) error {
+	if err := validateWorkerName(&req.Name); err != nil {
+		return err
+	}
+	if req.LinkedUserID != nil && *req.LinkedUserID == "" {
+		req.LinkedUserID = nil
+	}
+	if err := validateWorkerFields(req.Phone, req.LinkedUserID, req.Note); err != nil {
+		return err
+	}
+	return validateWorkerRates(req.HourlyRate, req.PieceRate)
+}
+
+// ValidateUpdateWorkerRequest validates worker update request
+func ValidateUpdateWorkerRequest(req *UpdateWorkerRequest) error {
+	if req.Name != nil {
+		if err := validateWorkerName(req.Name); err != nil {
+			return err
+		}
+	}
+	linkedUserID := req.LinkedUserID
+	if linkedUserID != nil && *linkedUserID == "" {
+		linkedUserID = nil
+	}
+	if err := validateWorkerFields(req.Phone, linkedUserID, req.Note); err != nil {
+		return err
+	}
+	return validateWorkerRates(req.HourlyRate, req.PieceRate)
+}
+
+// validateWorkerName trims the name and checks its length
+func validateWorkerName(name *string) error {
+	*name = strings.TrimSpace(*name)
+	if *name == "" {
+		return errors.New("worker name is required")
+	}
+	if len(*name) > 255 {
+		return errors.New("worker name must be less than 255 characters")
+	}
+	return nil
+}
+
+// validateWorkerFields validates the optional fields shared by create and update
+func validateWorkerFields(phone, linkedUserID, note *string) error {
+	if phone != nil && len(*phone) > 50 {
+		return errors.New("phone must be less than 50 characters")
+	}
+	if linkedUserID != nil {
+		if _, err := uuid.Parse(*linkedUserID); err != nil {
+			return errors.New("linked_user_id must be a valid UUID")
+		}
+	}
+	if note != nil && len(*note) > 1000 {
+		return errors.New("note must be less than 1000 characters")
+	}
+	return nil
+}
+
+// validateWorkerRates checks the default rates are not negative
+func validateWorkerRates(hourlyRate, pieceRate *float64) error {
+	if hourlyRate != nil && *hourlyRate < 0 {
+		return errors.New("hourly_rate must not be negative")
+	}
+	if pieceRate != nil && *pieceRate < 0 {
+		return errors.New("piece_rate must not be negative")
+	}
+	return nil
+}
+
+// ValidateCreateWorkSessionRequest validates work session creation request
+func ValidateCreateWorkSessionRequest(req *CreateWorkSessionRequest) error {
+	if _, err := parseSessionDate(req.Date); err != nil {
+		return err
+	}
+	if req.ActivityID != nil && *req.ActivityID == "" {
+		req.ActivityID = nil
+	}
+	if req.PlotID != nil && *req.PlotID == "" {
+		req.PlotID = nil
+	}
+	if err := validateSessionLinks(req.ActivityID, req.PlotID); err != nil {
+		return err
+	}
+	if err := validateSessionFields(&req.Basis, req.Hours, req.Quantity, req.Unit, req.Rate, req.Note); err != nil {
+		return err
+	}
+	if req.Basis == "" {
+		return errors.New("basis must be hourly or piece")
+	}
+	return nil
+}
+
+// ValidateUpdateWorkSessionRequest validates work session update request
+func ValidateUpdateWorkSessionRequest(req *UpdateWorkSessionRequest) error {
+	if req.Date != nil {
+		if _, err := parseSessionDate(*req.Date); err != nil {
+			return err
+		}
+	}
+	if err := validateSessionLinks(emptyToNil(req.ActivityID), emptyToNil(req.PlotID)); err != nil {
+		return err
+	}
+	if req.Basis != nil && strings.TrimSpace(*req.Basis) == "" {
+		return errors.New("basis must be hourly or piece")
+	}
+	basis := ""
+	if req.Basis != nil {
+		basis = *req.Basis
+	}
+	if err := validateSessionFields(&basis, req.Hours, req.Quantity, req.Unit, req.Rate, req.Note); err != nil {
+		return err
+	}
+	if req.Basis != nil {
+		req.Basis = &basis
+	}
+	return nil
+}
+
+// ValidateWorkSession checks the stored fields of a session agree with its basis,
+// it is run after a create or update request is applied
+func ValidateWorkSession(session *WorkSession) error {
+	switch session.Basis {
+	case BasisHourly:
+		if session.Hours == nil {
+			return errors.New("hours is required for hourly sessions")
+		}
+		if session.Quantity != nil {
+			return errors.New("quantity is only allowed for piece-rate sessions")
+		}
+	case BasisPiece:
+		if session.Quantity == nil {
+			return errors.New("quantity is required for piece-rate sessions")
+		}
+		if session.Hours != nil {
+			return errors.New("hours is only allowed for hourly sessions")
+		}
+	}
+	return nil
+}
+
+// validateSessionFields validates the fields shared by create and update, an empty basis is not checked
+func validateSessionFields(basis *string, hours, quantity *float64, unit *string, rate *float64, note *string) error {
+	*basis = strings.TrimSpace(*basis)
+	if *basis != "" && *basis != BasisHourly && *basis != BasisPiece {
+		return errors.New("basis must be hourly or piece")
+	}
+	if hours != nil && (*hours <= 0 || *hours > 24) {
+		return errors.New("hours must be greater than 0 and at most 24")
+	}
+	if quantity != nil && *quantity <= 0 {
+		return errors.New("quantity must be greater than 0")
+	}
+	if unit != nil && len(*unit) > 50 {
+		return errors.New("unit must be less than 50 characters")
+	}
+	if rate != nil && *rate < 0 {
+		return errors.New("rate must not be negative")
+	}
+	if note != nil && len(*note) > 1000 {
+		return errors.New("note must be less than 1000 characters")
+	}
+	return nil
+}
+
+// validateSessionLinks checks the linked IDs are UUIDs
+func validateSessionLinks(activityID, plotID *string) error {
+	if activityID != nil {
+		if _, err := uuid.Parse(*activityID); err != nil {
+			return errors.New("activity_id must be a valid UUID")
+		}
+	}
+	if plotID != nil {
+		if _, err := uuid.Parse(*plotID); err != nil {
+			return errors.New("plot_id must be a valid UUID")
+		}
+	}
+	return nil
+}
+
+// ValidateCreatePaymentRequest validates worker payment request
+func ValidateCreatePaymentRequest(req *CreatePaymentRequest) (*PayPeriod, error) {
+	period, err := ParsePayPeriod(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	if req.Note != nil && len(*req.Note) > 1000 {
+		return nil, errors.New("note must be less than 1000 characters")
+	}
+	return period, nil
+}
+
+// ParsePayPeriod parses a pay period of whole days, to is the last day of the period
+func ParsePayPeriod(fromStr, toStr string) (*PayPeriod, error) {
+	if fromStr == "" || toStr == "" {
+		return nil, errors.New("from and to are required")
+	}
+	from, err := time.Parse(common.DateLayout, fromStr)
+	if err != nil {
+		return nil, errors.New("invalid from: expected YYYY-MM-DD")
+	}
+	to, err := time.Parse(common.DateLayout, toStr)
+	if err != nil {
+		return nil, errors.New("invalid to: expected YYYY-MM-DD")
+	}
+	if to.Before(from) {
+		return nil, errors.New("to must not be before from")
+	}
+	if to.After(from.AddDate(1, 0, 0)) {
+		return nil, errors.New("pay period must not be longer than a year")
+	}
+	return &PayPeriod{From: from, To: to}, nil
+}
+
+// parseSessionDate parses the day of a work session
+func parseSessionDate(value string) (time.Time, error) {
+	date, err := time.Parse(common.DateLayout, value)
+	if err != nil {
+		return time.Time{}, errors.New("invalid date: expected YYYY-MM-DD")
+	}
+	return date, nil
+}
+
+// emptyToNil treats an empty string as absent
+func emptyToNil(value *string) *string {
+	if value != nil && *value == "" {
+		return nil
+	}
+	return value
+}